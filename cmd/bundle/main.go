@@ -27,6 +27,9 @@ type settings struct {
 	prune                     bool
 	target                    string
 	secretPath                string
+	registryCertsDirectory    string
+	registryMirrors           []string
+	registryDeleteProviders   []string
 	resultFileImageDigest     string
 	resultFileSourceTimestamp string
 	showListing               bool
@@ -45,7 +48,10 @@ func init() {
 	pflag.StringVar(&flagValues.resultFileSourceTimestamp, "result-file-source-timestamp", "", "A file to write the source timestamp")
 
 	pflag.StringVar(&flagValues.secretPath, "secret-path", "", "A directory that contains access credentials (optional)")
+	pflag.StringVar(&flagValues.registryCertsDirectory, "registry-certificates-directory", "", "A directory that contains a directory with the CA bundle and client certificate per registry host (optional)")
+	pflag.StringArrayVar(&flagValues.registryMirrors, "registry-mirror", nil, "A registry mirror rule in the format prefix=mirror,mirror[;mirror-by-digest-only], the mirrors are tried in order before the registry of the image (optional)")
 	pflag.BoolVar(&flagValues.prune, "prune", false, "Delete bundle image from registry after it was pulled")
+	pflag.StringArrayVar(&flagValues.registryDeleteProviders, "registry-delete-provider", nil, "A registry delete provider in the format host=provider,endpoint to prune with the API of Quay, Harbor or GitLab (optional)")
 	pflag.BoolVar(&flagValues.showListing, "show-listing", false, "Print file listing of files unpacked from the bundle")
 }
 
@@ -85,13 +91,47 @@ func Do(ctx context.Context) error {
 		return err
 	}
 
-	options, auth, err := image.GetOptions(ctx, ref, true, flagValues.secretPath, "Shipwright Build")
+	options, auth, err := image.GetOptions(ctx, ref, true, flagValues.secretPath, flagValues.registryCertsDirectory, "Shipwright Build")
 	if err != nil {
 		return err
 	}
 
+	registryMirrorRules, err := image.ParseRegistryMirrorRules(flagValues.registryMirrors)
+	if err != nil {
+		return err
+	}
+
+	deleteProviders, err := image.ParseDeleteProviderRules(flagValues.registryDeleteProviders)
+	if err != nil {
+		return err
+	}
+
+	// the mirrors of a rule that mirrors by digest only serve digest references, so that a tag is resolved
+	// against the registry of the image first
+	var resolvedRef name.Reference = ref
+	if _, isDigest := ref.(name.Digest); !isDigest && image.MirrorsByDigestOnly(ref, registryMirrorRules) {
+		if digest, err := image.ResolveDigest(ref, options); err != nil {
+			log.Printf("Pulling image %q without mirrors: %v", ref, err)
+		} else {
+			resolvedRef = ref.Context().Digest(digest)
+		}
+	}
+
 	log.Printf("Pulling image %q", ref)
-	desc, err := remote.Get(ref, options...)
+	var desc *remote.Descriptor
+	err = image.PullWithMirrors(resolvedRef, registryMirrorRules, func(pullRef name.Reference) error {
+		pullOptions := options
+		if pullRef != resolvedRef {
+			mirrorOptions, err := image.GetMirrorOptions(ctx, pullRef, true, flagValues.secretPath, flagValues.registryCertsDirectory, "Shipwright Build")
+			if err != nil {
+				return err
+			}
+			pullOptions = mirrorOptions
+		}
+
+		desc, err = remote.Get(pullRef, pullOptions...)
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -147,7 +187,7 @@ func Do(ctx context.Context) error {
 		}
 
 		log.Printf("Deleting image %q", ref)
-		if err := image.Delete(ref, options, *auth); err != nil {
+		if err := image.Delete(ref, options, *auth, deleteProviders...); err != nil {
 			return err
 		}
 	}
@@ -167,7 +167,7 @@ var _ = Describe("Bundle Loader", func() {
 		var dockerConfigFile string
 
 		var copyImage = func(src, dst name.Reference) {
-			options, _, err := image.GetOptions(context.TODO(), src, true, dockerConfigFile, "test-agent")
+			options, _, err := image.GetOptions(context.TODO(), src, true, dockerConfigFile, "", "test-agent")
 			Expect(err).ToNot(HaveOccurred())
 
 			srcDesc, err := remote.Get(src, options...)
@@ -176,7 +176,7 @@ var _ = Describe("Bundle Loader", func() {
 			srcImage, err := srcDesc.Image()
 			Expect(err).ToNot(HaveOccurred())
 
-			options, _, err = image.GetOptions(context.TODO(), dst, true, dockerConfigFile, "test-agent")
+			options, _, err = image.GetOptions(context.TODO(), dst, true, dockerConfigFile, "", "test-agent")
 			Expect(err).ToNot(HaveOccurred())
 
 			err = remote.Write(dst, srcImage, options...)
@@ -214,7 +214,7 @@ var _ = Describe("Bundle Loader", func() {
 				ref, err := name.ParseReference(testImage)
 				Expect(err).ToNot(HaveOccurred())
 
-				options, auth, err := image.GetOptions(context.TODO(), ref, true, dockerConfigFile, "test-agent")
+				options, auth, err := image.GetOptions(context.TODO(), ref, true, dockerConfigFile, "", "test-agent")
 				Expect(err).ToNot(HaveOccurred())
 
 				// Delete test image (best effort)
@@ -248,7 +248,7 @@ var _ = Describe("Bundle Loader", func() {
 				ref, err := name.ParseReference(testImage)
 				Expect(err).ToNot(HaveOccurred())
 
-				options, _, err := image.GetOptions(context.TODO(), ref, true, dockerConfigFile, "test-agent")
+				options, _, err := image.GetOptions(context.TODO(), ref, true, dockerConfigFile, "", "test-agent")
 				Expect(err).ToNot(HaveOccurred())
 
 				_, err = remote.Head(ref, options...)
@@ -9,17 +9,23 @@ package main
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
 	containerreg "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
 	"github.com/shipwright-io/build/pkg/image"
 	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
@@ -42,21 +48,70 @@ type settings struct {
 	help bool
 	push string
 	annotation,
-	label []string
-	insecure bool
+	indexAnnotation,
+	platform,
+	label,
+	additionalTag,
+	mirror,
+	registryMirror,
+	templateValueFile []string
+	insecure,
+	provenance bool
+	provenanceResultFile []string
+	vexFile,
+	vexImage []string
 	image,
 	imageTimestamp,
 	imageTimestampFile,
+	provenanceInput,
 	resultFileImageDigest,
 	resultFileImageSize,
+	resultFileImageReferences,
 	resultFileImageVulnerabilities,
-	secretPath string
-	vulnerabilitySettings   resources.VulnerablilityScanParams
-	vulnerabilityCountLimit int
+	resultFileImageSuppressedVulnerabilities,
+	resultFileImageSBOMDigest,
+	resultFileImageVulnerabilityDBUpdatedAt,
+	resultFileImageVulnerabilitySummary,
+	resultFileImageVulnerabilityReportDigest,
+	resultFileImageVulnerabilityReportOmitted,
+	resultFileImageVulnerabilityReport,
+	resultFileImageLicenseViolations,
+	resultFileImagePlatforms,
+	resultFileImageTruncatedResults,
+	exportFormat,
+	exportDirectory,
+	layerFormat,
+	sbomFormat,
+	secretPath,
+	registryCertificatesDirectory,
+	signingKeyPath,
+	signatureStorage,
+	vulnerabilityDBImage,
+	vulnerabilityDBPath string
+	vulnerabilityDBMaxAge            time.Duration
+	pushRetryBackoff                 time.Duration
+	vulnerabilitySettings            resources.VulnerablilityScanParams
+	licensePolicy                    resources.LicensePolicyParams
+	imageConfig                      resources.ImageConfigParams
+	ociAnnotations                   bool
+	ociAnnotationSource              string
+	ociAnnotationRevisionFile        string
+	ociAnnotationBaseImage           string
+	vulnerabilityCountLimit          int
+	vulnerabilityReportMaxResultSize int
+	listMaxResultSize                int
+	pushRetries                      int
+	pushConcurrency                  int
 }
 
 var flagValues settings
 
+// invalidTagCharacters matches the characters that are not allowed in a tag
+var invalidTagCharacters = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// invalidRepositoryCharacters matches the characters that are not allowed in a path component of a repository
+var invalidRepositoryCharacters = regexp.MustCompile(`[^a-z0-9_.-]`)
+
 func initializeFlag() {
 	// Explicitly define the help flag so that --help can be invoked and returns status code 0
 	pflag.BoolVar(&flagValues.help, "help", false, "Print the help")
@@ -66,20 +121,68 @@ func initializeFlag() {
 	pflag.StringVar(&flagValues.image, "image", "", "The name of image in container registry")
 	pflag.StringVar(&flagValues.secretPath, "secret-path", "", "A directory that contains access credentials (optional)")
 	pflag.BoolVar(&flagValues.insecure, "insecure", false, "Flag indicating the the container registry is insecure")
+	pflag.StringVar(&flagValues.registryCertificatesDirectory, "registry-certificates-directory", "", "A directory that contains a directory with the CA bundle and client certificate per registry host (optional)")
+	pflag.StringArrayVar(&flagValues.registryMirror, "registry-mirror", nil, "A registry mirror rule in the format prefix=mirror,mirror[;mirror-by-digest-only], the mirrors are tried in order before the registry of the image (optional)")
 
 	pflag.StringVar(&flagValues.push, "push", "", "Push the image contained in this directory")
 
 	pflag.StringArrayVar(&flagValues.annotation, "annotation", nil, "New annotations to add")
 	pflag.StringArrayVar(&flagValues.label, "label", nil, "New labels to add")
+	pflag.StringArrayVar(&flagValues.indexAnnotation, "index-annotation", nil, "New annotations to add to the image index only")
+	pflag.StringArrayVar(&flagValues.platform, "platform", nil, "A platform in the format os/architecture[/variant] to keep in the image index, the manifests of other platforms are removed")
+	pflag.BoolVar(&flagValues.ociAnnotations, "oci-annotations", false, "Add the standard OCI annotations for the source, revision, creation time, reference name and base image")
+	pflag.StringVar(&flagValues.ociAnnotationSource, "oci-annotation-source", "", "The URL of the source for the standard OCI annotations")
+	pflag.StringVar(&flagValues.ociAnnotationRevisionFile, "oci-annotation-revision-file", "", "A file that contains the revision of the source for the standard OCI annotations")
+	pflag.StringVar(&flagValues.ociAnnotationBaseImage, "oci-annotation-base-image", "", "The base image for the standard OCI annotations, its digest is looked up if it is not a reference by digest")
+	pflag.Var(&flagValues.imageConfig, "image-config", "Image configuration json string with the environment variables, entrypoint, command, user, exposed ports, working directory and stop signal to set")
 
 	pflag.StringVar(&flagValues.imageTimestamp, "image-timestamp", "", "number to use as Unix timestamp to set image creation timestamp")
 	pflag.StringVar(&flagValues.imageTimestampFile, "image-timestamp-file", "", "path to a file containing a unix timestamp to set as the image timestamp")
 
 	pflag.StringVar(&flagValues.resultFileImageDigest, "result-file-image-digest", "", "A file to write the image digest to")
 	pflag.StringVar(&flagValues.resultFileImageSize, "result-file-image-size", "", "A file to write the image size to")
+	pflag.StringVar(&flagValues.resultFileImageReferences, "result-file-image-references", "", "A file to write all references the image was pushed to")
+	pflag.StringVar(&flagValues.exportFormat, "export-format", "", "Write the image to the export directory in this format (OCILayout or Tarball) instead of pushing it to the registry")
+	pflag.StringVar(&flagValues.exportDirectory, "export-directory", "", "The directory to write the image to if an export format is set")
+	pflag.IntVar(&flagValues.pushRetries, "push-retries", 5, "The number of times that a failed layer upload is retried")
+	pflag.DurationVar(&flagValues.pushRetryBackoff, "push-retry-backoff", time.Second, "The time to wait before the first retry of a failed layer upload, it is doubled for each further retry")
+	pflag.IntVar(&flagValues.pushConcurrency, "push-concurrency", 4, "The number of layers that are uploaded in parallel")
+	pflag.StringVar(&flagValues.layerFormat, "layer-format", "", "Convert the layers of the image to this format (gzip, zstd or estargz) before it is pushed")
+	pflag.StringVar(&flagValues.resultFileImagePlatforms, "result-file-image-platforms", "", "A file to write the digests of the images per platform to if the image is an image index")
+
+	pflag.StringArrayVar(&flagValues.additionalTag, "additional-tag", nil, "An additional tag to add to the pushed image in its repository")
+	pflag.StringArrayVar(&flagValues.mirror, "mirror", nil, "An image reference to copy the pushed image to, optionally followed by =<directory> with its access credentials")
+	pflag.StringArrayVar(&flagValues.templateValueFile, "template-value-file", nil, "A placeholder and the file that contains its value, in the format <placeholder>=<file>, to resolve $(<placeholder>) in the additional tags and the mirrors")
 	pflag.StringVar(&flagValues.resultFileImageVulnerabilities, "result-file-image-vulnerabilities", "", "A file to write the image vulnerabilities to")
 	pflag.Var(&flagValues.vulnerabilitySettings, "vuln-settings", "Vulnerability settings json string. One can enable the scan by setting {\"enabled\":true} to this option")
 	pflag.IntVar(&flagValues.vulnerabilityCountLimit, "vuln-count-limit", 50, "vulnerability count limit for the output of vulnerability scan")
+	pflag.StringVar(&flagValues.vulnerabilityDBPath, "vuln-db-path", "", "A cache directory with a pre-populated trivy vulnerability database, and a grype database in its grype directory, to scan offline")
+	pflag.StringVar(&flagValues.vulnerabilityDBImage, "vuln-db-image", "", "A trivy vulnerability database artifact to pull from a registry to scan offline")
+	pflag.DurationVar(&flagValues.vulnerabilityDBMaxAge, "vuln-db-max-age", 0, "The maximum age of a pre-populated vulnerability database, older databases fail the scan (optional)")
+	pflag.StringVar(&flagValues.resultFileImageVulnerabilityDBUpdatedAt, "result-file-image-vulnerability-db-updated-at", "", "A file to write the update timestamp of a pre-populated vulnerability database to")
+	pflag.StringArrayVar(&flagValues.vexFile, "vuln-vex-file", nil, "A file with an OpenVEX document whose not_affected statements suppress vulnerabilities")
+	pflag.StringArrayVar(&flagValues.vexImage, "vuln-vex-image", nil, "An OCI artifact with an OpenVEX document whose not_affected statements suppress vulnerabilities")
+	pflag.StringVar(&flagValues.resultFileImageSuppressedVulnerabilities, "result-file-image-suppressed-vulnerabilities", "", "A file to write the vulnerabilities to that were suppressed by a VEX statement")
+	pflag.StringVar(&flagValues.resultFileImageVulnerabilitySummary, "result-file-image-vulnerability-summary", "", "A file to write the number of vulnerabilities per severity to")
+	pflag.StringVar(&flagValues.resultFileImageVulnerabilityReportDigest, "result-file-image-vulnerability-report-digest", "", "A file to write the digest of the vulnerability report that is attached to the image to")
+	pflag.StringVar(&flagValues.resultFileImageVulnerabilityReportOmitted, "result-file-image-vulnerability-report-omitted", "", "A file to write why the vulnerability report was not written to its result file to")
+	pflag.StringVar(&flagValues.resultFileImageVulnerabilityReport, "result-file-image-vulnerability-report", "", "A file to write the compressed vulnerability report to if the image is not pushed")
+	pflag.IntVar(&flagValues.vulnerabilityReportMaxResultSize, "vuln-report-max-result-size", 1024, "The maximum size of the compressed vulnerability report that is written to its result file")
+
+	pflag.Var(&flagValues.licensePolicy, "license-policy", "License policy json string with the denied and allowed SPDX license identifiers, the licenses of the image are scanned if it is set")
+	pflag.StringVar(&flagValues.resultFileImageLicenseViolations, "result-file-image-license-violations", "", "A file to write the packages with a license that is not allowed by the license policy to")
+	pflag.StringVar(&flagValues.resultFileImageTruncatedResults, "result-file-image-truncated-results", "", "A file to write the names of the results to that were truncated to their maximum size")
+	pflag.IntVar(&flagValues.listMaxResultSize, "list-max-result-size", 512, "The maximum size of the suppressed vulnerabilities and the license violations that are written to their result files")
+
+	pflag.StringVar(&flagValues.sbomFormat, "sbom-format", "", "The format of the software bill of materials to generate and attach to the image (spdx-json or cyclonedx)")
+	pflag.StringVar(&flagValues.signingKeyPath, "signing-key-path", "", "A directory that contains the private key to sign the image (optional)")
+	pflag.StringVar(&flagValues.signatureStorage, "signature-storage", string(buildapi.SignatureStorageTag), "Where to store the signature of the image (Tag or Referrers)")
+
+	pflag.BoolVar(&flagValues.provenance, "provenance", false, "Generate a SLSA provenance attestation for the image, it is signed if a signing key is provided")
+	pflag.StringVar(&flagValues.provenanceInput, "provenance-input", "", "The base64-encoded JSON information about the build to generate the provenance from")
+	pflag.StringArrayVar(&flagValues.provenanceResultFile, "provenance-result-file", nil, "A result name and the file that contains its value, in the format <name>=<file>, that the provenance input refers to")
+
+	pflag.StringVar(&flagValues.resultFileImageSBOMDigest, "result-file-image-sbom-digest", "", "A file to write the digest of the software bill of materials to")
 }
 
 func main() {
@@ -96,6 +199,194 @@ func main() {
 	}
 }
 
+func attachProvenance(subject name.Digest, signingKey *ecdsa.PrivateKey, options []remote.Option) error {
+	inputData, err := base64.StdEncoding.DecodeString(flagValues.provenanceInput)
+	if err != nil {
+		return fmt.Errorf("failed to decode the provenance input: %w", err)
+	}
+
+	var input image.ProvenanceInput
+	if err := json.Unmarshal(inputData, &input); err != nil {
+		return fmt.Errorf("failed to parse the provenance input: %w", err)
+	}
+
+	resultFiles := map[string]string{}
+	for _, resultFile := range flagValues.provenanceResultFile {
+		resultName, file, found := strings.Cut(resultFile, "=")
+		if !found {
+			return fmt.Errorf("invalid provenance result file %q, must be in the format <name>=<file>", resultFile)
+		}
+
+		resultFiles[resultName] = file
+	}
+
+	log.Printf("Generating the provenance for %q\n", subject.String())
+	statement, err := image.GenerateProvenance(input, subject, resultFiles, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	envelope, err := image.NewEnvelope(statement, signingKey)
+	if err != nil {
+		return err
+	}
+
+	if err := image.AttachProvenance(subject, envelope, buildapi.SignatureStorage(flagValues.signatureStorage), options); err != nil {
+		return err
+	}
+
+	log.Printf("Provenance for %s attached\n", subject.String())
+	return nil
+}
+
+// getStandardAnnotations determines the standard OCI annotations from the flags, the revision is read from its
+// file, and the digest of the base image is looked up in its registry
+func getStandardAnnotations(ctx context.Context, imageName name.Reference) (map[string]string, error) {
+	input := image.StandardAnnotationsInput{
+		Source:  flagValues.ociAnnotationSource,
+		Created: time.Now(),
+	}
+
+	if flagValues.imageTimestamp != "" {
+		sec, err := strconv.ParseInt(flagValues.imageTimestamp, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse image timestamp value %q as a number: %w", flagValues.imageTimestamp, err)
+		}
+		input.Created = time.Unix(sec, 0)
+	}
+
+	if tag, ok := imageName.(name.Tag); ok {
+		input.RefName = tag.TagStr()
+	}
+
+	if flagValues.ociAnnotationRevisionFile != "" {
+		data, err := os.ReadFile(flagValues.ociAnnotationRevisionFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the revision from %s: %w", flagValues.ociAnnotationRevisionFile, err)
+		}
+		input.Revision = strings.TrimSpace(string(data))
+	}
+
+	if flagValues.ociAnnotationBaseImage != "" {
+		baseImage, err := name.ParseReference(flagValues.ociAnnotationBaseImage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the base image %q: %w", flagValues.ociAnnotationBaseImage, err)
+		}
+		input.BaseName = baseImage.Name()
+
+		// the base image can be in another registry, an unknown digest is not an error
+		baseOptions, _, err := image.GetOptions(ctx, baseImage, false, flagValues.secretPath, flagValues.registryCertificatesDirectory, "Shipwright Build")
+		if err != nil {
+			return nil, err
+		}
+
+		if input.BaseDigest, err = image.ResolveDigest(baseImage, baseOptions); err != nil {
+			log.Printf("Omitting the digest of the base image: %v\n", err)
+		}
+	}
+
+	return image.StandardAnnotations(input), nil
+}
+
+// loadVEX reads the OpenVEX documents from the files and pulls those in OCI artifacts with the
+// registry options of the output image, nil is returned if there are no documents
+func loadVEX(imageName name.Reference, options []remote.Option) (*image.VEX, error) {
+	if len(flagValues.vexFile) == 0 && len(flagValues.vexImage) == 0 {
+		return nil, nil
+	}
+
+	var documents [][]byte
+	for _, file := range flagValues.vexFile {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the VEX document: %w", err)
+		}
+
+		documents = append(documents, data)
+	}
+
+	for _, vexImage := range flagValues.vexImage {
+		reference, err := name.ParseReference(vexImage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the VEX document reference: %w", err)
+		}
+
+		log.Printf("Pulling the VEX document %q\n", reference.String())
+		data, err := image.PullVEXDocument(reference, options)
+		if err != nil {
+			return nil, err
+		}
+
+		documents = append(documents, data)
+	}
+
+	return image.NewVEX(imageName, documents, time.Now())
+}
+
+// prepareVulnerabilityDB provides the cache directory of a pre-populated vulnerability database, it pulls the
+// database if it is provided as artifact in a registry. The update timestamp of the database is written to the
+// result file and checked against the maximum age. An empty directory is returned if the scanner should download
+// the database itself.
+func prepareVulnerabilityDB(ctx context.Context, scanner buildapi.VulnerabilityScanner) (string, error) {
+	dbCacheDir := flagValues.vulnerabilityDBPath
+
+	if flagValues.vulnerabilityDBImage != "" {
+		// the artifact is a trivy database, grype reads its database only from the volume
+		if scanner == buildapi.VulnerabilityScannerGrype {
+			return "", fmt.Errorf("the vulnerability database %s is a trivy database, %s requires a database in the vulnerability database volume", flagValues.vulnerabilityDBImage, scanner)
+		}
+
+		dbImage, err := name.ParseReference(flagValues.vulnerabilityDBImage)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse the vulnerability database reference: %w", err)
+		}
+
+		options, _, err := image.GetOptions(ctx, dbImage, flagValues.insecure, flagValues.secretPath, flagValues.registryCertificatesDirectory, "Shipwright Build")
+		if err != nil {
+			return "", err
+		}
+
+		dbCacheDir, err = os.MkdirTemp("", "vulnerability-db")
+		if err != nil {
+			return "", err
+		}
+
+		log.Printf("Pulling the vulnerability database %q\n", dbImage.String())
+		if err := image.PullVulnerabilityDB(dbImage, dbCacheDir, options); err != nil {
+			return "", err
+		}
+	}
+
+	if dbCacheDir == "" {
+		return "", nil
+	}
+
+	var updatedAt time.Time
+	var err error
+	if scanner == buildapi.VulnerabilityScannerGrype {
+		updatedAt, err = image.GetGrypeDBUpdatedAt(ctx, dbCacheDir)
+	} else {
+		updatedAt, err = image.GetVulnerabilityDBUpdatedAt(dbCacheDir)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("The vulnerability database was updated at %s\n", updatedAt.Format(time.RFC3339))
+
+	if flagValues.resultFileImageVulnerabilityDBUpdatedAt != "" {
+		if err := os.WriteFile(flagValues.resultFileImageVulnerabilityDBUpdatedAt, []byte(updatedAt.UTC().Format(time.RFC3339)), 0400); err != nil {
+			return "", err
+		}
+	}
+
+	if flagValues.vulnerabilityDBMaxAge > 0 && time.Since(updatedAt) > flagValues.vulnerabilityDBMaxAge {
+		return "", fmt.Errorf("the vulnerability database was updated at %s, it is older than the maximum age of %s", updatedAt.Format(time.RFC3339), flagValues.vulnerabilityDBMaxAge)
+	}
+
+	return dbCacheDir, nil
+}
+
 // Execute performs flag parsing, input validation and the image mutation
 func Execute(ctx context.Context) error {
 	initializeFlag()
@@ -106,12 +397,21 @@ func Execute(ctx context.Context) error {
 		return nil
 	}
 
+	// log which blobs were pushed, and which were skipped because they exist in the registry already
+	logs.Progress.SetOutput(log.Writer())
+
 	// validate that only one of the image timestamp flags are used
 	if flagValues.imageTimestamp != "" && flagValues.imageTimestampFile != "" {
 		pflag.Usage()
 		return fmt.Errorf("image timestamp and image timestamp file flag is used, they are mutually exclusive, only use one")
 	}
 
+	// validate that the provenance input is provided if a provenance should be generated
+	if flagValues.provenance && flagValues.provenanceInput == "" {
+		pflag.Usage()
+		return fmt.Errorf("provenance flag is used without provenance input")
+	}
+
 	// validate that image timestamp file exists (if set), and translate it into the imageTimestamp field
 	if flagValues.imageTimestampFile != "" {
 		_, err := os.Stat(flagValues.imageTimestampFile)
@@ -131,6 +431,11 @@ func Execute(ctx context.Context) error {
 }
 
 func runImageProcessing(ctx context.Context) error {
+	// resolve the placeholders that depend on the source
+	if err := resolveTemplates(); err != nil {
+		return err
+	}
+
 	// parse the image name
 	if flagValues.image == "" {
 		return &ExitError{Code: 100, Message: "the 'image' argument must not be empty"}
@@ -153,10 +458,25 @@ func runImageProcessing(ctx context.Context) error {
 	}
 
 	// prepare the registry options
-	options, auth, err := image.GetOptions(ctx, imageName, flagValues.insecure, flagValues.secretPath, "Shipwright Build")
+	options, auth, err := image.GetOptions(ctx, imageName, flagValues.insecure, flagValues.secretPath, flagValues.registryCertificatesDirectory, "Shipwright Build")
 	if err != nil {
 		return err
 	}
+	options = append(options, image.GetPushOptions(flagValues.pushRetries, flagValues.pushRetryBackoff, flagValues.pushConcurrency)...)
+
+	// add the standard OCI annotations, annotations that are defined explicitly take precedence
+	if flagValues.ociAnnotations {
+		standardAnnotations, err := getStandardAnnotations(ctx, imageName)
+		if err != nil {
+			return err
+		}
+
+		for key, value := range standardAnnotations {
+			if _, exists := annotations[key]; !exists {
+				annotations[key] = value
+			}
+		}
+	}
 
 	// load the image or image index (usually multi-platform image)
 	var img containerreg.Image
@@ -164,7 +484,7 @@ func runImageProcessing(ctx context.Context) error {
 	var isImageFromTar bool
 	if flagValues.push == "" {
 		log.Printf("Loading the image from the registry %q\n", imageName.String())
-		img, imageIndex, err = image.LoadImageOrImageIndexFromRegistry(imageName, options)
+		img, imageIndex, err = loadImageOrImageIndexFromRegistry(ctx, imageName, options)
 	} else {
 		log.Printf("Loading the image from the directory %q\n", flagValues.push)
 		img, imageIndex, isImageFromTar, err = image.LoadImageOrImageIndexFromDirectory(flagValues.push)
@@ -180,43 +500,86 @@ func runImageProcessing(ctx context.Context) error {
 		log.Printf("Loaded image index")
 	}
 
+	// remove the platforms that are not requested
+	if len(flagValues.platform) > 0 {
+		log.Printf("Filtering the image for the platforms %s\n", strings.Join(flagValues.platform, ", "))
+		img, imageIndex, err = image.FilterImageOrImageIndex(img, imageIndex, flagValues.platform)
+		if err != nil {
+			log.Printf("Failed to filter the image: %v\n", err)
+			return err
+		}
+	}
+
 	// mutate the image
-	if len(annotations) > 0 || len(labels) > 0 {
+	if len(annotations) > 0 || len(labels) > 0 || flagValues.imageConfig.ImageConfig != nil {
 		log.Println("Mutating the image")
-		img, imageIndex, err = image.MutateImageOrImageIndex(img, imageIndex, annotations, labels)
+		img, imageIndex, err = image.MutateImageOrImageIndex(img, imageIndex, annotations, labels, flagValues.imageConfig.ImageConfig)
 		if err != nil {
 			log.Printf("Failed to mutate the image: %v\n", err)
 			return err
 		}
 	}
 
+	// annotate the image index
+	if len(flagValues.indexAnnotation) > 0 {
+		indexAnnotations, err := splitKeyVals(flagValues.indexAnnotation)
+		if err != nil {
+			return err
+		}
+
+		if imageIndex != nil {
+			log.Println("Annotating the image index")
+			if imageIndex, err = image.AnnotateImageIndex(imageIndex, indexAnnotations); err != nil {
+				log.Printf("Failed to annotate the image index: %v\n", err)
+				return err
+			}
+		} else {
+			log.Println("Ignoring the index annotations because the image is not an image index")
+		}
+	}
+
 	// check for image vulnerabilities if vulnerability scanning is enabled.
 	var vulns []buildapi.Vulnerability
+	var scanResult *image.VulnerabilityScanResult
 
 	if flagValues.vulnerabilitySettings.Enabled {
-		var imageString string
-		var imageInDir bool
-		if flagValues.push != "" {
-			imageString = flagValues.push
-
-			// for single image in a tar file
-			if isImageFromTar {
-				entries, err := os.ReadDir(flagValues.push)
-				if err != nil {
-					return err
-				}
-				imageString = filepath.Join(imageString, entries[0].Name())
-			}
-			imageInDir = true
-		} else {
-			imageString = imageName.String()
-			imageInDir = false
+		imageString, imageInDir, err := getScanTarget(imageName, isImageFromTar)
+		if err != nil {
+			return err
 		}
-		vulns, err = image.RunVulnerabilityScan(ctx, imageString, flagValues.vulnerabilitySettings.VulnerabilityScanOptions, auth, flagValues.insecure, imageInDir, flagValues.vulnerabilityCountLimit)
+
+		scanner := buildapi.VulnerabilityScannerTrivy
+		if flagValues.vulnerabilitySettings.Scanner != nil {
+			scanner = *flagValues.vulnerabilitySettings.Scanner
+		}
+
+		dbCacheDir, err := prepareVulnerabilityDB(ctx, scanner)
+		if err != nil {
+			log.Printf("the vulnerability database is not usable, exiting with code 23: %v\n", err)
+			return &ExitError{Code: 23, Message: "vulnerability scan failed, exiting with code 23", Cause: err}
+		}
+
+		vex, err := loadVEX(imageName, options)
 		if err != nil {
+			log.Printf("the VEX documents are not usable, exiting with code 23: %v\n", err)
+			return &ExitError{Code: 23, Message: "vulnerability scan failed, exiting with code 23", Cause: err}
+		}
+
+		scanResult, err = image.RunVulnerabilityScan(ctx, imageString, flagValues.vulnerabilitySettings.VulnerabilityScanOptions, auth, flagValues.insecure, imageInDir, dbCacheDir, vex, flagValues.vulnerabilityCountLimit)
+		if err != nil {
+			// failures of the scanner itself are reported with a dedicated exit code, so that they are not
+			// confused with vulnerabilities that were found
+			var scanErr *image.VulnerabilityScanError
+			if errors.As(err, &scanErr) {
+				log.Printf("the vulnerability scan failed, exiting with code 23: %v\n", err)
+				return &ExitError{Code: 23, Message: "vulnerability scan failed, exiting with code 23", Cause: err}
+			}
+
 			return err
 		}
 
+		vulns = scanResult.Vulnerabilities
+
 		// log all the vulnerabilities
 		if len(vulns) > 0 {
 			log.Println("vulnerabilities found in the output image :")
@@ -228,15 +591,92 @@ func runImageProcessing(ctx context.Context) error {
 		if err := os.WriteFile(flagValues.resultFileImageVulnerabilities, vulnOuput, 0640); err != nil {
 			return err
 		}
+
+		// log and write the vulnerabilities that were suppressed by a VEX statement
+		if len(scanResult.Suppressed) > 0 {
+			log.Println("vulnerabilities suppressed by VEX statements :")
+			for _, vuln := range scanResult.Suppressed {
+				log.Printf("ID: %s, Severity: %s, Justification: %s\n", vuln.ID, vuln.Severity, vuln.Justification)
+			}
+		}
+		if flagValues.resultFileImageSuppressedVulnerabilities != "" {
+			data, truncated := image.SerializeSuppressedVulnerabilities(scanResult.Suppressed, flagValues.listMaxResultSize)
+			if err := os.WriteFile(flagValues.resultFileImageSuppressedVulnerabilities, data, 0640); err != nil {
+				return err
+			}
+
+			if truncated {
+				if err := writeTruncatedResult("suppressedVulnerabilities"); err != nil {
+					return err
+				}
+			}
+		}
+
+		if flagValues.resultFileImageVulnerabilitySummary != "" {
+			summary, err := json.Marshal(scanResult.Summary)
+			if err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(flagValues.resultFileImageVulnerabilitySummary, summary, 0640); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Don't push the image if fail is set to true for shipwright managed push
 	if flagValues.push != "" {
 		if flagValues.vulnerabilitySettings.FailOnFinding && len(vulns) > 0 {
+			// the report cannot be attached to an image that is not pushed, the controller stores it in a ConfigMap
+			if err := writeVulnerabilityReportResult(scanResult.Report); err != nil {
+				return err
+			}
+
 			log.Println("vulnerabilities have been found in the output image, exiting with code 22")
 			return &ExitError{Code: 22, Message: "vulnerabilities found, exiting with code 22", Cause: errors.New("vulnerabilities found in the image")}
 		}
 	}
+
+	// check the licenses of the image if a license policy is defined, an image that was pushed by the
+	// build strategy is already in the registry when the build run fails
+	if len(flagValues.licensePolicy.Denied) > 0 || len(flagValues.licensePolicy.Allowed) > 0 {
+		imageString, imageInDir, err := getScanTarget(imageName, isImageFromTar)
+		if err != nil {
+			return err
+		}
+
+		violations, err := image.RunLicenseScan(ctx, imageString, flagValues.licensePolicy.LicensePolicy, auth, flagValues.insecure, imageInDir, flagValues.vulnerabilityCountLimit)
+		if err != nil {
+			return err
+		}
+
+		if flagValues.resultFileImageLicenseViolations != "" {
+			data, truncated, err := image.SerializeLicenseViolations(violations, flagValues.listMaxResultSize)
+			if err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(flagValues.resultFileImageLicenseViolations, data, 0640); err != nil {
+				return err
+			}
+
+			if truncated {
+				if err := writeTruncatedResult("licenseViolations"); err != nil {
+					return err
+				}
+			}
+		}
+
+		if len(violations) > 0 {
+			log.Println("packages with licenses that are not allowed have been found in the output image :")
+			for _, violation := range violations {
+				log.Printf("Package: %s, License: %s\n", violation.Package, violation.License)
+			}
+
+			log.Println("the license policy has been violated, exiting with code 24")
+			return &ExitError{Code: 24, Message: "license policy violated, exiting with code 24", Cause: errors.New("licenses that are not allowed found in the image")}
+		}
+	}
 	// mutate the image timestamp
 	if flagValues.imageTimestamp != "" {
 		sec, err := strconv.ParseInt(flagValues.imageTimestamp, 10, 32)
@@ -251,15 +691,37 @@ func runImageProcessing(ctx context.Context) error {
 		}
 	}
 
-	// push the image and determine the digest and size
-	log.Printf("Pushing the image to registry %q\n", imageName.String())
-	digest, size, err := image.PushImageOrImageIndex(imageName, img, imageIndex, options)
-	if err != nil {
-		log.Printf("Failed to push the image: %v\n", err)
-		return err
+	// convert the layers as the last change, so that the layers of the other changes are converted as well
+	if flagValues.layerFormat != "" {
+		log.Printf("Converting the layers of the image to %s\n", flagValues.layerFormat)
+		img, imageIndex, err = image.ConvertImageOrImageIndexLayers(img, imageIndex, buildapi.LayerFormat(flagValues.layerFormat))
+		if err != nil {
+			return fmt.Errorf("failed to convert the layers: %w", err)
+		}
 	}
 
-	log.Printf("Image %s@%s pushed\n", imageName.String(), digest)
+	// push or export the image and determine the digest and size
+	var digest string
+	var size int64
+	if flagValues.exportFormat != "" {
+		log.Printf("Exporting the image to the directory %q\n", flagValues.exportDirectory)
+		digest, size, err = image.ExportImageOrImageIndex(imageName, img, imageIndex, buildapi.ImageExportFormat(flagValues.exportFormat), flagValues.exportDirectory)
+		if err != nil {
+			log.Printf("Failed to export the image: %v\n", err)
+			return err
+		}
+
+		log.Printf("Image %s@%s exported\n", imageName.String(), digest)
+	} else {
+		log.Printf("Pushing the image to registry %q\n", imageName.String())
+		digest, size, err = image.PushImageOrImageIndex(imageName, img, imageIndex, options)
+		if err != nil {
+			log.Printf("Failed to push the image: %v\n", err)
+			return pushFailed(err)
+		}
+
+		log.Printf("Image %s@%s pushed\n", imageName.String(), digest)
+	}
 
 	// Writing image digest to file
 	if digest != "" && flagValues.resultFileImageDigest != "" {
@@ -275,9 +737,291 @@ func runImageProcessing(ctx context.Context) error {
 		}
 	}
 
+	// Writing the digests of the images per platform to file
+	if imageIndex != nil && flagValues.resultFileImagePlatforms != "" {
+		platformDigests, err := image.GetPlatformDigests(imageIndex)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(platformDigests)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(flagValues.resultFileImagePlatforms, data, 0400); err != nil {
+			return err
+		}
+	}
+
+	// an exported image is not in the registry, so that nothing can be attached to it, the complete
+	// vulnerability report is written next to the image and passed in its result if it is small enough
+	if flagValues.exportFormat != "" {
+		if scanResult != nil {
+			reportFile := filepath.Join(flagValues.exportDirectory, image.ExportVulnerabilityReportFileName)
+			if err := os.WriteFile(reportFile, scanResult.Report, 0644); err != nil {
+				return fmt.Errorf("failed to write the vulnerability report to the export directory: %w", err)
+			}
+
+			return writeVulnerabilityReportResult(scanResult.Report)
+		}
+
+		return nil
+	}
+
+	// sign the pushed image, and for an image index each image of it
+	var signingKey *ecdsa.PrivateKey
+	if flagValues.signingKeyPath != "" {
+		signingKey, err = image.LoadSigningKey(flagValues.signingKeyPath)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Signing the image %q\n", imageName.String())
+		signed, err := image.SignImageOrImageIndex(imageName, img, imageIndex, signingKey, buildapi.SignatureStorage(flagValues.signatureStorage), options)
+		if err != nil {
+			log.Printf("Failed to sign the image: %v\n", err)
+			return err
+		}
+
+		for _, signedDigest := range signed {
+			log.Printf("Image %s@%s signed\n", imageName.Context().String(), signedDigest)
+		}
+	}
+
+	// generate the provenance for the pushed image and attach it, signed if there is a signing key
+	if flagValues.provenance {
+		if err := attachProvenance(imageName.Context().Digest(digest), signingKey, options); err != nil {
+			log.Printf("Failed to attach the provenance: %v\n", err)
+			return err
+		}
+	}
+
+	// generate the software bill of materials for the pushed image and attach it
+	if flagValues.sbomFormat != "" {
+		subject := imageName.Context().Digest(digest)
+
+		log.Printf("Generating the SBOM for %q\n", subject.String())
+		sbom, err := image.GenerateSBOM(ctx, subject, buildapi.SBOMFormat(flagValues.sbomFormat), auth, flagValues.insecure)
+		if err != nil {
+			return err
+		}
+
+		sbomDigest, err := image.AttachSBOM(subject, sbom, buildapi.SBOMFormat(flagValues.sbomFormat), options)
+		if err != nil {
+			log.Printf("Failed to attach the SBOM: %v\n", err)
+			return err
+		}
+
+		log.Printf("SBOM %s@%s attached\n", imageName.Context().String(), sbomDigest)
+
+		if flagValues.resultFileImageSBOMDigest != "" {
+			if err := os.WriteFile(flagValues.resultFileImageSBOMDigest, []byte(sbomDigest), 0400); err != nil {
+				return err
+			}
+		}
+	}
+
+	// attach the complete vulnerability report to the pushed image
+	if scanResult != nil {
+		subject := imageName.Context().Digest(digest)
+
+		reportDigest, err := image.AttachVulnerabilityReport(subject, scanResult.Report, scanResult.Scanner, options)
+		if err != nil {
+			log.Printf("Failed to attach the vulnerability report: %v\n", err)
+			return err
+		}
+
+		log.Printf("Vulnerability report %s@%s attached\n", imageName.Context().String(), reportDigest)
+
+		if flagValues.resultFileImageVulnerabilityReportDigest != "" {
+			if err := os.WriteFile(flagValues.resultFileImageVulnerabilityReportDigest, []byte(reportDigest), 0400); err != nil {
+				return err
+			}
+		}
+	}
+
+	// add the additional tags and copy the image to its mirrors as the last step, so that the mirrors
+	// receive the signatures and all artifacts that are attached to the image
+	if len(flagValues.additionalTag) > 0 || len(flagValues.mirror) > 0 || flagValues.resultFileImageReferences != "" {
+		references, err := tagAndMirror(ctx, imageName.Context().Digest(digest), options)
+		if err != nil {
+			return err
+		}
+
+		if flagValues.resultFileImageReferences != "" {
+			if err := os.WriteFile(flagValues.resultFileImageReferences, []byte(strings.Join(references, ",")), 0400); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
+// resolveTemplates replaces the placeholders in the additional tags and the mirrors with the values from their
+// files. Characters that are not allowed in the part of the reference that contains the placeholder are replaced
+// with a dash, for example the slash of a branch name. In the repository of a mirror, the value is also converted
+// to lowercase.
+func resolveTemplates() error {
+	if len(flagValues.templateValueFile) == 0 {
+		return nil
+	}
+
+	var tagReplacements, repositoryReplacements []string
+	for _, templateValueFile := range flagValues.templateValueFile {
+		placeholder, file, found := strings.Cut(templateValueFile, "=")
+		if !found {
+			return fmt.Errorf("the template value %q is not in the format <placeholder>=<file>", templateValueFile)
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read the value of the placeholder %s from %s: %w", placeholder, file, err)
+		}
+
+		value := strings.TrimSpace(string(data))
+		tagReplacements = append(tagReplacements, fmt.Sprintf("$(%s)", placeholder), invalidTagCharacters.ReplaceAllString(value, "-"))
+		repositoryReplacements = append(repositoryReplacements, fmt.Sprintf("$(%s)", placeholder), strings.Trim(invalidRepositoryCharacters.ReplaceAllString(strings.ToLower(value), "-"), "-._"))
+	}
+
+	tagReplacer := strings.NewReplacer(tagReplacements...)
+	repositoryReplacer := strings.NewReplacer(repositoryReplacements...)
+
+	for i := range flagValues.additionalTag {
+		flagValues.additionalTag[i] = tagReplacer.Replace(flagValues.additionalTag[i])
+	}
+
+	for i, mirror := range flagValues.mirror {
+		mirrorImage, secretPath, hasSecret := strings.Cut(mirror, "=")
+
+		repository, tag := splitTag(mirrorImage)
+		mirrorImage = repositoryReplacer.Replace(repository) + tagReplacer.Replace(tag)
+
+		if hasSecret {
+			mirrorImage = fmt.Sprintf("%s=%s", mirrorImage, secretPath)
+		}
+
+		flagValues.mirror[i] = mirrorImage
+	}
+
+	return nil
+}
+
+// splitTag splits an image reference into its repository and its tag, the tag keeps its leading colon and is
+// empty if the reference has no tag
+func splitTag(reference string) (string, string) {
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		return reference[:i], reference[i:]
+	}
+
+	return reference, ""
+}
+
+// pushFailed returns the error with the exit code that tells failures of the registry apart from failures of the build
+func pushFailed(err error) error {
+	log.Println("the image could not be pushed, exiting with code 25")
+	return &ExitError{Code: 25, Message: "failed to push the image, exiting with code 25", Cause: err}
+}
+
+// tagAndMirror adds the additional tags to the pushed image and copies it to the mirrors together with its
+// signatures and attached artifacts, it returns all references the image was pushed to
+func tagAndMirror(ctx context.Context, subject name.Digest, options []remote.Option) ([]string, error) {
+	references := []string{flagValues.image}
+
+	if len(flagValues.additionalTag) > 0 {
+		tagged, err := image.TagImageOrImageIndex(subject, flagValues.additionalTag, options)
+		if err != nil {
+			log.Printf("Failed to tag the image: %v\n", err)
+			return nil, pushFailed(err)
+		}
+
+		for _, tag := range tagged {
+			log.Printf("Image %s tagged\n", tag.String())
+			references = append(references, tag.String())
+		}
+	}
+
+	for _, mirror := range flagValues.mirror {
+		mirrorImage, secretPath, _ := strings.Cut(mirror, "=")
+
+		mirrorName, err := name.ParseReference(mirrorImage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse mirror name: %w", err)
+		}
+
+		// the mirrors use the insecure setting of the output image
+		mirrorOptions, _, err := image.GetOptions(ctx, mirrorName, flagValues.insecure, secretPath, flagValues.registryCertificatesDirectory, "Shipwright Build")
+		if err != nil {
+			return nil, err
+		}
+		mirrorOptions = append(mirrorOptions, image.GetPushOptions(flagValues.pushRetries, flagValues.pushRetryBackoff, flagValues.pushConcurrency)...)
+
+		log.Printf("Copying the image to the mirror %q\n", mirrorName.String())
+		if err := image.CopyImageOrImageIndex(subject, mirrorName, options, mirrorOptions); err != nil {
+			log.Printf("Failed to copy the image to the mirror: %v\n", err)
+			return nil, pushFailed(err)
+		}
+
+		if err := image.CopyAttachedArtifacts(subject, mirrorName.Context(), options, mirrorOptions); err != nil {
+			log.Printf("Failed to copy the signatures and attached artifacts to the mirror: %v\n", err)
+			return nil, pushFailed(err)
+		}
+
+		references = append(references, mirrorName.String())
+	}
+
+	return references, nil
+}
+
+// getScanTarget returns the image to scan, which is the directory or tar file of the image if it is pushed by
+// image-processing, or otherwise the image in the registry
+func getScanTarget(imageName name.Reference, isImageFromTar bool) (string, bool, error) {
+	if flagValues.push == "" {
+		return imageName.String(), false, nil
+	}
+
+	imageString := flagValues.push
+
+	// for single image in a tar file
+	if isImageFromTar {
+		entries, err := os.ReadDir(flagValues.push)
+		if err != nil {
+			return "", false, err
+		}
+		imageString = filepath.Join(imageString, entries[0].Name())
+	}
+
+	return imageString, true, nil
+}
+
+// writeVulnerabilityReportResult writes the compressed vulnerability report to its result file, a report
+// that exceeds the maximum result size is skipped because it would fail the TaskRun, and the reason is
+// written to its own result file so that it is surfaced in the BuildRun status
+func writeVulnerabilityReportResult(report []byte) error {
+	if flagValues.resultFileImageVulnerabilityReport == "" {
+		return nil
+	}
+
+	encodedReport, err := image.EncodeVulnerabilityReport(report)
+	if err != nil {
+		return err
+	}
+
+	if len(encodedReport) > flagValues.vulnerabilityReportMaxResultSize {
+		reason := fmt.Sprintf("the compressed vulnerability report has %d bytes and exceeds the maximum result size of %d bytes", len(encodedReport), flagValues.vulnerabilityReportMaxResultSize)
+		log.Printf("The report is not stored, %s\n", reason)
+
+		if flagValues.resultFileImageVulnerabilityReportOmitted == "" {
+			return nil
+		}
+
+		return os.WriteFile(flagValues.resultFileImageVulnerabilityReportOmitted, []byte(reason), 0640)
+	}
+
+	return os.WriteFile(flagValues.resultFileImageVulnerabilityReport, []byte(encodedReport), 0640)
+}
+
 // splitKeyVals splits key value pairs which is in form hello=world
 func splitKeyVals(kvPairs []string) (map[string]string, error) {
 	m := map[string]string{}
@@ -293,6 +1037,27 @@ func splitKeyVals(kvPairs []string) (map[string]string, error) {
 	return m, nil
 }
 
+// writeTruncatedResult adds the name of a result that was truncated to its maximum size to the result file
+// with the truncated results, the names are separated by a comma
+func writeTruncatedResult(resultName string) error {
+	log.Printf("The result %s exceeds the maximum result size of %d bytes and was truncated\n", resultName, flagValues.listMaxResultSize)
+
+	if flagValues.resultFileImageTruncatedResults == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(flagValues.resultFileImageTruncatedResults)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if len(data) > 0 {
+		data = append(data, ',')
+	}
+
+	return os.WriteFile(flagValues.resultFileImageTruncatedResults, append(data, resultName...), 0640)
+}
+
 func serializeVulnerabilities(Vulnerabilities []buildapi.Vulnerability) []byte {
 	var output []string
 	for _, vuln := range Vulnerabilities {
@@ -300,3 +1065,30 @@ func serializeVulnerabilities(Vulnerabilities []buildapi.Vulnerability) []byte {
 	}
 	return []byte(strings.Join(output, ","))
 }
+
+// loadImageOrImageIndexFromRegistry loads an image or an image index from the mirrors of the registry, or from
+// the registry itself
+func loadImageOrImageIndexFromRegistry(ctx context.Context, imageName name.Reference, options []remote.Option) (containerreg.Image, containerreg.ImageIndex, error) {
+	registryMirrorRules, err := image.ParseRegistryMirrorRules(flagValues.registryMirror)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var img containerreg.Image
+	var imageIndex containerreg.ImageIndex
+	err = image.PullWithMirrors(imageName, registryMirrorRules, func(ref name.Reference) error {
+		pullOptions := options
+		if ref != imageName {
+			mirrorOptions, err := image.GetMirrorOptions(ctx, ref, flagValues.insecure, flagValues.secretPath, flagValues.registryCertificatesDirectory, "Shipwright Build")
+			if err != nil {
+				return err
+			}
+			pullOptions = mirrorOptions
+		}
+
+		img, imageIndex, err = image.LoadImageOrImageIndexFromRegistry(ref, pullOptions)
+		return err
+	})
+
+	return img, imageIndex, err
+}
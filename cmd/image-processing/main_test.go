@@ -6,6 +6,12 @@ package main_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	cryptorand "crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"log"
 	"net/http/httptest"
@@ -27,6 +33,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/image"
 	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
 	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/util/rand"
@@ -252,6 +259,116 @@ var _ = Describe("Image Processing Resource", Ordered, func() {
 		})
 	})
 
+	Context("loading the image from registry mirrors", func() {
+		withImageInMirror := func(f func(tag name.Tag, mirror string)) {
+			withTempRegistry(func(endpoint string) {
+				withTempRegistry(func(mirror string) {
+					tag, err := name.NewTag(fmt.Sprintf("%s/%s:%s", endpoint, "temp-image", rand.String(5)))
+					Expect(err).ToNot(HaveOccurred())
+
+					// only the mirror serves the image
+					mirrorTag, err := name.NewTag(fmt.Sprintf("%s/%s:%s", mirror, "temp-image", tag.TagStr()))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(remote.Write(mirrorTag, empty.Image)).To(Succeed())
+
+					f(tag, mirror)
+				})
+			})
+		}
+
+		It("should load a tag from the mirror", func() {
+			withImageInMirror(func(tag name.Tag, mirror string) {
+				Expect(run(
+					"--insecure",
+					"--image", tag.String(),
+					"--registry-mirror", fmt.Sprintf("%s=%s", tag.RegistryStr(), mirror),
+					"--label", "description=image description",
+				)).ToNot(HaveOccurred())
+
+				Expect(getImageConfigLabel(tag.String(), "description")).
+					To(Equal("image description"))
+			})
+		})
+
+		It("should load a tag from its registry if the mirrors are only used for digests", func() {
+			withImageInMirror(func(tag name.Tag, mirror string) {
+				Expect(run(
+					"--insecure",
+					"--image", tag.String(),
+					"--registry-mirror", fmt.Sprintf("%s=%s;mirror-by-digest-only", tag.RegistryStr(), mirror),
+					"--label", "description=image description",
+				)).To(HaveOccurred())
+			})
+		})
+	})
+
+	Context("copying the image to mirrors", func() {
+		It("should copy the signature of the image to the mirror", func() {
+			withTestImage(func(tag name.Tag) {
+				withTempRegistry(func(mirror string) {
+					withTempDir(func(keyDir string) {
+						key, err := ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+						Expect(err).ToNot(HaveOccurred())
+
+						der, err := x509.MarshalECPrivateKey(key)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(os.WriteFile(path.Join(keyDir, image.SigningKeyFileName), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600)).To(Succeed())
+
+						mirrorTag, err := name.NewTag(fmt.Sprintf("%s/%s:%s", mirror, "mirrored-image", tag.TagStr()))
+						Expect(err).ToNot(HaveOccurred())
+
+						withTempFile("image-references", func(filename string) {
+							Expect(run(
+								"--insecure",
+								"--image", tag.String(),
+								"--signing-key-path", keyDir,
+								"--mirror", mirrorTag.String(),
+								"--result-file-image-references", filename,
+							)).ToNot(HaveOccurred())
+
+							Expect(filecontent(filename)).To(Equal(fmt.Sprintf("%s,%s", tag.String(), mirrorTag.String())))
+						})
+
+						digest := getImageDigest(tag)
+						Expect(getImageDigest(mirrorTag)).To(Equal(digest))
+
+						_, err = remote.Head(mirrorTag.Context().Tag(fmt.Sprintf("%s-%s.sig", digest.Algorithm, digest.Hex)))
+						Expect(err).ToNot(HaveOccurred())
+					})
+				})
+			})
+		})
+	})
+
+	Context("resolving placeholders that depend on the source", func() {
+		It("should resolve the placeholders for the part of the reference they are used in", func() {
+			withTestImage(func(tag name.Tag) {
+				withTempRegistry(func(mirror string) {
+					withTempFile("branch-name", func(branchName string) {
+						Expect(os.WriteFile(branchName, []byte("Feature/Login\n"), 0644)).To(Succeed())
+
+						withTempFile("image-references", func(filename string) {
+							Expect(run(
+								"--insecure",
+								"--image", tag.String(),
+								"--additional-tag", "$(source.git.branchName)",
+								"--mirror", fmt.Sprintf("%s/app-$(source.git.branchName):$(source.git.branchName)", mirror),
+								"--template-value-file", fmt.Sprintf("source.git.branchName=%s", branchName),
+								"--result-file-image-references", filename,
+							)).ToNot(HaveOccurred())
+
+							Expect(filecontent(filename)).To(Equal(strings.Join([]string{
+								tag.String(),
+								tag.Context().Tag("Feature-Login").String(),
+								fmt.Sprintf("%s/app-feature-login:Feature-Login", mirror),
+							}, ",")))
+						})
+					})
+				})
+			})
+		})
+	})
+
 	Context("mutating the image", func() {
 		It("should mutate an image with single annotation", func() {
 			withTestImage(func(tag name.Tag) {
@@ -534,5 +651,32 @@ var _ = Describe("Image Processing Resource", Ordered, func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
+
+		It("should fail the scan with grype and a trivy vulnerability database artifact", func() {
+			scanner := buildapi.VulnerabilityScannerGrype
+			vulnOptions := &buildapi.VulnerabilityScanOptions{
+				Enabled: true,
+				Scanner: &scanner,
+			}
+
+			withTempRegistry(func(endpoint string) {
+				tag, err := name.NewTag(fmt.Sprintf("%s/%s:%s", endpoint, "temp-image", rand.String(5)))
+				Expect(err).ToNot(HaveOccurred())
+
+				vulnSettings := &resources.VulnerablilityScanParams{VulnerabilityScanOptions: *vulnOptions}
+				err = run(
+					"--insecure",
+					"--image", tag.String(),
+					"--push", directory,
+					"--vuln-settings", vulnSettings.String(),
+					"--vuln-db-image", fmt.Sprintf("%s/aquasecurity/trivy-db:2", endpoint),
+				)
+
+				var exitErr *ExitError
+				Expect(errors.As(err, &exitErr)).To(BeTrue())
+				Expect(exitErr.Code).To(Equal(23))
+				Expect(exitErr.Cause).To(MatchError(ContainSubstring("is a trivy database, grype requires a database in the vulnerability database volume")))
+			})
+		})
 	})
 })
@@ -0,0 +1,157 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/shipwright-io/build/pkg/bundle"
+)
+
+type settings struct {
+	help                    bool
+	target                  string
+	files                   []string
+	fromDirs                []string
+	resultFileSourceDigest  string
+	resultFileSourceDirHash string
+}
+
+var flagValues settings
+
+func init() {
+	// Explicitly define the help flag so that --help can be invoked and returns status code 0
+	pflag.BoolVar(&flagValues.help, "help", false, "Print the help")
+
+	pflag.StringVar(&flagValues.target, "target", "/workspace/source", "The target directory to place the files")
+	pflag.StringArrayVar(&flagValues.files, "file", nil, "A file to write in the format <relative path>=<base64 encoded content> (can be repeated)")
+	pflag.StringArrayVar(&flagValues.fromDirs, "from-dir", nil, "A directory, i.e. a mounted ConfigMap or Secret, whose files are copied (can be repeated)")
+	pflag.StringVar(&flagValues.resultFileSourceDigest, "result-file-source-digest", "", "A file to write the digest of the written source")
+	pflag.StringVar(&flagValues.resultFileSourceDirHash, "result-file-source-dir-hash", "", "A file to write the directory hash of the written source")
+}
+
+func main() {
+	if err := Do(context.Background()); err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+// Do is the main entry point of the inline command
+func Do(_ context.Context) error {
+	flagValues = settings{target: "/workspace/source"}
+	pflag.Parse()
+
+	if flagValues.help {
+		pflag.Usage()
+		return nil
+	}
+
+	if len(flagValues.files) == 0 && len(flagValues.fromDirs) == 0 {
+		return fmt.Errorf("at least one of the flags --file and --from-dir must be set")
+	}
+
+	for _, fromDir := range flagValues.fromDirs {
+		if err := copyDirectory(fromDir, flagValues.target); err != nil {
+			return err
+		}
+	}
+
+	// files from the Build are written last so that they take precedence
+	for _, file := range flagValues.files {
+		name, encoded, found := strings.Cut(file, "=")
+		if !found {
+			return fmt.Errorf("file %q is not in the format <relative path>=<base64 encoded content>", file)
+		}
+
+		content, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("failed to decode the content of file %q: %w", name, err)
+		}
+
+		if err := writeFile(flagValues.target, name, content); err != nil {
+			return err
+		}
+	}
+
+	if flagValues.resultFileSourceDigest != "" || flagValues.resultFileSourceDirHash != "" {
+		details, err := bundle.Inspect(flagValues.target)
+		if err != nil {
+			return err
+		}
+
+		if flagValues.resultFileSourceDigest != "" {
+			if err := os.WriteFile(flagValues.resultFileSourceDigest, []byte(details.Digest), 0644); err != nil {
+				return err
+			}
+		}
+
+		if flagValues.resultFileSourceDirHash != "" {
+			if err := os.WriteFile(flagValues.resultFileSourceDirHash, []byte(details.DirHash), 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// copyDirectory copies the files of a mounted ConfigMap or Secret. Kubernetes
+// places the actual data in hidden directories starting with two dots and
+// links the keys to them, those hidden entries are skipped.
+func copyDirectory(fromDir string, target string) error {
+	entries, err := os.ReadDir(fromDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "..") {
+			continue
+		}
+
+		// the keys are links, stat follows them
+		info, err := os.Stat(filepath.Join(fromDir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(fromDir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		if err := writeFile(target, entry.Name(), content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeFile(target string, name string, content []byte) error {
+	if !filepath.IsLocal(name) {
+		return fmt.Errorf("file %q results in a path outside of the target directory", name)
+	}
+
+	path := filepath.Join(target, name)
+	if err := os.MkdirAll(filepath.Dir(path), os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	log.Printf("Writing file %s\n", name)
+	return os.WriteFile(path, content, os.FileMode(0644))
+}
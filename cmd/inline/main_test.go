@@ -0,0 +1,130 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"context"
+	"encoding/base64"
+	"log"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shipwright-io/build/cmd/inline"
+	"github.com/shipwright-io/build/pkg/bundle"
+)
+
+var _ = Describe("Inline", func() {
+	run := func(args ...string) error {
+		log.SetOutput(GinkgoWriter)
+
+		// discard stderr output
+		var tmp = os.Stderr
+		os.Stderr = nil
+		defer func() { os.Stderr = tmp }()
+
+		os.Args = append([]string{"tool"}, args...)
+		return Do(context.Background())
+	}
+
+	withTempDir := func(f func(target string)) {
+		path, err := os.MkdirTemp(os.TempDir(), "inline")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(path)
+
+		f(path)
+	}
+
+	encode := func(content string) string {
+		return base64.StdEncoding.EncodeToString([]byte(content))
+	}
+
+	Context("validations and error cases", func() {
+		It("should succeed in case the help flag is used", func() {
+			Expect(run("--help")).To(Succeed())
+		})
+
+		It("should fail in case no file and no directory is provided", func() {
+			Expect(run("--target", "/tmp")).To(MatchError("at least one of the flags --file and --from-dir must be set"))
+		})
+
+		It("should fail in case the file content is not base64 encoded", func() {
+			withTempDir(func(target string) {
+				Expect(run("--target", target, "--file", "Dockerfile=FROM scratch")).To(MatchError(ContainSubstring("failed to decode the content")))
+			})
+		})
+
+		It("should fail in case the file is outside of the target directory", func() {
+			withTempDir(func(target string) {
+				Expect(run("--target", target, "--file", "../Dockerfile="+encode("FROM scratch"))).To(MatchError(ContainSubstring("outside of the target directory")))
+			})
+		})
+	})
+
+	Context("writing files", func() {
+		It("should write the inline files", func() {
+			withTempDir(func(target string) {
+				Expect(run(
+					"--target", target,
+					"--file", "Dockerfile="+encode("FROM ghcr.io/shipwright-io/base-base:latest\nCOPY config /config\n"),
+					"--file", "config/app.yaml="+encode("replicas: 2"),
+				)).To(Succeed())
+
+				Expect(os.ReadFile(filepath.Join(target, "Dockerfile"))).To(ContainSubstring("COPY config /config"))
+				Expect(os.ReadFile(filepath.Join(target, "config", "app.yaml"))).To(Equal([]byte("replicas: 2")))
+			})
+		})
+
+		It("should write the digest and the directory hash of the source", func() {
+			withTempDir(func(dir string) {
+				target := filepath.Join(dir, "source")
+				resultDigest := filepath.Join(dir, "source-digest")
+				resultDirHash := filepath.Join(dir, "source-dir-hash")
+
+				Expect(run(
+					"--target", target,
+					"--file", "Dockerfile="+encode("FROM scratch"),
+					"--result-file-source-digest", resultDigest,
+					"--result-file-source-dir-hash", resultDirHash,
+				)).To(Succeed())
+
+				details, err := bundle.Inspect(target)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(os.ReadFile(resultDigest)).To(Equal([]byte(details.Digest)))
+				Expect(details.Digest).To(HavePrefix("sha256:"))
+				Expect(os.ReadFile(resultDirHash)).To(Equal([]byte(details.DirHash)))
+				Expect(details.DirHash).To(HavePrefix("h1:"))
+			})
+		})
+
+		It("should copy the keys of a mounted ConfigMap and let inline files take precedence", func() {
+			withTempDir(func(dir string) {
+				// mimic the layout that Kubernetes uses for ConfigMap volumes
+				configMapDir := filepath.Join(dir, "configmap")
+				Expect(os.MkdirAll(filepath.Join(configMapDir, "..2024_03_01_10_00_00.000000000"), 0755)).To(Succeed())
+				Expect(os.Symlink("..2024_03_01_10_00_00.000000000", filepath.Join(configMapDir, "..data"))).To(Succeed())
+				for name, content := range map[string]string{"Dockerfile": "FROM scratch", "settings.json": "{}"} {
+					Expect(os.WriteFile(filepath.Join(configMapDir, "..data", name), []byte(content), 0644)).To(Succeed())
+					Expect(os.Symlink(filepath.Join("..data", name), filepath.Join(configMapDir, name))).To(Succeed())
+				}
+
+				target := filepath.Join(dir, "source")
+				Expect(run(
+					"--target", target,
+					"--from-dir", configMapDir,
+					"--file", "Dockerfile="+encode("FROM busybox"),
+				)).To(Succeed())
+
+				entries, err := os.ReadDir(target)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(entries).To(HaveLen(2))
+				Expect(os.ReadFile(filepath.Join(target, "settings.json"))).To(Equal([]byte("{}")))
+				Expect(os.ReadFile(filepath.Join(target, "Dockerfile"))).To(Equal([]byte("FROM busybox")))
+			})
+		})
+	})
+})
@@ -0,0 +1,202 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/shipwright-io/build/pkg/objectstorage"
+	"github.com/shipwright-io/build/pkg/util"
+)
+
+const (
+	accessKeyIDFile     = "accessKeyID"
+	secretAccessKeyFile = "secretAccessKey"
+)
+
+type settings struct {
+	help                      bool
+	endpoint                  string
+	region                    string
+	bucket                    string
+	key                       string
+	prefix                    string
+	versionID                 string
+	target                    string
+	secretPath                string
+	resultFileDigest          string
+	resultFileETag            string
+	resultFileSourceDirHash   string
+	resultFileVersionID       string
+	resultFileSourceTimestamp string
+	showListing               bool
+}
+
+var flagValues settings
+
+func init() {
+	// Explicitly define the help flag so that --help can be invoked and returns status code 0
+	pflag.BoolVar(&flagValues.help, "help", false, "Print the help")
+
+	// Main flags of the object storage step
+	pflag.StringVar(&flagValues.endpoint, "endpoint", "", "The URL of the S3-compatible object storage (mandatory)")
+	pflag.StringVar(&flagValues.region, "region", objectstorage.DefaultRegion, "The region used to sign the requests")
+	pflag.StringVar(&flagValues.bucket, "bucket", "", "The name of the bucket (mandatory)")
+	pflag.StringVar(&flagValues.key, "key", "", "The key of a single object to download")
+	pflag.StringVar(&flagValues.prefix, "prefix", "", "The prefix of the objects to download")
+	pflag.StringVar(&flagValues.versionID, "version-id", "", "The version of the object to download")
+	pflag.StringVar(&flagValues.target, "target", "/workspace/source", "The target directory to place the code")
+	pflag.StringVar(&flagValues.resultFileDigest, "result-file-digest", "", "A file to write the source digest")
+	pflag.StringVar(&flagValues.resultFileSourceDirHash, "result-file-source-dir-hash", "", "A file to write the directory hash of the objects with the prefix")
+	pflag.StringVar(&flagValues.resultFileETag, "result-file-etag", "", "A file to write the object ETag")
+	pflag.StringVar(&flagValues.resultFileVersionID, "result-file-version-id", "", "A file to write the object version")
+	pflag.StringVar(&flagValues.resultFileSourceTimestamp, "result-file-source-timestamp", "", "A file to write the source timestamp")
+
+	pflag.StringVar(&flagValues.secretPath, "secret-path", "", "A directory that contains access credentials (optional)")
+	pflag.BoolVar(&flagValues.showListing, "show-listing", false, "Print file listing of files downloaded from the object storage")
+}
+
+func main() {
+	if err := Do(context.Background()); err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+// Do is the main entry point of the object storage command
+func Do(ctx context.Context) error {
+	flagValues = settings{region: objectstorage.DefaultRegion, target: "/workspace/source"}
+	pflag.Parse()
+
+	if val, ok := os.LookupEnv("OBJECT_STORAGE_SHOW_LISTING"); ok {
+		flagValues.showListing, _ = strconv.ParseBool(val)
+	}
+
+	if flagValues.help {
+		pflag.Usage()
+		return nil
+	}
+
+	switch {
+	case flagValues.endpoint == "":
+		return fmt.Errorf("mandatory flag --endpoint is not set")
+
+	case flagValues.bucket == "":
+		return fmt.Errorf("mandatory flag --bucket is not set")
+
+	case (flagValues.key == "") == (flagValues.prefix == ""):
+		return fmt.Errorf("exactly one of the flags --key and --prefix must be set")
+
+	case flagValues.versionID != "" && flagValues.key == "":
+		return fmt.Errorf("flag --version-id can only be used together with flag --key")
+	}
+
+	accessKeyID, secretAccessKey, err := readCredentials(flagValues.secretPath)
+	if err != nil {
+		return err
+	}
+
+	client, err := objectstorage.NewClient(flagValues.endpoint, flagValues.region, accessKeyID, secretAccessKey)
+	if err != nil {
+		return err
+	}
+
+	var details *objectstorage.DownloadDetails
+	if flagValues.key != "" {
+		log.Printf("Downloading object %q from bucket %q", flagValues.key, flagValues.bucket)
+		details, err = client.DownloadObject(ctx, flagValues.bucket, flagValues.key, flagValues.versionID, flagValues.target)
+	} else {
+		log.Printf("Downloading objects with prefix %q from bucket %q", flagValues.prefix, flagValues.bucket)
+		details, err = client.DownloadPrefix(ctx, flagValues.bucket, flagValues.prefix, flagValues.target)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Objects were downloaded to %s\n", flagValues.target)
+	if flagValues.showListing {
+		// ignore any errors when walking through the file system, the listing is only for informational purposes
+		_ = util.ListFiles(log.Writer(), flagValues.target)
+	}
+
+	if flagValues.resultFileDigest != "" {
+		if err = os.WriteFile(flagValues.resultFileDigest, []byte(details.Digest), 0644); err != nil {
+			return err
+		}
+	}
+
+	if flagValues.resultFileSourceDirHash != "" {
+		if err = os.WriteFile(flagValues.resultFileSourceDirHash, []byte(details.DirHash), 0644); err != nil {
+			return err
+		}
+	}
+
+	if flagValues.resultFileETag != "" {
+		if err = os.WriteFile(flagValues.resultFileETag, []byte(details.ETag), 0644); err != nil {
+			return err
+		}
+	}
+
+	if flagValues.resultFileVersionID != "" {
+		if err = os.WriteFile(flagValues.resultFileVersionID, []byte(details.VersionID), 0644); err != nil {
+			return err
+		}
+	}
+
+	if flagValues.resultFileSourceTimestamp != "" {
+		if details.MostRecentFileTimestamp != nil {
+			if err = os.WriteFile(flagValues.resultFileSourceTimestamp, []byte(strconv.FormatInt(details.MostRecentFileTimestamp.Unix(), 10)), 0644); err != nil {
+				return err
+			}
+
+		} else {
+			log.Printf("Unable to determine source timestamp of content in %s\n", flagValues.target)
+		}
+	}
+
+	return nil
+}
+
+// readCredentials reads the access key and secret key from the secret directory,
+// no credentials are returned if no directory is provided
+func readCredentials(secretPath string) (string, string, error) {
+	if secretPath == "" {
+		return "", "", nil
+	}
+
+	read := func(name string) (string, error) {
+		data, err := os.ReadFile(filepath.Join(secretPath, name))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return "", fmt.Errorf("the secret does not contain the key %q", name)
+			}
+
+			return "", err
+		}
+
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	accessKeyID, err := read(accessKeyIDFile)
+	if err != nil {
+		return "", "", err
+	}
+
+	secretAccessKey, err := read(secretAccessKeyFile)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessKeyID, secretAccessKey, nil
+}
@@ -0,0 +1,166 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shipwright-io/build/cmd/object-storage"
+
+	utils "github.com/shipwright-io/build/test/utils"
+)
+
+var _ = Describe("Object Storage Loader", func() {
+	const bucket = "sources"
+
+	var lastModified = time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	run := func(args ...string) error {
+		log.SetOutput(GinkgoWriter)
+
+		// discard stderr output
+		var tmp = os.Stderr
+		os.Stderr = nil
+		defer func() { os.Stderr = tmp }()
+
+		os.Args = append([]string{"tool"}, args...)
+		return Do(context.Background())
+	}
+
+	withTempDir := func(f func(target string)) {
+		path, err := os.MkdirTemp(os.TempDir(), "object-storage")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(path)
+
+		f(path)
+	}
+
+	withFakeObjectStorage := func(accessKeyID string, f func(fakeObjectStorage *utils.FakeObjectStorage)) {
+		fakeObjectStorage := utils.StartFakeObjectStorage(accessKeyID)
+		defer fakeObjectStorage.Close()
+
+		f(fakeObjectStorage)
+	}
+
+	Context("validations and error cases", func() {
+		It("should succeed in case the help flag is used", func() {
+			Expect(run("--help")).To(Succeed())
+		})
+
+		It("should fail in case the endpoint is not set", func() {
+			Expect(run("--bucket", bucket, "--key", "source.tar")).To(MatchError("mandatory flag --endpoint is not set"))
+		})
+
+		It("should fail in case neither key nor prefix is set", func() {
+			Expect(run("--endpoint", "http://localhost:9000", "--bucket", bucket)).To(MatchError("exactly one of the flags --key and --prefix must be set"))
+		})
+
+		It("should fail in case the version is set without a key", func() {
+			Expect(run(
+				"--endpoint", "http://localhost:9000",
+				"--bucket", bucket,
+				"--prefix", "builds/",
+				"--version-id", "1",
+			)).To(MatchError("flag --version-id can only be used together with flag --key"))
+		})
+
+		It("should fail in case the secret does not contain the access key", func() {
+			withTempDir(func(secretPath string) {
+				Expect(run(
+					"--endpoint", "http://localhost:9000",
+					"--bucket", bucket,
+					"--key", "source.tar",
+					"--secret-path", secretPath,
+				)).To(MatchError(`the secret does not contain the key "accessKeyID"`))
+			})
+		})
+	})
+
+	Context("downloading from an object storage", func() {
+		It("should download an object using the credentials and write the results", func() {
+			withFakeObjectStorage("minioadmin", func(fakeObjectStorage *utils.FakeObjectStorage) {
+				etag := fakeObjectStorage.PutObject(bucket, "artifacts/data.csv", []byte("a,b,c"), "version-1", lastModified)
+				contentDigest := sha256.Sum256([]byte("a,b,c"))
+
+				withTempDir(func(dir string) {
+					secretPath := filepath.Join(dir, "secret")
+					Expect(os.Mkdir(secretPath, 0755)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(secretPath, "accessKeyID"), []byte("minioadmin"), 0644)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(secretPath, "secretAccessKey"), []byte("minioadmin\n"), 0644)).To(Succeed())
+
+					target := filepath.Join(dir, "source")
+					resultDigest := filepath.Join(dir, "digest")
+					resultETag := filepath.Join(dir, "etag")
+					resultVersionID := filepath.Join(dir, "version-id")
+					resultSourceTimestamp := filepath.Join(dir, "source-timestamp")
+
+					Expect(run(
+						"--endpoint", fakeObjectStorage.URL,
+						"--bucket", bucket,
+						"--key", "artifacts/data.csv",
+						"--target", target,
+						"--secret-path", secretPath,
+						"--result-file-digest", resultDigest,
+						"--result-file-etag", resultETag,
+						"--result-file-version-id", resultVersionID,
+						"--result-file-source-timestamp", resultSourceTimestamp,
+					)).To(Succeed())
+
+					Expect(os.ReadFile(filepath.Join(target, "data.csv"))).To(Equal([]byte("a,b,c")))
+					Expect(os.ReadFile(resultDigest)).To(Equal([]byte("sha256:" + hex.EncodeToString(contentDigest[:]))))
+					Expect(os.ReadFile(resultETag)).To(Equal([]byte(etag)))
+					Expect(os.ReadFile(resultVersionID)).To(Equal([]byte("version-1")))
+					Expect(os.ReadFile(resultSourceTimestamp)).To(Equal([]byte("1709287200")))
+				})
+			})
+		})
+
+		It("should download all objects with a prefix anonymously", func() {
+			withFakeObjectStorage("", func(fakeObjectStorage *utils.FakeObjectStorage) {
+				fakeObjectStorage.PutObject(bucket, "builds/42/main.go", []byte("package main"), "", lastModified)
+
+				withTempDir(func(dir string) {
+					target := filepath.Join(dir, "source")
+					resultDirHash := filepath.Join(dir, "source-dir-hash")
+
+					Expect(run(
+						"--endpoint", fakeObjectStorage.URL,
+						"--bucket", bucket,
+						"--prefix", "builds/42",
+						"--target", target,
+						"--result-file-source-dir-hash", resultDirHash,
+					)).To(Succeed())
+
+					Expect(os.ReadFile(filepath.Join(target, "main.go"))).To(Equal([]byte("package main")))
+					Expect(os.ReadFile(resultDirHash)).To(HavePrefix("h1:"))
+				})
+			})
+		})
+
+		It("should fail when the credentials are missing", func() {
+			withFakeObjectStorage("minioadmin", func(fakeObjectStorage *utils.FakeObjectStorage) {
+				fakeObjectStorage.PutObject(bucket, "source.tar", []byte("content"), "", lastModified)
+
+				withTempDir(func(target string) {
+					Expect(run(
+						"--endpoint", fakeObjectStorage.URL,
+						"--bucket", bucket,
+						"--key", "source.tar",
+						"--target", target,
+					)).To(MatchError(ContainSubstring("AccessDenied")))
+				})
+			})
+		})
+	})
+})
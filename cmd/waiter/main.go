@@ -15,6 +15,13 @@ import (
 type settings struct {
 	lockFile string        // path to lock file
 	timeout  time.Duration // how long wait for 'done'
+
+	sourceDir                 string // directory the data is uploaded to
+	resultFileSourceDigest    string // path to write the source digest to
+	resultFileSourceDirHash   string // path to write the source directory hash to
+	resultFileSourceTimestamp string // path to write the source timestamp to
+	resultFileCommitSha       string // path to write the commit sha to
+	resultFileBranchName      string // path to write the branch name to
 }
 
 const longDesc = `
@@ -38,6 +45,13 @@ Or, alternatively:
 
 	$ rm -f <lock-file>
 
+## Results
+
+When "--source-dir" is set, the content of the directory is inspected after the upload
+is done. The digest of the content, the most recent file timestamp and, in case the
+directory contains a Git repository, the commit SHA and branch name are written into
+the respective "--result-file-*" files.
+
 ## Return-Code
 
 In the case of timeout, the waiter will return error, it only exits gracefully via
@@ -66,6 +80,13 @@ func init() {
 	flags.StringVar(&flagValues.lockFile, "lock-file", defaultLockFile, "lock file full path")
 	flags.DurationVar(&flagValues.timeout, "timeout", defaultTimeout, "how long to wait until 'done'")
 
+	flags.StringVar(&flagValues.sourceDir, "source-dir", "", "directory to inspect once 'done' (optional)")
+	flags.StringVar(&flagValues.resultFileSourceDigest, "result-file-source-digest", "", "file to write the source digest to")
+	flags.StringVar(&flagValues.resultFileSourceDirHash, "result-file-source-dir-hash", "", "file to write the source directory hash to")
+	flags.StringVar(&flagValues.resultFileSourceTimestamp, "result-file-source-timestamp", "", "file to write the source timestamp to")
+	flags.StringVar(&flagValues.resultFileCommitSha, "result-file-commit-sha", "", "file to write the commit sha to")
+	flags.StringVar(&flagValues.resultFileBranchName, "result-file-branch-name", "", "file to write the branch name to")
+
 	rootCmd.AddCommand(startCmd)
 	rootCmd.AddCommand(doneCmd)
 }
@@ -85,7 +106,10 @@ func newStartCmd() *cobra.Command {
 		SilenceUsage: true,
 		RunE: func(_ *cobra.Command, _ []string) error {
 			w := NewWaiter(flagValues)
-			return w.Wait()
+			if err := w.Wait(); err != nil {
+				return err
+			}
+			return writeSourceResults(flagValues)
 		},
 	}
 }
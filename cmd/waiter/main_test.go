@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"time"
 
 	"github.com/onsi/gomega/gbytes"
@@ -113,6 +114,56 @@ var _ = Describe("Waiter", func() {
 		})
 	})
 
+	Describe("expect to write the source results when lock-file removed before timeout", func() {
+		var startCh = make(chan interface{})
+		var sourceDir, resultDir string
+
+		BeforeEach(func() {
+			var err error
+			sourceDir, err = os.MkdirTemp("", "waiter-source")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(sourceDir, "some-file"), []byte("foobar"), 0644)).To(Succeed())
+
+			resultDir, err = os.MkdirTemp("", "waiter-results")
+			Expect(err).ToNot(HaveOccurred())
+
+			session := run("start",
+				"--source-dir", sourceDir,
+				"--result-file-source-digest", filepath.Join(resultDir, "source-digest"),
+				"--result-file-source-dir-hash", filepath.Join(resultDir, "source-dir-hash"),
+				"--result-file-source-timestamp", filepath.Join(resultDir, "source-timestamp"),
+				"--result-file-commit-sha", filepath.Join(resultDir, "commit-sha"),
+			)
+
+			go inspectSession(session, startCh, gexec.Exit(0))
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(sourceDir)
+			_ = os.RemoveAll(resultDir)
+		})
+
+		It("writes the source digest and timestamp", func() {
+			err := os.RemoveAll(defaultLockFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(startCh, defaultTimeout).Should(BeClosed())
+
+			digest, err := os.ReadFile(filepath.Join(resultDir, "source-digest"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(digest)).To(HavePrefix("sha256:"))
+
+			dirHash, err := os.ReadFile(filepath.Join(resultDir, "source-dir-hash"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(dirHash)).To(HavePrefix("h1:"))
+
+			Expect(filepath.Join(resultDir, "source-timestamp")).To(BeAnExistingFile())
+
+			// the source is not a Git repository
+			Expect(filepath.Join(resultDir, "commit-sha")).ToNot(BeAnExistingFile())
+		})
+	})
+
 	Describe("expect to fail when timeout is reached", func() {
 		var startCh = make(chan interface{})
 
@@ -0,0 +1,72 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strconv"
+
+	gogitv5 "github.com/go-git/go-git/v5"
+
+	"github.com/shipwright-io/build/pkg/bundle"
+	"github.com/shipwright-io/build/pkg/git"
+)
+
+// writeSourceResults inspects the uploaded source directory, and writes the source digest,
+// directory hash, timestamp and the Git details (if any) into the configured result files.
+func writeSourceResults(flagValues settings) error {
+	if flagValues.sourceDir == "" {
+		return nil
+	}
+
+	details, err := bundle.Inspect(flagValues.sourceDir)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Source content digest is %s\n", details.Digest)
+	if err := writeResult(flagValues.resultFileSourceDigest, details.Digest); err != nil {
+		return err
+	}
+
+	if err := writeResult(flagValues.resultFileSourceDirHash, details.DirHash); err != nil {
+		return err
+	}
+
+	if details.MostRecentFileTimestamp != nil {
+		if err := writeResult(flagValues.resultFileSourceTimestamp, strconv.FormatInt(details.MostRecentFileTimestamp.Unix(), 10)); err != nil {
+			return err
+		}
+	} else {
+		log.Printf("Unable to determine source timestamp of content in %s\n", flagValues.sourceDir)
+	}
+
+	commitSha, branchName, err := git.ReadLocalRepository(flagValues.sourceDir)
+	switch {
+	case errors.Is(err, gogitv5.ErrRepositoryNotExists):
+		return nil
+
+	case err != nil:
+		// the Git details are informational only, an uploaded repository in an unexpected state must not fail the build
+		log.Printf("Unable to read Git repository details in %s: %v\n", flagValues.sourceDir, err)
+		return nil
+	}
+
+	if err := writeResult(flagValues.resultFileCommitSha, commitSha); err != nil {
+		return err
+	}
+
+	return writeResult(flagValues.resultFileBranchName, branchName)
+}
+
+// writeResult writes the value into the result file, if both are set.
+func writeResult(resultFile string, value string) error {
+	if resultFile == "" || value == "" {
+		return nil
+	}
+
+	return os.WriteFile(resultFile, []byte(value), 0644)
+}
@@ -18,6 +18,8 @@ require (
 	github.com/spf13/pflag v1.0.6
 	github.com/tektoncd/pipeline v0.68.0
 	go.uber.org/zap v1.27.0
+	golang.org/x/crypto v0.32.0
+	golang.org/x/mod v0.22.0
 	k8s.io/api v0.30.6
 	k8s.io/apiextensions-apiserver v0.30.6
 	k8s.io/apimachinery v0.30.6
@@ -102,9 +104,7 @@ require (
 	github.com/xanzy/ssh-agent v0.3.3 // indirect
 	go.opencensus.io v0.24.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
-	golang.org/x/crypto v0.32.0 // indirect
 	golang.org/x/exp v0.0.0-20240719175910-8a7402abbf56 // indirect
-	golang.org/x/mod v0.22.0 // indirect
 	golang.org/x/net v0.34.0 // indirect
 	golang.org/x/oauth2 v0.25.0 // indirect
 	golang.org/x/sync v0.10.0 // indirect
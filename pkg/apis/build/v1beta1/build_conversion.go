@@ -114,7 +114,8 @@ func (dest *BuildSpec) ConvertFrom(orig *v1alpha1.BuildSpec) error {
 				Name:    orig.Sources[index].Name,
 				Timeout: orig.Sources[index].Timeout,
 			},
-			ContextDir: orig.Source.ContextDir,
+			AdditionalLocals: getAdditionalLocals(orig.Sources, index),
+			ContextDir:       orig.Source.ContextDir,
 		}
 	} else if orig.Source.BundleContainer != nil {
 		dest.Source = &Source{
@@ -235,6 +236,7 @@ func (dest *BuildSpec) ConvertTo(bs *v1alpha1.BuildSpec) error {
 			Type:    v1alpha1.LocalCopy,
 			Timeout: dest.Source.Local.Timeout,
 		})
+		bs.Sources = append(bs.Sources, getAlphaAdditionalLocalSources(dest.Source.AdditionalLocals)...)
 	} else {
 		bs.Source = getAlphaBuildSource(*dest)
 	}
@@ -458,3 +460,32 @@ func getAlphaBuildSource(src BuildSpec) v1alpha1.Source {
 
 	return source
 }
+
+// getAdditionalLocals returns all LocalCopy sources that follow the one at the provided index
+func getAdditionalLocals(sources []v1alpha1.BuildSource, index int) []Local {
+	var additionalLocals []Local
+	for _, source := range sources[index+1:] {
+		if source.Type == v1alpha1.LocalCopy {
+			additionalLocals = append(additionalLocals, Local{
+				Name:    source.Name,
+				Timeout: source.Timeout,
+			})
+		}
+	}
+
+	return additionalLocals
+}
+
+// getAlphaAdditionalLocalSources converts the additional local sources into LocalCopy sources
+func getAlphaAdditionalLocalSources(additionalLocals []Local) []v1alpha1.BuildSource {
+	var sources []v1alpha1.BuildSource
+	for _, additionalLocal := range additionalLocals {
+		sources = append(sources, v1alpha1.BuildSource{
+			Name:    additionalLocal.Name,
+			Type:    v1alpha1.LocalCopy,
+			Timeout: additionalLocal.Timeout,
+		})
+	}
+
+	return sources
+}
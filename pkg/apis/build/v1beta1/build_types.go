@@ -28,8 +28,14 @@ const (
 	SpecSourceSecretRefNotFound BuildReason = "SpecSourceSecretRefNotFound"
 	// SpecOutputSecretRefNotFound indicates the referenced secret in output is missing
 	SpecOutputSecretRefNotFound BuildReason = "SpecOutputSecretRefNotFound"
+	// SpecOutputSigningSecretRefNotFound indicates the referenced secret with the signing key in output is missing
+	SpecOutputSigningSecretRefNotFound BuildReason = "SpecOutputSigningSecretRefNotFound"
 	// SpecBuilderSecretRefNotFound indicates the referenced secret in builder is missing
 	SpecBuilderSecretRefNotFound BuildReason = "SpecBuilderSecretRefNotFound"
+	// SpecSourceInlineSecretRefNotFound indicates the Secret referenced by the inline source is missing
+	SpecSourceInlineSecretRefNotFound BuildReason = "SpecSourceInlineSecretRefNotFound"
+	// SpecSourceInlineConfigMapRefNotFound indicates the ConfigMap referenced by the inline source is missing
+	SpecSourceInlineConfigMapRefNotFound BuildReason = "SpecSourceInlineConfigMapRefNotFound"
 	// MultipleSecretRefNotFound indicates that multiple secrets are missing
 	MultipleSecretRefNotFound BuildReason = "MultipleSecretRefNotFound"
 	// SpecEnvNameCanNotBeBlank indicates that the name for an environment variable is blank
@@ -76,8 +82,32 @@ const (
 	OutputTimestampNotSupported BuildReason = "OutputTimestampNotSupported"
 	// OutputTimestampNotValid indicates that the output timestamp value is not valid
 	OutputTimestampNotValid BuildReason = "OutputTimestampNotValid"
+	// OutputVEXNotValid indicates that a VEX document reference of the vulnerability scan is not valid
+	OutputVEXNotValid BuildReason = "OutputVEXNotValid"
+	// OutputImageNotValid indicates that the output image uses a placeholder that is not supported in it
+	OutputImageNotValid BuildReason = "OutputImageNotValid"
+	// OutputAdditionalTagNotValid indicates that an additional tag of the output image is not valid
+	OutputAdditionalTagNotValid BuildReason = "OutputAdditionalTagNotValid"
+	// OutputMirrorNotValid indicates that a mirror of the output image is not valid
+	OutputMirrorNotValid BuildReason = "OutputMirrorNotValid"
+	// OutputImageConfigNotValid indicates that the configuration of the output image is not valid
+	OutputImageConfigNotValid BuildReason = "OutputImageConfigNotValid"
+	// OutputPlatformNotValid indicates that a platform of the output image is not valid
+	OutputPlatformNotValid BuildReason = "OutputPlatformNotValid"
+	// OutputExportNotValid indicates that the export of the output image is not valid
+	OutputExportNotValid BuildReason = "OutputExportNotValid"
 	// NodeSelectorNotValid indicates that the nodeSelector value is not valid
 	NodeSelectorNotValid BuildReason = "NodeSelectorNotValid"
+	// AdditionalLocalSourcesNotValid indicates that the additional local sources are not valid
+	AdditionalLocalSourcesNotValid BuildReason = "AdditionalLocalSourcesNotValid"
+	// ObjectStorageSourceNotValid indicates that the object storage source is not valid
+	ObjectStorageSourceNotValid BuildReason = "ObjectStorageSourceNotValid"
+	// InlineSourceNotValid indicates that the inline source is not valid
+	InlineSourceNotValid BuildReason = "InlineSourceNotValid"
+	// SourceWorkspaceNotValid indicates that the source workspace is not valid
+	SourceWorkspaceNotValid BuildReason = "SourceWorkspaceNotValid"
+	// RetryNotValid indicates that the retry is not valid
+	RetryNotValid BuildReason = "RetryNotValid"
 
 	// AllValidationsSucceeded indicates a Build was successfully validated
 	AllValidationsSucceeded = "all validations succeeded"
@@ -183,6 +213,18 @@ type BuildSpec struct {
 	//
 	// +optional
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// SourceWorkspace defines the volume that holds the source.
+	// If not defined, an emptyDir volume is used.
+	//
+	// +optional
+	SourceWorkspace *SourceWorkspace `json:"sourceWorkspace,omitempty"`
+
+	// Retry defines how the BuildRuns of the Build are retried when they failed for a
+	// retryable reason.
+	//
+	// +optional
+	Retry *Retry `json:"retry,omitempty"`
 }
 
 // BuildVolume is a volume that will be mounted in build pod during build step
@@ -234,8 +276,150 @@ type VulnerabilityIgnoreOptions struct {
 	//
 	// +optional
 	Unfixed *bool `json:"unfixed,omitempty"`
+
+	// VEX references OpenVEX documents, vulnerabilities with a not_affected statement
+	// that applies to the image or the vulnerable package are suppressed
+	//
+	// +optional
+	VEX []VEXDocument `json:"vex,omitempty"`
+}
+
+// VEXDocument references an OpenVEX document, exactly one of ConfigMap and Image must be set
+type VEXDocument struct {
+	// ConfigMap references a key of a ConfigMap in the namespace of the BuildRun that
+	// contains the OpenVEX document
+	//
+	// +optional
+	ConfigMap *VEXConfigMapKeyRef `json:"configMap,omitempty"`
+
+	// Image references an OCI artifact that contains the OpenVEX document in its layer,
+	// it is pulled with the credentials of the output image
+	//
+	// +optional
+	Image *string `json:"image,omitempty"`
+}
+
+// VEXConfigMapKeyRef references a key of a ConfigMap
+type VEXConfigMapKeyRef struct {
+	// Name of the ConfigMap
+	Name string `json:"name"`
+
+	// Key in the ConfigMap that contains the OpenVEX document
+	Key string `json:"key"`
+}
+
+// SBOMFormat is an enum for the possible formats of a software bill of materials
+type SBOMFormat string
+
+const (
+	// SBOMFormatSPDXJSON indicates the SPDX format using JSON encoding
+	SBOMFormatSPDXJSON SBOMFormat = "spdx-json"
+
+	// SBOMFormatCycloneDX indicates the CycloneDX format using JSON encoding
+	SBOMFormatCycloneDX SBOMFormat = "cyclonedx"
+)
+
+// ImageExportFormat is an enum for the possible formats of an exported image
+type ImageExportFormat string
+
+const (
+	// ImageExportFormatOCILayout indicates an OCI image layout directory
+	ImageExportFormatOCILayout ImageExportFormat = "OCILayout"
+
+	// ImageExportFormatTarball indicates a tarball in the format of docker save
+	ImageExportFormatTarball ImageExportFormat = "Tarball"
+)
+
+// ImageExport defines that the image is written to a volume instead of being pushed to a registry
+type ImageExport struct {
+	// Format is the format of the exported image, supported values are:
+	// - "OCILayout", for an OCI image layout directory
+	// - "Tarball", for a tarball in the format of docker save, which cannot contain an image index
+	//
+	// +kubebuilder:validation:Enum=OCILayout;Tarball
+	Format ImageExportFormat `json:"format"`
+
+	// PersistentVolumeClaim is the name of the PersistentVolumeClaim that the image is written to
+	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
+
+	// Path is the relative path of the directory in the volume that the image is written to,
+	// the root of the volume is used if it is omitted
+	//
+	// +optional
+	Path string `json:"path,omitempty"`
 }
 
+// LayerFormat is an enum for the possible compression formats of the layers of an image
+type LayerFormat string
+
+const (
+	// LayerFormatGzip indicates layers that are compressed with gzip
+	LayerFormatGzip LayerFormat = "gzip"
+
+	// LayerFormatZstd indicates layers that are compressed with zstd
+	LayerFormatZstd LayerFormat = "zstd"
+
+	// LayerFormatEstargz indicates eStargz layers that can be pulled lazily
+	LayerFormatEstargz LayerFormat = "estargz"
+)
+
+// SBOMOptions provides configurations about generating a software bill of materials for your generated image
+type SBOMOptions struct {
+	// Format is the format of the software bill of materials, valid values are:
+	// - "spdx-json", for SPDX using JSON encoding
+	// - "cyclonedx", for CycloneDX using JSON encoding
+	//
+	// +kubebuilder:validation:Enum=spdx-json;cyclonedx
+	Format SBOMFormat `json:"format"`
+}
+
+// SignatureStorage is an enum for the possible locations where an image signature is stored
+type SignatureStorage string
+
+const (
+	// SignatureStorageTag indicates that the signature is stored in a tag that is named after the
+	// digest of the image, i.e. sha256-<digest>.sig
+	SignatureStorageTag SignatureStorage = "Tag"
+
+	// SignatureStorageReferrers indicates that the signature is stored as an artifact that refers
+	// to the image through the OCI referrers API
+	SignatureStorageReferrers SignatureStorage = "Referrers"
+)
+
+// SigningOptions provides configurations about signing your generated image
+type SigningOptions struct {
+	// KeySecret references the secret that contains the cosign-compatible ECDSA private key
+	// in the key `cosign.key`, and optionally the password to decrypt it in the key `cosign.password`
+	KeySecret string `json:"keySecret"`
+
+	// Storage defines where the signature is stored, valid values are:
+	// - "Tag", to store it in a tag named after the digest of the image, i.e. sha256-<digest>.sig
+	// - "Referrers", to store it as an artifact that refers to the image
+	//
+	// If not defined, it defaults to "Tag".
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=Tag;Referrers
+	Storage *SignatureStorage `json:"storage,omitempty"`
+}
+
+// ProvenanceOptions provides configurations about generating a SLSA provenance attestation for your generated image
+type ProvenanceOptions struct {
+	// Enabled indicates whether to generate a SLSA provenance attestation for the image
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// VulnerabilityScanner is an enum for the supported vulnerability scanners
+type VulnerabilityScanner string
+
+const (
+	// VulnerabilityScannerTrivy scans the image using trivy
+	VulnerabilityScannerTrivy VulnerabilityScanner = "trivy"
+
+	// VulnerabilityScannerGrype scans the image using grype
+	VulnerabilityScannerGrype VulnerabilityScanner = "grype"
+)
+
 // VulnerabilityScanOptions provides configurations about running a scan for your generated image
 type VulnerabilityScanOptions struct {
 
@@ -247,11 +431,103 @@ type VulnerabilityScanOptions struct {
 
 	// Ignore refers to ignore options for vulnerability scan
 	Ignore *VulnerabilityIgnoreOptions `json:"ignore,omitempty"`
+
+	// Scanner defines the vulnerability scanner to use, the default is defined
+	// in the controller configuration, which defaults to trivy
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=trivy;grype
+	Scanner *VulnerabilityScanner `json:"scanner,omitempty"`
+}
+
+// LicensePolicy defines which licenses are allowed for the packages in your generated image
+type LicensePolicy struct {
+	// Denied is a list of SPDX license identifiers that are not allowed in the image
+	//
+	// +optional
+	Denied []string `json:"denied,omitempty"`
+
+	// Allowed is a list of SPDX license identifiers, if defined, every license in the
+	// image that is not in this list is a violation
+	//
+	// +optional
+	Allowed []string `json:"allowed,omitempty"`
+}
+
+// ImageConfigEnvVar defines an environment variable that is set in the output image
+type ImageConfigEnvVar struct {
+	// Name of the environment variable
+	Name string `json:"name"`
+
+	// Value of the environment variable
+	//
+	// +optional
+	Value string `json:"value,omitempty"`
+}
+
+// ImageConfig defines changes to the configuration of the output image, they are applied
+// to every image of an image index
+type ImageConfig struct {
+	// Env are environment variables that are set in the image, they replace variables
+	// with the same name
+	//
+	// +optional
+	Env []ImageConfigEnvVar `json:"env,omitempty"`
+
+	// Entrypoint replaces the entrypoint of the image, an empty list removes it
+	//
+	// +optional
+	Entrypoint []string `json:"entrypoint"`
+
+	// Cmd replaces the default arguments of the entrypoint of the image, an empty list
+	// removes them
+	//
+	// +optional
+	Cmd []string `json:"cmd"`
+
+	// User is the user, and optionally the group, that the image runs as, for example
+	// 1000 or 1000:1000
+	//
+	// +optional
+	User *string `json:"user,omitempty"`
+
+	// ExposedPorts are ports that are added to the exposed ports of the image, in the
+	// format <port>/<protocol>, the protocol is tcp if omitted
+	//
+	// +optional
+	ExposedPorts []string `json:"exposedPorts,omitempty"`
+
+	// WorkingDir is the absolute path of the working directory of the image
+	//
+	// +optional
+	WorkingDir *string `json:"workingDir,omitempty"`
+
+	// StopSignal is the signal that is sent to stop a container of the image, for
+	// example SIGTERM
+	//
+	// +optional
+	StopSignal *string `json:"stopSignal,omitempty"`
+}
+
+// ImageMirror refers to another image reference that the output image is copied to
+type ImageMirror struct {
+	// Image is the reference of the mirror, it can be in another registry
+	Image string `json:"image"`
+
+	// Describes the secret name for pushing to the mirror.
+	//
+	// +optional
+	PushSecret *string `json:"pushSecret,omitempty"`
 }
 
 // Image refers to an container image with credentials
 type Image struct {
 	// Image is the reference of the image.
+	//
+	// It can contain the placeholders $(build.name), $(buildrun.name), $(params.<name>),
+	// $(source.git.commitSha), $(source.git.branchName), and $(source.timestamp), which
+	// are resolved when the build runs. The same placeholders can be used in additional
+	// tags and mirrors.
 	Image string `json:"image"`
 
 	// Insecure defines whether the registry is not secure
@@ -264,6 +540,16 @@ type Image struct {
 	// +optional
 	PushSecret *string `json:"pushSecret,omitempty"`
 
+	// AdditionalTags are tags that are added to the pushed image in the repository of the image
+	//
+	// +optional
+	AdditionalTags []string `json:"additionalTags,omitempty"`
+
+	// Mirrors are image references that the pushed image is copied to
+	//
+	// +optional
+	Mirrors []ImageMirror `json:"mirrors,omitempty"`
+
 	// Annotations references the additional annotations to be applied on the image
 	//
 	// +optional
@@ -274,11 +560,78 @@ type Image struct {
 	// +optional
 	Labels map[string]string `json:"labels,omitempty"`
 
+	// IndexAnnotations references the additional annotations to be applied on the image
+	// index only, they are ignored if the image is not an image index
+	//
+	// +optional
+	IndexAnnotations map[string]string `json:"indexAnnotations,omitempty"`
+
+	// Platforms are the platforms in the format os/architecture[/variant] that are kept
+	// in the image index, the manifests of other platforms are removed from it. If the
+	// image is not an image index, its platform must be one of them.
+	//
+	// +optional
+	Platforms []string `json:"platforms,omitempty"`
+
+	// OCIAnnotations defines whether the standard OCI annotations for the source, revision,
+	// creation time, reference name and base image are added to the image. It overrides the
+	// default of the cluster.
+	//
+	// +optional
+	OCIAnnotations *bool `json:"ociAnnotations,omitempty"`
+
+	// Config defines changes to the configuration of the image, like its environment
+	// variables, entrypoint, or user
+	//
+	// +optional
+	Config *ImageConfig `json:"config,omitempty"`
+
 	// VulnerabilityScan provides configurations about running a scan for your generated image
 	//
 	// +optional
 	VulnerabilityScan *VulnerabilityScanOptions `json:"vulnerabilityScan,omitempty"`
 
+	// LicensePolicy defines which licenses are allowed for the packages in your generated
+	// image, the build run fails if the license scan finds a violation
+	//
+	// +optional
+	LicensePolicy *LicensePolicy `json:"licensePolicy,omitempty"`
+
+	// SBOM provides configurations about generating a software bill of materials for your
+	// generated image, it is attached to the image as an OCI referrer artifact
+	//
+	// +optional
+	SBOM *SBOMOptions `json:"sbom,omitempty"`
+
+	// Signing provides configurations about signing your generated image, including each
+	// image of an image index
+	//
+	// +optional
+	Signing *SigningOptions `json:"signing,omitempty"`
+
+	// Provenance provides configurations about generating a SLSA provenance attestation
+	// for your generated image, it is signed if signing is configured
+	//
+	// +optional
+	Provenance *ProvenanceOptions `json:"provenance,omitempty"`
+
+	// LayerFormat defines the format that the layers of the image are converted to before
+	// it is pushed, supported values are:
+	// - "gzip", to compress the layers with gzip
+	// - "zstd", to compress the layers with zstd, which requires an OCI image manifest
+	// - "estargz", to convert the layers to eStargz for lazy pulling
+	// Layers that are already in the format are not changed
+	//
+	// +kubebuilder:validation:Enum=gzip;zstd;estargz
+	// +optional
+	LayerFormat *LayerFormat `json:"layerFormat,omitempty"`
+
+	// Export defines that the image is written to a volume instead of being pushed to the
+	// registry, the image reference is used as its name
+	//
+	// +optional
+	Export *ImageExport `json:"export,omitempty"`
+
 	// Timestamp references the optional image timestamp to be set, valid values are:
 	// - "Zero", to set 00:00:00 UTC on 1 January 1970
 	// - "SourceTimestamp", to set the source timestamp dereived from the input source
@@ -381,6 +734,14 @@ func (b Build) GetSourceCredentials() *string {
 		if b.Spec.Source.OCIArtifact != nil && b.Spec.Source.OCIArtifact.PullSecret != nil {
 			return b.Spec.Source.OCIArtifact.PullSecret
 		}
+	case ObjectStorageType:
+		if b.Spec.Source.ObjectStorage != nil && b.Spec.Source.ObjectStorage.CredentialsSecret != nil {
+			return b.Spec.Source.ObjectStorage.CredentialsSecret
+		}
+	case InlineType:
+		if b.Spec.Source.Inline != nil && b.Spec.Source.Inline.SecretRef != nil {
+			return b.Spec.Source.Inline.SecretRef
+		}
 	default:
 		if b.Spec.Source.Git != nil && b.Spec.Source.Git.CloneSecret != nil {
 			return b.Spec.Source.Git.CloneSecret
@@ -49,6 +49,7 @@ func (src *BuildRun) ConvertTo(ctx context.Context, obj *unstructured.Unstructur
 			Type:    v1alpha1.LocalCopy,
 			Timeout: src.Spec.Source.Local.Timeout,
 		})
+		alphaBuildRun.Spec.Sources = append(alphaBuildRun.Spec.Sources, getAlphaAdditionalLocalSources(src.Spec.Source.AdditionalLocals)...)
 	}
 
 	// BuildRunSpec ServiceAccount
@@ -288,6 +289,7 @@ func (dest *BuildRunSpec) ConvertFrom(orig *v1alpha1.BuildRunSpec) error {
 				Name:    orig.Sources[index].Name,
 				Timeout: orig.Sources[index].Timeout,
 			},
+			AdditionalLocals: getAdditionalLocals(orig.Sources, index),
 		}
 	}
 
@@ -115,6 +115,19 @@ type BuildRunSpec struct {
 	//
 	// +optional
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// SourceWorkspace defines the volume that holds the source.
+	// It takes precedence over the source workspace of the Build.
+	// If not defined, an emptyDir volume is used.
+	//
+	// +optional
+	SourceWorkspace *SourceWorkspace `json:"sourceWorkspace,omitempty"`
+
+	// Retry defines how the BuildRun is retried when it failed for a retryable reason.
+	// It takes precedence over the retry of the Build.
+	//
+	// +optional
+	Retry *Retry `json:"retry,omitempty"`
 }
 
 // BuildRunRequestedState defines the buildrun state the user can provide to override whatever is the current state.
@@ -133,12 +146,28 @@ const (
 	// BuildRunStateVulnerabilitiesFound indicates that unignored vulnerabilities were found in the image that was built
 	BuildRunStateVulnerabilitiesFound = "VulnerabilitiesFound"
 
+	// BuildRunStateVulnerabilityScanFailed indicates that the vulnerability scanner failed to scan the image,
+	// for example because it could not download its database
+	BuildRunStateVulnerabilityScanFailed = "VulnerabilityScanFailed"
+
+	// BuildRunStateLicenseViolation indicates that the image that was built contains packages
+	// with licenses that are not allowed by the license policy
+	BuildRunStateLicenseViolation = "LicenseViolation"
+
+	// BuildRunStateImagePushFailed indicates that the image that was built could not be pushed to the
+	// registry, its additional tags, or its mirrors, after all retries
+	BuildRunStateImagePushFailed = "ImagePushFailed"
+
 	// BuildRunStatePodEvicted indicates that if the pods got evicted
 	// due to some reason. (Probably ran out of ephemeral storage)
 	BuildRunStatePodEvicted = "PodEvicted"
 
 	// BuildRunStateStepOutOfMemory indicates that a step failed because it went out of memory.
 	BuildRunStateStepOutOfMemory = "StepOutOfMemory"
+
+	// BuildRunStateRetrying indicates that the TaskRun of the BuildRun failed for a retryable reason
+	// and that a new TaskRun is created after the backoff
+	BuildRunStateRetrying = "Retrying"
 )
 
 // SourceResult holds the results emitted from the different sources
@@ -156,6 +185,24 @@ type SourceResult struct {
 	// +optional
 	OciArtifact *OciArtifactSourceResult `json:"ociArtifact,omitempty"`
 
+	// Local holds the results emitted from
+	// the source step of type local
+	//
+	// +optional
+	Local *LocalSourceResult `json:"local,omitempty"`
+
+	// AdditionalLocals holds the results emitted from the
+	// source steps of the additional local sources
+	//
+	// +optional
+	AdditionalLocals []LocalSourceResult `json:"additionalLocals,omitempty"`
+
+	// ObjectStorage holds the results emitted from
+	// the source step of type object storage
+	//
+	// +optional
+	ObjectStorage *ObjectStorageSourceResult `json:"objectStorage,omitempty"`
+
 	// Timestamp holds the timestamp of the source, which
 	// depends on the actual source type and could range from
 	// being the commit timestamp or the fileystem timestamp
@@ -171,6 +218,36 @@ type OciArtifactSourceResult struct {
 	Digest string `json:"digest,omitempty"`
 }
 
+// LocalSourceResult holds the results emitted from the local source
+type LocalSourceResult struct {
+	// Name holds the name of the additional local source
+	//
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Digest holds the digest computed over the content of the uploaded source
+	Digest string `json:"digest,omitempty"`
+}
+
+// ObjectStorageSourceResult holds the results emitted from the object storage source
+type ObjectStorageSourceResult struct {
+	// Digest holds the SHA-256 digest of the downloaded object, or the digest
+	// of the downloaded directory in case of a prefix
+	Digest string `json:"digest,omitempty"`
+
+	// ETag holds the ETag of the downloaded object, it is not set in case
+	// of a prefix
+	//
+	// +optional
+	ETag string `json:"etag,omitempty"`
+
+	// VersionID holds the version of the downloaded object, if the bucket
+	// has versioning enabled
+	//
+	// +optional
+	VersionID string `json:"versionId,omitempty"`
+}
+
 // GitSourceResult holds the results emitted from the git source
 type GitSourceResult struct {
 	// CommitSha holds the commit sha of git source
@@ -192,6 +269,37 @@ type Vulnerability struct {
 	Severity VulnerabilitySeverity `json:"severity,omitempty"`
 }
 
+// SuppressedVulnerability defines a vulnerability that was suppressed by a VEX statement
+type SuppressedVulnerability struct {
+	ID            string                `json:"id,omitempty"`
+	Severity      VulnerabilitySeverity `json:"severity,omitempty"`
+	Justification string                `json:"justification,omitempty"`
+}
+
+// LicenseViolation defines a package in the image with a license that is not allowed by the license policy
+type LicenseViolation struct {
+	Package string `json:"package,omitempty"`
+	License string `json:"license,omitempty"`
+}
+
+// PlatformDigest defines the digest of the image for a platform in the output image index
+type PlatformDigest struct {
+	// Platform is the platform of the image in the format os/architecture[/variant]
+	Platform string `json:"platform"`
+
+	// Digest is the digest of the image
+	Digest string `json:"digest"`
+}
+
+// VulnerabilitySummary holds the number of vulnerabilities per severity that were found in an image
+type VulnerabilitySummary struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+}
+
 // Output holds the information about the container image that the BuildRun built
 type Output struct {
 	// Digest holds the digest of output image
@@ -204,10 +312,82 @@ type Output struct {
 	// +optional
 	Size int64 `json:"size,omitempty"`
 
+	// Images holds all references the output image was pushed to, which are the
+	// output image, its additional tags, and its mirrors
+	//
+	// +optional
+	Images []string `json:"images,omitempty"`
+
+	// Platforms holds the digests of the images for the platforms in the output image
+	// if it is an image index
+	//
+	// +optional
+	Platforms []PlatformDigest `json:"platforms,omitempty"`
+
 	// Vulnerabilities holds the list of vulnerabilities detected in the image
 	//
 	// +optional
 	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty"`
+
+	// SuppressedVulnerabilities holds the list of vulnerabilities detected in the image
+	// that were suppressed by a VEX statement
+	//
+	// +optional
+	SuppressedVulnerabilities []SuppressedVulnerability `json:"suppressedVulnerabilities,omitempty"`
+
+	// SuppressedVulnerabilitiesTruncated is true if the list of suppressed vulnerabilities
+	// was truncated because it exceeded the maximum result size
+	//
+	// +optional
+	SuppressedVulnerabilitiesTruncated bool `json:"suppressedVulnerabilitiesTruncated,omitempty"`
+
+	// LicenseViolations holds the list of packages in the image with a license that
+	// is not allowed by the license policy
+	//
+	// +optional
+	LicenseViolations []LicenseViolation `json:"licenseViolations,omitempty"`
+
+	// LicenseViolationsTruncated is true if the list of license violations was
+	// truncated because it exceeded the maximum result size
+	//
+	// +optional
+	LicenseViolationsTruncated bool `json:"licenseViolationsTruncated,omitempty"`
+
+	// SBOMDigest holds the digest of the software bill of materials artifact that
+	// is attached to the output image
+	//
+	// +optional
+	SBOMDigest string `json:"sbomDigest,omitempty"`
+
+	// VulnerabilityDBUpdatedAt holds when the pre-populated vulnerability database
+	// that was used to scan the output image was updated
+	//
+	// +optional
+	VulnerabilityDBUpdatedAt *metav1.Time `json:"vulnerabilityDBUpdatedAt,omitempty"`
+
+	// VulnerabilitySummary holds the number of vulnerabilities per severity that were
+	// detected in the image, it is not limited like the list of vulnerabilities
+	//
+	// +optional
+	VulnerabilitySummary *VulnerabilitySummary `json:"vulnerabilitySummary,omitempty"`
+
+	// VulnerabilityReportDigest holds the digest of the artifact with the complete
+	// vulnerability report that is attached to the output image
+	//
+	// +optional
+	VulnerabilityReportDigest string `json:"vulnerabilityReportDigest,omitempty"`
+
+	// VulnerabilityReportConfigMap holds the name of the ConfigMap with the complete
+	// vulnerability report if the output image was not pushed
+	//
+	// +optional
+	VulnerabilityReportConfigMap string `json:"vulnerabilityReportConfigMap,omitempty"`
+
+	// VulnerabilityReportOmitted holds why the complete vulnerability report was not
+	// stored in a ConfigMap, for example because it exceeds the maximum result size
+	//
+	// +optional
+	VulnerabilityReportOmitted string `json:"vulnerabilityReportOmitted,omitempty"`
 }
 
 // BuildRunStatus defines the observed state of BuildRun
@@ -245,6 +425,11 @@ type BuildRunStatus struct {
 	// FailureDetails contains error details that are collected and surfaced from TaskRun
 	// +optional
 	FailureDetails *FailureDetails `json:"failureDetails,omitempty"`
+
+	// Attempts contains the previous attempts of a retried BuildRun that failed,
+	// the current attempt is the one of the TaskRunName
+	// +optional
+	Attempts []BuildRunAttempt `json:"attempts,omitempty"`
 }
 
 // Location describes the location where the failure happened
@@ -19,6 +19,10 @@ const (
 
 	// LabelBuildStrategyGeneration is a label key for defining the build strategy generation
 	LabelBuildStrategyGeneration = BuildStrategyDomain + "/generation"
+
+	// AnnotationBaseImageParameter is an annotation of a BuildStrategy or ClusterBuildStrategy that names the
+	// string parameter whose value is the base image of the output image
+	AnnotationBaseImageParameter = BuildStrategyDomain + "/base-image-parameter"
 )
 
 // +genclient
@@ -0,0 +1,85 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1beta1
+
+import (
+	"time"
+
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultRetryBackoff is the time to wait before the second attempt of a BuildRun if the retry
+	// does not define a backoff
+	DefaultRetryBackoff = 30 * time.Second
+
+	// GitErrorReason is the failure reason of the Git source step for errors that are not related to
+	// authentication or to a missing repository or revision, for example network errors
+	GitErrorReason = "GitError"
+)
+
+// DefaultRetryReasons are the infrastructure failure reasons that are retried if the retry does not
+// define reasons
+var DefaultRetryReasons = []string{
+	BuildRunStatePodEvicted,
+	BuildRunStateImagePushFailed,
+	string(pipelineapi.TaskRunReasonImagePullFailed),
+	GitErrorReason,
+}
+
+// Retry defines how a BuildRun is retried with a new TaskRun when its TaskRun failed for a
+// retryable reason
+type Retry struct {
+	// MaxAttempts is the maximum number of TaskRuns of a BuildRun, including the first one.
+	//
+	// +kubebuilder:validation:Minimum=1
+	MaxAttempts int `json:"maxAttempts"`
+
+	// Backoff is the time to wait before the second attempt, it doubles for every further
+	// attempt up to one hour.
+	//
+	// If not defined, it defaults to 30s.
+	//
+	// +optional
+	// +kubebuilder:validation:Format=duration
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+
+	// Reasons are the failure reasons that are retried, a failure is retried if the reason of the
+	// Succeeded condition or of the failure details of the BuildRun is one of them. The reasons
+	// VulnerabilitiesFound and LicenseViolation cannot be retried because another attempt leads
+	// to the same result. BuildRuns with a Local source are never retried because the source is
+	// uploaded only once.
+	//
+	// If not defined, it defaults to the infrastructure reasons PodEvicted, ImagePushFailed,
+	// TaskRunImagePullFailed and GitError.
+	//
+	// +optional
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// BuildRunAttempt is a previous attempt of a BuildRun that failed and was retried
+type BuildRunAttempt struct {
+	// TaskRunName is the name of the TaskRun of the attempt
+	TaskRunName string `json:"taskRunName"`
+
+	// Reason is the reason of the failure of the attempt
+	Reason string `json:"reason"`
+
+	// Message is the message of the failure of the attempt
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// StartTime is the time the TaskRun of the attempt started
+	//
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the TaskRun of the attempt failed
+	//
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
@@ -23,6 +23,14 @@ const GitType BuildSourceType = "Git"
 // OCIArtifactType represents a source code bundle container image to pull. This is where the source code resides.
 const OCIArtifactType BuildSourceType = "OCI"
 
+// ObjectStorageType represents an object or a set of objects in an S3-compatible object storage
+// that is downloaded. This is where the source code resides.
+const ObjectStorageType BuildSourceType = "ObjectStorage"
+
+// InlineType represents files that are defined in the Build itself, or in a ConfigMap or
+// Secret, and that are written into the source directory.
+const InlineType BuildSourceType = "Inline"
+
 const (
 	// Do not delete image after it was pulled
 	PruneNever PruneOption = "Never"
@@ -82,6 +90,73 @@ type OCIArtifact struct {
 	PullSecret *string `json:"pullSecret,omitempty"`
 }
 
+// ObjectStorage describes the object, or the objects under a prefix, to download
+// from an S3-compatible object storage
+type ObjectStorage struct {
+	// Endpoint is the URL of the S3-compatible object storage, i.e.
+	// https://s3.us-east-1.amazonaws.com or http://minio.minio.svc:9000
+	Endpoint string `json:"endpoint"`
+
+	// Region is the region used to sign the requests.
+	//
+	// If not defined, it defaults to 'us-east-1'.
+	//
+	// +optional
+	Region *string `json:"region,omitempty"`
+
+	// Bucket is the name of the bucket that contains the source.
+	Bucket string `json:"bucket"`
+
+	// Key is the key of a single object to download. If the object is a tar
+	// archive, optionally gzip compressed, it is extracted. Otherwise, it is
+	// placed into the source directory using the last segment of the key
+	// as file name. Exactly one of Key and Prefix must be defined.
+	//
+	// +optional
+	Key *string `json:"key,omitempty"`
+
+	// Prefix selects all objects with a key that starts with it. They are
+	// placed into the source directory using the remainder of the key
+	// as relative path. Exactly one of Key and Prefix must be defined.
+	//
+	// +optional
+	Prefix *string `json:"prefix,omitempty"`
+
+	// VersionID selects a specific version of the object in a bucket that has
+	// versioning enabled. It can only be used together with Key.
+	//
+	// +optional
+	VersionID *string `json:"versionId,omitempty"`
+
+	// CredentialsSecret references a Secret that contains the access key in
+	// the 'accessKeyID' key, and the secret key in the 'secretAccessKey' key.
+	//
+	// +optional
+	CredentialsSecret *string `json:"credentialsSecret,omitempty"`
+}
+
+// Inline describes files that are materialized in the source directory
+type Inline struct {
+	// Files maps file paths, relative to the source directory, to their
+	// content, i.e. a Dockerfile that extends a base image. The paths must
+	// not contain an equal sign.
+	//
+	// +optional
+	Files map[string]string `json:"files,omitempty"`
+
+	// ConfigMapRef references a ConfigMap in the namespace of the Build. Each
+	// key of the ConfigMap is written as a file into the source directory.
+	//
+	// +optional
+	ConfigMapRef *string `json:"configMapRef,omitempty"`
+
+	// SecretRef references a Secret in the namespace of the Build. Each key of
+	// the Secret is written as a file into the source directory.
+	//
+	// +optional
+	SecretRef *string `json:"secretRef,omitempty"`
+}
+
 // Source describes the build source type to fetch.
 type Source struct {
 	// Type is the BuildSource qualifier, the type of the source.
@@ -106,6 +181,23 @@ type Source struct {
 	//
 	// +optional
 	Local *Local `json:"local,omitempty"`
+
+	// ObjectStorage contains the details for the source of type ObjectStorage
+	//
+	// +optional
+	ObjectStorage *ObjectStorage `json:"objectStorage,omitempty"`
+
+	// Inline contains the details for the source of type Inline
+	//
+	// +optional
+	Inline *Inline `json:"inline,omitempty"`
+
+	// AdditionalLocals contains further named local sources for the source of
+	// type Local. Each of them is uploaded individually into a sub-directory of
+	// the source directory that matches its name.
+	//
+	// +optional
+	AdditionalLocals []Local `json:"additionalLocals,omitempty"`
 }
 
 // BuildRunSource describes the local source to use
@@ -119,4 +211,11 @@ type BuildRunSource struct {
 	//
 	// +optional
 	Local *Local `json:"local,omitempty"`
+
+	// AdditionalLocals contains further named local sources for the source of
+	// type Local. Each of them is uploaded individually into a sub-directory of
+	// the source directory that matches its name.
+	//
+	// +optional
+	AdditionalLocals []Local `json:"additionalLocals,omitempty"`
 }
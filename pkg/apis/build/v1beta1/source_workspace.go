@@ -0,0 +1,49 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1beta1
+
+import corev1 "k8s.io/api/core/v1"
+
+// SourceWorkspaceDeletionPolicy defines when a generated PersistentVolumeClaim is deleted
+type SourceWorkspaceDeletionPolicy string
+
+const (
+	// SourceWorkspaceDelete deletes the PersistentVolumeClaim when the BuildRun completed
+	SourceWorkspaceDelete SourceWorkspaceDeletionPolicy = "Delete"
+
+	// SourceWorkspaceDeleteOnSuccess deletes the PersistentVolumeClaim when the BuildRun
+	// succeeded, and retains it until the BuildRun is deleted otherwise
+	SourceWorkspaceDeleteOnSuccess SourceWorkspaceDeletionPolicy = "DeleteOnSuccess"
+
+	// SourceWorkspaceRetain retains the PersistentVolumeClaim until the BuildRun is deleted
+	SourceWorkspaceRetain SourceWorkspaceDeletionPolicy = "Retain"
+)
+
+// SourceWorkspace describes the volume that holds the source. If not defined, an
+// emptyDir volume is used.
+type SourceWorkspace struct {
+	// VolumeClaimTemplate is the specification of a PersistentVolumeClaim that is
+	// created for each BuildRun. The claim is owned by the BuildRun.
+	//
+	// +optional
+	VolumeClaimTemplate *corev1.PersistentVolumeClaimSpec `json:"volumeClaimTemplate,omitempty"`
+
+	// PersistentVolumeClaimName references an existing PersistentVolumeClaim. Each
+	// BuildRun uses a sub-directory of the volume that matches its name.
+	//
+	// +optional
+	PersistentVolumeClaimName *string `json:"persistentVolumeClaimName,omitempty"`
+
+	// DeletionPolicy defines when the PersistentVolumeClaim that is created from
+	// the VolumeClaimTemplate is deleted. Allowed values are 'Delete' (deletion
+	// when the BuildRun completed), 'DeleteOnSuccess' (deletion when the BuildRun
+	// succeeded) and 'Retain' (deletion together with the BuildRun).
+	//
+	// If not defined, it defaults to 'Delete'.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=Delete;DeleteOnSuccess;Retain
+	DeletionPolicy *SourceWorkspaceDeletionPolicy `json:"deletionPolicy,omitempty"`
+}
@@ -145,6 +145,30 @@ func (in *BuildRun) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildRunAttempt) DeepCopyInto(out *BuildRunAttempt) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildRunAttempt.
+func (in *BuildRunAttempt) DeepCopy() *BuildRunAttempt {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildRunAttempt)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildRunList) DeepCopyInto(out *BuildRunList) {
 	*out = *in
@@ -212,6 +236,13 @@ func (in *BuildRunSource) DeepCopyInto(out *BuildRunSource) {
 		*out = new(Local)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AdditionalLocals != nil {
+		in, out := &in.AdditionalLocals, &out.AdditionalLocals
+		*out = make([]Local, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -287,6 +318,16 @@ func (in *BuildRunSpec) DeepCopyInto(out *BuildRunSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.SourceWorkspace != nil {
+		in, out := &in.SourceWorkspace, &out.SourceWorkspace
+		*out = new(SourceWorkspace)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(Retry)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -343,6 +384,13 @@ func (in *BuildRunStatus) DeepCopyInto(out *BuildRunStatus) {
 		*out = new(FailureDetails)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Attempts != nil {
+		in, out := &in.Attempts, &out.Attempts
+		*out = make([]BuildRunAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -409,6 +457,16 @@ func (in *BuildSpec) DeepCopyInto(out *BuildSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.SourceWorkspace != nil {
+		in, out := &in.SourceWorkspace, &out.SourceWorkspace
+		*out = new(SourceWorkspace)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(Retry)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -808,6 +866,18 @@ func (in *Image) DeepCopyInto(out *Image) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.AdditionalTags != nil {
+		in, out := &in.AdditionalTags, &out.AdditionalTags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Mirrors != nil {
+		in, out := &in.Mirrors, &out.Mirrors
+		*out = make([]ImageMirror, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Annotations != nil {
 		in, out := &in.Annotations, &out.Annotations
 		*out = make(map[string]string, len(*in))
@@ -822,11 +892,63 @@ func (in *Image) DeepCopyInto(out *Image) {
 			(*out)[key] = val
 		}
 	}
+	if in.IndexAnnotations != nil {
+		in, out := &in.IndexAnnotations, &out.IndexAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Platforms != nil {
+		in, out := &in.Platforms, &out.Platforms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OCIAnnotations != nil {
+		in, out := &in.OCIAnnotations, &out.OCIAnnotations
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(ImageConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.VulnerabilityScan != nil {
 		in, out := &in.VulnerabilityScan, &out.VulnerabilityScan
 		*out = new(VulnerabilityScanOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.LicensePolicy != nil {
+		in, out := &in.LicensePolicy, &out.LicensePolicy
+		*out = new(LicensePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SBOM != nil {
+		in, out := &in.SBOM, &out.SBOM
+		*out = new(SBOMOptions)
+		**out = **in
+	}
+	if in.Signing != nil {
+		in, out := &in.Signing, &out.Signing
+		*out = new(SigningOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Provenance != nil {
+		in, out := &in.Provenance, &out.Provenance
+		*out = new(ProvenanceOptions)
+		**out = **in
+	}
+	if in.LayerFormat != nil {
+		in, out := &in.LayerFormat, &out.LayerFormat
+		*out = new(LayerFormat)
+		**out = **in
+	}
+	if in.Export != nil {
+		in, out := &in.Export, &out.Export
+		*out = new(ImageExport)
+		**out = **in
+	}
 	if in.Timestamp != nil {
 		in, out := &in.Timestamp, &out.Timestamp
 		*out = new(string)
@@ -845,6 +967,185 @@ func (in *Image) DeepCopy() *Image {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageConfig) DeepCopyInto(out *ImageConfig) {
+	*out = *in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]ImageConfigEnvVar, len(*in))
+		copy(*out, *in)
+	}
+	if in.Entrypoint != nil {
+		in, out := &in.Entrypoint, &out.Entrypoint
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Cmd != nil {
+		in, out := &in.Cmd, &out.Cmd
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.User != nil {
+		in, out := &in.User, &out.User
+		*out = new(string)
+		**out = **in
+	}
+	if in.ExposedPorts != nil {
+		in, out := &in.ExposedPorts, &out.ExposedPorts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WorkingDir != nil {
+		in, out := &in.WorkingDir, &out.WorkingDir
+		*out = new(string)
+		**out = **in
+	}
+	if in.StopSignal != nil {
+		in, out := &in.StopSignal, &out.StopSignal
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageConfig.
+func (in *ImageConfig) DeepCopy() *ImageConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageConfigEnvVar) DeepCopyInto(out *ImageConfigEnvVar) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageConfigEnvVar.
+func (in *ImageConfigEnvVar) DeepCopy() *ImageConfigEnvVar {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageConfigEnvVar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageExport) DeepCopyInto(out *ImageExport) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageExport.
+func (in *ImageExport) DeepCopy() *ImageExport {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageMirror) DeepCopyInto(out *ImageMirror) {
+	*out = *in
+	if in.PushSecret != nil {
+		in, out := &in.PushSecret, &out.PushSecret
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageMirror.
+func (in *ImageMirror) DeepCopy() *ImageMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Inline) DeepCopyInto(out *Inline) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(string)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Inline.
+func (in *Inline) DeepCopy() *Inline {
+	if in == nil {
+		return nil
+	}
+	out := new(Inline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LicensePolicy) DeepCopyInto(out *LicensePolicy) {
+	*out = *in
+	if in.Denied != nil {
+		in, out := &in.Denied, &out.Denied
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Allowed != nil {
+		in, out := &in.Allowed, &out.Allowed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicensePolicy.
+func (in *LicensePolicy) DeepCopy() *LicensePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(LicensePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LicenseViolation) DeepCopyInto(out *LicenseViolation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseViolation.
+func (in *LicenseViolation) DeepCopy() *LicenseViolation {
+	if in == nil {
+		return nil
+	}
+	out := new(LicenseViolation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Local) DeepCopyInto(out *Local) {
 	*out = *in
@@ -866,6 +1167,22 @@ func (in *Local) DeepCopy() *Local {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalSourceResult) DeepCopyInto(out *LocalSourceResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalSourceResult.
+func (in *LocalSourceResult) DeepCopy() *LocalSourceResult {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalSourceResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Location) DeepCopyInto(out *Location) {
 	*out = *in
@@ -929,6 +1246,63 @@ func (in *ObjectKeyRef) DeepCopy() *ObjectKeyRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStorage) DeepCopyInto(out *ObjectStorage) {
+	*out = *in
+	if in.Region != nil {
+		in, out := &in.Region, &out.Region
+		*out = new(string)
+		**out = **in
+	}
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(string)
+		**out = **in
+	}
+	if in.Prefix != nil {
+		in, out := &in.Prefix, &out.Prefix
+		*out = new(string)
+		**out = **in
+	}
+	if in.VersionID != nil {
+		in, out := &in.VersionID, &out.VersionID
+		*out = new(string)
+		**out = **in
+	}
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorage.
+func (in *ObjectStorage) DeepCopy() *ObjectStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStorageSourceResult) DeepCopyInto(out *ObjectStorageSourceResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorageSourceResult.
+func (in *ObjectStorageSourceResult) DeepCopy() *ObjectStorageSourceResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStorageSourceResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OciArtifactSourceResult) DeepCopyInto(out *OciArtifactSourceResult) {
 	*out = *in
@@ -948,11 +1322,40 @@ func (in *OciArtifactSourceResult) DeepCopy() *OciArtifactSourceResult {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Output) DeepCopyInto(out *Output) {
 	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Platforms != nil {
+		in, out := &in.Platforms, &out.Platforms
+		*out = make([]PlatformDigest, len(*in))
+		copy(*out, *in)
+	}
 	if in.Vulnerabilities != nil {
 		in, out := &in.Vulnerabilities, &out.Vulnerabilities
 		*out = make([]Vulnerability, len(*in))
 		copy(*out, *in)
 	}
+	if in.SuppressedVulnerabilities != nil {
+		in, out := &in.SuppressedVulnerabilities, &out.SuppressedVulnerabilities
+		*out = make([]SuppressedVulnerability, len(*in))
+		copy(*out, *in)
+	}
+	if in.LicenseViolations != nil {
+		in, out := &in.LicenseViolations, &out.LicenseViolations
+		*out = make([]LicenseViolation, len(*in))
+		copy(*out, *in)
+	}
+	if in.VulnerabilityDBUpdatedAt != nil {
+		in, out := &in.VulnerabilityDBUpdatedAt, &out.VulnerabilityDBUpdatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.VulnerabilitySummary != nil {
+		in, out := &in.VulnerabilitySummary, &out.VulnerabilitySummary
+		*out = new(VulnerabilitySummary)
+		**out = **in
+	}
 	return
 }
 
@@ -1024,6 +1427,38 @@ func (in *Parameter) DeepCopy() *Parameter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlatformDigest) DeepCopyInto(out *PlatformDigest) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlatformDigest.
+func (in *PlatformDigest) DeepCopy() *PlatformDigest {
+	if in == nil {
+		return nil
+	}
+	out := new(PlatformDigest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvenanceOptions) DeepCopyInto(out *ProvenanceOptions) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvenanceOptions.
+func (in *ProvenanceOptions) DeepCopy() *ProvenanceOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvenanceOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReferencedBuild) DeepCopyInto(out *ReferencedBuild) {
 	*out = *in
@@ -1050,6 +1485,69 @@ func (in *ReferencedBuild) DeepCopy() *ReferencedBuild {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Retry) DeepCopyInto(out *Retry) {
+	*out = *in
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Retry.
+func (in *Retry) DeepCopy() *Retry {
+	if in == nil {
+		return nil
+	}
+	out := new(Retry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SBOMOptions) DeepCopyInto(out *SBOMOptions) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SBOMOptions.
+func (in *SBOMOptions) DeepCopy() *SBOMOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SBOMOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SigningOptions) DeepCopyInto(out *SigningOptions) {
+	*out = *in
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(SignatureStorage)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SigningOptions.
+func (in *SigningOptions) DeepCopy() *SigningOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SigningOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SingleValue) DeepCopyInto(out *SingleValue) {
 	*out = *in
@@ -1104,6 +1602,23 @@ func (in *Source) DeepCopyInto(out *Source) {
 		*out = new(Local)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ObjectStorage != nil {
+		in, out := &in.ObjectStorage, &out.ObjectStorage
+		*out = new(ObjectStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Inline != nil {
+		in, out := &in.Inline, &out.Inline
+		*out = new(Inline)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalLocals != nil {
+		in, out := &in.AdditionalLocals, &out.AdditionalLocals
+		*out = make([]Local, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1130,6 +1645,21 @@ func (in *SourceResult) DeepCopyInto(out *SourceResult) {
 		*out = new(OciArtifactSourceResult)
 		**out = **in
 	}
+	if in.Local != nil {
+		in, out := &in.Local, &out.Local
+		*out = new(LocalSourceResult)
+		**out = **in
+	}
+	if in.AdditionalLocals != nil {
+		in, out := &in.AdditionalLocals, &out.AdditionalLocals
+		*out = make([]LocalSourceResult, len(*in))
+		copy(*out, *in)
+	}
+	if in.ObjectStorage != nil {
+		in, out := &in.ObjectStorage, &out.ObjectStorage
+		*out = new(ObjectStorageSourceResult)
+		**out = **in
+	}
 	if in.Timestamp != nil {
 		in, out := &in.Timestamp, &out.Timestamp
 		*out = (*in).DeepCopy()
@@ -1147,6 +1677,37 @@ func (in *SourceResult) DeepCopy() *SourceResult {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SourceWorkspace) DeepCopyInto(out *SourceWorkspace) {
+	*out = *in
+	if in.VolumeClaimTemplate != nil {
+		in, out := &in.VolumeClaimTemplate, &out.VolumeClaimTemplate
+		*out = new(corev1.PersistentVolumeClaimSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PersistentVolumeClaimName != nil {
+		in, out := &in.PersistentVolumeClaimName, &out.PersistentVolumeClaimName
+		*out = new(string)
+		**out = **in
+	}
+	if in.DeletionPolicy != nil {
+		in, out := &in.DeletionPolicy, &out.DeletionPolicy
+		*out = new(SourceWorkspaceDeletionPolicy)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceWorkspace.
+func (in *SourceWorkspace) DeepCopy() *SourceWorkspace {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceWorkspace)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Step) DeepCopyInto(out *Step) {
 	*out = *in
@@ -1214,6 +1775,22 @@ func (in *Strategy) DeepCopy() *Strategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SuppressedVulnerability) DeepCopyInto(out *SuppressedVulnerability) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SuppressedVulnerability.
+func (in *SuppressedVulnerability) DeepCopy() *SuppressedVulnerability {
+	if in == nil {
+		return nil
+	}
+	out := new(SuppressedVulnerability)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Trigger) DeepCopyInto(out *Trigger) {
 	*out = *in
@@ -1273,6 +1850,48 @@ func (in *TriggerWhen) DeepCopy() *TriggerWhen {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VEXConfigMapKeyRef) DeepCopyInto(out *VEXConfigMapKeyRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VEXConfigMapKeyRef.
+func (in *VEXConfigMapKeyRef) DeepCopy() *VEXConfigMapKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(VEXConfigMapKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VEXDocument) DeepCopyInto(out *VEXDocument) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(VEXConfigMapKeyRef)
+		**out = **in
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VEXDocument.
+func (in *VEXDocument) DeepCopy() *VEXDocument {
+	if in == nil {
+		return nil
+	}
+	out := new(VEXDocument)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Vulnerability) DeepCopyInto(out *Vulnerability) {
 	*out = *in
@@ -1307,6 +1926,13 @@ func (in *VulnerabilityIgnoreOptions) DeepCopyInto(out *VulnerabilityIgnoreOptio
 		*out = new(bool)
 		**out = **in
 	}
+	if in.VEX != nil {
+		in, out := &in.VEX, &out.VEX
+		*out = make([]VEXDocument, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1328,6 +1954,11 @@ func (in *VulnerabilityScanOptions) DeepCopyInto(out *VulnerabilityScanOptions)
 		*out = new(VulnerabilityIgnoreOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Scanner != nil {
+		in, out := &in.Scanner, &out.Scanner
+		*out = new(VulnerabilityScanner)
+		**out = **in
+	}
 	return
 }
 
@@ -1341,6 +1972,22 @@ func (in *VulnerabilityScanOptions) DeepCopy() *VulnerabilityScanOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VulnerabilitySummary) DeepCopyInto(out *VulnerabilitySummary) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VulnerabilitySummary.
+func (in *VulnerabilitySummary) DeepCopy() *VulnerabilitySummary {
+	if in == nil {
+		return nil
+	}
+	out := new(VulnerabilitySummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WhenGitHub) DeepCopyInto(out *WhenGitHub) {
 	*out = *in
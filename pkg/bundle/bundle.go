@@ -7,12 +7,15 @@ package bundle
 import (
 	"archive/tar"
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/fs"
 	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -23,15 +26,26 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"golang.org/x/mod/sumdb/dirhash"
 )
 
-const shpIgnoreFilename = ".shpignore"
+const (
+	shpIgnoreFilename = ".shpignore"
+	gitDirectoryName  = ".git"
+)
 
 // UnpackDetails contains details about the files that were unpacked
 type UnpackDetails struct {
 	MostRecentFileTimestamp *time.Time
 }
 
+// DirectoryDetails contains details about the files of a local directory
+type DirectoryDetails struct {
+	Digest                  string
+	DirHash                 string
+	MostRecentFileTimestamp *time.Time
+}
+
 // PackAndPush a local directory as-is into a container image. See
 // remote.Option for optional options to the image push to the registry, for
 // example to provide the appropriate access credentials.
@@ -321,6 +335,101 @@ func Unpack(in io.Reader, targetPath string) (*UnpackDetails, error) {
 	}
 }
 
+// Inspect walks through a local directory and computes a content digest over
+// all directories, regular files, and symlinks (including their relative path
+// and mode), and determines the most recent file modification timestamp. The
+// Git metadata directory is not considered, because its content changes with
+// every Git operation even if the source itself did not change. Special files
+// like named pipes, sockets, and devices are skipped.
+//
+// In addition, the directory hash of the regular files is computed with the
+// Hash1 function of golang.org/x/mod/sumdb/dirhash, which is the dirHash
+// algorithm of in-toto digest sets. It is empty if a file name contains a
+// newline, which the function does not support.
+func Inspect(directory string) (*DirectoryDetails, error) {
+	var details = DirectoryDetails{}
+	var hash = sha256.New()
+	var files []string
+
+	err := filepath.WalkDir(directory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() && d.Name() == gitDirectoryName {
+			return filepath.SkipDir
+		}
+
+		relPath, err := filepath.Rel(directory, path)
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		// special files like named pipes, sockets, and devices have no content that is
+		// part of the source, they are skipped and do not change the digest
+		if !info.Mode().IsDir() && !info.Mode().IsRegular() && info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+
+		fmt.Fprintf(hash, "%s\x00%s\x00", filepath.ToSlash(relPath), info.Mode().String())
+
+		switch {
+		case info.Mode().IsDir():
+			return nil
+
+		case info.Mode().IsRegular():
+			modTime := info.ModTime()
+			if details.MostRecentFileTimestamp == nil || details.MostRecentFileTimestamp.Before(modTime) {
+				details.MostRecentFileTimestamp = &modTime
+			}
+
+			files = append(files, filepath.ToSlash(relPath))
+
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+
+			defer file.Close()
+
+			_, err = io.Copy(hash, file)
+			return err
+
+		case info.Mode()&os.ModeSymlink == os.ModeSymlink:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.WriteString(hash, target)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	details.Digest = "sha256:" + hex.EncodeToString(hash.Sum(nil))
+
+	if !slices.ContainsFunc(files, func(file string) bool { return strings.Contains(file, "\n") }) {
+		details.DirHash, err = dirhash.Hash1(files, func(file string) (io.ReadCloser, error) {
+			return os.Open(filepath.Join(directory, filepath.FromSlash(file)))
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &details, nil
+}
+
 func fileMode(tarHeader *tar.Header) os.FileMode {
 	mode := tarHeader.Mode
 	if mode < 0 || mode > math.MaxUint32 {
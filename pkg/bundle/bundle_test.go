@@ -7,10 +7,13 @@ package bundle_test
 import (
 	"fmt"
 	"log"
+	"net"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -18,6 +21,7 @@ import (
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/registry"
+	"golang.org/x/mod/sumdb/dirhash"
 	"k8s.io/apimachinery/pkg/util/rand"
 )
 
@@ -113,4 +117,82 @@ var _ = Describe("Bundle", func() {
 			})
 		})
 	})
+
+	Context("inspecting a directory", func() {
+		It("should compute a stable digest and the most recent file timestamp", func() {
+			withTempDir(func(tempDir string) {
+				timestamp := time.Unix(1691650396, 0)
+				Expect(os.WriteFile(filepath.Join(tempDir, "some-file"), []byte(`foobar`), os.FileMode(0644))).To(Succeed())
+				Expect(os.Chtimes(filepath.Join(tempDir, "some-file"), timestamp, timestamp)).To(Succeed())
+
+				details, err := Inspect(tempDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(details.Digest).To(HavePrefix("sha256:"))
+				Expect(details.MostRecentFileTimestamp).ToNot(BeNil())
+				Expect(details.MostRecentFileTimestamp.Unix()).To(Equal(timestamp.Unix()))
+
+				again, err := Inspect(tempDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(again.Digest).To(Equal(details.Digest))
+			})
+		})
+
+		It("should ignore the Git metadata directory, but not changes to the content", func() {
+			withTempDir(func(tempDir string) {
+				Expect(os.WriteFile(filepath.Join(tempDir, "some-file"), []byte(`foobar`), os.FileMode(0644))).To(Succeed())
+
+				before, err := Inspect(tempDir)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(os.Mkdir(filepath.Join(tempDir, ".git"), os.FileMode(0755))).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(tempDir, ".git", "HEAD"), []byte(`ref: refs/heads/main`), os.FileMode(0644))).To(Succeed())
+
+				withGit, err := Inspect(tempDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(withGit.Digest).To(Equal(before.Digest))
+
+				Expect(os.WriteFile(filepath.Join(tempDir, "some-file"), []byte(`barfoo`), os.FileMode(0644))).To(Succeed())
+
+				changed, err := Inspect(tempDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(changed.Digest).ToNot(Equal(before.Digest))
+			})
+		})
+
+		It("should skip named pipes and sockets", func() {
+			withTempDir(func(tempDir string) {
+				Expect(os.WriteFile(filepath.Join(tempDir, "some-file"), []byte(`foobar`), os.FileMode(0644))).To(Succeed())
+
+				before, err := Inspect(tempDir)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(syscall.Mkfifo(filepath.Join(tempDir, "some-pipe"), 0644)).To(Succeed())
+
+				listener, err := net.Listen("unix", filepath.Join(tempDir, "some-socket"))
+				Expect(err).ToNot(HaveOccurred())
+				defer listener.Close()
+
+				details, err := Inspect(tempDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(details.Digest).To(Equal(before.Digest))
+				Expect(details.DirHash).To(Equal(before.DirHash))
+			})
+		})
+
+		It("should compute the directory hash of the Go dirhash package", func() {
+			withTempDir(func(tempDir string) {
+				Expect(os.MkdirAll(filepath.Join(tempDir, "src"), os.FileMode(0755))).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(tempDir, "src", "main.go"), []byte(`package main`), os.FileMode(0644))).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte(`module example.com/app`), os.FileMode(0644))).To(Succeed())
+
+				expected, err := dirhash.HashDir(tempDir, "", dirhash.Hash1)
+				Expect(err).ToNot(HaveOccurred())
+
+				details, err := Inspect(tempDir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(details.DirHash).To(HavePrefix("h1:"))
+				Expect(details.DirHash).To(Equal(expected))
+			})
+		})
+	})
 })
@@ -6,7 +6,9 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -41,6 +43,16 @@ const (
 	bundleImageEnvVar             = "BUNDLE_CONTAINER_IMAGE"
 	bundleContainerTemplateEnvVar = "BUNDLE_CONTAINER_TEMPLATE"
 
+	// the object storage image is also created by ko
+	objectStorageDefaultImage            = "ghcr.io/shipwright-io/build/object-storage:latest"
+	objectStorageImageEnvVar             = "OBJECT_STORAGE_CONTAINER_IMAGE"
+	objectStorageContainerTemplateEnvVar = "OBJECT_STORAGE_CONTAINER_TEMPLATE"
+
+	// the inline image is also created by ko
+	inlineDefaultImage            = "ghcr.io/shipwright-io/build/inline:latest"
+	inlineImageEnvVar             = "INLINE_CONTAINER_IMAGE"
+	inlineContainerTemplateEnvVar = "INLINE_CONTAINER_TEMPLATE"
+
 	// environment variable to hold waiter's container image, created by ko
 	waiterDefaultImage            = "ghcr.io/shipwright-io/build/waiter:latest"
 	waiterImageEnvVar             = "WAITER_CONTAINER_IMAGE"
@@ -79,6 +91,37 @@ const (
 
 	// environment variable to hold vulnerability count limit
 	VulnerabilityCountLimitEnvVar = "VULNERABILITY_COUNT_LIMIT"
+
+	// environment variable to hold the default vulnerability scanner
+	VulnerabilityScannerEnvVar = "VULNERABILITY_SCANNER"
+
+	// environment variable to hold the maximum size of a vulnerability report that is passed in a result
+	VulnerabilityReportMaxResultSizeEnvVar = "VULNERABILITY_REPORT_MAX_RESULT_SIZE"
+
+	// environment variables for a pre-populated vulnerability database
+	vulnerabilityDBVolumeClaimEnvVar = "VULNERABILITY_DB_VOLUME_CLAIM"
+	vulnerabilityDBImageEnvVar       = "VULNERABILITY_DB_IMAGE"
+	vulnerabilityDBMaxAgeEnvVar      = "VULNERABILITY_DB_MAX_AGE"
+
+	// environment variable to enable the standard OCI annotations of output images by default
+	ociAnnotationsEnvVar = "IMAGE_ENABLE_OCI_ANNOTATIONS"
+
+	// environment variables for the push of output images by the image-processing step
+	imagePushRetriesEnvVar      = "IMAGE_PUSH_RETRIES"
+	imagePushRetryBackoffEnvVar = "IMAGE_PUSH_RETRY_BACKOFF"
+	imagePushConcurrencyEnvVar  = "IMAGE_PUSH_CONCURRENCY"
+
+	// environment variable to hold the CA bundles and client certificates of container registries
+	registryTLSEnvVar = "REGISTRY_TLS"
+
+	// environment variable to hold the mirrors of container registries that are used for pulls
+	registryMirrorsEnvVar = "REGISTRY_MIRRORS"
+
+	// environment variable to hold the providers of container registries that delete images with a provider API
+	registryDeleteProvidersEnvVar = "REGISTRY_DELETE_PROVIDERS"
+
+	// default key of the CA bundle in its ConfigMap
+	defaultRegistryCABundleKey = "ca.crt"
 )
 
 var (
@@ -98,6 +141,8 @@ type Config struct {
 	GitContainerTemplate             Step
 	ImageProcessingContainerTemplate Step
 	BundleContainerTemplate          Step
+	ObjectStorageContainerTemplate   Step
+	InlineContainerTemplate          Step
 	WaiterContainerTemplate          Step
 	RemoteArtifactsContainerImage    string
 	TerminationLogPath               string
@@ -107,6 +152,14 @@ type Config struct {
 	KubeAPIOptions                   KubeAPIOptions
 	GitRewriteRule                   bool
 	VulnerabilityCountLimit          int
+	VulnerabilityScanner             string
+	VulnerabilityReportMaxResultSize int
+	VulnerabilityDatabase            VulnerabilityDatabase
+	OCIAnnotations                   bool
+	ImagePush                        ImagePush
+	RegistryTLS                      []RegistryTLS
+	RegistryMirrors                  []RegistryMirror
+	RegistryDeleteProviders          []RegistryDeleteProvider
 }
 
 // PrometheusConfig contains the specific configuration for the
@@ -144,6 +197,50 @@ type KubeAPIOptions struct {
 	Burst int
 }
 
+// VulnerabilityDatabase contains the configuration of a pre-populated vulnerability database, which is
+// used instead of downloading the database from the internet, either from a PersistentVolumeClaim or
+// from an OCI artifact in a registry
+type VulnerabilityDatabase struct {
+	VolumeClaim string
+	Image       string
+	MaxAge      *time.Duration
+}
+
+// ImagePush contains the configuration of the push of output images by the image-processing step,
+// the defaults of the step are used for the values that are not set
+type ImagePush struct {
+	Retries      *int
+	RetryBackoff *time.Duration
+	Concurrency  *int
+}
+
+// RegistryTLS contains the CA bundle and the client certificate that are used to access a container
+// registry host. The ConfigMap and the Secret are read from the namespace of the BuildRun, the Secret
+// is of type kubernetes.io/tls.
+type RegistryTLS struct {
+	Host                    string `json:"host"`
+	CABundleConfigMap       string `json:"caBundleConfigMap,omitempty"`
+	CABundleKey             string `json:"caBundleKey,omitempty"`
+	ClientCertificateSecret string `json:"clientCertificateSecret,omitempty"`
+}
+
+// RegistryMirror maps the image references that start with a prefix to an ordered list of mirrors that the
+// bundle and image-processing steps try before they pull from the registry of the reference. With
+// MirrorByDigestOnly, the mirrors are only used for references by digest.
+type RegistryMirror struct {
+	Prefix             string   `json:"prefix"`
+	Mirrors            []string `json:"mirrors"`
+	MirrorByDigestOnly bool     `json:"mirrorByDigestOnly,omitempty"`
+}
+
+// RegistryDeleteProvider maps a registry host to the provider whose API the bundle step uses to prune images,
+// the provider is one of quay, harbor and gitlab. The endpoint of the API defaults to the registry host.
+type RegistryDeleteProvider struct {
+	Host     string `json:"host"`
+	Provider string `json:"provider"`
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
 type Step struct {
 	Args            []string                    `json:"args,omitempty"`
 	Command         []string                    `json:"command,omitempty"`
@@ -164,6 +261,8 @@ func NewDefaultConfig() *Config {
 		GitRewriteRule:                false,
 		VulnerabilityCountLimit:       50,
 
+		VulnerabilityReportMaxResultSize: 1024,
+
 		GitContainerTemplate: Step{
 			Image: gitDefaultImage,
 			Command: []string{
@@ -220,6 +319,54 @@ func NewDefaultConfig() *Config {
 			},
 		},
 
+		ObjectStorageContainerTemplate: Step{
+			Image: objectStorageDefaultImage,
+			Command: []string{
+				"/ko-app/object-storage",
+			},
+			// This directory is created in the base image as writable for everybody
+			Env: []corev1.EnvVar{
+				{
+					Name:  "HOME",
+					Value: "/shared-home",
+				},
+			},
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: ptr.To(false),
+				Capabilities: &corev1.Capabilities{
+					Drop: []corev1.Capability{
+						"ALL",
+					},
+				},
+				RunAsUser:  nonRoot,
+				RunAsGroup: nonRoot,
+			},
+		},
+
+		InlineContainerTemplate: Step{
+			Image: inlineDefaultImage,
+			Command: []string{
+				"/ko-app/inline",
+			},
+			// This directory is created in the base image as writable for everybody
+			Env: []corev1.EnvVar{
+				{
+					Name:  "HOME",
+					Value: "/shared-home",
+				},
+			},
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: ptr.To(false),
+				Capabilities: &corev1.Capabilities{
+					Drop: []corev1.Capability{
+						"ALL",
+					},
+				},
+				RunAsUser:  nonRoot,
+				RunAsGroup: nonRoot,
+			},
+		},
+
 		ImageProcessingContainerTemplate: Step{
 			Image: imageProcessingDefaultImage,
 			Command: []string{
@@ -361,11 +508,119 @@ func (c *Config) SetConfigFromEnv() error {
 		c.VulnerabilityCountLimit = vc
 	}
 
+	// set environment variable for the default vulnerability scanner
+	if scanner := os.Getenv(VulnerabilityScannerEnvVar); scanner != "" {
+		c.VulnerabilityScanner = scanner
+	}
+
+	// set environment variable for the maximum size of a vulnerability report that is passed in a result
+	if sizeStr := os.Getenv(VulnerabilityReportMaxResultSizeEnvVar); sizeStr != "" {
+		size, err := strconv.Atoi(sizeStr)
+		if err != nil {
+			return err
+		}
+		c.VulnerabilityReportMaxResultSize = size
+	}
+
+	// set environment variables for a pre-populated vulnerability database
+	c.VulnerabilityDatabase.VolumeClaim = os.Getenv(vulnerabilityDBVolumeClaimEnvVar)
+	c.VulnerabilityDatabase.Image = os.Getenv(vulnerabilityDBImageEnvVar)
+	if c.VulnerabilityDatabase.VolumeClaim != "" && c.VulnerabilityDatabase.Image != "" {
+		return fmt.Errorf("the environment variables %s and %s are mutually exclusive", vulnerabilityDBVolumeClaimEnvVar, vulnerabilityDBImageEnvVar)
+	}
+
+	if err := updateBuildControllerDurationOption(&c.VulnerabilityDatabase.MaxAge, vulnerabilityDBMaxAgeEnvVar); err != nil {
+		return err
+	}
+
 	// Mark that the Git wrapper is suppose to use Git rewrite rule
 	if useGitRewriteRule := os.Getenv(useGitRewriteRule); useGitRewriteRule != "" {
 		c.GitRewriteRule = strings.ToLower(useGitRewriteRule) == "true"
 	}
 
+	// Mark that the standard OCI annotations are added to output images unless a Build opts out
+	if ociAnnotations := os.Getenv(ociAnnotationsEnvVar); ociAnnotations != "" {
+		c.OCIAnnotations = strings.ToLower(ociAnnotations) == "true"
+	}
+
+	// set environment variables for the push of output images
+	if retriesStr := os.Getenv(imagePushRetriesEnvVar); retriesStr != "" {
+		retries, err := strconv.Atoi(retriesStr)
+		if err != nil {
+			return err
+		}
+		if retries < 0 {
+			return fmt.Errorf("the environment variable %s must not be negative", imagePushRetriesEnvVar)
+		}
+		c.ImagePush.Retries = &retries
+	}
+
+	if err := updateBuildControllerDurationOption(&c.ImagePush.RetryBackoff, imagePushRetryBackoffEnvVar); err != nil {
+		return err
+	}
+
+	if concurrencyStr := os.Getenv(imagePushConcurrencyEnvVar); concurrencyStr != "" {
+		concurrency, err := strconv.Atoi(concurrencyStr)
+		if err != nil {
+			return err
+		}
+		if concurrency < 1 {
+			return fmt.Errorf("the environment variable %s must be at least 1", imagePushConcurrencyEnvVar)
+		}
+		c.ImagePush.Concurrency = &concurrency
+	}
+
+	if registryTLS := os.Getenv(registryTLSEnvVar); registryTLS != "" {
+		c.RegistryTLS = nil
+		if err := json.Unmarshal([]byte(registryTLS), &c.RegistryTLS); err != nil {
+			return err
+		}
+
+		for i := range c.RegistryTLS {
+			if c.RegistryTLS[i].Host == "" {
+				return fmt.Errorf("the environment variable %s contains an entry without a host", registryTLSEnvVar)
+			}
+			if c.RegistryTLS[i].CABundleConfigMap == "" && c.RegistryTLS[i].ClientCertificateSecret == "" {
+				return fmt.Errorf("the environment variable %s contains the host %s without a CA bundle or client certificate", registryTLSEnvVar, c.RegistryTLS[i].Host)
+			}
+			if c.RegistryTLS[i].CABundleKey == "" {
+				c.RegistryTLS[i].CABundleKey = defaultRegistryCABundleKey
+			}
+		}
+	}
+
+	if registryMirrors := os.Getenv(registryMirrorsEnvVar); registryMirrors != "" {
+		c.RegistryMirrors = nil
+		if err := json.Unmarshal([]byte(registryMirrors), &c.RegistryMirrors); err != nil {
+			return err
+		}
+
+		for _, registryMirror := range c.RegistryMirrors {
+			if registryMirror.Prefix == "" {
+				return fmt.Errorf("the environment variable %s contains an entry without a prefix", registryMirrorsEnvVar)
+			}
+			if len(registryMirror.Mirrors) == 0 || slices.Contains(registryMirror.Mirrors, "") {
+				return fmt.Errorf("the environment variable %s contains the prefix %s without mirrors", registryMirrorsEnvVar, registryMirror.Prefix)
+			}
+		}
+	}
+
+	if registryDeleteProviders := os.Getenv(registryDeleteProvidersEnvVar); registryDeleteProviders != "" {
+		c.RegistryDeleteProviders = nil
+		if err := json.Unmarshal([]byte(registryDeleteProviders), &c.RegistryDeleteProviders); err != nil {
+			return err
+		}
+
+		for _, registryDeleteProvider := range c.RegistryDeleteProviders {
+			if registryDeleteProvider.Host == "" {
+				return fmt.Errorf("the environment variable %s contains an entry without a host", registryDeleteProvidersEnvVar)
+			}
+			if !slices.Contains([]string{"quay", "harbor", "gitlab"}, registryDeleteProvider.Provider) {
+				return fmt.Errorf("the environment variable %s contains the host %s with the unknown provider %q", registryDeleteProvidersEnvVar, registryDeleteProvider.Host, registryDeleteProvider.Provider)
+			}
+		}
+	}
+
 	if bundleContainerTemplate := os.Getenv(bundleContainerTemplateEnvVar); bundleContainerTemplate != "" {
 		c.BundleContainerTemplate = Step{}
 		if err := json.Unmarshal([]byte(bundleContainerTemplate), &c.BundleContainerTemplate); err != nil {
@@ -381,6 +636,36 @@ func (c *Config) SetConfigFromEnv() error {
 		c.BundleContainerTemplate.Image = bundleImage
 	}
 
+	if objectStorageContainerTemplate := os.Getenv(objectStorageContainerTemplateEnvVar); objectStorageContainerTemplate != "" {
+		c.ObjectStorageContainerTemplate = Step{}
+		if err := json.Unmarshal([]byte(objectStorageContainerTemplate), &c.ObjectStorageContainerTemplate); err != nil {
+			return err
+		}
+		if c.ObjectStorageContainerTemplate.Image == "" {
+			c.ObjectStorageContainerTemplate.Image = objectStorageDefaultImage
+		}
+	}
+
+	// the dedicated environment variable for the image overwrites what is defined in the object storage container template
+	if objectStorageImage := os.Getenv(objectStorageImageEnvVar); objectStorageImage != "" {
+		c.ObjectStorageContainerTemplate.Image = objectStorageImage
+	}
+
+	if inlineContainerTemplate := os.Getenv(inlineContainerTemplateEnvVar); inlineContainerTemplate != "" {
+		c.InlineContainerTemplate = Step{}
+		if err := json.Unmarshal([]byte(inlineContainerTemplate), &c.InlineContainerTemplate); err != nil {
+			return err
+		}
+		if c.InlineContainerTemplate.Image == "" {
+			c.InlineContainerTemplate.Image = inlineDefaultImage
+		}
+	}
+
+	// the dedicated environment variable for the image overwrites what is defined in the inline container template
+	if inlineImage := os.Getenv(inlineImageEnvVar); inlineImage != "" {
+		c.InlineContainerTemplate.Image = inlineImage
+	}
+
 	if waiterContainerTemplate := os.Getenv(waiterContainerTemplateEnvVar); waiterContainerTemplate != "" {
 		c.WaiterContainerTemplate = Step{}
 		if err := json.Unmarshal([]byte(waiterContainerTemplate), &c.WaiterContainerTemplate); err != nil {
@@ -101,6 +101,126 @@ var _ = Describe("Config", func() {
 			})
 		})
 
+		It("should allow for an override of the default vulnerability scanner", func() {
+			configWithEnvVariableOverrides(map[string]string{"VULNERABILITY_SCANNER": "grype"}, func(config *Config) {
+				Expect(config.VulnerabilityScanner).To(Equal("grype"))
+			})
+		})
+
+		It("should allow for an override of the maximum result size of a vulnerability report", func() {
+			configWithEnvVariableOverrides(map[string]string{"VULNERABILITY_REPORT_MAX_RESULT_SIZE": "4096"}, func(config *Config) {
+				Expect(config.VulnerabilityReportMaxResultSize).To(Equal(4096))
+			})
+		})
+
+		It("should allow to enable the standard OCI annotations", func() {
+			configWithEnvVariableOverrides(map[string]string{"IMAGE_ENABLE_OCI_ANNOTATIONS": "true"}, func(config *Config) {
+				Expect(config.OCIAnnotations).To(BeTrue())
+			})
+		})
+
+		It("should allow to configure the push of output images", func() {
+			configWithEnvVariableOverrides(map[string]string{
+				"IMAGE_PUSH_RETRIES":       "10",
+				"IMAGE_PUSH_RETRY_BACKOFF": "2s",
+				"IMAGE_PUSH_CONCURRENCY":   "8",
+			}, func(config *Config) {
+				Expect(config.ImagePush.Retries).To(Equal(ptr.To(10)))
+				Expect(config.ImagePush.RetryBackoff).To(Equal(ptr.To(2 * time.Second)))
+				Expect(config.ImagePush.Concurrency).To(Equal(ptr.To(8)))
+			})
+		})
+
+		It("should fail for a push concurrency of zero", func() {
+			Expect(os.Setenv("IMAGE_PUSH_CONCURRENCY", "0")).To(Succeed())
+			DeferCleanup(func() {
+				Expect(os.Unsetenv("IMAGE_PUSH_CONCURRENCY")).To(Succeed())
+			})
+
+			Expect(NewDefaultConfig().SetConfigFromEnv()).To(MatchError(ContainSubstring("IMAGE_PUSH_CONCURRENCY")))
+		})
+
+		It("should allow to configure the TLS of container registries", func() {
+			configWithEnvVariableOverrides(map[string]string{
+				"REGISTRY_TLS": `[{"host":"registry.example.com:5000","caBundleConfigMap":"registry-ca"},{"host":"mtls.example.com","clientCertificateSecret":"registry-client"}]`,
+			}, func(config *Config) {
+				Expect(config.RegistryTLS).To(Equal([]RegistryTLS{
+					{Host: "registry.example.com:5000", CABundleConfigMap: "registry-ca", CABundleKey: "ca.crt"},
+					{Host: "mtls.example.com", CABundleKey: "ca.crt", ClientCertificateSecret: "registry-client"},
+				}))
+			})
+		})
+
+		It("should fail for a registry without a CA bundle or client certificate", func() {
+			Expect(os.Setenv("REGISTRY_TLS", `[{"host":"registry.example.com"}]`)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(os.Unsetenv("REGISTRY_TLS")).To(Succeed())
+			})
+
+			Expect(NewDefaultConfig().SetConfigFromEnv()).To(MatchError(ContainSubstring("without a CA bundle or client certificate")))
+		})
+
+		It("should allow to configure the mirrors of container registries", func() {
+			configWithEnvVariableOverrides(map[string]string{
+				"REGISTRY_MIRRORS": `[{"prefix":"docker.io/library","mirrors":["mirror-a.example.com/library","mirror-b.example.com/library"]},{"prefix":"quay.io","mirrors":["mirror-a.example.com/quay"],"mirrorByDigestOnly":true}]`,
+			}, func(config *Config) {
+				Expect(config.RegistryMirrors).To(Equal([]RegistryMirror{
+					{Prefix: "docker.io/library", Mirrors: []string{"mirror-a.example.com/library", "mirror-b.example.com/library"}},
+					{Prefix: "quay.io", Mirrors: []string{"mirror-a.example.com/quay"}, MirrorByDigestOnly: true},
+				}))
+			})
+		})
+
+		It("should fail for a registry prefix without mirrors", func() {
+			Expect(os.Setenv("REGISTRY_MIRRORS", `[{"prefix":"docker.io"}]`)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(os.Unsetenv("REGISTRY_MIRRORS")).To(Succeed())
+			})
+
+			Expect(NewDefaultConfig().SetConfigFromEnv()).To(MatchError(ContainSubstring("without mirrors")))
+		})
+
+		It("should allow to configure the delete providers of container registries", func() {
+			configWithEnvVariableOverrides(map[string]string{
+				"REGISTRY_DELETE_PROVIDERS": `[{"host":"quay.example.com","provider":"quay"},{"host":"registry.example.com","provider":"gitlab","endpoint":"https://gitlab.example.com"}]`,
+			}, func(config *Config) {
+				Expect(config.RegistryDeleteProviders).To(Equal([]RegistryDeleteProvider{
+					{Host: "quay.example.com", Provider: "quay"},
+					{Host: "registry.example.com", Provider: "gitlab", Endpoint: "https://gitlab.example.com"},
+				}))
+			})
+		})
+
+		It("should fail for an unknown registry delete provider", func() {
+			Expect(os.Setenv("REGISTRY_DELETE_PROVIDERS", `[{"host":"registry.example.com","provider":"nexus"}]`)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(os.Unsetenv("REGISTRY_DELETE_PROVIDERS")).To(Succeed())
+			})
+
+			Expect(NewDefaultConfig().SetConfigFromEnv()).To(MatchError(ContainSubstring("unknown provider")))
+		})
+
+		It("should allow to configure a pre-populated vulnerability database", func() {
+			configWithEnvVariableOverrides(map[string]string{
+				"VULNERABILITY_DB_IMAGE":   "registry.example.com/aquasecurity/trivy-db:2",
+				"VULNERABILITY_DB_MAX_AGE": "72h",
+			}, func(config *Config) {
+				Expect(config.VulnerabilityDatabase.Image).To(Equal("registry.example.com/aquasecurity/trivy-db:2"))
+				Expect(config.VulnerabilityDatabase.MaxAge).To(Equal(ptr.To(72 * time.Hour)))
+			})
+		})
+
+		It("should fail if both a volume claim and an image are configured for the vulnerability database", func() {
+			Expect(os.Setenv("VULNERABILITY_DB_VOLUME_CLAIM", "trivy-db")).To(Succeed())
+			Expect(os.Setenv("VULNERABILITY_DB_IMAGE", "registry.example.com/aquasecurity/trivy-db:2")).To(Succeed())
+			DeferCleanup(func() {
+				Expect(os.Unsetenv("VULNERABILITY_DB_VOLUME_CLAIM")).To(Succeed())
+				Expect(os.Unsetenv("VULNERABILITY_DB_IMAGE")).To(Succeed())
+			})
+
+			Expect(NewDefaultConfig().SetConfigFromEnv()).To(MatchError(ContainSubstring("mutually exclusive")))
+		})
+
 		It("should allow for an override of the Git container template", func() {
 			var overrides = map[string]string{
 				"GIT_CONTAINER_TEMPLATE": "{\"image\":\"myregistry/custom/git-image\",\"resources\":{\"requests\":{\"cpu\":\"0.5\",\"memory\":\"128Mi\"}}}",
@@ -224,6 +344,44 @@ var _ = Describe("Config", func() {
 
 		})
 
+		It("should allow for an override of the object storage container template and image", func() {
+			overrides := map[string]string{
+				"OBJECT_STORAGE_CONTAINER_TEMPLATE": `{"image":"myregistry/custom/object-storage","resources":{"requests":{"cpu":"0.5","memory":"128Mi"}}}`,
+				"OBJECT_STORAGE_CONTAINER_IMAGE":    "myregistry/custom/object-storage:override",
+			}
+
+			configWithEnvVariableOverrides(overrides, func(config *Config) {
+				Expect(config.ObjectStorageContainerTemplate).To(Equal(Step{
+					Image: "myregistry/custom/object-storage:override",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("0.5"),
+							corev1.ResourceMemory: resource.MustParse("128Mi"),
+						},
+					},
+				}))
+			})
+		})
+
+		It("should allow for an override of the inline container template and image", func() {
+			overrides := map[string]string{
+				"INLINE_CONTAINER_TEMPLATE": `{"image":"myregistry/custom/inline","resources":{"requests":{"cpu":"0.5","memory":"128Mi"}}}`,
+				"INLINE_CONTAINER_IMAGE":    "myregistry/custom/inline:override",
+			}
+
+			configWithEnvVariableOverrides(overrides, func(config *Config) {
+				Expect(config.InlineContainerTemplate).To(Equal(Step{
+					Image: "myregistry/custom/inline:override",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("0.5"),
+							corev1.ResourceMemory: resource.MustParse("128Mi"),
+						},
+					},
+				}))
+			})
+		})
+
 		It("should allow for an override of the Waiter container image", func() {
 			var overrides = map[string]string{
 				"WAITER_CONTAINER_IMAGE": "myregistry/custom/image",
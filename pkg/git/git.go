@@ -89,3 +89,26 @@ func ValidateGitURLExists(ctx context.Context, urlPath string) error {
 
 	return nil
 }
+
+// ReadLocalRepository opens the Git repository in the provided directory and
+// returns the commit SHA of HEAD as well as the branch name, if HEAD refers to
+// a branch. In case the directory is not a Git repository, the error returned
+// is gogitv5.ErrRepositoryNotExists.
+func ReadLocalRepository(directory string) (string, string, error) {
+	repo, err := gogitv5.PlainOpen(directory)
+	if err != nil {
+		return "", "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", "", err
+	}
+
+	var branchName string
+	if head.Name().IsBranch() {
+		branchName = head.Name().Short()
+	}
+
+	return head.Hash().String(), branchName, nil
+}
@@ -0,0 +1,70 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// The standard annotations of the OCI image specification that describe the source and the build of an image
+const (
+	AnnotationSource          = "org.opencontainers.image.source"
+	AnnotationRevision        = "org.opencontainers.image.revision"
+	AnnotationCreated         = "org.opencontainers.image.created"
+	AnnotationRefName         = "org.opencontainers.image.ref.name"
+	AnnotationBaseImageName   = "org.opencontainers.image.base.name"
+	AnnotationBaseImageDigest = "org.opencontainers.image.base.digest"
+)
+
+// StandardAnnotationsInput contains the information about the source and the build of an image
+type StandardAnnotationsInput struct {
+	Source     string
+	Revision   string
+	Created    time.Time
+	RefName    string
+	BaseName   string
+	BaseDigest string
+}
+
+// StandardAnnotations returns the standard OCI annotations for the input, values that are unknown are omitted
+func StandardAnnotations(input StandardAnnotationsInput) map[string]string {
+	annotations := map[string]string{}
+
+	add := func(key string, value string) {
+		if value != "" {
+			annotations[key] = value
+		}
+	}
+
+	add(AnnotationSource, input.Source)
+	add(AnnotationRevision, input.Revision)
+	add(AnnotationRefName, input.RefName)
+	add(AnnotationBaseImageName, input.BaseName)
+	add(AnnotationBaseImageDigest, input.BaseDigest)
+
+	if !input.Created.IsZero() {
+		annotations[AnnotationCreated] = input.Created.UTC().Format(time.RFC3339)
+	}
+
+	return annotations
+}
+
+// ResolveDigest returns the digest of an image or image index, a reference by digest is not looked up
+func ResolveDigest(reference name.Reference, options []remote.Option) (string, error) {
+	if digest, ok := reference.(name.Digest); ok {
+		return digest.DigestStr(), nil
+	}
+
+	descriptor, err := remote.Head(reference, options...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the digest of %s: %w", reference.String(), err)
+	}
+
+	return descriptor.Digest.String(), nil
+}
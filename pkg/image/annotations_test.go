@@ -0,0 +1,71 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/shipwright-io/build/pkg/image"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Standard annotations", func() {
+	Context("StandardAnnotations", func() {
+		It("returns the annotations with a value", func() {
+			Expect(image.StandardAnnotations(image.StandardAnnotationsInput{
+				Source:   "https://github.com/shipwright-io/sample-go",
+				Revision: "0e0583421a5e4bf562ffe33f3651e16ba0c78591",
+				Created:  time.Date(2024, 5, 1, 12, 30, 0, 0, time.FixedZone("CEST", 2*60*60)),
+				RefName:  "v1.0.0",
+			})).To(Equal(map[string]string{
+				"org.opencontainers.image.source":   "https://github.com/shipwright-io/sample-go",
+				"org.opencontainers.image.revision": "0e0583421a5e4bf562ffe33f3651e16ba0c78591",
+				"org.opencontainers.image.created":  "2024-05-01T10:30:00Z",
+				"org.opencontainers.image.ref.name": "v1.0.0",
+			}))
+		})
+	})
+
+	Context("ResolveDigest", func() {
+		It("returns the digest of a reference by digest without a lookup", func() {
+			reference, err := name.ParseReference("registry.invalid/namespace/image@sha256:8d7bd2e29e1b1d1a8e8b2f6e7c1ad2b6b37cb87c86d0e83e8b1e7d6b6f2c5d4e")
+			Expect(err).ToNot(HaveOccurred())
+
+			digest, err := image.ResolveDigest(reference, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(digest).To(Equal("sha256:8d7bd2e29e1b1d1a8e8b2f6e7c1ad2b6b37cb87c86d0e83e8b1e7d6b6f2c5d4e"))
+		})
+
+		It("looks up the digest of a tag", func() {
+			server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+			DeferCleanup(server.Close)
+
+			reference, err := name.ParseReference(fmt.Sprintf("%s/namespace/base:latest", strings.TrimPrefix(server.URL, "http://")))
+			Expect(err).ToNot(HaveOccurred())
+
+			img, err := random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(remote.Write(reference, img)).To(Succeed())
+
+			expected, err := img.Digest()
+			Expect(err).ToNot(HaveOccurred())
+
+			digest, err := image.ResolveDigest(reference, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(digest).To(Equal(expected.String()))
+		})
+	})
+})
@@ -21,93 +21,202 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 )
 
-// Delete removes the image from the container registry
+// DeleteProvider deletes images from the registries of a registry provider, some providers do not support the
+// delete API of the OCI distribution specification or need their own API to delete tags
+type DeleteProvider interface {
+	// Matches returns whether the provider is responsible for a registry host
+	Matches(registry string) bool
+
+	// Delete removes the image from the container registry
+	Delete(ref name.Reference, options []remote.Option, auth authn.AuthConfig) error
+}
+
+// DeleteProviders are the registry providers that are checked in order for the registry of an image after the
+// configured providers, the OCIDeleteProvider is used for registries that no provider matches
+var DeleteProviders = []DeleteProvider{
+	DockerHubDeleteProvider{},
+	ICRDeleteProvider{},
+}
+
+// ParseDeleteProviderRules parses the providers of registry hosts in the format host=provider or
+// host=provider,endpoint, the provider is one of quay, harbor and gitlab
+func ParseDeleteProviderRules(values []string) ([]DeleteProvider, error) {
+	providers := make([]DeleteProvider, 0, len(values))
+	for _, value := range values {
+		host, provider, found := strings.Cut(value, "=")
+		if !found || host == "" || provider == "" {
+			return nil, fmt.Errorf("the registry delete provider rule %q is not in the format host=provider,endpoint", value)
+		}
+
+		provider, endpoint, _ := strings.Cut(provider, ",")
+		switch provider {
+		case "quay":
+			providers = append(providers, QuayDeleteProvider{Host: host, Endpoint: endpoint})
+		case "harbor":
+			providers = append(providers, HarborDeleteProvider{Host: host, Endpoint: endpoint})
+		case "gitlab":
+			providers = append(providers, GitLabDeleteProvider{Host: host, Endpoint: endpoint})
+		default:
+			return nil, fmt.Errorf("the registry delete provider rule %q contains the unknown provider %q", value, provider)
+		}
+	}
+
+	return providers, nil
+}
+
+// Delete removes the image from the container registry with the provider of the registry, the given providers
+// are checked before the DeleteProviders
+func Delete(ref name.Reference, options []remote.Option, auth authn.AuthConfig, providers ...DeleteProvider) error {
+	return getDeleteProvider(ref.Context().RegistryStr(), providers).Delete(ref, options, auth)
+}
+
+func getDeleteProvider(registry string, providers []DeleteProvider) DeleteProvider {
+	for _, provider := range append(providers, DeleteProviders...) {
+		if provider.Matches(registry) {
+			return provider
+		}
+	}
+
+	return OCIDeleteProvider{}
+}
+
+// OCIDeleteProvider deletes images with the delete API of the OCI distribution specification. A tag reference
+// only deletes the tag, so that other tags of the same manifest remain. Deleting a tag is optional in the
+// specification, the manifest is deleted by digest if the registry does not support it.
+type OCIDeleteProvider struct{}
+
+// Matches implements DeleteProvider
+func (OCIDeleteProvider) Matches(string) bool {
+	return true
+}
+
+// Delete implements DeleteProvider
+func (OCIDeleteProvider) Delete(ref name.Reference, options []remote.Option, _ authn.AuthConfig) error {
+	err := remote.Delete(ref, options...)
+	if _, ok := ref.(name.Digest); ok || !isTagDeletionUnsupported(err) {
+		return err
+	}
+
+	descriptor, err := remote.Head(ref, options...)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Deleting a tag is not supported on %q, deleting the manifest %s of %q.\n", ref.Context().RegistryStr(), descriptor.Digest, ref.String())
+	return remote.Delete(ref.Context().Digest(descriptor.Digest.String()), options...)
+}
+
+// isTagDeletionUnsupported returns whether the error of a tag deletion means that the registry does not support
+// to delete tags, the specification defines the error code UNSUPPORTED or the status method not allowed for it
+func isTagDeletionUnsupported(err error) bool {
+	var transportErr *transport.Error
+	if !errors.As(err, &transportErr) {
+		return false
+	}
+
+	if transportErr.StatusCode == http.StatusMethodNotAllowed {
+		return true
+	}
+
+	for _, diagnostic := range transportErr.Errors {
+		if diagnostic.Code == transport.UnsupportedErrorCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DockerHubDeleteProvider deletes images from DockerHub
 //
 // Deleting a tag, or a whole repo is not as straightforward as initially
 // planned as DockerHub seems to restrict deleting a single tag for
 // standard users. This might be subject to change, but as of September
 // 2021 it is limited to the business tier. However, there is an API call
 // to delete the whole repository. In case there is only one tag used in
-// a repository, the effect is pretty much the same. For convenience, there
-// is a provider switch to deal with images on DockerHub differently.
+// a repository, the effect is pretty much the same.
 //
-// DockerHub images:
 //   - In case the repository only has one tag, the repository is deleted.
 //   - If there are multiple tags, the tag to be deleted is overwritten
 //     with an empty image (to remove the content, and save quota).
 //   - Edge case would be no tags in the repository, which is ignored.
-//
-// IBM Container Registry images:
-// Custom delete API call has to be used, since ICR does not support the
-// default registry API for deletions. The credentials need to have an
-// IBM API key, which is used to obtain an identity token that needs to
-// contains the respective authorization token for requests as well as
-// an account identifier to select the IBM account in which the registry
-// namespace and image is located.
-//
-// Other registries:
-// Use standard spec delete API request to delete the provided tag.
-func Delete(ref name.Reference, options []remote.Option, auth authn.AuthConfig) error {
-	var registry = ref.Context().RegistryStr()
-	switch {
-	case isDockerHubEndpoint(registry):
-		list, err := remote.List(ref.Context(), options...)
-		if err != nil {
-			return err
-		}
+type DockerHubDeleteProvider struct{}
 
-		switch len(list) {
-		case 0:
-			return nil
-
-		case 1:
-			var token string
-			token, err = dockerHubLogin(auth.Username, auth.Password)
-			if err != nil {
-				return err
-			}
-
-			return dockerHubRepoDelete(token, ref)
+// Matches implements DeleteProvider
+func (DockerHubDeleteProvider) Matches(registry string) bool {
+	return isDockerHubEndpoint(registry)
+}
 
-		default:
-			log.Printf("Removing a specific image tag is not supported on %q, the respective image tag will be overwritten with an empty image.\n", registry)
-
-			// In case the input argument included a digest, the reference
-			// needs to be updated to exclude the digest for the empty image
-			// override to succeed.
-			switch ref.(type) {
-			case name.Digest:
-				ref, err = name.NewTag(ref.Context().Name())
-				if err != nil {
-					return err
-				}
-			}
+// Delete implements DeleteProvider
+func (DockerHubDeleteProvider) Delete(ref name.Reference, options []remote.Option, auth authn.AuthConfig) error {
+	list, err := remote.List(ref.Context(), options...)
+	if err != nil {
+		return err
+	}
 
-			return remote.Write(
-				ref,
-				empty.Image,
-				options...,
-			)
-		}
+	switch len(list) {
+	case 0:
+		return nil
 
-	case isIcrEndpoint(registry):
-		token, accountID, err := icrLogin(registry, auth.Username, auth.Password)
+	case 1:
+		var token string
+		token, err = dockerHubLogin(auth.Username, auth.Password)
 		if err != nil {
 			return err
 		}
 
-		return icrDelete(token, accountID, ref)
+		return dockerHubRepoDelete(token, ref)
 
 	default:
-		return remote.Delete(
+		log.Printf("Removing a specific image tag is not supported on %q, the respective image tag will be overwritten with an empty image.\n", ref.Context().RegistryStr())
+
+		// In case the input argument included a digest, the reference
+		// needs to be updated to exclude the digest for the empty image
+		// override to succeed.
+		switch ref.(type) {
+		case name.Digest:
+			ref, err = name.NewTag(ref.Context().Name())
+			if err != nil {
+				return err
+			}
+		}
+
+		return remote.Write(
 			ref,
+			empty.Image,
 			options...,
 		)
 	}
 }
 
+// ICRDeleteProvider deletes images from the IBM Container Registry
+//
+// Custom delete API call has to be used, since ICR does not support the
+// default registry API for deletions. The credentials need to have an
+// IBM API key, which is used to obtain an identity token that needs to
+// contains the respective authorization token for requests as well as
+// an account identifier to select the IBM account in which the registry
+// namespace and image is located.
+type ICRDeleteProvider struct{}
+
+// Matches implements DeleteProvider
+func (ICRDeleteProvider) Matches(registry string) bool {
+	return isIcrEndpoint(registry)
+}
+
+// Delete implements DeleteProvider
+func (ICRDeleteProvider) Delete(ref name.Reference, _ []remote.Option, auth authn.AuthConfig) error {
+	token, accountID, err := icrLogin(ref.Context().RegistryStr(), auth.Username, auth.Password)
+	if err != nil {
+		return err
+	}
+
+	return icrDelete(token, accountID, ref)
+}
+
 func httpClient() *http.Client {
 	return &http.Client{
 		Timeout: 30 * time.Second,
@@ -343,3 +452,63 @@ func icrDelete(token string, accountID string, ref name.Reference) error {
 		)
 	}
 }
+
+// registryAPIEndpoint returns the endpoint override of a provider, or the scheme and host of the registry
+func registryAPIEndpoint(endpoint string, ref name.Reference) string {
+	if endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/")
+	}
+
+	return fmt.Sprintf("%s://%s", ref.Context().Scheme(), ref.Context().RegistryStr())
+}
+
+// tagOf returns the tag of a reference, a digest reference can contain a tag like name:tag@digest
+func tagOf(ref name.Reference) (string, bool) {
+	switch ref := ref.(type) {
+	case name.Tag:
+		return ref.TagStr(), true
+
+	case name.Digest:
+		original, _, _ := strings.Cut(ref.String(), "@")
+		if i := strings.LastIndex(original, ":"); i > strings.LastIndex(original, "/") {
+			return original[i+1:], true
+		}
+	}
+
+	return "", false
+}
+
+// sendRegistryAPIRequest sends a request to the API of a registry provider and returns the response body if the
+// response has the expected status code
+func sendRegistryAPIRequest(req *http.Request, expectedStatusCode int) ([]byte, error) {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	respData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != expectedStatusCode {
+		return respData, &registryAPIError{method: req.Method, url: req.URL.Redacted(), body: string(respData), statusCode: resp.StatusCode}
+	}
+
+	return respData, nil
+}
+
+type registryAPIError struct {
+	method     string
+	url        string
+	body       string
+	statusCode int
+}
+
+func (e *registryAPIError) Error() string {
+	return fmt.Sprintf("request %s %s failed: %s (HTTP status code %d)", e.method, e.url, e.body, e.statusCode)
+}
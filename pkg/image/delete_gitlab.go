@@ -0,0 +1,127 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// GitLabDeleteProvider deletes image tags from the GitLab container registry with the GitLab API, GitLab removes
+// the manifest once no tag references it anymore. The password of the credentials needs to be a personal,
+// group or project access token with the api scope, deploy tokens and job tokens cannot use the API and delete
+// with the OCIDeleteProvider. The image needs a tag because GitLab deletes tags only.
+type GitLabDeleteProvider struct {
+	// Host is the registry host that the provider is responsible for
+	Host string
+
+	// Endpoint is the URL of the GitLab instance, it defaults to the registry host without a registry. prefix
+	Endpoint string
+}
+
+// Matches implements DeleteProvider
+func (p GitLabDeleteProvider) Matches(registry string) bool {
+	return registry == p.Host
+}
+
+// Delete implements DeleteProvider
+func (p GitLabDeleteProvider) Delete(ref name.Reference, options []remote.Option, auth authn.AuthConfig) error {
+	if !isGitLabAccessToken(auth) {
+		log.Printf("Provided access credentials for %q do not contain a GitLab access token, deleting with the registry API.\n", ref.Context().RegistryStr())
+		return OCIDeleteProvider{}.Delete(ref, options, auth)
+	}
+
+	tag, ok := tagOf(ref)
+	if !ok {
+		return fmt.Errorf("failed to delete image %q: the GitLab container registry only supports to delete tags", ref.String())
+	}
+
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		if registry := ref.Context().RegistryStr(); strings.HasPrefix(registry, "registry.") {
+			endpoint = fmt.Sprintf("%s://%s", ref.Context().Scheme(), strings.TrimPrefix(registry, "registry."))
+		}
+	}
+
+	repositoryURL, err := p.repositoryURL(registryAPIEndpoint(endpoint, ref), ref.Context().RepositoryStr(), auth.Password)
+	if err != nil {
+		return fmt.Errorf("failed to delete image %q: %w", ref.String(), err)
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/tags/%s", repositoryURL, url.PathEscape(tag)), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", auth.Password)
+
+	if _, err := sendRegistryAPIRequest(req, http.StatusOK); err != nil {
+		return fmt.Errorf("failed to delete image %q: %w", ref.String(), err)
+	}
+
+	return nil
+}
+
+// isGitLabAccessToken returns whether the credentials contain an access token, deploy tokens have a user name
+// with the gitlab+deploy-token prefix and job tokens the user name gitlab-ci-token
+func isGitLabAccessToken(auth authn.AuthConfig) bool {
+	return auth.Password != "" &&
+		!strings.HasPrefix(auth.Username, "gitlab+deploy-token") &&
+		auth.Username != "gitlab-ci-token"
+}
+
+// repositoryURL returns the API URL of a container repository. The project of the repository is not known, a
+// repository is either the project itself or below it, therefore the projects are looked up from the longest
+// path to the shortest.
+func (p GitLabDeleteProvider) repositoryURL(endpoint string, repositoryPath string, token string) (string, error) {
+	type gitLabRepository struct {
+		ID   int    `json:"id"`
+		Path string `json:"path"`
+	}
+
+	segments := strings.Split(repositoryPath, "/")
+	for i := len(segments); i >= 2; i-- {
+		projectURL := fmt.Sprintf("%s/api/v4/projects/%s", endpoint, url.PathEscape(strings.Join(segments[:i], "/")))
+
+		req, err := http.NewRequest(http.MethodGet, projectURL+"/registry/repositories", nil)
+		if err != nil {
+			return "", err
+		}
+
+		req.Header.Set("PRIVATE-TOKEN", token)
+
+		body, err := sendRegistryAPIRequest(req, http.StatusOK)
+		if err != nil {
+			var apiErr *registryAPIError
+			if errors.As(err, &apiErr) && apiErr.statusCode == http.StatusNotFound {
+				continue
+			}
+
+			return "", err
+		}
+
+		var repositories []gitLabRepository
+		if err := json.Unmarshal(body, &repositories); err != nil {
+			return "", err
+		}
+
+		for _, repository := range repositories {
+			if repository.Path == repositoryPath {
+				return fmt.Sprintf("%s/registry/repositories/%d", projectURL, repository.ID), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("failed to find the GitLab container repository %s", repositoryPath)
+}
@@ -0,0 +1,68 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// HarborDeleteProvider deletes images from Harbor with its API, which deletes the artifact of a tag or digest
+// including all its tags. The registry credentials, for example of a robot account, are used for the API, images
+// are deleted with the OCIDeleteProvider without credentials.
+type HarborDeleteProvider struct {
+	// Host is the registry host that the provider is responsible for
+	Host string
+
+	// Endpoint is the URL of the Harbor API, it defaults to the registry host
+	Endpoint string
+}
+
+// Matches implements DeleteProvider
+func (p HarborDeleteProvider) Matches(registry string) bool {
+	return registry == p.Host
+}
+
+// Delete implements DeleteProvider
+func (p HarborDeleteProvider) Delete(ref name.Reference, options []remote.Option, auth authn.AuthConfig) error {
+	if auth.Username == "" || auth.Password == "" {
+		log.Printf("No access credentials provided for %q, deleting with the registry API.\n", ref.Context().RegistryStr())
+		return OCIDeleteProvider{}.Delete(ref, options, auth)
+	}
+
+	project, repository, found := strings.Cut(ref.Context().RepositoryStr(), "/")
+	if !found {
+		return fmt.Errorf("the image %q does not contain a Harbor project", ref.String())
+	}
+
+	// the artifact reference is the digest if there is one, the repository name is encoded twice because it
+	// can contain slashes
+	artifactURL := fmt.Sprintf("%s/api/v2.0/projects/%s/repositories/%s/artifacts/%s",
+		registryAPIEndpoint(p.Endpoint, ref),
+		url.PathEscape(project),
+		url.PathEscape(url.PathEscape(repository)),
+		url.PathEscape(ref.Identifier()),
+	)
+
+	req, err := http.NewRequest(http.MethodDelete, artifactURL, nil)
+	if err != nil {
+		return err
+	}
+
+	req.SetBasicAuth(auth.Username, auth.Password)
+
+	if _, err := sendRegistryAPIRequest(req, http.StatusOK); err != nil {
+		return fmt.Errorf("failed to delete image %q: %w", ref.String(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// quayOAuthTokenUsername is the user name to log in to Quay with an OAuth access token as password
+const quayOAuthTokenUsername = "$oauthtoken"
+
+// QuayDeleteProvider deletes images from Quay with its API. The API needs an OAuth access token with the user
+// name $oauthtoken in the credentials, other credentials like robot accounts use the OCIDeleteProvider. The tags
+// of an image are deleted, Quay removes the manifest once no tag references it anymore. For a reference by
+// digest without a tag, all tags of the digest are deleted.
+type QuayDeleteProvider struct {
+	// Host is the registry host that the provider is responsible for
+	Host string
+
+	// Endpoint is the URL of the Quay API, it defaults to the registry host
+	Endpoint string
+}
+
+// Matches implements DeleteProvider
+func (p QuayDeleteProvider) Matches(registry string) bool {
+	return registry == p.Host
+}
+
+// Delete implements DeleteProvider
+func (p QuayDeleteProvider) Delete(ref name.Reference, options []remote.Option, auth authn.AuthConfig) error {
+	if auth.Username != quayOAuthTokenUsername || auth.Password == "" {
+		log.Printf("Provided access credentials for %q do not contain a Quay OAuth token, deleting with the registry API.\n", ref.Context().RegistryStr())
+		return OCIDeleteProvider{}.Delete(ref, options, auth)
+	}
+
+	repositoryURL := fmt.Sprintf("%s/api/v1/repository/%s", registryAPIEndpoint(p.Endpoint, ref), ref.Context().RepositoryStr())
+
+	var tags []string
+	if tag, ok := tagOf(ref); ok {
+		tags = append(tags, tag)
+	} else {
+		var err error
+		if tags, err = p.tagsOfDigest(repositoryURL, ref.Identifier(), auth.Password); err != nil {
+			return err
+		}
+	}
+
+	for _, tag := range tags {
+		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/tag/%s", repositoryURL, url.PathEscape(tag)), nil)
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+auth.Password)
+
+		if _, err := sendRegistryAPIRequest(req, http.StatusNoContent); err != nil {
+			return fmt.Errorf("failed to delete the tag %q of image %q: %w", tag, ref.String(), err)
+		}
+	}
+
+	return nil
+}
+
+// tagsOfDigest returns the active tags of a repository that reference a manifest digest
+func (p QuayDeleteProvider) tagsOfDigest(repositoryURL string, digest string, token string) ([]string, error) {
+	type quayTags struct {
+		Tags []struct {
+			Name           string `json:"name"`
+			ManifestDigest string `json:"manifest_digest"`
+		} `json:"tags"`
+		HasAdditional bool `json:"has_additional"`
+	}
+
+	var tags []string
+	for page := 1; ; page++ {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/tag/?onlyActiveTags=true&limit=100&page=%d", repositoryURL, page), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		body, err := sendRegistryAPIRequest(req, http.StatusOK)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the tags of the digest %s: %w", digest, err)
+		}
+
+		var response quayTags
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, err
+		}
+
+		for _, tag := range response.Tags {
+			if tag.ManifestDigest == digest {
+				tags = append(tags, tag.Name)
+			}
+		}
+
+		if !response.HasAdditional {
+			return tags, nil
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"net/http/httptest"
 	"strings"
 
@@ -27,12 +28,25 @@ var _ = Describe("Delete", func() {
 
 	var registryHost string
 
+	// supportsTagDeletion can be set to false to let the registry reject the deletion of tags like registries that
+	// only delete manifests by digest
+	var supportsTagDeletion bool
+
 	BeforeEach(func() {
+		supportsTagDeletion = true
+
 		logger := log.New(io.Discard, "", 0)
 		reg := registry.New(registry.Logger(logger))
 		// Use the following instead to see which requests happened
 		// reg := registry.New()
-		server := httptest.NewServer(reg)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !supportsTagDeletion && r.Method == http.MethodDelete && !strings.Contains(r.URL.Path, "sha256:") {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			reg.ServeHTTP(w, r)
+		}))
 		DeferCleanup(func() {
 			server.Close()
 		})
@@ -64,5 +78,203 @@ var _ = Describe("Delete", func() {
 			// Verify the non-existence of the manifest
 			Expect(fmt.Sprintf("http://%s/v2/test-namespace/test-image/manifests/latest", registryHost)).ToNot(utils.Return(200))
 		})
+
+		It("deletes only the tag of a tag reference", func() {
+			img, err := remote.Image(imageName)
+			Expect(err).ToNot(HaveOccurred())
+
+			otherTag, err := name.ParseReference(fmt.Sprintf("%s/%s/%s:%s", registryHost, "test-namespace", "test-image", "other"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(remote.Write(otherTag, img)).To(Succeed())
+
+			Expect(image.Delete(imageName, []remote.Option{}, authn.AuthConfig{})).To(Succeed())
+
+			Expect(fmt.Sprintf("http://%s/v2/test-namespace/test-image/manifests/latest", registryHost)).ToNot(utils.Return(200))
+			Expect(fmt.Sprintf("http://%s/v2/test-namespace/test-image/manifests/other", registryHost)).To(utils.Return(200))
+		})
+
+		It("deletes the manifest by digest if the registry does not support to delete tags", func() {
+			supportsTagDeletion = false
+
+			desc, err := remote.Head(imageName)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(image.Delete(imageName, []remote.Option{}, authn.AuthConfig{})).To(Succeed())
+
+			Expect(fmt.Sprintf("http://%s/v2/test-namespace/test-image/manifests/%s", registryHost, desc.Digest)).ToNot(utils.Return(200))
+		})
+
+		It("deletes the image with the registry API if the credentials do not contain a Quay OAuth token", func() {
+			Expect(image.Delete(imageName, []remote.Option{}, authn.AuthConfig{
+				Username: "namespace+robot",
+				Password: "secret",
+			}, image.QuayDeleteProvider{Host: registryHost})).To(Succeed())
+
+			Expect(fmt.Sprintf("http://%s/v2/test-namespace/test-image/manifests/latest", registryHost)).ToNot(utils.Return(200))
+		})
+
+		It("deletes the image with the registry API if the credentials contain a GitLab deploy token", func() {
+			Expect(image.Delete(imageName, []remote.Option{}, authn.AuthConfig{
+				Username: "gitlab+deploy-token-1",
+				Password: "secret",
+			}, image.GitLabDeleteProvider{Host: registryHost})).To(Succeed())
+
+			Expect(fmt.Sprintf("http://%s/v2/test-namespace/test-image/manifests/latest", registryHost)).ToNot(utils.Return(200))
+		})
+	})
+
+	Context("for registry providers", func() {
+
+		var requests []string
+
+		// newAPIServer starts a stand-in for the API of a registry provider that records the requests and
+		// responds with the response of the first matching request
+		newAPIServer := func(responses map[string]func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+			requests = nil
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request := r.Method + " " + r.URL.EscapedPath()
+				requests = append(requests, request)
+
+				if respond, ok := responses[request]; ok {
+					respond(w, r)
+					return
+				}
+
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			DeferCleanup(server.Close)
+			return server
+		}
+
+		parse := func(reference string) name.Reference {
+			GinkgoHelper()
+
+			ref, err := name.ParseReference(reference)
+			Expect(err).ToNot(HaveOccurred())
+			return ref
+		}
+
+		It("deletes a tag with the Quay API", func() {
+			server := newAPIServer(map[string]func(w http.ResponseWriter, r *http.Request){
+				"DELETE /api/v1/repository/namespace/image/tag/v1": func(w http.ResponseWriter, r *http.Request) {
+					Expect(r.Header.Get("Authorization")).To(Equal("Bearer oauth-token"))
+					w.WriteHeader(http.StatusNoContent)
+				},
+			})
+
+			provider := image.QuayDeleteProvider{Endpoint: server.URL}
+			Expect(provider.Delete(parse("quay.io/namespace/image:v1@sha256:8d7bd2e29e1b1d1a8e8b2f6e7c1ad2b6b37cb87c86d0e83e8b1e7d6b6f2c5d4e"), nil, authn.AuthConfig{
+				Username: "$oauthtoken",
+				Password: "oauth-token",
+			})).To(Succeed())
+			Expect(requests).To(Equal([]string{"DELETE /api/v1/repository/namespace/image/tag/v1"}))
+		})
+
+		It("deletes the tags of a digest with the Quay API", func() {
+			digest := "sha256:8d7bd2e29e1b1d1a8e8b2f6e7c1ad2b6b37cb87c86d0e83e8b1e7d6b6f2c5d4e"
+			server := newAPIServer(map[string]func(w http.ResponseWriter, r *http.Request){
+				"GET /api/v1/repository/namespace/image/tag/": func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprintf(w, `{"tags":[{"name":"v1","manifest_digest":%q},{"name":"v2","manifest_digest":"sha256:other"},{"name":"latest","manifest_digest":%q}],"has_additional":false}`, digest, digest)
+				},
+				"DELETE /api/v1/repository/namespace/image/tag/v1": func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusNoContent)
+				},
+				"DELETE /api/v1/repository/namespace/image/tag/latest": func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusNoContent)
+				},
+			})
+
+			provider := image.QuayDeleteProvider{Endpoint: server.URL}
+			Expect(provider.Delete(parse("quay.io/namespace/image@"+digest), nil, authn.AuthConfig{
+				Username: "$oauthtoken",
+				Password: "oauth-token",
+			})).To(Succeed())
+			Expect(requests).To(Equal([]string{
+				"GET /api/v1/repository/namespace/image/tag/",
+				"DELETE /api/v1/repository/namespace/image/tag/v1",
+				"DELETE /api/v1/repository/namespace/image/tag/latest",
+			}))
+		})
+
+		It("deletes an artifact with the Harbor API", func() {
+			server := newAPIServer(map[string]func(w http.ResponseWriter, r *http.Request){
+				"DELETE /api/v2.0/projects/project/repositories/team%252Fimage/artifacts/v1": func(w http.ResponseWriter, r *http.Request) {
+					username, password, ok := r.BasicAuth()
+					Expect(ok).To(BeTrue())
+					Expect(username).To(Equal("robot$project+ci"))
+					Expect(password).To(Equal("secret"))
+				},
+			})
+
+			ref := parse(strings.TrimPrefix(server.URL, "http://") + "/project/team/image:v1")
+			Expect(image.HarborDeleteProvider{}.Delete(ref, nil, authn.AuthConfig{
+				Username: "robot$project+ci",
+				Password: "secret",
+			})).To(Succeed())
+			Expect(requests).To(HaveLen(1))
+		})
+
+		It("reports the error of the Harbor API", func() {
+			server := newAPIServer(nil)
+
+			ref := parse(strings.TrimPrefix(server.URL, "http://") + "/project/image:v1")
+			Expect(image.HarborDeleteProvider{}.Delete(ref, nil, authn.AuthConfig{
+				Username: "robot$project+ci",
+				Password: "secret",
+			})).To(MatchError(ContainSubstring("HTTP status code 404")))
+		})
+
+		It("deletes a tag with the GitLab API", func() {
+			server := newAPIServer(map[string]func(w http.ResponseWriter, r *http.Request){
+				"GET /api/v4/projects/group%2Fproject/registry/repositories": func(w http.ResponseWriter, r *http.Request) {
+					Expect(r.Header.Get("PRIVATE-TOKEN")).To(Equal("access-token"))
+					fmt.Fprint(w, `[{"id":1,"path":"group/project"},{"id":7,"path":"group/project/image"}]`)
+				},
+				"DELETE /api/v4/projects/group%2Fproject/registry/repositories/7/tags/v1": func(w http.ResponseWriter, r *http.Request) {
+					Expect(r.Header.Get("PRIVATE-TOKEN")).To(Equal("access-token"))
+				},
+			})
+
+			provider := image.GitLabDeleteProvider{Endpoint: server.URL}
+			Expect(provider.Delete(parse("registry.gitlab.com/group/project/image:v1"), nil, authn.AuthConfig{
+				Username: "user",
+				Password: "access-token",
+			})).To(Succeed())
+			Expect(requests).To(Equal([]string{
+				"GET /api/v4/projects/group%2Fproject%2Fimage/registry/repositories",
+				"GET /api/v4/projects/group%2Fproject/registry/repositories",
+				"DELETE /api/v4/projects/group%2Fproject/registry/repositories/7/tags/v1",
+			}))
+		})
+
+		It("fails to delete a digest without a tag with GitLab", func() {
+			Expect(image.GitLabDeleteProvider{}.Delete(parse("registry.gitlab.com/group/project@sha256:8d7bd2e29e1b1d1a8e8b2f6e7c1ad2b6b37cb87c86d0e83e8b1e7d6b6f2c5d4e"), nil, authn.AuthConfig{
+				Password: "access-token",
+			})).To(MatchError(ContainSubstring("only supports to delete tags")))
+		})
+
+		It("selects the configured provider of the registry host", func() {
+			providers, err := image.ParseDeleteProviderRules([]string{
+				"quay.io=quay",
+				"harbor.example.com=harbor,https://harbor-api.example.com",
+				"registry.example.com=gitlab,https://gitlab.example.com",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(providers).To(Equal([]image.DeleteProvider{
+				image.QuayDeleteProvider{Host: "quay.io"},
+				image.HarborDeleteProvider{Host: "harbor.example.com", Endpoint: "https://harbor-api.example.com"},
+				image.GitLabDeleteProvider{Host: "registry.example.com", Endpoint: "https://gitlab.example.com"},
+			}))
+
+			Expect(providers[0].Matches("quay.io")).To(BeTrue())
+			Expect(providers[0].Matches("quay.example.com")).To(BeFalse())
+			Expect(providers[2].Matches("registry.example.com")).To(BeTrue())
+			Expect(providers[2].Matches("registry.gitlab.com")).To(BeFalse())
+		})
+
+		It("fails for an unknown provider", func() {
+			_, err := image.ParseDeleteProviderRules([]string{"registry.example.com=nexus"})
+			Expect(err).To(MatchError(ContainSubstring("unknown provider")))
+		})
 	})
 })
@@ -0,0 +1,93 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	containerreg "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+)
+
+// ExportTarballFileName is the name of the file in the export directory that a tarball is written to
+const ExportTarballFileName = "image.tar"
+
+// ExportVulnerabilityReportFileName is the name of the file in the export directory that the complete
+// vulnerability report is written to
+const ExportVulnerabilityReportFileName = "vulnerability-report.json"
+
+// ExportImageOrImageIndex writes an image or image index to a directory instead of pushing it to a registry,
+// either as an OCI image layout or as a tarball in the format of docker save. The directory can be loaded with
+// LoadImageOrImageIndexFromDirectory. It returns the digest and the size like PushImageOrImageIndex.
+func ExportImageOrImageIndex(imageName name.Reference, image containerreg.Image, imageIndex containerreg.ImageIndex, format buildapi.ImageExportFormat, directory string) (string, int64, error) {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return "", 0, fmt.Errorf("failed to create the export directory %s: %w", directory, err)
+	}
+
+	// a tag is the name of the image in both formats
+	tag, ok := imageName.(name.Tag)
+	if !ok {
+		tag = imageName.Context().Tag(name.DefaultTag)
+	}
+
+	switch format {
+	case buildapi.ImageExportFormatOCILayout:
+		layoutPath, err := layout.Write(directory, empty.Index)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to write the OCI image layout: %w", err)
+		}
+
+		option := layout.WithAnnotations(map[string]string{AnnotationRefName: tag.TagStr()})
+		if imageIndex != nil {
+			err = layoutPath.AppendIndex(imageIndex, option)
+		} else {
+			err = layoutPath.AppendImage(image, option)
+		}
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to write the image to the OCI image layout: %w", err)
+		}
+
+	case buildapi.ImageExportFormatTarball:
+		if imageIndex != nil {
+			return "", 0, errors.New("an image index cannot be exported as a tarball, use the OCILayout format")
+		}
+
+		if err := tarball.WriteToFile(path.Join(directory, ExportTarballFileName), tag, image); err != nil {
+			return "", 0, fmt.Errorf("failed to write the tarball: %w", err)
+		}
+
+	default:
+		return "", 0, fmt.Errorf("unsupported export format %q", format)
+	}
+
+	if imageIndex != nil {
+		hash, err := imageIndex.Digest()
+		if err != nil {
+			return "", 0, err
+		}
+
+		return hash.String(), -1, nil
+	}
+
+	hash, err := image.Digest()
+	if err != nil {
+		return "", 0, err
+	}
+
+	size, err := getImageSize(image)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return hash.String(), size, nil
+}
@@ -0,0 +1,97 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/image"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExportImageOrImageIndex", func() {
+	var (
+		directory string
+		imageName name.Reference
+	)
+
+	BeforeEach(func() {
+		directory = filepath.Join(GinkgoT().TempDir(), "export")
+
+		var err error
+		imageName, err = name.ParseReference("registry.example.com/namespace/image:v1.0.0")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("writes an image as an OCI image layout that can be loaded", func() {
+		img, err := random.Image(1024, 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		digest, size, err := image.ExportImageOrImageIndex(imageName, img, nil, buildapi.ImageExportFormatOCILayout, directory)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(size).To(BeNumerically(">", 2048))
+
+		loadedImage, loadedImageIndex, isImageFromTar, err := image.LoadImageOrImageIndexFromDirectory(directory)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loadedImageIndex).To(BeNil())
+		Expect(isImageFromTar).To(BeFalse())
+
+		loadedDigest, err := loadedImage.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loadedDigest.String()).To(Equal(digest))
+	})
+
+	It("writes an image index as an OCI image layout that can be loaded", func() {
+		index, err := random.Index(1024, 1, 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		digest, _, err := image.ExportImageOrImageIndex(imageName, nil, index, buildapi.ImageExportFormatOCILayout, directory)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, loadedImageIndex, _, err := image.LoadImageOrImageIndexFromDirectory(directory)
+		Expect(err).ToNot(HaveOccurred())
+
+		loadedDigest, err := loadedImageIndex.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loadedDigest.String()).To(Equal(digest))
+	})
+
+	It("writes an image as a tarball that can be loaded", func() {
+		img, err := random.Image(1024, 1)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, _, err = image.ExportImageOrImageIndex(imageName, img, nil, buildapi.ImageExportFormatTarball, directory)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filepath.Join(directory, image.ExportTarballFileName)).To(BeARegularFile())
+
+		loadedImage, _, isImageFromTar, err := image.LoadImageOrImageIndexFromDirectory(directory)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(isImageFromTar).To(BeTrue())
+
+		expectedLayers, err := img.Layers()
+		Expect(err).ToNot(HaveOccurred())
+		loadedLayers, err := loadedImage.Layers()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loadedLayers).To(HaveLen(len(expectedLayers)))
+	})
+
+	It("fails to write an image index as a tarball", func() {
+		index, err := random.Index(1024, 1, 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, _, err = image.ExportImageOrImageIndex(imageName, nil, index, buildapi.ImageExportFormatTarball, directory)
+		Expect(err).To(MatchError(ContainSubstring("cannot be exported as a tarball")))
+
+		entries, err := os.ReadDir(directory)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+})
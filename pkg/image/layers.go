@@ -0,0 +1,234 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/compression"
+	containerreg "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+)
+
+// annotationEstargzTOCDigest is the annotation of an eStargz layer that contains the digest of its table of contents
+const annotationEstargzTOCDigest = "containerd.io/snapshot/stargz/toc.digest"
+
+// ConvertImageOrImageIndexLayers converts the layers of an image or of the images of an image index to a
+// layer format. Layers that are already in the format, and layers that are not regular filesystem layers,
+// are kept. Images are only changed if at least one layer is converted, the diff IDs in their configuration
+// are updated for the converted layers. Images with zstd layers use the OCI media types.
+func ConvertImageOrImageIndexLayers(image containerreg.Image, imageIndex containerreg.ImageIndex, format buildapi.LayerFormat) (containerreg.Image, containerreg.ImageIndex, error) {
+	switch format {
+	case buildapi.LayerFormatGzip, buildapi.LayerFormatZstd, buildapi.LayerFormatEstargz:
+	default:
+		return nil, nil, fmt.Errorf("unsupported layer format %q", format)
+	}
+
+	if imageIndex != nil {
+		imageIndex, err := convertImageIndexLayers(imageIndex, format)
+		return nil, imageIndex, err
+	}
+
+	image, err := convertImageLayers(image, format)
+	return image, nil, err
+}
+
+func convertImageIndexLayers(imageIndex containerreg.ImageIndex, format buildapi.LayerFormat) (containerreg.ImageIndex, error) {
+	indexManifest, err := imageIndex.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, descriptor := range indexManifest.Manifests {
+		switch descriptor.MediaType {
+		case types.OCIImageIndex, types.DockerManifestList:
+			childImageIndex, err := imageIndex.ImageIndex(descriptor.Digest)
+			if err != nil {
+				return nil, err
+			}
+
+			converted, err := convertImageIndexLayers(childImageIndex, format)
+			if err != nil {
+				return nil, err
+			}
+
+			if descriptor.MediaType, err = converted.MediaType(); err != nil {
+				return nil, err
+			}
+
+			imageIndex = replaceManifestIn(imageIndex, descriptor, converted)
+
+		case types.OCIManifestSchema1, types.DockerManifestSchema2:
+			image, err := imageIndex.Image(descriptor.Digest)
+			if err != nil {
+				return nil, err
+			}
+
+			converted, err := convertImageLayers(image, format)
+			if err != nil {
+				return nil, err
+			}
+
+			if converted == image {
+				continue
+			}
+
+			if descriptor.MediaType, err = converted.MediaType(); err != nil {
+				return nil, err
+			}
+
+			imageIndex = replaceManifestIn(imageIndex, descriptor, converted)
+		}
+	}
+
+	// a Docker manifest list cannot reference OCI image manifests
+	if mediaType, err := imageIndex.MediaType(); err == nil && format == buildapi.LayerFormatZstd && mediaType == types.DockerManifestList {
+		imageIndex = mutate.IndexMediaType(imageIndex, types.OCIImageIndex)
+	}
+
+	return imageIndex, nil
+}
+
+func convertImageLayers(image containerreg.Image, format buildapi.LayerFormat) (containerreg.Image, error) {
+	manifest, err := image.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	layers, err := image.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(layers) != len(manifest.Layers) {
+		return nil, errors.New("the number of layers does not match the manifest of the image")
+	}
+
+	manifestMediaType, configMediaType := manifest.MediaType, manifest.Config.MediaType
+	if format == buildapi.LayerFormatZstd {
+		manifestMediaType, configMediaType = types.OCIManifestSchema1, types.OCIConfigJSON
+	}
+
+	addenda := make([]mutate.Addendum, 0, len(layers))
+	converted := false
+	for i, layer := range layers {
+		descriptor := manifest.Layers[i]
+
+		if isLayerInFormat(descriptor, format) {
+			addenda = append(addenda, mutate.Addendum{Layer: layer, MediaType: descriptor.MediaType, Annotations: descriptor.Annotations})
+			continue
+		}
+
+		convertedLayer, err := convertLayer(layer, format, manifestMediaType == types.OCIManifestSchema1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert the layer %s: %w", descriptor.Digest, err)
+		}
+
+		addenda = append(addenda, mutate.Addendum{Layer: convertedLayer})
+		converted = true
+	}
+
+	if !converted {
+		return image, nil
+	}
+
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	// the diff IDs are recomputed from the layers, and mutate.Append adds a history entry per layer, which is
+	// replaced by the original history afterwards
+	configFile = configFile.DeepCopy()
+	history := configFile.History
+	configFile.RootFS.DiffIDs = nil
+	configFile.History = nil
+
+	base := mutate.ConfigMediaType(mutate.MediaType(empty.Image, manifestMediaType), configMediaType)
+	base, err = mutate.ConfigFile(base, configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := mutate.Append(base, addenda...)
+	if err != nil {
+		return nil, err
+	}
+
+	configFile, err = result.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	configFile = configFile.DeepCopy()
+	configFile.History = history
+	result, err = mutate.ConfigFile(result, configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(manifest.Annotations) > 0 {
+		var castSucceeded bool
+		result, castSucceeded = mutate.Annotations(result, manifest.Annotations).(containerreg.Image)
+		if !castSucceeded {
+			return nil, errors.New("expected mutate.Annotation to return an Image when passing in an Image")
+		}
+	}
+
+	return result, nil
+}
+
+// isLayerInFormat returns whether a layer does not need to be converted, which includes layers that are not
+// regular filesystem layers like foreign layers
+func isLayerInFormat(descriptor containerreg.Descriptor, format buildapi.LayerFormat) bool {
+	switch descriptor.MediaType {
+	case types.DockerLayer, types.OCILayer:
+		switch format {
+		case buildapi.LayerFormatGzip:
+			return true
+		case buildapi.LayerFormatEstargz:
+			return descriptor.Annotations[annotationEstargzTOCDigest] != ""
+		default:
+			return false
+		}
+
+	case types.OCILayerZStd:
+		return format == buildapi.LayerFormatZstd
+
+	case types.DockerUncompressedLayer, types.OCIUncompressedLayer:
+		return false
+
+	default:
+		return true
+	}
+}
+
+func convertLayer(layer containerreg.Layer, format buildapi.LayerFormat, oci bool) (containerreg.Layer, error) {
+	var options []tarball.LayerOption
+	switch format {
+	case buildapi.LayerFormatZstd:
+		options = append(options, tarball.WithCompression(compression.ZStd), tarball.WithMediaType(types.OCILayerZStd))
+
+	case buildapi.LayerFormatEstargz:
+		//nolint:staticcheck // SA1019 there is no replacement for the eStargz conversion of go-containerregistry
+		options = append(options, tarball.WithEstargz)
+		fallthrough
+
+	default:
+		if oci {
+			options = append(options, tarball.WithMediaType(types.OCILayer))
+		} else {
+			options = append(options, tarball.WithMediaType(types.DockerLayer))
+		}
+	}
+
+	return tarball.LayerFromOpener(layer.Uncompressed, options...)
+}
@@ -0,0 +1,134 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	containerreg "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/image"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConvertImageOrImageIndexLayers", func() {
+	diffIDsOf := func(img containerreg.Image) []containerreg.Hash {
+		GinkgoHelper()
+
+		layers, err := img.Layers()
+		Expect(err).ToNot(HaveOccurred())
+
+		var diffIDs []containerreg.Hash
+		for _, layer := range layers {
+			diffID, err := layer.DiffID()
+			Expect(err).ToNot(HaveOccurred())
+			diffIDs = append(diffIDs, diffID)
+		}
+		return diffIDs
+	}
+
+	configDiffIDsOf := func(img containerreg.Image) []containerreg.Hash {
+		GinkgoHelper()
+
+		configFile, err := img.ConfigFile()
+		Expect(err).ToNot(HaveOccurred())
+		return configFile.RootFS.DiffIDs
+	}
+
+	var img containerreg.Image
+
+	BeforeEach(func() {
+		var err error
+		img, err = random.Image(1024, 2)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("keeps an image with gzip layers unchanged", func() {
+		converted, _, err := image.ConvertImageOrImageIndexLayers(img, nil, buildapi.LayerFormatGzip)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(converted).To(BeIdenticalTo(img))
+	})
+
+	It("compresses the layers with zstd and keeps their diff IDs", func() {
+		converted, _, err := image.ConvertImageOrImageIndexLayers(img, nil, buildapi.LayerFormatZstd)
+		Expect(err).ToNot(HaveOccurred())
+
+		manifest, err := converted.Manifest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest.MediaType).To(Equal(types.OCIManifestSchema1))
+		Expect(manifest.Config.MediaType).To(Equal(types.OCIConfigJSON))
+		Expect(manifest.Layers).To(HaveLen(2))
+		for _, layer := range manifest.Layers {
+			Expect(layer.MediaType).To(Equal(types.OCILayerZStd))
+		}
+
+		Expect(diffIDsOf(converted)).To(Equal(diffIDsOf(img)))
+		Expect(configDiffIDsOf(converted)).To(Equal(diffIDsOf(img)))
+
+		originalDigest, err := img.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		convertedDigest, err := converted.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(convertedDigest).ToNot(Equal(originalDigest))
+	})
+
+	It("converts the layers to eStargz and updates the diff IDs in the configuration", func() {
+		// the eStargz library panics when it writes its footer with the gzip implementation of newer Go versions
+		supported := func() (supported bool) {
+			defer func() { supported = recover() == nil }()
+			_, _, _ = image.ConvertImageOrImageIndexLayers(img, nil, buildapi.LayerFormatEstargz)
+			return
+		}
+		if !supported() {
+			Skip("eStargz is not supported with this Go version")
+		}
+
+		converted, _, err := image.ConvertImageOrImageIndexLayers(img, nil, buildapi.LayerFormatEstargz)
+		Expect(err).ToNot(HaveOccurred())
+
+		manifest, err := converted.Manifest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest.Layers).To(HaveLen(2))
+		for _, layer := range manifest.Layers {
+			Expect(layer.MediaType).To(Equal(types.DockerLayer))
+			Expect(layer.Annotations).To(HaveKey("containerd.io/snapshot/stargz/toc.digest"))
+		}
+
+		Expect(configDiffIDsOf(converted)).To(Equal(diffIDsOf(converted)))
+
+		// converting it again does not change it
+		again, _, err := image.ConvertImageOrImageIndexLayers(converted, nil, buildapi.LayerFormatEstargz)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(again).To(BeIdenticalTo(converted))
+	})
+
+	It("converts the images of an image index and uses an OCI image index for zstd", func() {
+		index, err := random.Index(1024, 1, 2)
+		Expect(err).ToNot(HaveOccurred())
+		index = mutate.IndexMediaType(index, types.DockerManifestList)
+
+		_, converted, err := image.ConvertImageOrImageIndexLayers(nil, index, buildapi.LayerFormatZstd)
+		Expect(err).ToNot(HaveOccurred())
+
+		indexManifest, err := converted.IndexManifest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(indexManifest.MediaType).To(Equal(types.OCIImageIndex))
+		Expect(indexManifest.Manifests).To(HaveLen(2))
+
+		for _, descriptor := range indexManifest.Manifests {
+			Expect(descriptor.MediaType).To(Equal(types.OCIManifestSchema1))
+
+			convertedImage, err := converted.Image(descriptor.Digest)
+			Expect(err).ToNot(HaveOccurred())
+
+			manifest, err := convertedImage.Manifest()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest.Layers[0].MediaType).To(Equal(types.OCILayerZStd))
+		}
+	})
+})
@@ -0,0 +1,161 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+)
+
+type trivyLicense struct {
+	Name     string `json:"Name"`
+	PkgName  string `json:"PkgName"`
+	FilePath string `json:"FilePath"`
+}
+
+type trivyLicenseResult struct {
+	Results []struct {
+		Licenses []trivyLicense `json:"Licenses"`
+	} `json:"Results"`
+}
+
+// RunLicenseScan scans the licenses of the packages in the image using trivy and returns the packages whose license
+// is not allowed by the policy, sorted by package and limited to the count limit
+func RunLicenseScan(ctx context.Context, imagePath string, policy buildapi.LicensePolicy, auth *authn.AuthConfig, insecure bool, imageInDir bool, countLimit int) ([]buildapi.LicenseViolation, error) {
+	trivyArgs := []string{"image", "--quiet", "--scanners", "license", "--format", "json"}
+	if imageInDir {
+		trivyArgs = append(trivyArgs, "--input", imagePath)
+	} else {
+		trivyArgs = append(trivyArgs, imagePath)
+		trivyArgs = append(trivyArgs, getAuthStringForTrivyScan(auth)...)
+
+		if insecure {
+			trivyArgs = append(trivyArgs, "--insecure")
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "trivy", trivyArgs...)
+	cmd.Stdin = nil
+
+	result, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Printf("failed to run trivy:\n%s", string(result))
+		return nil, fmt.Errorf("failed to run the license scan: %w", err)
+	}
+
+	var trivyResult trivyLicenseResult
+	if err := json.Unmarshal(result, &trivyResult); err != nil {
+		return nil, fmt.Errorf("failed to parse the result of the license scan: %w", err)
+	}
+
+	seen := map[buildapi.LicenseViolation]bool{}
+	var violations []buildapi.LicenseViolation
+	for _, result := range trivyResult.Results {
+		for _, license := range result.Licenses {
+			if !violatesLicensePolicy(policy, license.Name) {
+				continue
+			}
+
+			violation := buildapi.LicenseViolation{
+				Package: license.PkgName,
+				License: license.Name,
+			}
+
+			// licenses of loose files have no package
+			if violation.Package == "" {
+				violation.Package = license.FilePath
+			}
+
+			if !seen[violation] {
+				seen[violation] = true
+				violations = append(violations, violation)
+			}
+		}
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].Package != violations[j].Package {
+			return violations[i].Package < violations[j].Package
+		}
+		return violations[i].License < violations[j].License
+	})
+
+	if len(violations) > countLimit {
+		violations = violations[:countLimit]
+	}
+
+	return violations, nil
+}
+
+// violatesLicensePolicy returns whether a license expression is not allowed by the policy. The alternatives of an
+// expression with OR violate the policy only if all of them do, the parts of an expression with AND violate it if
+// one of them does. Parentheses are ignored, and identifiers are compared case-insensitively.
+func violatesLicensePolicy(policy buildapi.LicensePolicy, expression string) bool {
+	expression = strings.NewReplacer("(", " ", ")", " ").Replace(expression)
+
+	for _, alternative := range splitLicenseExpression(expression, "OR") {
+		violated := false
+		for _, license := range splitLicenseExpression(alternative, "AND") {
+			if violatesLicensePolicyIdentifier(policy, license) {
+				violated = true
+				break
+			}
+		}
+
+		if !violated {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitLicenseExpression splits a license expression at an operator, which is case-insensitive in SPDX
+func splitLicenseExpression(expression string, operator string) []string {
+	var parts []string
+	var current []string
+	for _, field := range strings.Fields(expression) {
+		if strings.EqualFold(field, operator) {
+			parts = append(parts, strings.Join(current, " "))
+			current = nil
+			continue
+		}
+		current = append(current, field)
+	}
+
+	return append(parts, strings.Join(current, " "))
+}
+
+func violatesLicensePolicyIdentifier(policy buildapi.LicensePolicy, license string) bool {
+	if license == "" {
+		return false
+	}
+
+	for _, denied := range policy.Denied {
+		if strings.EqualFold(denied, license) {
+			return true
+		}
+	}
+
+	if len(policy.Allowed) == 0 {
+		return false
+	}
+
+	for _, allowed := range policy.Allowed {
+		if strings.EqualFold(allowed, license) {
+			return false
+		}
+	}
+
+	return true
+}
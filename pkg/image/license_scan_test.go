@@ -0,0 +1,86 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/image"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RunLicenseScan", func() {
+	installTrivy := func(script string) {
+		binDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(binDir, "trivy"), []byte(script), 0700)).To(Succeed())
+
+		GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	}
+
+	BeforeEach(func() {
+		// install a fake trivy that reports the licenses of some packages
+		installTrivy(`#!/bin/sh
+cat <<'EOF'
+{"Results":[
+	{"Target":"OS Packages","Licenses":[
+		{"Name":"GPL-3.0-only","PkgName":"bash"},
+		{"Name":"GPL-3.0-only","PkgName":"bash"},
+		{"Name":"MIT","PkgName":"zlib"},
+		{"Name":"GPL-2.0-only OR MIT","PkgName":"dual"},
+		{"Name":"LGPL-2.1-only AND MIT","PkgName":"mixed"}
+	]},
+	{"Target":"Loose File License(s)","Licenses":[
+		{"Name":"AGPL-3.0-only","FilePath":"/app/LICENSE"}
+	]}
+]}
+EOF
+`)
+	})
+
+	It("reports the packages with a denied license", func() {
+		violations, err := image.RunLicenseScan(context.TODO(), "some-image", buildapi.LicensePolicy{
+			Denied: []string{"gpl-3.0-only", "AGPL-3.0-only", "LGPL-2.1-only"},
+		}, nil, false, false, 20)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(violations).To(Equal([]buildapi.LicenseViolation{
+			{Package: "/app/LICENSE", License: "AGPL-3.0-only"},
+			{Package: "bash", License: "GPL-3.0-only"},
+			{Package: "mixed", License: "LGPL-2.1-only AND MIT"},
+		}))
+	})
+
+	It("reports the packages with a license that is not allowed", func() {
+		violations, err := image.RunLicenseScan(context.TODO(), "some-image", buildapi.LicensePolicy{
+			Allowed: []string{"MIT", "LGPL-2.1-only"},
+		}, nil, false, false, 20)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(violations).To(Equal([]buildapi.LicenseViolation{
+			{Package: "/app/LICENSE", License: "AGPL-3.0-only"},
+			{Package: "bash", License: "GPL-3.0-only"},
+		}))
+	})
+
+	It("limits the violations to the count limit", func() {
+		violations, err := image.RunLicenseScan(context.TODO(), "some-image", buildapi.LicensePolicy{
+			Allowed: []string{"MIT"},
+		}, nil, false, false, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(violations).To(HaveLen(1))
+	})
+
+	It("fails if trivy fails", func() {
+		installTrivy("#!/bin/sh\necho 'FATAL scan error'\nexit 1\n")
+
+		_, err := image.RunLicenseScan(context.TODO(), "some-image", buildapi.LicensePolicy{
+			Denied: []string{"GPL-3.0-only"},
+		}, nil, false, false, 20)
+		Expect(err).To(MatchError(ContainSubstring("failed to run the license scan")))
+	})
+})
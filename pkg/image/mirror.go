@@ -0,0 +1,143 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	containerreg "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// TagImageOrImageIndex adds tags to a pushed image or image index in its repository, the manifest is not
+// uploaded again
+func TagImageOrImageIndex(subject name.Digest, tags []string, options []remote.Option) ([]name.Tag, error) {
+	descriptor, err := remote.Get(subject, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the image %s: %w", subject.String(), err)
+	}
+
+	var tagged []name.Tag
+	for _, tag := range tags {
+		tagName := subject.Context().Tag(tag)
+		if err := remote.Tag(tagName, descriptor, options...); err != nil {
+			return nil, fmt.Errorf("failed to tag the image as %s: %w", tagName.String(), err)
+		}
+
+		tagged = append(tagged, tagName)
+	}
+
+	return tagged, nil
+}
+
+// CopyImageOrImageIndex copies a pushed image or image index to another reference. The blobs are read with the
+// source options and written with the target options, they are mounted from the source repository if the
+// target is in the same registry.
+func CopyImageOrImageIndex(source name.Digest, target name.Reference, sourceOptions []remote.Option, targetOptions []remote.Option) error {
+	descriptor, err := remote.Get(source, sourceOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to get the image %s: %w", source.String(), err)
+	}
+
+	if descriptor.MediaType.IsIndex() {
+		imageIndex, err := descriptor.ImageIndex()
+		if err != nil {
+			return err
+		}
+
+		return remote.WriteIndex(target, imageIndex, targetOptions...)
+	}
+
+	img, err := descriptor.Image()
+	if err != nil {
+		return err
+	}
+
+	return remote.Write(target, img, targetOptions...)
+}
+
+// CopyAttachedArtifacts copies the artifacts that are attached to a pushed image or image index, and for an image
+// index to each image of it, to another repository. These are the signatures and attestations that are stored
+// in the tag schema of cosign, and the referrers like signatures, provenance, SBOMs and vulnerability reports.
+// The image itself must be copied with CopyImageOrImageIndex, the digests stay the same so that the artifacts
+// refer to the copy.
+func CopyAttachedArtifacts(source name.Digest, target name.Repository, sourceOptions []remote.Option, targetOptions []remote.Option) error {
+	descriptor, err := remote.Get(source, sourceOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to get the image %s: %w", source.String(), err)
+	}
+
+	digests := []containerreg.Hash{descriptor.Digest}
+	if descriptor.MediaType.IsIndex() {
+		imageIndex, err := descriptor.ImageIndex()
+		if err != nil {
+			return err
+		}
+
+		indexManifest, err := imageIndex.IndexManifest()
+		if err != nil {
+			return err
+		}
+
+		for _, manifest := range indexManifest.Manifests {
+			digests = append(digests, manifest.Digest)
+		}
+	}
+
+	for _, digest := range digests {
+		for _, suffix := range []string{"sig", "att"} {
+			tag := fmt.Sprintf("%s-%s.%s", digest.Algorithm, digest.Hex, suffix)
+			if err := copyTagIfExists(source.Context().Tag(tag), target.Tag(tag), sourceOptions, targetOptions); err != nil {
+				return err
+			}
+		}
+
+		referrers, err := remote.Referrers(source.Context().Digest(digest.String()), sourceOptions...)
+		if err != nil {
+			return fmt.Errorf("failed to list the referrers of %s@%s: %w", source.Context().String(), digest.String(), err)
+		}
+
+		referrersManifest, err := referrers.IndexManifest()
+		if err != nil {
+			return err
+		}
+
+		// the remote package updates the referrers tag if the target registry does not support the referrers API
+		for _, referrer := range referrersManifest.Manifests {
+			artifact, err := remote.Image(source.Context().Digest(referrer.Digest.String()), sourceOptions...)
+			if err != nil {
+				return fmt.Errorf("failed to get the referrer %s of %s@%s: %w", referrer.Digest.String(), source.Context().String(), digest.String(), err)
+			}
+
+			if err := remote.Write(target.Digest(referrer.Digest.String()), artifact, targetOptions...); err != nil {
+				return fmt.Errorf("failed to copy the referrer %s to %s: %w", referrer.Digest.String(), target.String(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// copyTagIfExists copies the artifact with the given tag, nothing is copied if the tag does not exist
+func copyTagIfExists(source name.Tag, target name.Tag, sourceOptions []remote.Option, targetOptions []remote.Option) error {
+	artifact, err := remote.Image(source, sourceOptions...)
+	if err != nil {
+		var transportErr *transport.Error
+		if errors.As(err, &transportErr) && transportErr.StatusCode == 404 {
+			return nil
+		}
+
+		return fmt.Errorf("failed to get the artifact %s: %w", source.String(), err)
+	}
+
+	if err := remote.Write(target, artifact, targetOptions...); err != nil {
+		return fmt.Errorf("failed to copy the artifact %s to %s: %w", source.String(), target.String(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/image"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Mirroring images", func() {
+	var (
+		registryHost string
+		uploads      *atomic.Int32
+	)
+
+	newRegistry := func(uploads *atomic.Int32) string {
+		logger := log.New(io.Discard, "", 0)
+		reg := registry.New(registry.Logger(logger))
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/blobs/uploads/") && r.URL.Query().Get("mount") == "" {
+				uploads.Add(1)
+			}
+			reg.ServeHTTP(w, r)
+		}))
+		DeferCleanup(func() {
+			server.Close()
+		})
+
+		return strings.ReplaceAll(server.URL, "http://", "")
+	}
+
+	pushRandomImage := func() name.Digest {
+		imageName, err := name.ParseReference(fmt.Sprintf("%s/test-namespace/test-image", registryHost))
+		Expect(err).ToNot(HaveOccurred())
+
+		img, err := random.Image(1024, 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		digest, _, err := image.PushImageOrImageIndex(imageName, img, nil, []remote.Option{})
+		Expect(err).ToNot(HaveOccurred())
+
+		return imageName.Context().Digest(digest)
+	}
+
+	BeforeEach(func() {
+		uploads = &atomic.Int32{}
+		registryHost = newRegistry(uploads)
+	})
+
+	Context("TagImageOrImageIndex", func() {
+		It("adds the tags to the image", func() {
+			subject := pushRandomImage()
+
+			tagged, err := image.TagImageOrImageIndex(subject, []string{"v1.0.0", "stable"}, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tagged).To(HaveLen(2))
+
+			for _, tag := range tagged {
+				descriptor, err := remote.Head(tag)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(descriptor.Digest.String()).To(Equal(subject.DigestStr()))
+			}
+		})
+	})
+
+	Context("CopyImageOrImageIndex", func() {
+		It("does not upload the blobs again when copying the image within the registry", func() {
+			subject := pushRandomImage()
+			uploads.Store(0)
+
+			target, err := name.ParseReference(fmt.Sprintf("%s/other-namespace/other-image:latest", registryHost))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(image.CopyImageOrImageIndex(subject, target, []remote.Option{}, []remote.Option{})).To(Succeed())
+			Expect(uploads.Load()).To(BeZero())
+
+			descriptor, err := remote.Head(target)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(descriptor.Digest.String()).To(Equal(subject.DigestStr()))
+		})
+
+		It("copies the image index to another registry", func() {
+			imageName, err := name.ParseReference(fmt.Sprintf("%s/test-namespace/test-index", registryHost))
+			Expect(err).ToNot(HaveOccurred())
+
+			index, err := random.Index(1024, 1, 2)
+			Expect(err).ToNot(HaveOccurred())
+
+			digest, _, err := image.PushImageOrImageIndex(imageName, nil, index, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+
+			otherRegistryHost := newRegistry(&atomic.Int32{})
+			target, err := name.ParseReference(fmt.Sprintf("%s/mirror/test-index:latest", otherRegistryHost))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(image.CopyImageOrImageIndex(imageName.Context().Digest(digest), target, []remote.Option{}, []remote.Option{})).To(Succeed())
+
+			descriptor, err := remote.Head(target)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(descriptor.Digest.String()).To(Equal(digest))
+		})
+	})
+
+	Context("CopyAttachedArtifacts", func() {
+		It("copies the signatures and the referrers to another registry", func() {
+			imageName, err := name.ParseReference(fmt.Sprintf("%s/test-namespace/test-image", registryHost))
+			Expect(err).ToNot(HaveOccurred())
+
+			img, err := random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			digest, _, err := image.PushImageOrImageIndex(imageName, img, nil, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+			subject := imageName.Context().Digest(digest)
+
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = image.SignImageOrImageIndex(imageName, img, nil, key, buildapi.SignatureStorageTag, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+
+			sbomDigest, err := image.AttachSBOM(subject, []byte(`{"spdxVersion":"SPDX-2.3"}`), buildapi.SBOMFormatSPDXJSON, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+
+			otherRegistryHost := newRegistry(&atomic.Int32{})
+			target, err := name.ParseReference(fmt.Sprintf("%s/mirror/test-image:latest", otherRegistryHost))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(image.CopyImageOrImageIndex(subject, target, []remote.Option{}, []remote.Option{})).To(Succeed())
+			Expect(image.CopyAttachedArtifacts(subject, target.Context(), []remote.Option{}, []remote.Option{})).To(Succeed())
+
+			_, err = remote.Head(target.Context().Tag(strings.Replace(digest, ":", "-", 1) + ".sig"))
+			Expect(err).ToNot(HaveOccurred())
+
+			referrers, err := remote.Referrers(target.Context().Digest(digest))
+			Expect(err).ToNot(HaveOccurred())
+
+			indexManifest, err := referrers.IndexManifest()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(indexManifest.Manifests).To(HaveLen(1))
+			Expect(indexManifest.Manifests[0].Digest.String()).To(Equal(sbomDigest))
+		})
+
+		It("copies nothing if no artifacts are attached", func() {
+			subject := pushRandomImage()
+
+			otherRegistryHost := newRegistry(&atomic.Int32{})
+			target, err := name.ParseReference(fmt.Sprintf("%s/mirror/test-image:latest", otherRegistryHost))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(image.CopyImageOrImageIndex(subject, target, []remote.Option{}, []remote.Option{})).To(Succeed())
+			Expect(image.CopyAttachedArtifacts(subject, target.Context(), []remote.Option{}, []remote.Option{})).To(Succeed())
+
+			_, err = remote.Head(target.Context().Tag(strings.Replace(subject.DigestStr(), ":", "-", 1) + ".sig"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
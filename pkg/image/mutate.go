@@ -6,11 +6,14 @@ package image
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	containerreg "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
 )
 
 // replaceManifestIn replaces a manifest in an index, because there is no
@@ -32,15 +35,16 @@ func replaceManifestIn(imageIndex containerreg.ImageIndex, descriptor containerr
 	})
 }
 
-// MutateImageOrImageIndex mutates an image or image index with additional annotations and labels
-func MutateImageOrImageIndex(image containerreg.Image, imageIndex containerreg.ImageIndex, annotations map[string]string, labels map[string]string) (containerreg.Image, containerreg.ImageIndex, error) {
+// MutateImageOrImageIndex mutates an image or image index with additional annotations and labels, and
+// the changes of the image configuration
+func MutateImageOrImageIndex(image containerreg.Image, imageIndex containerreg.ImageIndex, annotations map[string]string, labels map[string]string, config *buildapi.ImageConfig) (containerreg.Image, containerreg.ImageIndex, error) {
 	if imageIndex != nil {
 		indexManifest, err := imageIndex.IndexManifest()
 		if err != nil {
 			return nil, nil, err
 		}
 
-		if len(labels) > 0 || len(annotations) > 0 {
+		if len(labels) > 0 || len(annotations) > 0 || config != nil {
 			for _, descriptor := range indexManifest.Manifests {
 				switch descriptor.MediaType {
 				case types.OCIImageIndex, types.DockerManifestList:
@@ -48,7 +52,7 @@ func MutateImageOrImageIndex(image containerreg.Image, imageIndex containerreg.I
 					if err != nil {
 						return nil, nil, err
 					}
-					_, childImageIndex, err = MutateImageOrImageIndex(nil, childImageIndex, annotations, labels)
+					_, childImageIndex, err = MutateImageOrImageIndex(nil, childImageIndex, annotations, labels, config)
 					if err != nil {
 						return nil, nil, err
 					}
@@ -61,7 +65,7 @@ func MutateImageOrImageIndex(image containerreg.Image, imageIndex containerreg.I
 						return nil, nil, err
 					}
 
-					image, err = mutateImage(image, annotations, labels)
+					image, err = mutateImage(image, annotations, labels, config)
 					if err != nil {
 						return nil, nil, err
 					}
@@ -80,7 +84,7 @@ func MutateImageOrImageIndex(image containerreg.Image, imageIndex containerreg.I
 		}
 	} else {
 		var err error
-		image, err = mutateImage(image, annotations, labels)
+		image, err = mutateImage(image, annotations, labels, config)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -89,22 +93,38 @@ func MutateImageOrImageIndex(image containerreg.Image, imageIndex containerreg.I
 	return image, imageIndex, nil
 }
 
-func mutateImage(image containerreg.Image, annotations map[string]string, labels map[string]string) (containerreg.Image, error) {
-	if len(labels) > 0 {
+// AnnotateImageIndex adds annotations to an image index, the images in it are not changed
+func AnnotateImageIndex(imageIndex containerreg.ImageIndex, annotations map[string]string) (containerreg.ImageIndex, error) {
+	imageIndex, castSucceeded := mutate.Annotations(imageIndex, annotations).(containerreg.ImageIndex)
+	if !castSucceeded {
+		return nil, errors.New("expected mutate.Annotation to return an ImageIndex when passing in an ImageIndex")
+	}
+
+	return imageIndex, nil
+}
+
+func mutateImage(image containerreg.Image, annotations map[string]string, labels map[string]string, config *buildapi.ImageConfig) (containerreg.Image, error) {
+	if len(labels) > 0 || config != nil {
 		cfg, err := image.ConfigFile()
 		if err != nil {
 			return nil, err
 		}
 		cfg = cfg.DeepCopy()
 
-		if cfg.Config.Labels == nil {
-			cfg.Config.Labels = labels
-		} else {
-			for key, value := range labels {
-				cfg.Config.Labels[key] = value
+		if len(labels) > 0 {
+			if cfg.Config.Labels == nil {
+				cfg.Config.Labels = labels
+			} else {
+				for key, value := range labels {
+					cfg.Config.Labels[key] = value
+				}
 			}
 		}
 
+		if config != nil {
+			mutateConfig(&cfg.Config, config)
+		}
+
 		image, err = mutate.ConfigFile(image, cfg)
 		if err != nil {
 			return nil, err
@@ -122,6 +142,57 @@ func mutateImage(image containerreg.Image, annotations map[string]string, labels
 	return image, nil
 }
 
+// mutateConfig applies the changes to the configuration of an image
+func mutateConfig(cfg *containerreg.Config, config *buildapi.ImageConfig) {
+	for _, envVar := range config.Env {
+		replaced := false
+		for i, existing := range cfg.Env {
+			if name, _, _ := strings.Cut(existing, "="); name == envVar.Name {
+				cfg.Env[i] = envVar.Name + "=" + envVar.Value
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			cfg.Env = append(cfg.Env, envVar.Name+"="+envVar.Value)
+		}
+	}
+
+	if config.Entrypoint != nil {
+		cfg.Entrypoint = config.Entrypoint
+	}
+
+	if config.Cmd != nil {
+		cfg.Cmd = config.Cmd
+	}
+
+	if config.User != nil {
+		cfg.User = *config.User
+	}
+
+	if len(config.ExposedPorts) > 0 {
+		if cfg.ExposedPorts == nil {
+			cfg.ExposedPorts = map[string]struct{}{}
+		}
+
+		for _, port := range config.ExposedPorts {
+			if !strings.Contains(port, "/") {
+				port += "/tcp"
+			}
+			cfg.ExposedPorts[port] = struct{}{}
+		}
+	}
+
+	if config.WorkingDir != nil {
+		cfg.WorkingDir = *config.WorkingDir
+	}
+
+	if config.StopSignal != nil {
+		cfg.StopSignal = *config.StopSignal
+	}
+}
+
 func MutateImageOrImageIndexTimestamp(image containerreg.Image, imageIndex containerreg.ImageIndex, timestamp time.Time) (containerreg.Image, containerreg.ImageIndex, error) {
 	if image != nil {
 		image, err := mutateImageTimestamp(image, timestamp)
@@ -11,7 +11,9 @@ import (
 	containerreg "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/random"
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
 	"github.com/shipwright-io/build/pkg/image"
+	"k8s.io/utils/ptr"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -36,7 +38,7 @@ var _ = Describe("MutateImageOrImageIndex", func() {
 				},
 				map[string]string{
 					"label": "someLabelValue",
-				})
+				}, nil)
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(newImageIndex).To(BeNil())
@@ -89,7 +91,7 @@ var _ = Describe("MutateImageOrImageIndex", func() {
 				map[string]string{
 					"label":          "someLabelValue",
 					"existingLabel1": "newValue",
-				})
+				}, nil)
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(newImageIndex).To(BeNil())
@@ -129,7 +131,7 @@ var _ = Describe("MutateImageOrImageIndex", func() {
 				},
 				map[string]string{
 					"label": "someLabelValue",
-				})
+				}, nil)
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(newImageIndex).ToNot(BeNil())
@@ -161,6 +163,94 @@ var _ = Describe("MutateImageOrImageIndex", func() {
 		})
 	})
 
+	Context("for an image with a configuration", func() {
+
+		var img containerreg.Image
+
+		BeforeEach(func() {
+			var err error
+			img, err = random.Image(1234, 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			cfg, err := img.ConfigFile()
+			Expect(err).ToNot(HaveOccurred())
+			cfg = cfg.DeepCopy()
+			cfg.Config.Env = []string{"PATH=/usr/bin", "MODE=debug"}
+			cfg.Config.Entrypoint = []string{"/bin/sh", "-c"}
+			cfg.Config.Cmd = []string{"echo hello"}
+			cfg.Config.User = "root"
+			cfg.Config.ExposedPorts = map[string]struct{}{"80/tcp": {}}
+			cfg.Config.WorkingDir = "/"
+			img, err = mutate.ConfigFile(img, cfg)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("correctly patches the configuration", func() {
+			newImg, _, err := image.MutateImageOrImageIndex(img, nil, nil, nil, &buildapi.ImageConfig{
+				Env: []buildapi.ImageConfigEnvVar{
+					{Name: "MODE", Value: "production"},
+					{Name: "PORT", Value: "8080"},
+				},
+				Entrypoint:   []string{"/app/server"},
+				Cmd:          []string{},
+				User:         ptr.To("1000:1000"),
+				ExposedPorts: []string{"8080", "5353/udp"},
+				WorkingDir:   ptr.To("/app"),
+				StopSignal:   ptr.To("SIGINT"),
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			configFile, err := newImg.ConfigFile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(configFile.Config.Env).To(Equal([]string{"PATH=/usr/bin", "MODE=production", "PORT=8080"}))
+			Expect(configFile.Config.Entrypoint).To(Equal([]string{"/app/server"}))
+			Expect(configFile.Config.Cmd).To(BeEmpty())
+			Expect(configFile.Config.User).To(Equal("1000:1000"))
+			Expect(configFile.Config.ExposedPorts).To(Equal(map[string]struct{}{"80/tcp": {}, "8080/tcp": {}, "5353/udp": {}}))
+			Expect(configFile.Config.WorkingDir).To(Equal("/app"))
+			Expect(configFile.Config.StopSignal).To(Equal("SIGINT"))
+		})
+
+		It("keeps the settings that are not part of the configuration", func() {
+			newImg, _, err := image.MutateImageOrImageIndex(img, nil, nil, nil, &buildapi.ImageConfig{
+				User: ptr.To("1000"),
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			configFile, err := newImg.ConfigFile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(configFile.Config.User).To(Equal("1000"))
+			Expect(configFile.Config.Env).To(Equal([]string{"PATH=/usr/bin", "MODE=debug"}))
+			Expect(configFile.Config.Entrypoint).To(Equal([]string{"/bin/sh", "-c"}))
+			Expect(configFile.Config.Cmd).To(Equal([]string{"echo hello"}))
+			Expect(configFile.Config.WorkingDir).To(Equal("/"))
+		})
+	})
+
+	Context("for an index with a configuration", func() {
+		It("patches the configuration of every image", func() {
+			index, err := random.Index(1024, 1, 2)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, newImageIndex, err := image.MutateImageOrImageIndex(nil, index, nil, nil, &buildapi.ImageConfig{
+				WorkingDir: ptr.To("/app"),
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			indexManifest, err := newImageIndex.IndexManifest()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(indexManifest.Manifests).To(HaveLen(2))
+			for _, descriptor := range indexManifest.Manifests {
+				img, err := newImageIndex.Image(descriptor.Digest)
+				Expect(err).ToNot(HaveOccurred())
+
+				configFile, err := img.ConfigFile()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(configFile.Config.WorkingDir).To(Equal("/app"))
+			}
+		})
+	})
+
 	Context("mutate creation timestamp", func() {
 		referenceTime := time.Unix(1700000000, 0)
 
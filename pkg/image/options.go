@@ -7,6 +7,7 @@ package image
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net/http"
@@ -45,8 +46,25 @@ func (ort *optionsRoundTripper) RoundTrip(in *http.Request) (*http.Response, err
 	return ort.inner.RoundTrip(in)
 }
 
-// GetOptions constructs go-containerregistry options to access the remote registry, in addition, it returns the authentication separately which can be an empty object
-func GetOptions(ctx context.Context, imageName name.Reference, insecure bool, dockerConfigJSONPath string, userAgent string) ([]remote.Option, *authn.AuthConfig, error) {
+// Names of the files in the directory of a registry host that contain its CA bundle and the client certificate
+const (
+	registryCABundleFileName          = "ca.crt"
+	registryClientCertificateFileName = "client.cert"
+	registryClientKeyFileName         = "client.key"
+)
+
+// ErrRegistryCredentialsNotFound is returned by GetOptions if the Docker config has no credentials for the registry
+var ErrRegistryCredentialsNotFound = errors.New("failed to find registry credentials")
+
+// RegistryCertificatesDirectoryName returns the name of the directory that contains the CA bundle and the
+// client certificate of a registry host, the colon of a port is not allowed in the key of a volume item
+func RegistryCertificatesDirectoryName(host string) string {
+	return strings.ReplaceAll(host, ":", "_")
+}
+
+// GetOptions constructs go-containerregistry options to access the remote registry, in addition, it returns the authentication separately which can be an empty object.
+// The registry certificates directory can contain a directory per registry host with a ca.crt, and a client.cert and client.key for mutual TLS.
+func GetOptions(ctx context.Context, imageName name.Reference, insecure bool, dockerConfigJSONPath string, registryCertificatesDirectory string, userAgent string) ([]remote.Option, *authn.AuthConfig, error) {
 	var options []remote.Option
 
 	options = append(options, remote.WithContext(ctx))
@@ -63,6 +81,12 @@ func GetOptions(ctx context.Context, imageName name.Reference, insecure bool, do
 		transport.TLSClientConfig.MinVersion = 0
 	}
 
+	if registryCertificatesDirectory != "" {
+		if err := addRegistryCertificates(transport.TLSClientConfig, filepath.Join(registryCertificatesDirectory, RegistryCertificatesDirectoryName(imageName.Context().RegistryStr()))); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// find a Docker config.json
 	if dockerConfigJSONPath != "" {
 		// if we have a value provided already, then we support a directory that contains a .dockerconfigjson file
@@ -124,7 +148,8 @@ func GetOptions(ctx context.Context, imageName name.Reference, insecure bool, do
 				availableConfigs = "none"
 			}
 
-			return nil, nil, fmt.Errorf("failed to find registry credentials for %s, available configurations: %s",
+			return nil, nil, fmt.Errorf("%w for %s, available configurations: %s",
+				ErrRegistryCredentialsNotFound,
 				registryName,
 				availableConfigs,
 			)
@@ -141,3 +166,40 @@ func GetOptions(ctx context.Context, imageName name.Reference, insecure bool, do
 
 	return options, &auth, nil
 }
+
+// addRegistryCertificates adds the CA bundle and the client certificate in the directory of a registry host to
+// the TLS configuration, the files are optional
+func addRegistryCertificates(tlsConfig *tls.Config, directory string) error {
+	caBundle, err := os.ReadFile(filepath.Join(directory, registryCABundleFileName))
+	switch {
+	case err == nil:
+		certPool, err := x509.SystemCertPool()
+		if err != nil {
+			certPool = x509.NewCertPool()
+		}
+
+		if !certPool.AppendCertsFromPEM(caBundle) {
+			return fmt.Errorf("failed to find a certificate in the CA bundle %s", filepath.Join(directory, registryCABundleFileName))
+		}
+
+		tlsConfig.RootCAs = certPool
+
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("failed to read the CA bundle: %w", err)
+	}
+
+	certificateFile := filepath.Join(directory, registryClientCertificateFileName)
+	keyFile := filepath.Join(directory, registryClientKeyFileName)
+	if _, err := os.Stat(certificateFile); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certificateFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load the client certificate: %w", err)
+	}
+
+	tlsConfig.Certificates = []tls.Certificate{certificate}
+
+	return nil
+}
@@ -6,11 +6,28 @@ package image_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/shipwright-io/build/pkg/image"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -42,7 +59,7 @@ var _ = Describe("GetOptions", func() {
 	Context("without a dockerconfigjson", func() {
 
 		It("constructs options and empty auth", func() {
-			options, auth, err := image.GetOptions(context.TODO(), imageName, true, "", "test-agent")
+			options, auth, err := image.GetOptions(context.TODO(), imageName, true, "", "", "test-agent")
 			Expect(err).ToNot(HaveOccurred())
 
 			// there is no way to further check what is in because the options are functions
@@ -60,7 +77,7 @@ var _ = Describe("GetOptions", func() {
 		It("constructs options and auth with the matching user", func() {
 			withDockerConfigJSON(authn.DefaultAuthKey, "aUser", "aPassword", func(dockerConfigJSONPath string) {
 
-				options, auth, err := image.GetOptions(context.TODO(), imageName, true, dockerConfigJSONPath, "test-agent")
+				options, auth, err := image.GetOptions(context.TODO(), imageName, true, dockerConfigJSONPath, "", "test-agent")
 				Expect(err).ToNot(HaveOccurred())
 
 				// there is no way to further check what is in because the options are functions
@@ -78,9 +95,102 @@ var _ = Describe("GetOptions", func() {
 
 		It("fails with an error", func() {
 			withDockerConfigJSON("ghcr.io", "aUser", "aPassword", func(dockerConfigJSONPath string) {
-				_, _, err := image.GetOptions(context.TODO(), imageName, true, dockerConfigJSONPath, "test-agent")
+				_, _, err := image.GetOptions(context.TODO(), imageName, true, dockerConfigJSONPath, "", "test-agent")
 				Expect(err).To(HaveOccurred())
 			})
 		})
 	})
+
+	Context("with registry certificates", func() {
+		var (
+			imageName                     name.Reference
+			registryCertificatesDirectory string
+			hostDirectory                 string
+		)
+
+		writePEM := func(file string, blockType string, bytes []byte) {
+			GinkgoHelper()
+			Expect(os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: bytes}), 0600)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			// the client certificate is self-signed and therefore its own CA
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+
+			template := &x509.Certificate{
+				SerialNumber: big.NewInt(1),
+				Subject:      pkix.Name{CommonName: "shipwright"},
+				NotBefore:    time.Now().Add(-time.Hour),
+				NotAfter:     time.Now().Add(time.Hour),
+				KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+				ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+				IsCA:         true,
+
+				BasicConstraintsValid: true,
+			}
+			certificateBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+			Expect(err).ToNot(HaveOccurred())
+			clientCertificate, err := x509.ParseCertificate(certificateBytes)
+			Expect(err).ToNot(HaveOccurred())
+
+			clientCAs := x509.NewCertPool()
+			clientCAs.AddCert(clientCertificate)
+
+			server := httptest.NewUnstartedServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+			server.TLS = &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				ClientAuth: tls.RequireAndVerifyClientCert,
+				ClientCAs:  clientCAs,
+			}
+			server.StartTLS()
+			DeferCleanup(server.Close)
+
+			imageName, err = name.ParseReference(strings.TrimPrefix(server.URL, "https://") + "/namespace/image:tag")
+			Expect(err).ToNot(HaveOccurred())
+
+			registryCertificatesDirectory = GinkgoT().TempDir()
+			hostDirectory = filepath.Join(registryCertificatesDirectory, image.RegistryCertificatesDirectoryName(imageName.Context().RegistryStr()))
+			Expect(os.Mkdir(hostDirectory, 0755)).To(Succeed())
+
+			writePEM(filepath.Join(hostDirectory, "ca.crt"), "CERTIFICATE", server.Certificate().Raw)
+			writePEM(filepath.Join(hostDirectory, "client.cert"), "CERTIFICATE", certificateBytes)
+			keyBytes, err := x509.MarshalECPrivateKey(key)
+			Expect(err).ToNot(HaveOccurred())
+			writePEM(filepath.Join(hostDirectory, "client.key"), "EC PRIVATE KEY", keyBytes)
+		})
+
+		push := func(registryCertificatesDirectory string) error {
+			GinkgoHelper()
+
+			options, _, err := image.GetOptions(context.TODO(), imageName, false, "", registryCertificatesDirectory, "test-agent")
+			Expect(err).ToNot(HaveOccurred())
+
+			img, err := random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			return remote.Write(imageName, img, options...)
+		}
+
+		It("trusts the CA bundle and presents the client certificate of the registry host", func() {
+			Expect(push(registryCertificatesDirectory)).To(Succeed())
+		})
+
+		It("fails to access the registry without the CA bundle", func() {
+			Expect(os.Remove(filepath.Join(hostDirectory, "ca.crt"))).To(Succeed())
+			Expect(push(registryCertificatesDirectory)).To(MatchError(ContainSubstring("certificate")))
+		})
+
+		It("fails to access the registry without the client certificate", func() {
+			Expect(os.Remove(filepath.Join(hostDirectory, "client.cert"))).To(Succeed())
+			Expect(push(registryCertificatesDirectory)).ToNot(Succeed())
+		})
+
+		It("fails for a CA bundle without a certificate", func() {
+			Expect(os.WriteFile(filepath.Join(hostDirectory, "ca.crt"), []byte("no certificate"), 0600)).To(Succeed())
+
+			_, _, err := image.GetOptions(context.TODO(), imageName, false, "", registryCertificatesDirectory, "test-agent")
+			Expect(err).To(MatchError(ContainSubstring("failed to find a certificate in the CA bundle")))
+		})
+	})
 })
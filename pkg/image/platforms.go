@@ -0,0 +1,187 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	containerreg "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+)
+
+// FilterImageOrImageIndex removes the manifests of the platforms that are not requested from an image index,
+// which includes manifests without a platform like attestations. An image must be of a requested platform.
+func FilterImageOrImageIndex(image containerreg.Image, imageIndex containerreg.ImageIndex, platforms []string) (containerreg.Image, containerreg.ImageIndex, error) {
+	var requested []containerreg.Platform
+	for _, value := range platforms {
+		platform, err := containerreg.ParsePlatform(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse the platform %q: %w", value, err)
+		}
+		requested = append(requested, *platform)
+	}
+
+	if imageIndex == nil {
+		platform, err := getImagePlatform(image)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if platform == nil || !isRequestedPlatform(*platform, requested) {
+			return nil, nil, fmt.Errorf("the platform %s of the image is not one of the platforms %s", platformString(platform), strings.Join(platforms, ", "))
+		}
+
+		return image, nil, nil
+	}
+
+	imageIndex, err := filterImageIndex(imageIndex, requested)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	indexManifest, err := imageIndex.IndexManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(indexManifest.Manifests) == 0 {
+		return nil, nil, fmt.Errorf("the image index contains none of the platforms %s", strings.Join(platforms, ", "))
+	}
+
+	return nil, imageIndex, nil
+}
+
+func filterImageIndex(imageIndex containerreg.ImageIndex, requested []containerreg.Platform) (containerreg.ImageIndex, error) {
+	indexManifest, err := imageIndex.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, descriptor := range indexManifest.Manifests {
+		keep := false
+
+		switch {
+		case descriptor.MediaType.IsIndex():
+			childImageIndex, err := imageIndex.ImageIndex(descriptor.Digest)
+			if err != nil {
+				return nil, err
+			}
+
+			filtered, err := filterImageIndex(childImageIndex, requested)
+			if err != nil {
+				return nil, err
+			}
+
+			childIndexManifest, err := filtered.IndexManifest()
+			if err != nil {
+				return nil, err
+			}
+
+			if len(childIndexManifest.Manifests) > 0 {
+				imageIndex = replaceManifestIn(imageIndex, descriptor, filtered)
+				continue
+			}
+
+		case descriptor.Platform != nil:
+			keep = isRequestedPlatform(*descriptor.Platform, requested)
+
+		case descriptor.MediaType.IsImage():
+			image, err := imageIndex.Image(descriptor.Digest)
+			if err != nil {
+				return nil, err
+			}
+
+			platform, err := getImagePlatform(image)
+			if err != nil {
+				return nil, err
+			}
+
+			keep = platform != nil && isRequestedPlatform(*platform, requested)
+		}
+
+		if !keep {
+			digest := descriptor.Digest
+			imageIndex = mutate.RemoveManifests(imageIndex, func(candidate containerreg.Descriptor) bool {
+				return candidate.Digest == digest
+			})
+		}
+	}
+
+	return imageIndex, nil
+}
+
+// GetPlatformDigests returns the digests of the images in an image index that have a platform, images of
+// nested image indexes are included
+func GetPlatformDigests(imageIndex containerreg.ImageIndex) ([]buildapi.PlatformDigest, error) {
+	indexManifest, err := imageIndex.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var platformDigests []buildapi.PlatformDigest
+	for _, descriptor := range indexManifest.Manifests {
+		switch {
+		case descriptor.MediaType.IsIndex():
+			childImageIndex, err := imageIndex.ImageIndex(descriptor.Digest)
+			if err != nil {
+				return nil, err
+			}
+
+			childPlatformDigests, err := GetPlatformDigests(childImageIndex)
+			if err != nil {
+				return nil, err
+			}
+			platformDigests = append(platformDigests, childPlatformDigests...)
+
+		case descriptor.Platform != nil && !isUnknownPlatform(*descriptor.Platform):
+			platformDigests = append(platformDigests, buildapi.PlatformDigest{
+				Platform: descriptor.Platform.String(),
+				Digest:   descriptor.Digest.String(),
+			})
+		}
+	}
+
+	return platformDigests, nil
+}
+
+func getImagePlatform(image containerreg.Image) (*containerreg.Platform, error) {
+	if image == nil {
+		return nil, errors.New("no image")
+	}
+
+	configFile, err := image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	return configFile.Platform(), nil
+}
+
+func isRequestedPlatform(platform containerreg.Platform, requested []containerreg.Platform) bool {
+	for _, spec := range requested {
+		if platform.Satisfies(spec) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isUnknownPlatform returns whether the platform is the one of manifests that are not images, like attestations
+func isUnknownPlatform(platform containerreg.Platform) bool {
+	return platform.OS == "unknown" && platform.Architecture == "unknown"
+}
+
+func platformString(platform *containerreg.Platform) string {
+	if platform == nil {
+		return "unknown"
+	}
+
+	return platform.String()
+}
@@ -0,0 +1,99 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	containerreg "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/shipwright-io/build/pkg/image"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Platforms", func() {
+	var index containerreg.ImageIndex
+
+	platformsOf := func(index containerreg.ImageIndex) []string {
+		GinkgoHelper()
+
+		indexManifest, err := index.IndexManifest()
+		Expect(err).ToNot(HaveOccurred())
+
+		var platforms []string
+		for _, descriptor := range indexManifest.Manifests {
+			platforms = append(platforms, descriptor.Platform.String())
+		}
+		return platforms
+	}
+
+	BeforeEach(func() {
+		index = empty.Index
+		for _, platform := range []containerreg.Platform{
+			{OS: "linux", Architecture: "amd64"},
+			{OS: "linux", Architecture: "arm64", Variant: "v8"},
+			{OS: "linux", Architecture: "s390x"},
+			{OS: "unknown", Architecture: "unknown"},
+		} {
+			img, err := random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			index = mutate.AppendManifests(index, mutate.IndexAddendum{
+				Add: img,
+				Descriptor: containerreg.Descriptor{
+					Platform: &platform,
+				},
+			})
+		}
+	})
+
+	Context("FilterImageOrImageIndex", func() {
+		It("keeps only the requested platforms in the image index", func() {
+			_, filtered, err := image.FilterImageOrImageIndex(nil, index, []string{"linux/amd64", "linux/arm64"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(platformsOf(filtered)).To(Equal([]string{"linux/amd64", "linux/arm64/v8"}))
+		})
+
+		It("fails if the image index contains none of the platforms", func() {
+			_, _, err := image.FilterImageOrImageIndex(nil, index, []string{"windows/amd64"})
+			Expect(err).To(MatchError(ContainSubstring("contains none of the platforms")))
+		})
+
+		It("fails for an image of another platform", func() {
+			img, err := random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			configFile, err := img.ConfigFile()
+			Expect(err).ToNot(HaveOccurred())
+			configFile = configFile.DeepCopy()
+			configFile.OS = "linux"
+			configFile.Architecture = "arm64"
+			img, err = mutate.ConfigFile(img, configFile)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, err = image.FilterImageOrImageIndex(img, nil, []string{"linux/amd64"})
+			Expect(err).To(MatchError(ContainSubstring("the platform linux/arm64 of the image is not one of the platforms")))
+
+			filtered, _, err := image.FilterImageOrImageIndex(img, nil, []string{"linux/arm64"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filtered).To(Equal(img))
+		})
+	})
+
+	Context("GetPlatformDigests", func() {
+		It("returns the digests of the images with a known platform", func() {
+			platformDigests, err := image.GetPlatformDigests(index)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(platformDigests).To(HaveLen(3))
+
+			indexManifest, err := index.IndexManifest()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(platformDigests[0].Platform).To(Equal("linux/amd64"))
+			Expect(platformDigests[0].Digest).To(Equal(indexManifest.Manifests[0].Digest.String()))
+		})
+	})
+})
@@ -0,0 +1,262 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+)
+
+const (
+	// StatementType is the type of an in-toto statement
+	StatementType = "https://in-toto.io/Statement/v1"
+
+	// PredicateTypeSLSAProvenance is the predicate type of a SLSA provenance
+	PredicateTypeSLSAProvenance = "https://slsa.dev/provenance/v1"
+
+	// MediaTypeInToto is the payload type of a DSSE envelope that contains an in-toto statement,
+	// and the artifact type of an unsigned attestation that is stored as referrer
+	MediaTypeInToto types.MediaType = "application/vnd.in-toto+json"
+
+	// MediaTypeDSSEEnvelope is the media type of a DSSE envelope
+	MediaTypeDSSEEnvelope types.MediaType = "application/vnd.dsse.envelope.v1+json"
+
+	// AnnotationPredicateType is the annotation of an attestation layer that contains the predicate type
+	AnnotationPredicateType = "predicateType"
+)
+
+// ProvenanceInput contains the information about a build that the controller passes to image-processing
+// to generate the provenance
+type ProvenanceInput struct {
+	BuildType          string                 `json:"buildType"`
+	BuilderID          string                 `json:"builderId"`
+	BuilderVersion     map[string]string      `json:"builderVersion,omitempty"`
+	InvocationID       string                 `json:"invocationId,omitempty"`
+	StartedOn          *time.Time             `json:"startedOn,omitempty"`
+	ExternalParameters map[string]interface{} `json:"externalParameters"`
+	InternalParameters map[string]interface{} `json:"internalParameters,omitempty"`
+	Dependencies       []ProvenanceDependency `json:"dependencies,omitempty"`
+}
+
+// ProvenanceDependency is an artifact that the build depends on, its digest can be known upfront, or
+// it is determined during the build and read from the file of a result
+type ProvenanceDependency struct {
+	URI    string            `json:"uri,omitempty"`
+	Name   string            `json:"name,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+
+	// DigestResults maps a digest algorithm to the name of the result that contains the digest value
+	DigestResults map[string]string `json:"digestResults,omitempty"`
+}
+
+// Statement is an in-toto statement
+type Statement struct {
+	Type          string      `json:"_type"`
+	Subject       []Subject   `json:"subject"`
+	PredicateType string      `json:"predicateType"`
+	Predicate     interface{} `json:"predicate"`
+}
+
+// Subject is the artifact an in-toto statement is about
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// ResourceDescriptor describes an artifact in a SLSA provenance
+type ResourceDescriptor struct {
+	URI    string            `json:"uri,omitempty"`
+	Name   string            `json:"name,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// Provenance is the predicate of a SLSA provenance
+type Provenance struct {
+	BuildDefinition struct {
+		BuildType            string                 `json:"buildType"`
+		ExternalParameters   map[string]interface{} `json:"externalParameters"`
+		InternalParameters   map[string]interface{} `json:"internalParameters,omitempty"`
+		ResolvedDependencies []ResourceDescriptor   `json:"resolvedDependencies,omitempty"`
+	} `json:"buildDefinition"`
+	RunDetails struct {
+		Builder struct {
+			ID      string            `json:"id"`
+			Version map[string]string `json:"version,omitempty"`
+		} `json:"builder"`
+		Metadata struct {
+			InvocationID string     `json:"invocationId,omitempty"`
+			StartedOn    *time.Time `json:"startedOn,omitempty"`
+			FinishedOn   *time.Time `json:"finishedOn,omitempty"`
+		} `json:"metadata"`
+	} `json:"runDetails"`
+}
+
+// Envelope is a DSSE envelope, it has no signatures if the attestation is unsigned
+type Envelope struct {
+	PayloadType string              `json:"payloadType"`
+	Payload     string              `json:"payload"`
+	Signatures  []EnvelopeSignature `json:"signatures"`
+}
+
+// EnvelopeSignature is a signature of a DSSE envelope
+type EnvelopeSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// GenerateProvenance generates an in-toto statement with a SLSA provenance for the subject image. The digests
+// of dependencies that are determined during the build are read from the result files, which are mapped by
+// the result name.
+func GenerateProvenance(input ProvenanceInput, subject name.Digest, resultFiles map[string]string, finishedOn time.Time) (*Statement, error) {
+	algorithm, hex, found := strings.Cut(subject.DigestStr(), ":")
+	if !found {
+		return nil, fmt.Errorf("invalid digest %q of the subject", subject.DigestStr())
+	}
+
+	provenance := Provenance{}
+	provenance.BuildDefinition.BuildType = input.BuildType
+	provenance.BuildDefinition.ExternalParameters = input.ExternalParameters
+	provenance.BuildDefinition.InternalParameters = input.InternalParameters
+	provenance.RunDetails.Builder.ID = input.BuilderID
+	provenance.RunDetails.Builder.Version = input.BuilderVersion
+	provenance.RunDetails.Metadata.InvocationID = input.InvocationID
+	provenance.RunDetails.Metadata.StartedOn = input.StartedOn
+	provenance.RunDetails.Metadata.FinishedOn = &finishedOn
+
+	for _, dependency := range input.Dependencies {
+		digest := map[string]string{}
+		for algorithm, value := range dependency.Digest {
+			digest[algorithm] = value
+		}
+
+		for algorithm, resultName := range dependency.DigestResults {
+			file, ok := resultFiles[resultName]
+			if !ok {
+				return nil, fmt.Errorf("the file of the result %q is unknown", resultName)
+			}
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the result %q: %w", resultName, err)
+			}
+
+			// results of image digests contain the hash function as prefix, the prefix of a
+			// dirHash value is part of the value
+			value := strings.TrimSpace(string(data))
+			if alg, hex, found := strings.Cut(value, ":"); found && alg == algorithm {
+				value = hex
+			}
+
+			if value != "" {
+				digest[algorithm] = value
+			}
+		}
+
+		descriptor := ResourceDescriptor{URI: dependency.URI, Name: dependency.Name}
+		if len(digest) > 0 {
+			descriptor.Digest = digest
+		}
+
+		provenance.BuildDefinition.ResolvedDependencies = append(provenance.BuildDefinition.ResolvedDependencies, descriptor)
+	}
+
+	return &Statement{
+		Type: StatementType,
+		Subject: []Subject{{
+			Name:   subject.Context().Name(),
+			Digest: map[string]string{algorithm: hex},
+		}},
+		PredicateType: PredicateTypeSLSAProvenance,
+		Predicate:     provenance,
+	}, nil
+}
+
+// NewEnvelope wraps an in-toto statement in a DSSE envelope, the envelope is signed if a key is provided
+func NewEnvelope(statement *Statement, key *ecdsa.PrivateKey) (*Envelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := Envelope{
+		PayloadType: string(MediaTypeInToto),
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []EnvelopeSignature{},
+	}
+
+	if key != nil {
+		hash := sha256.Sum256(preAuthenticationEncoding(envelope.PayloadType, payload))
+		signature, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+		if err != nil {
+			return nil, err
+		}
+
+		envelope.Signatures = append(envelope.Signatures, EnvelopeSignature{Sig: base64.StdEncoding.EncodeToString(signature)})
+	}
+
+	return &envelope, nil
+}
+
+// preAuthenticationEncoding returns the data of a DSSE envelope that is signed
+func preAuthenticationEncoding(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// AttachProvenance pushes a DSSE envelope with a provenance for the subject image or image index. A signed
+// envelope is stored in the format that cosign uses for attestations, either appended to the attestation tag
+// or as referrer depending on the signature storage. An unsigned envelope is always stored as referrer.
+func AttachProvenance(subject name.Digest, envelope *Envelope, storage buildapi.SignatureStorage, options []remote.Option) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	subjectDescriptor, err := remote.Head(subject, options...)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the descriptor of %s: %w", subject.String(), err)
+	}
+
+	layer := mutate.Addendum{
+		Layer:     static.NewLayer(data, MediaTypeDSSEEnvelope),
+		MediaType: MediaTypeDSSEEnvelope,
+		Annotations: map[string]string{
+			AnnotationPredicateType: PredicateTypeSLSAProvenance,
+		},
+	}
+
+	if len(envelope.Signatures) > 0 && storage != buildapi.SignatureStorageReferrers {
+		if storage != "" && storage != buildapi.SignatureStorageTag {
+			return fmt.Errorf("unsupported signature storage %q", storage)
+		}
+
+		tag := subject.Context().Tag(fmt.Sprintf("%s-%s.att", subjectDescriptor.Digest.Algorithm, subjectDescriptor.Digest.Hex))
+		if err := appendToTag(tag, layer, options); err != nil {
+			return fmt.Errorf("failed to push the provenance: %w", err)
+		}
+
+		return nil
+	}
+
+	if _, err := pushReferrer(subject.Context(), *subjectDescriptor, MediaTypeInToto, layer, options); err != nil {
+		return fmt.Errorf("failed to push the provenance: %w", err)
+	}
+
+	return nil
+}
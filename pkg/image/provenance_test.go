@@ -0,0 +1,256 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/image"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Provenance", func() {
+	const subjectName = "registry.example.com/some-namespace/some-image"
+
+	var (
+		input   image.ProvenanceInput
+		subject name.Digest
+	)
+
+	BeforeEach(func() {
+		input = image.ProvenanceInput{
+			BuildType:    "https://shipwright.io/buildrun/v1beta1",
+			BuilderID:    "https://shipwright.io/build",
+			InvocationID: "some-uid",
+			ExternalParameters: map[string]interface{}{
+				"build": map[string]interface{}{"name": "some-build"},
+			},
+			Dependencies: []image.ProvenanceDependency{{
+				URI:           "git+https://github.com/shipwright-io/sample-go",
+				DigestResults: map[string]string{"gitCommit": "shp-source-default-commit-sha"},
+			}, {
+				URI:    "oci://moby/buildkit@sha256:0123456789abcdef",
+				Digest: map[string]string{"sha256": "0123456789abcdef"},
+			}},
+		}
+
+		var err error
+		subject, err = name.NewDigest(subjectName + "@sha256:ffb4bd8b7b4f4a4f7a6e7a4cfe3a5ddd8f1a1d2c7b0e3b5e4b8b2a6e1d3c4f5a")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("GenerateProvenance", func() {
+		It("generates a SLSA provenance with the digests from the result files", func() {
+			resultFile := filepath.Join(GinkgoT().TempDir(), "commit-sha")
+			Expect(os.WriteFile(resultFile, []byte("abc123\n"), 0600)).To(Succeed())
+
+			finishedOn := time.Unix(1234567890, 0).UTC()
+			statement, err := image.GenerateProvenance(input, subject, map[string]string{"shp-source-default-commit-sha": resultFile}, finishedOn)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(statement.Type).To(Equal(image.StatementType))
+			Expect(statement.PredicateType).To(Equal(image.PredicateTypeSLSAProvenance))
+			Expect(statement.Subject).To(Equal([]image.Subject{{
+				Name:   subjectName,
+				Digest: map[string]string{"sha256": "ffb4bd8b7b4f4a4f7a6e7a4cfe3a5ddd8f1a1d2c7b0e3b5e4b8b2a6e1d3c4f5a"},
+			}}))
+
+			provenance, ok := statement.Predicate.(image.Provenance)
+			Expect(ok).To(BeTrue())
+			Expect(provenance.BuildDefinition.BuildType).To(Equal(input.BuildType))
+			Expect(provenance.RunDetails.Builder.ID).To(Equal(input.BuilderID))
+			Expect(provenance.RunDetails.Metadata.InvocationID).To(Equal("some-uid"))
+			Expect(provenance.RunDetails.Metadata.FinishedOn).To(Equal(&finishedOn))
+			Expect(provenance.BuildDefinition.ResolvedDependencies).To(Equal([]image.ResourceDescriptor{{
+				URI:    "git+https://github.com/shipwright-io/sample-go",
+				Digest: map[string]string{"gitCommit": "abc123"},
+			}, {
+				URI:    "oci://moby/buildkit@sha256:0123456789abcdef",
+				Digest: map[string]string{"sha256": "0123456789abcdef"},
+			}}))
+		})
+
+		It("keeps the h1 prefix of a dirHash and strips the prefix of a sha256 digest", func() {
+			dir := GinkgoT().TempDir()
+			dirHashFile := filepath.Join(dir, "source-dir-hash")
+			Expect(os.WriteFile(dirHashFile, []byte("h1:QBzSLFfOeHeCAv7WdkeX1IdRLNP2bpAvXHRbUSOMtPk="), 0600)).To(Succeed())
+			digestFile := filepath.Join(dir, "image-digest")
+			Expect(os.WriteFile(digestFile, []byte("sha256:0123456789abcdef"), 0600)).To(Succeed())
+
+			input.Dependencies = []image.ProvenanceDependency{{
+				Name:          "inline",
+				DigestResults: map[string]string{"dirHash": "shp-source-default-source-dir-hash"},
+			}, {
+				URI:           "oci://ghcr.io/shipwright-io/sources",
+				DigestResults: map[string]string{"sha256": "shp-source-default-image-digest"},
+			}}
+
+			statement, err := image.GenerateProvenance(input, subject, map[string]string{
+				"shp-source-default-source-dir-hash": dirHashFile,
+				"shp-source-default-image-digest":    digestFile,
+			}, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+
+			provenance, ok := statement.Predicate.(image.Provenance)
+			Expect(ok).To(BeTrue())
+			Expect(provenance.BuildDefinition.ResolvedDependencies).To(Equal([]image.ResourceDescriptor{{
+				Name:   "inline",
+				Digest: map[string]string{"dirHash": "h1:QBzSLFfOeHeCAv7WdkeX1IdRLNP2bpAvXHRbUSOMtPk="},
+			}, {
+				URI:    "oci://ghcr.io/shipwright-io/sources",
+				Digest: map[string]string{"sha256": "0123456789abcdef"},
+			}}))
+		})
+
+		It("fails if the file of a result is unknown", func() {
+			_, err := image.GenerateProvenance(input, subject, map[string]string{}, time.Now())
+			Expect(err).To(MatchError(ContainSubstring(`the file of the result "shp-source-default-commit-sha" is unknown`)))
+		})
+	})
+
+	Context("NewEnvelope", func() {
+		var statement *image.Statement
+
+		BeforeEach(func() {
+			input.Dependencies = nil
+
+			var err error
+			statement, err = image.GenerateProvenance(input, subject, nil, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("creates an unsigned envelope without a key", func() {
+			envelope, err := image.NewEnvelope(statement, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(envelope.PayloadType).To(Equal("application/vnd.in-toto+json"))
+			Expect(envelope.Signatures).To(BeEmpty())
+
+			payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(payload)).To(ContainSubstring(`"predicateType":"https://slsa.dev/provenance/v1"`))
+		})
+
+		It("creates an envelope that is signed using the pre-authentication encoding", func() {
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+
+			envelope, err := image.NewEnvelope(statement, key)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(envelope.Signatures).To(HaveLen(1))
+
+			payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+			Expect(err).ToNot(HaveOccurred())
+
+			signature, err := base64.StdEncoding.DecodeString(envelope.Signatures[0].Sig)
+			Expect(err).ToNot(HaveOccurred())
+
+			hash := sha256.Sum256([]byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(envelope.PayloadType), envelope.PayloadType, len(payload), payload)))
+			Expect(ecdsa.VerifyASN1(&key.PublicKey, hash[:], signature)).To(BeTrue())
+		})
+	})
+
+	Context("AttachProvenance", func() {
+		var pushedSubject name.Digest
+
+		BeforeEach(func() {
+			logger := log.New(io.Discard, "", 0)
+			server := httptest.NewServer(registry.New(registry.Logger(logger), registry.WithReferrersSupport(true)))
+			DeferCleanup(func() {
+				server.Close()
+			})
+
+			imageName, err := name.ParseReference(fmt.Sprintf("%s/%s/%s", strings.ReplaceAll(server.URL, "http://", ""), "test-namespace", "test-image"))
+			Expect(err).ToNot(HaveOccurred())
+
+			img, err := random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			digest, _, err := image.PushImageOrImageIndex(imageName, img, nil, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+
+			pushedSubject = imageName.Context().Digest(digest)
+		})
+
+		// readEnvelope reads the envelope from the single layer of an attestation
+		readEnvelope := func(reference name.Reference) image.Envelope {
+			attestation, err := remote.Image(reference)
+			Expect(err).ToNot(HaveOccurred())
+
+			manifest, err := attestation.Manifest()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest.Layers).To(HaveLen(1))
+			Expect(manifest.Layers[0].MediaType).To(Equal(image.MediaTypeDSSEEnvelope))
+			Expect(manifest.Layers[0].Annotations).To(HaveKeyWithValue(image.AnnotationPredicateType, image.PredicateTypeSLSAProvenance))
+
+			layers, err := attestation.Layers()
+			Expect(err).ToNot(HaveOccurred())
+
+			reader, err := layers[0].Uncompressed()
+			Expect(err).ToNot(HaveOccurred())
+			defer reader.Close()
+
+			var envelope image.Envelope
+			Expect(json.NewDecoder(reader).Decode(&envelope)).To(Succeed())
+			return envelope
+		}
+
+		It("attaches an unsigned provenance as referrer", func() {
+			statement, err := image.GenerateProvenance(image.ProvenanceInput{}, pushedSubject, nil, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+
+			envelope, err := image.NewEnvelope(statement, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(image.AttachProvenance(pushedSubject, envelope, buildapi.SignatureStorageTag, []remote.Option{})).To(Succeed())
+
+			referrers, err := remote.Referrers(pushedSubject)
+			Expect(err).ToNot(HaveOccurred())
+
+			indexManifest, err := referrers.IndexManifest()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(indexManifest.Manifests).To(HaveLen(1))
+			Expect(indexManifest.Manifests[0].ArtifactType).To(Equal(string(image.MediaTypeInToto)))
+
+			Expect(readEnvelope(pushedSubject.Context().Digest(indexManifest.Manifests[0].Digest.String())).Payload).To(Equal(envelope.Payload))
+		})
+
+		It("attaches a signed provenance to the attestation tag", func() {
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+
+			statement, err := image.GenerateProvenance(image.ProvenanceInput{}, pushedSubject, nil, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+
+			envelope, err := image.NewEnvelope(statement, key)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(image.AttachProvenance(pushedSubject, envelope, "", []remote.Option{})).To(Succeed())
+
+			attestation := readEnvelope(pushedSubject.Context().Tag(strings.Replace(pushedSubject.DigestStr(), ":", "-", 1) + ".att"))
+			Expect(attestation.Signatures).To(Equal(envelope.Signatures))
+		})
+	})
+})
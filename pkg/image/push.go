@@ -5,19 +5,43 @@
 package image
 
 import (
+	"time"
+
 	"github.com/google/go-containerregistry/pkg/name"
 	containerreg "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 )
 
+// pushProgressInterval is the interval in which the progress of the layer uploads is logged
+const pushProgressInterval = 15 * time.Second
+
+// GetPushOptions returns the go-containerregistry options for the retries and the concurrency of a push.
+// A failed layer upload is retried up to the number of retries, the backoff before the first retry is
+// doubled for each further retry. Layers that exist in the repository already are never uploaded.
+func GetPushOptions(retries int, retryBackoff time.Duration, concurrency int) []remote.Option {
+	return []remote.Option{
+		remote.WithRetryBackoff(remote.Backoff{
+			Duration: retryBackoff,
+			Factor:   2.0,
+			Jitter:   0.1,
+			Steps:    retries + 1,
+		}),
+		remote.WithJobs(concurrency),
+	}
+}
+
 // PushImageOrImageIndex pushes and image or image index and returns the digest and size. The size is only returned for an image.
+// The uploaded bytes of each layer are logged periodically while the push is running.
 func PushImageOrImageIndex(imageName name.Reference, image containerreg.Image, imageIndex containerreg.ImageIndex, options []remote.Option) (string, int64, error) {
 	var digest string
 	var size int64
 	size = -1
 
+	progress := newPushProgress()
+	defer progress.start(pushProgressInterval)()
+
 	if image != nil {
-		if err := remote.Write(imageName, image, options...); err != nil {
+		if err := remote.Write(imageName, progress.trackImage(image), options...); err != nil {
 			return "", 0, err
 		}
 
@@ -27,19 +51,13 @@ func PushImageOrImageIndex(imageName name.Reference, image containerreg.Image, i
 		}
 		digest = hash.String()
 
-		manifest, err := image.Manifest()
-		if err != nil {
+		if size, err = getImageSize(image); err != nil {
 			return "", 0, err
 		}
-
-		size = manifest.Config.Size
-		for _, layer := range manifest.Layers {
-			size += layer.Size
-		}
 	}
 
 	if imageIndex != nil {
-		if err := remote.WriteIndex(imageName, imageIndex, options...); err != nil {
+		if err := remote.WriteIndex(imageName, progress.trackImageIndex(imageIndex), options...); err != nil {
 			return "", 0, err
 		}
 
@@ -52,3 +70,18 @@ func PushImageOrImageIndex(imageName name.Reference, image containerreg.Image, i
 
 	return digest, size, nil
 }
+
+// getImageSize returns the compressed size of an image, which is the size of its config and layers
+func getImageSize(image containerreg.Image) (int64, error) {
+	manifest, err := image.Manifest()
+	if err != nil {
+		return 0, err
+	}
+
+	size := manifest.Config.Size
+	for _, layer := range manifest.Layers {
+		size += layer.Size
+	}
+
+	return size, nil
+}
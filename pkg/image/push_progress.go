@@ -0,0 +1,242 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	containerreg "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// pushProgress tracks the uploaded bytes of each layer of a push, layers that exist in the repository already
+// are never read and therefore not logged
+type pushProgress struct {
+	mutex  sync.Mutex
+	layers map[containerreg.Hash]*layerProgress
+	order  []containerreg.Hash
+}
+
+type layerProgress struct {
+	size     int64
+	started  atomic.Bool
+	uploaded atomic.Int64
+}
+
+func newPushProgress() *pushProgress {
+	return &pushProgress{
+		layers: map[containerreg.Hash]*layerProgress{},
+	}
+}
+
+// start logs the progress of the layers that are being uploaded in the interval, and returns the function
+// that stops the logging
+func (p *pushProgress) start(interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				p.log()
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+func (p *pushProgress) log() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, digest := range p.order {
+		layer := p.layers[digest]
+		if uploaded := layer.uploaded.Load(); layer.started.Load() && uploaded < layer.size {
+			log.Printf("Uploading layer %s: %d of %d bytes\n", digest, uploaded, layer.size)
+		}
+	}
+}
+
+func (p *pushProgress) layerProgress(layer containerreg.Layer) (*layerProgress, error) {
+	digest, err := layer.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := layer.Size()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// the same layer can be in several images of an image index
+	if existing, ok := p.layers[digest]; ok {
+		return existing, nil
+	}
+
+	progress := &layerProgress{size: size}
+	p.layers[digest] = progress
+	p.order = append(p.order, digest)
+
+	return progress, nil
+}
+
+func (p *pushProgress) trackImage(image containerreg.Image) containerreg.Image {
+	return &progressImage{Image: image, progress: p}
+}
+
+func (p *pushProgress) trackImageIndex(imageIndex containerreg.ImageIndex) containerreg.ImageIndex {
+	return &progressImageIndex{inner: imageIndex, progress: p}
+}
+
+// progressImage is an image whose layers report the bytes that are read from them
+type progressImage struct {
+	containerreg.Image
+	progress *pushProgress
+}
+
+// Layers implements containerreg.Image
+func (i *progressImage) Layers() ([]containerreg.Layer, error) {
+	layers, err := i.Image.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := make([]containerreg.Layer, 0, len(layers))
+	for _, layer := range layers {
+		// layers that are mounted from another repository are not uploaded
+		if _, ok := layer.(*remote.MountableLayer); ok {
+			tracked = append(tracked, layer)
+			continue
+		}
+
+		progress, err := i.progress.layerProgress(layer)
+		if err != nil {
+			return nil, err
+		}
+
+		tracked = append(tracked, &progressLayer{Layer: layer, progress: progress})
+	}
+
+	return tracked, nil
+}
+
+// progressImageIndex is an image index whose images report the bytes that are read from their layers, it
+// cannot embed the image index because of its ImageIndex method
+type progressImageIndex struct {
+	inner    containerreg.ImageIndex
+	progress *pushProgress
+}
+
+// MediaType implements containerreg.ImageIndex
+func (i *progressImageIndex) MediaType() (types.MediaType, error) {
+	return i.inner.MediaType()
+}
+
+// Digest implements containerreg.ImageIndex
+func (i *progressImageIndex) Digest() (containerreg.Hash, error) {
+	return i.inner.Digest()
+}
+
+// Size implements containerreg.ImageIndex
+func (i *progressImageIndex) Size() (int64, error) {
+	return i.inner.Size()
+}
+
+// IndexManifest implements containerreg.ImageIndex
+func (i *progressImageIndex) IndexManifest() (*containerreg.IndexManifest, error) {
+	return i.inner.IndexManifest()
+}
+
+// RawManifest implements containerreg.ImageIndex
+func (i *progressImageIndex) RawManifest() ([]byte, error) {
+	return i.inner.RawManifest()
+}
+
+// Image implements containerreg.ImageIndex
+func (i *progressImageIndex) Image(hash containerreg.Hash) (containerreg.Image, error) {
+	image, err := i.inner.Image(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.progress.trackImage(image), nil
+}
+
+// ImageIndex implements containerreg.ImageIndex
+func (i *progressImageIndex) ImageIndex(hash containerreg.Hash) (containerreg.ImageIndex, error) {
+	imageIndex, err := i.inner.ImageIndex(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.progress.trackImageIndex(imageIndex), nil
+}
+
+// Manifests is used by remote.WriteIndex to get the children of the image index, other children than images
+// and image indexes are kept
+func (i *progressImageIndex) Manifests() ([]partial.Describable, error) {
+	children, err := partial.Manifests(i.inner)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, child := range children {
+		switch child := child.(type) {
+		case containerreg.ImageIndex:
+			children[j] = i.progress.trackImageIndex(child)
+		case containerreg.Image:
+			children[j] = i.progress.trackImage(child)
+		}
+	}
+
+	return children, nil
+}
+
+// progressLayer is a layer that counts the bytes that are read from its compressed content
+type progressLayer struct {
+	containerreg.Layer
+	progress *layerProgress
+}
+
+// Compressed implements containerreg.Layer
+func (l *progressLayer) Compressed() (io.ReadCloser, error) {
+	readCloser, err := l.Layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+
+	// a retried upload reads the layer from the start
+	l.progress.started.Store(true)
+	l.progress.uploaded.Store(0)
+
+	return &countingReadCloser{ReadCloser: readCloser, count: &l.progress.uploaded}, nil
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+	count *atomic.Int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.count.Add(int64(n))
+	return n, err
+}
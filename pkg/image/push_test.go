@@ -11,6 +11,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/registry"
@@ -85,4 +87,51 @@ var _ = Describe("PushImageOrImageIndex", func() {
 			Expect(response.StatusCode).To(Equal(200))
 		})
 	})
+
+	Context("For a registry that fails the first layer upload", func() {
+
+		var failedUploads atomic.Int32
+
+		BeforeEach(func() {
+			failedUploads.Store(0)
+
+			logger := log.New(io.Discard, "", 0)
+			reg := registry.New(registry.Logger(logger))
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPatch && strings.Contains(r.URL.Path, "/blobs/uploads/") && failedUploads.Add(1) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+
+				reg.ServeHTTP(w, r)
+			}))
+			DeferCleanup(func() {
+				server.Close()
+			})
+			registryHost = strings.ReplaceAll(server.URL, "http://", "")
+		})
+
+		It("retries the upload", func() {
+			img, err := random.Image(3245, 2)
+			Expect(err).ToNot(HaveOccurred())
+
+			imageName, err := name.ParseReference(fmt.Sprintf("%s/%s/%s", registryHost, "test-namespace", "test-image"))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, err = image.PushImageOrImageIndex(imageName, img, nil, image.GetPushOptions(2, time.Millisecond, 1))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(failedUploads.Load()).To(BeNumerically(">", 2))
+		})
+
+		It("fails without retries", func() {
+			img, err := random.Image(3245, 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			imageName, err := name.ParseReference(fmt.Sprintf("%s/%s/%s", registryHost, "test-namespace", "test-image"))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, err = image.PushImageOrImageIndex(imageName, img, nil, image.GetPushOptions(0, time.Millisecond, 1))
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
@@ -0,0 +1,52 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	containerreg "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// pushReferrer pushes an artifact with a single layer that refers to the subject image or image
+// index. The artifact type is stored as media type of the config like the OCI image specification
+// suggests for compatibility. Registries that do not support the referrers API are tracked through
+// the referrers tag schema, a tag that is named after the digest of the subject. The digest of the
+// artifact is returned.
+func pushReferrer(repository name.Repository, subject containerreg.Descriptor, artifactType types.MediaType, layer mutate.Addendum, options []remote.Option) (string, error) {
+	artifact, err := mutate.Append(
+		mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), artifactType),
+		layer,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	artifact, ok := mutate.Subject(artifact, containerreg.Descriptor{
+		MediaType: subject.MediaType,
+		Digest:    subject.Digest,
+		Size:      subject.Size,
+	}).(containerreg.Image)
+	if !ok {
+		return "", fmt.Errorf("failed to set the subject of the %s artifact", artifactType)
+	}
+
+	digest, err := artifact.Digest()
+	if err != nil {
+		return "", err
+	}
+
+	// the remote package updates the referrers tag if the registry does not support the referrers API
+	if err := remote.Write(repository.Digest(digest.String()), artifact, options...); err != nil {
+		return "", err
+	}
+
+	return digest.String(), nil
+}
@@ -0,0 +1,175 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// MirrorByDigestOnlyOption is the option of a registry mirror rule to use the mirrors only for digest references
+const MirrorByDigestOnlyOption = "mirror-by-digest-only"
+
+// RegistryMirrorRule maps the references that start with a prefix to an ordered list of mirrors, like the
+// mirrors of a registry in containers-registries.conf. The prefix is a registry host, optionally followed by a
+// repository path, and the matching part of a reference is replaced with the mirror. Like mirror-by-digest-only
+// in containers-registries.conf, MirrorByDigestOnly restricts the mirrors to digest references, because a mirror
+// can still serve the previous image of a tag.
+type RegistryMirrorRule struct {
+	Prefix             string
+	Mirrors            []string
+	MirrorByDigestOnly bool
+}
+
+// ParseRegistryMirrorRules parses registry mirror rules in the format prefix=mirror,mirror[;mirror-by-digest-only]
+func ParseRegistryMirrorRules(values []string) ([]RegistryMirrorRule, error) {
+	rules := make([]RegistryMirrorRule, 0, len(values))
+	for _, value := range values {
+		rule, option, hasOption := strings.Cut(value, ";")
+		prefix, mirrors, found := strings.Cut(rule, "=")
+		if !found || prefix == "" || mirrors == "" || (hasOption && option != MirrorByDigestOnlyOption) {
+			return nil, fmt.Errorf("the registry mirror rule %q is not in the format prefix=mirror,mirror[;%s]", value, MirrorByDigestOnlyOption)
+		}
+
+		rules = append(rules, RegistryMirrorRule{
+			Prefix:             normalizeRegistryMirrorPrefix(prefix),
+			Mirrors:            strings.Split(mirrors, ","),
+			MirrorByDigestOnly: hasOption,
+		})
+	}
+
+	return rules, nil
+}
+
+// normalizeRegistryMirrorPrefix uses the name of go-containerregistry for the registry of Docker Hub, so that
+// the prefix matches the name of the references
+func normalizeRegistryMirrorPrefix(prefix string) string {
+	registry, repository, found := strings.Cut(strings.TrimSuffix(prefix, "/"), "/")
+	if registry == "docker.io" {
+		registry = name.DefaultRegistry
+	}
+
+	if !found {
+		return registry
+	}
+
+	return registry + "/" + repository
+}
+
+// GetMirrorReferences returns the references of the mirrors of the rule with the longest prefix that matches a
+// reference, in the order of the rule. It returns no references if no rule matches, or if the rule mirrors by
+// digest only and the reference is no digest.
+func GetMirrorReferences(ref name.Reference, rules []RegistryMirrorRule) ([]name.Reference, error) {
+	fullName := ref.Name()
+
+	match := getRegistryMirrorRule(ref, rules)
+	if match == nil {
+		return nil, nil
+	}
+
+	if _, isDigest := ref.(name.Digest); !isDigest && match.MirrorByDigestOnly {
+		return nil, nil
+	}
+
+	mirrorRefs := make([]name.Reference, 0, len(match.Mirrors))
+	for _, mirror := range match.Mirrors {
+		mirrorRef, err := name.ParseReference(strings.TrimSuffix(mirror, "/") + strings.TrimPrefix(fullName, match.Prefix))
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine the reference of %s on the mirror %s: %w", ref, mirror, err)
+		}
+
+		mirrorRefs = append(mirrorRefs, mirrorRef)
+	}
+
+	return mirrorRefs, nil
+}
+
+// MirrorsByDigestOnly returns whether the rule that matches a reference uses its mirrors only for digest
+// references, so that a tag has to be resolved to its digest in its registry first to pull it from a mirror
+func MirrorsByDigestOnly(ref name.Reference, rules []RegistryMirrorRule) bool {
+	match := getRegistryMirrorRule(ref, rules)
+	return match != nil && match.MirrorByDigestOnly
+}
+
+// getRegistryMirrorRule returns the rule with the longest prefix that matches a reference, or nil
+func getRegistryMirrorRule(ref name.Reference, rules []RegistryMirrorRule) *RegistryMirrorRule {
+	fullName := ref.Name()
+
+	var match *RegistryMirrorRule
+	for i := range rules {
+		if !registryMirrorPrefixMatches(rules[i].Prefix, fullName) {
+			continue
+		}
+
+		if match == nil || len(rules[i].Prefix) > len(match.Prefix) {
+			match = &rules[i]
+		}
+	}
+
+	return match
+}
+
+// registryMirrorPrefixMatches returns whether a prefix matches the full name of a reference up to a separator,
+// a prefix without a repository path only matches the complete registry host
+func registryMirrorPrefixMatches(prefix string, fullName string) bool {
+	if !strings.HasPrefix(fullName, prefix) {
+		return false
+	}
+
+	rest := strings.TrimPrefix(fullName, prefix)
+	if rest == "" || rest[0] == '/' {
+		return true
+	}
+
+	return strings.Contains(prefix, "/") && (rest[0] == ':' || rest[0] == '@')
+}
+
+// PullWithMirrors calls pull for the mirrors of a reference in the order of the matching rule and for the
+// reference itself last, until a pull succeeds. It logs the mirror that served the content. A tag is pulled
+// from its registry if the matching rule mirrors by digest only.
+func PullWithMirrors(ref name.Reference, rules []RegistryMirrorRule, pull func(ref name.Reference) error) error {
+	mirrorRefs, err := GetMirrorReferences(ref, rules)
+	if err != nil {
+		return err
+	}
+
+	if _, isDigest := ref.(name.Digest); !isDigest && MirrorsByDigestOnly(ref, rules) {
+		log.Printf("Pulling %s from its registry, its mirrors are only used for digest references\n", ref)
+		return pull(ref)
+	}
+
+	for _, mirrorRef := range mirrorRefs {
+		if err := pull(mirrorRef); err != nil {
+			log.Printf("Failed to pull %s from the mirror %s: %v\n", ref, mirrorRef, err)
+			continue
+		}
+
+		log.Printf("Pulled %s from the mirror %s\n", ref, mirrorRef)
+		return nil
+	}
+
+	if len(mirrorRefs) > 0 {
+		log.Printf("Pulling %s from its registry, no mirror could serve it\n", ref)
+	}
+
+	return pull(ref)
+}
+
+// GetMirrorOptions constructs the options to access a registry mirror like GetOptions, the mirror is accessed
+// anonymously if the Docker config has no credentials for it
+func GetMirrorOptions(ctx context.Context, mirrorRef name.Reference, insecure bool, dockerConfigJSONPath string, registryCertificatesDirectory string, userAgent string) ([]remote.Option, error) {
+	options, _, err := GetOptions(ctx, mirrorRef, insecure, dockerConfigJSONPath, registryCertificatesDirectory, userAgent)
+	if errors.Is(err, ErrRegistryCredentialsNotFound) {
+		options, _, err = GetOptions(ctx, mirrorRef, insecure, "", registryCertificatesDirectory, userAgent)
+	}
+
+	return options, err
+}
@@ -0,0 +1,214 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	containerreg "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/shipwright-io/build/pkg/image"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry mirrors", func() {
+	mirrorNamesOf := func(imageName string, rules []image.RegistryMirrorRule) []string {
+		GinkgoHelper()
+
+		ref, err := name.ParseReference(imageName)
+		Expect(err).ToNot(HaveOccurred())
+
+		mirrorRefs, err := image.GetMirrorReferences(ref, rules)
+		Expect(err).ToNot(HaveOccurred())
+
+		var mirrorNames []string
+		for _, mirrorRef := range mirrorRefs {
+			mirrorNames = append(mirrorNames, mirrorRef.Name())
+		}
+		return mirrorNames
+	}
+
+	Context("ParseRegistryMirrorRules", func() {
+		It("parses the prefix and the ordered mirrors", func() {
+			rules, err := image.ParseRegistryMirrorRules([]string{
+				"docker.io/library=mirror-a.example.com/library,mirror-b.example.com/dockerhub/library",
+				"quay.io=mirror-a.example.com/quay;mirror-by-digest-only",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rules).To(Equal([]image.RegistryMirrorRule{
+				{Prefix: "index.docker.io/library", Mirrors: []string{"mirror-a.example.com/library", "mirror-b.example.com/dockerhub/library"}},
+				{Prefix: "quay.io", Mirrors: []string{"mirror-a.example.com/quay"}, MirrorByDigestOnly: true},
+			}))
+		})
+
+		It("fails for a rule without mirrors", func() {
+			_, err := image.ParseRegistryMirrorRules([]string{"quay.io"})
+			Expect(err).To(MatchError(ContainSubstring("is not in the format prefix=mirror,mirror[;mirror-by-digest-only]")))
+		})
+
+		It("fails for a rule with an unknown option", func() {
+			_, err := image.ParseRegistryMirrorRules([]string{"quay.io=mirror-a.example.com/quay;insecure"})
+			Expect(err).To(MatchError(ContainSubstring("is not in the format prefix=mirror,mirror[;mirror-by-digest-only]")))
+		})
+	})
+
+	Context("GetMirrorReferences", func() {
+		var rules []image.RegistryMirrorRule
+
+		BeforeEach(func() {
+			var err error
+			rules, err = image.ParseRegistryMirrorRules([]string{
+				"docker.io=mirror.example.com/dockerhub",
+				"docker.io/library=mirror-a.example.com/library,mirror-b.example.com/library",
+				"registry.example.com/team/app=mirror.example.com/app",
+				"quay.io=mirror.example.com/quay;mirror-by-digest-only",
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("uses the rule with the longest matching prefix", func() {
+			Expect(mirrorNamesOf("busybox:1.36", rules)).To(Equal([]string{
+				"mirror-a.example.com/library/busybox:1.36",
+				"mirror-b.example.com/library/busybox:1.36",
+			}))
+			Expect(mirrorNamesOf("docker.io/bitnami/redis", rules)).To(Equal([]string{
+				"mirror.example.com/dockerhub/bitnami/redis:latest",
+			}))
+		})
+
+		It("matches a repository prefix up to a tag or digest", func() {
+			Expect(mirrorNamesOf("registry.example.com/team/app:v1", rules)).To(Equal([]string{
+				"mirror.example.com/app:v1",
+			}))
+			Expect(mirrorNamesOf("registry.example.com/team/app@sha256:8d7bd2e29e1b1d1a8e8b2f6e7c1ad2b6b37cb87c86d0e83e8b1e7d6b6f2c5d4e", rules)).To(Equal([]string{
+				"mirror.example.com/app@sha256:8d7bd2e29e1b1d1a8e8b2f6e7c1ad2b6b37cb87c86d0e83e8b1e7d6b6f2c5d4e",
+			}))
+		})
+
+		It("uses the mirrors of a rule that mirrors by digest only for digests but not for tags", func() {
+			Expect(mirrorNamesOf("quay.io/team/app:v1", rules)).To(BeEmpty())
+			Expect(mirrorNamesOf("quay.io/team/app@sha256:8d7bd2e29e1b1d1a8e8b2f6e7c1ad2b6b37cb87c86d0e83e8b1e7d6b6f2c5d4e", rules)).To(Equal([]string{
+				"mirror.example.com/quay/team/app@sha256:8d7bd2e29e1b1d1a8e8b2f6e7c1ad2b6b37cb87c86d0e83e8b1e7d6b6f2c5d4e",
+			}))
+		})
+
+		It("does not match a prefix that ends within a path component", func() {
+			Expect(mirrorNamesOf("registry.example.com/team/application:v1", rules)).To(BeEmpty())
+			Expect(mirrorNamesOf("docker.io.example.com/image:v1", rules)).To(BeEmpty())
+		})
+	})
+
+	Context("PullWithMirrors", func() {
+		var (
+			imageRef    name.Reference
+			imageDigest containerreg.Hash
+			hostOf      = func(server *httptest.Server) string { return strings.TrimPrefix(server.URL, "http://") }
+			newRegistry = func() *httptest.Server {
+				server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+				DeferCleanup(server.Close)
+				return server
+			}
+			pullImage = func(pulled *[]string) func(ref name.Reference) error {
+				return func(ref name.Reference) error {
+					img, _, err := image.LoadImageOrImageIndexFromRegistry(ref, nil)
+					if err != nil {
+						return err
+					}
+
+					digest, err := img.Digest()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(digest).To(Equal(imageDigest))
+
+					*pulled = append(*pulled, ref.Context().RegistryStr())
+					return nil
+				}
+			}
+		)
+
+		BeforeEach(func() {
+			origin := newRegistry()
+
+			var err error
+			imageRef, err = name.ParseReference(hostOf(origin) + "/namespace/image:v1")
+			Expect(err).ToNot(HaveOccurred())
+
+			img, err := random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(remote.Write(imageRef, img)).To(Succeed())
+
+			imageDigest, err = img.Digest()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("pulls from the first mirror that has the image", func() {
+			emptyMirror, mirror := newRegistry(), newRegistry()
+
+			mirrorRef, err := name.ParseReference(hostOf(mirror) + "/cache/namespace/image:v1")
+			Expect(err).ToNot(HaveOccurred())
+			img, _, err := image.LoadImageOrImageIndexFromRegistry(imageRef, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(remote.Write(mirrorRef, img)).To(Succeed())
+
+			rules, err := image.ParseRegistryMirrorRules([]string{imageRef.Context().RegistryStr() + "=" + hostOf(emptyMirror) + "/cache," + hostOf(mirror) + "/cache"})
+			Expect(err).ToNot(HaveOccurred())
+
+			var pulled []string
+			Expect(image.PullWithMirrors(imageRef.Context().Digest(imageDigest.String()), rules, pullImage(&pulled))).To(Succeed())
+			Expect(pulled).To(Equal([]string{hostOf(mirror)}))
+		})
+
+		It("falls back to the registry of the reference if no mirror has the image", func() {
+			rules, err := image.ParseRegistryMirrorRules([]string{imageRef.Context().RegistryStr() + "=" + hostOf(newRegistry())})
+			Expect(err).ToNot(HaveOccurred())
+
+			var pulled []string
+			Expect(image.PullWithMirrors(imageRef.Context().Digest(imageDigest.String()), rules, pullImage(&pulled))).To(Succeed())
+			Expect(pulled).To(Equal([]string{imageRef.Context().RegistryStr()}))
+		})
+
+		It("pulls a tag from a mirror", func() {
+			mirror := newRegistry()
+
+			mirrorRef, err := name.ParseReference(hostOf(mirror) + "/namespace/image:v1")
+			Expect(err).ToNot(HaveOccurred())
+			img, _, err := image.LoadImageOrImageIndexFromRegistry(imageRef, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(remote.Write(mirrorRef, img)).To(Succeed())
+
+			rules, err := image.ParseRegistryMirrorRules([]string{imageRef.Context().RegistryStr() + "=" + hostOf(mirror)})
+			Expect(err).ToNot(HaveOccurred())
+
+			var pulled []string
+			Expect(image.PullWithMirrors(imageRef, rules, pullImage(&pulled))).To(Succeed())
+			Expect(pulled).To(Equal([]string{hostOf(mirror)}))
+		})
+
+		It("pulls a tag from the registry of the reference if the mirrors are only used for digests", func() {
+			mirror := newRegistry()
+
+			// the mirror still serves a previous image for the tag
+			mirrorRef, err := name.ParseReference(hostOf(mirror) + "/namespace/image:v1")
+			Expect(err).ToNot(HaveOccurred())
+			staleImage, err := random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(remote.Write(mirrorRef, staleImage)).To(Succeed())
+
+			rules, err := image.ParseRegistryMirrorRules([]string{imageRef.Context().RegistryStr() + "=" + hostOf(mirror) + ";mirror-by-digest-only"})
+			Expect(err).ToNot(HaveOccurred())
+
+			var pulled []string
+			Expect(image.PullWithMirrors(imageRef, rules, pullImage(&pulled))).To(Succeed())
+			Expect(pulled).To(Equal([]string{imageRef.Context().RegistryStr()}))
+		})
+	})
+})
@@ -0,0 +1,62 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+)
+
+// SerializeSuppressedVulnerabilities serializes the suppressed vulnerabilities like the vulnerabilities, with
+// the justification as additional field. Entries are only added while the serialized value stays within the
+// maximum size, the second return value tells whether entries were left out.
+func SerializeSuppressedVulnerabilities(vulnerabilities []buildapi.SuppressedVulnerability, maxSize int) ([]byte, bool) {
+	var output strings.Builder
+	for i, vuln := range vulnerabilities {
+		// the entries are separated by a comma, so that the justification must not contain one
+		entry := fmt.Sprintf("%s:%c:%s", vuln.ID, vuln.Severity[0], strings.ReplaceAll(vuln.Justification, ",", ";"))
+		if i > 0 {
+			entry = "," + entry
+		}
+
+		if output.Len()+len(entry) > maxSize {
+			return []byte(output.String()), true
+		}
+
+		output.WriteString(entry)
+	}
+
+	return []byte(output.String()), false
+}
+
+// SerializeLicenseViolations serializes the license violations as JSON array. Entries are only added while
+// the serialized value stays within the maximum size, the second return value tells whether entries were
+// left out.
+func SerializeLicenseViolations(violations []buildapi.LicenseViolation, maxSize int) ([]byte, bool, error) {
+	// an empty array takes two bytes for the brackets
+	size := 2
+	for i, violation := range violations {
+		entry, err := json.Marshal(violation)
+		if err != nil {
+			return nil, false, err
+		}
+
+		size += len(entry)
+		if i > 0 {
+			size++
+		}
+
+		if size > maxSize {
+			data, err := json.Marshal(violations[:i])
+			return data, true, err
+		}
+	}
+
+	data, err := json.Marshal(violations)
+	return data, false, err
+}
@@ -0,0 +1,104 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"fmt"
+	"strings"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/image"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Results", func() {
+	Context("SerializeSuppressedVulnerabilities", func() {
+		It("serializes all vulnerabilities that fit into the maximum size", func() {
+			data, truncated := image.SerializeSuppressedVulnerabilities([]buildapi.SuppressedVulnerability{
+				{ID: "CVE-2024-0001", Severity: buildapi.High, Justification: "vulnerable_code_not_in_execute_path"},
+				{ID: "CVE-2024-0002", Severity: buildapi.Low, Justification: "component_not_present"},
+			}, 1024)
+
+			Expect(truncated).To(BeFalse())
+			Expect(string(data)).To(Equal("CVE-2024-0001:h:vulnerable_code_not_in_execute_path,CVE-2024-0002:l:component_not_present"))
+		})
+
+		It("replaces commas in the justification", func() {
+			data, truncated := image.SerializeSuppressedVulnerabilities([]buildapi.SuppressedVulnerability{
+				{ID: "CVE-2024-0001", Severity: buildapi.Medium, Justification: "only used in tests, never shipped"},
+			}, 1024)
+
+			Expect(truncated).To(BeFalse())
+			Expect(string(data)).To(Equal("CVE-2024-0001:m:only used in tests; never shipped"))
+		})
+
+		It("truncates vulnerabilities with long justifications at the maximum size", func() {
+			var vulnerabilities []buildapi.SuppressedVulnerability
+			for i := 0; i < 50; i++ {
+				vulnerabilities = append(vulnerabilities, buildapi.SuppressedVulnerability{
+					ID:            fmt.Sprintf("CVE-2024-%04d", i),
+					Severity:      buildapi.Critical,
+					Justification: strings.Repeat("the vulnerable function is never called by the application ", 3),
+				})
+			}
+
+			data, truncated := image.SerializeSuppressedVulnerabilities(vulnerabilities, 512)
+
+			Expect(truncated).To(BeTrue())
+			Expect(len(data)).To(BeNumerically("<=", 512))
+			Expect(strings.Split(string(data), ",")).To(HaveLen(2))
+			Expect(string(data)).To(HavePrefix("CVE-2024-0000:c:the vulnerable function"))
+		})
+
+		It("returns an empty value if the first entry exceeds the maximum size", func() {
+			data, truncated := image.SerializeSuppressedVulnerabilities([]buildapi.SuppressedVulnerability{
+				{ID: "CVE-2024-0001", Severity: buildapi.High, Justification: strings.Repeat("x", 600)},
+			}, 512)
+
+			Expect(truncated).To(BeTrue())
+			Expect(data).To(BeEmpty())
+		})
+	})
+
+	Context("SerializeLicenseViolations", func() {
+		It("serializes all violations that fit into the maximum size", func() {
+			data, truncated, err := image.SerializeLicenseViolations([]buildapi.LicenseViolation{
+				{Package: "bash", License: "GPL-3.0-only"},
+			}, 512)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(truncated).To(BeFalse())
+			Expect(string(data)).To(Equal(`[{"package":"bash","license":"GPL-3.0-only"}]`))
+		})
+
+		It("serializes no violations as null like the JSON encoding of a nil slice", func() {
+			data, truncated, err := image.SerializeLicenseViolations(nil, 512)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(truncated).To(BeFalse())
+			Expect(string(data)).To(Equal("null"))
+		})
+
+		It("truncates the violations at the maximum size", func() {
+			var violations []buildapi.LicenseViolation
+			for i := 0; i < 50; i++ {
+				violations = append(violations, buildapi.LicenseViolation{
+					Package: fmt.Sprintf("package-with-a-rather-long-name-%02d", i),
+					License: "GPL-3.0-only OR LGPL-3.0-or-later WITH Classpath-exception-2.0",
+				})
+			}
+
+			data, truncated, err := image.SerializeLicenseViolations(violations, 512)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(truncated).To(BeTrue())
+			Expect(len(data)).To(BeNumerically("<=", 512))
+			Expect(string(data)).To(HavePrefix(`[{"package":"package-with-a-rather-long-name-00"`))
+			Expect(string(data)).To(HaveSuffix("}]"))
+		})
+	})
+})
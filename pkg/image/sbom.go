@@ -0,0 +1,92 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+)
+
+const (
+	// MediaTypeSPDXJSON is the media type of an SPDX document using JSON encoding
+	MediaTypeSPDXJSON types.MediaType = "application/spdx+json"
+
+	// MediaTypeCycloneDXJSON is the media type of a CycloneDX document using JSON encoding
+	MediaTypeCycloneDXJSON types.MediaType = "application/vnd.cyclonedx+json"
+)
+
+// GetSBOMMediaType returns the media type of a software bill of materials in the given format
+func GetSBOMMediaType(format buildapi.SBOMFormat) (types.MediaType, error) {
+	switch format {
+	case buildapi.SBOMFormatSPDXJSON:
+		return MediaTypeSPDXJSON, nil
+	case buildapi.SBOMFormatCycloneDX:
+		return MediaTypeCycloneDXJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported SBOM format %q, must be %s or %s", format, buildapi.SBOMFormatSPDXJSON, buildapi.SBOMFormatCycloneDX)
+	}
+}
+
+// GenerateSBOM runs trivy to generate a software bill of materials for an image in a registry
+func GenerateSBOM(ctx context.Context, imageName name.Reference, format buildapi.SBOMFormat, auth *authn.AuthConfig, insecure bool) ([]byte, error) {
+	if _, err := GetSBOMMediaType(format); err != nil {
+		return nil, err
+	}
+
+	trivyArgs := []string{"image", "--quiet", "--format", string(format), imageName.String()}
+	trivyArgs = append(trivyArgs, getAuthStringForTrivyScan(auth)...)
+	if insecure {
+		trivyArgs = append(trivyArgs, "--insecure")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "trivy", trivyArgs...)
+	cmd.Stdin = nil
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		log.Printf("failed to run trivy:\n%s", stderr.String())
+		return nil, fmt.Errorf("failed to generate the SBOM: %w", err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// AttachSBOM pushes a software bill of materials as an artifact that refers to the subject image
+// or image index. The digest of the artifact is returned.
+func AttachSBOM(subject name.Digest, sbom []byte, format buildapi.SBOMFormat, options []remote.Option) (string, error) {
+	mediaType, err := GetSBOMMediaType(format)
+	if err != nil {
+		return "", err
+	}
+
+	subjectDescriptor, err := remote.Head(subject, options...)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve the descriptor of %s: %w", subject.String(), err)
+	}
+
+	digest, err := pushReferrer(subject.Context(), *subjectDescriptor, mediaType, mutate.Addendum{
+		Layer:     static.NewLayer(sbom, mediaType),
+		MediaType: mediaType,
+	}, options)
+	if err != nil {
+		return "", fmt.Errorf("failed to push the SBOM: %w", err)
+	}
+
+	return digest, nil
+}
@@ -0,0 +1,109 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/image"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AttachSBOM", func() {
+	const sbom = `{"spdxVersion":"SPDX-2.3"}`
+
+	var (
+		registryHost string
+		subject      name.Digest
+	)
+
+	startRegistry := func(referrersSupport bool) {
+		logger := log.New(io.Discard, "", 0)
+		reg := registry.New(registry.Logger(logger), registry.WithReferrersSupport(referrersSupport))
+		server := httptest.NewServer(reg)
+		DeferCleanup(func() {
+			server.Close()
+		})
+		registryHost = strings.ReplaceAll(server.URL, "http://", "")
+
+		imageName, err := name.ParseReference(fmt.Sprintf("%s/%s/%s", registryHost, "test-namespace", "test-image"))
+		Expect(err).ToNot(HaveOccurred())
+
+		img, err := random.Image(1024, 1)
+		Expect(err).ToNot(HaveOccurred())
+
+		digest, _, err := image.PushImageOrImageIndex(imageName, img, nil, []remote.Option{})
+		Expect(err).ToNot(HaveOccurred())
+
+		subject = imageName.Context().Digest(digest)
+	}
+
+	verifyReferrer := func(sbomDigest string, artifactType string) {
+		referrers, err := remote.Referrers(subject)
+		Expect(err).ToNot(HaveOccurred())
+
+		manifest, err := referrers.IndexManifest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest.Manifests).To(HaveLen(1))
+		Expect(manifest.Manifests[0].Digest.String()).To(Equal(sbomDigest))
+		Expect(manifest.Manifests[0].ArtifactType).To(Equal(artifactType))
+
+		artifact, err := remote.Image(subject.Context().Digest(sbomDigest))
+		Expect(err).ToNot(HaveOccurred())
+
+		layers, err := artifact.Layers()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(layers).To(HaveLen(1))
+
+		reader, err := layers[0].Uncompressed()
+		Expect(err).ToNot(HaveOccurred())
+		defer reader.Close()
+
+		content, err := io.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal(sbom))
+	}
+
+	It("attaches the SBOM using the referrers API", func() {
+		startRegistry(true)
+
+		sbomDigest, err := image.AttachSBOM(subject, []byte(sbom), buildapi.SBOMFormatSPDXJSON, []remote.Option{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sbomDigest).To(HavePrefix("sha256:"))
+
+		verifyReferrer(sbomDigest, "application/spdx+json")
+	})
+
+	It("attaches the SBOM using the referrers tag schema for registries without referrers API", func() {
+		startRegistry(false)
+
+		sbomDigest, err := image.AttachSBOM(subject, []byte(sbom), buildapi.SBOMFormatCycloneDX, []remote.Option{})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = remote.Head(subject.Context().Tag(strings.ReplaceAll(subject.DigestStr(), ":", "-")))
+		Expect(err).ToNot(HaveOccurred())
+
+		verifyReferrer(sbomDigest, "application/vnd.cyclonedx+json")
+	})
+
+	It("fails for an unsupported format", func() {
+		startRegistry(true)
+
+		_, err := image.AttachSBOM(subject, []byte(sbom), buildapi.SBOMFormat("syft-json"), []remote.Option{})
+		Expect(err).To(MatchError(ContainSubstring("unsupported SBOM format")))
+	})
+})
@@ -0,0 +1,307 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	containerreg "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+)
+
+const (
+	// SigningKeyFileName is the name of the file, respectively secret key, that contains the private key
+	SigningKeyFileName = "cosign.key"
+
+	// SigningPasswordFileName is the name of the file, respectively secret key, that contains the
+	// password to decrypt the private key
+	SigningPasswordFileName = "cosign.password"
+
+	// MediaTypeSimpleSigning is the media type of the payload that is signed
+	MediaTypeSimpleSigning types.MediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+
+	// MediaTypeSignatureArtifact is the artifact type of a signature that is stored as referrer
+	MediaTypeSignatureArtifact types.MediaType = "application/vnd.dev.cosign.artifact.sig.v1+json"
+
+	// AnnotationSignature is the annotation of the payload layer that contains the signature
+	AnnotationSignature = "dev.cosignproject.cosign/signature"
+
+	// pemTypeEncryptedKey and pemTypeEncryptedCosignKey are the PEM types of keys that
+	// are generated by cosign, they are encrypted using scrypt and nacl/secretbox
+	pemTypeEncryptedKey       = "ENCRYPTED SIGSTORE PRIVATE KEY"
+	pemTypeEncryptedCosignKey = "ENCRYPTED COSIGN PRIVATE KEY"
+)
+
+// encryptedKey is the JSON structure of an encrypted private key that is generated by cosign
+type encryptedKey struct {
+	KDF struct {
+		Name   string `json:"name"`
+		Params struct {
+			N int `json:"N"`
+			R int `json:"r"`
+			P int `json:"p"`
+		} `json:"params"`
+		Salt []byte `json:"salt"`
+	} `json:"kdf"`
+	Cipher struct {
+		Name  string `json:"name"`
+		Nonce []byte `json:"nonce"`
+	} `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// simpleSigningPayload is the payload that is signed for an image, it follows the
+// format that cosign uses
+type simpleSigningPayload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]string `json:"optional"`
+}
+
+// LoadSigningKey loads an ECDSA private key from a directory, i.e. a mounted secret. The key can be
+// encrypted in the format that cosign generates, or an unencrypted PEM-encoded EC or PKCS #8 key.
+func LoadSigningKey(directory string) (*ecdsa.PrivateKey, error) {
+	keyData, err := os.ReadFile(filepath.Join(directory, SigningKeyFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the signing key: %w", err)
+	}
+
+	var password []byte
+	if data, err := os.ReadFile(filepath.Join(directory, SigningPasswordFileName)); err == nil {
+		password = []byte(strings.TrimSuffix(string(data), "\n"))
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read the password of the signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(keyData)
+	if block == nil {
+		return nil, fmt.Errorf("the signing key is not PEM-encoded")
+	}
+
+	var key crypto.PrivateKey
+	switch block.Type {
+	case pemTypeEncryptedKey, pemTypeEncryptedCosignKey:
+		der, err := decryptKey(block.Bytes, password)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err = x509.ParsePKCS8PrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the decrypted signing key: %w", err)
+		}
+
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the signing key: %w", err)
+		}
+
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the signing key: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported PEM type %q of the signing key", block.Type)
+	}
+
+	ecdsaKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the signing key is not an ECDSA key")
+	}
+
+	return ecdsaKey, nil
+}
+
+func decryptKey(data []byte, password []byte) ([]byte, error) {
+	var encrypted encryptedKey
+	if err := json.Unmarshal(data, &encrypted); err != nil {
+		return nil, fmt.Errorf("failed to parse the encrypted signing key: %w", err)
+	}
+
+	if encrypted.KDF.Name != "scrypt" || encrypted.Cipher.Name != "nacl/secretbox" {
+		return nil, fmt.Errorf("unsupported encryption %s with %s of the signing key", encrypted.Cipher.Name, encrypted.KDF.Name)
+	}
+
+	if len(encrypted.Cipher.Nonce) != 24 {
+		return nil, fmt.Errorf("invalid nonce length %d of the signing key", len(encrypted.Cipher.Nonce))
+	}
+
+	secretKey, err := scrypt.Key(password, encrypted.KDF.Salt, encrypted.KDF.Params.N, encrypted.KDF.Params.R, encrypted.KDF.Params.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the key to decrypt the signing key: %w", err)
+	}
+
+	var nonce [24]byte
+	copy(nonce[:], encrypted.Cipher.Nonce)
+
+	var secretKeyArray [32]byte
+	copy(secretKeyArray[:], secretKey)
+
+	decrypted, ok := secretbox.Open(nil, encrypted.Ciphertext, &nonce, &secretKeyArray)
+	if !ok {
+		return nil, fmt.Errorf("failed to decrypt the signing key, the password is wrong")
+	}
+
+	return decrypted, nil
+}
+
+// SignImageOrImageIndex signs a pushed image or image index. For an image index, each image of the index
+// is signed as well. The signatures are stored in the format that cosign uses. The digests of the signed
+// manifests are returned.
+func SignImageOrImageIndex(imageName name.Reference, image containerreg.Image, imageIndex containerreg.ImageIndex, key *ecdsa.PrivateKey, storage buildapi.SignatureStorage, options []remote.Option) ([]string, error) {
+	var subjects []containerreg.Descriptor
+
+	if image != nil {
+		descriptor, err := getDescriptor(image)
+		if err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, *descriptor)
+	}
+
+	if imageIndex != nil {
+		descriptor, err := getDescriptor(imageIndex)
+		if err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, *descriptor)
+
+		indexManifest, err := imageIndex.IndexManifest()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, manifest := range indexManifest.Manifests {
+			if manifest.MediaType.IsImage() {
+				subjects = append(subjects, manifest)
+			}
+		}
+	}
+
+	var signed []string
+	for _, subject := range subjects {
+		if err := sign(imageName.Context(), subject, key, storage, options); err != nil {
+			return nil, fmt.Errorf("failed to sign %s: %w", subject.Digest.String(), err)
+		}
+
+		signed = append(signed, subject.Digest.String())
+	}
+
+	return signed, nil
+}
+
+func sign(repository name.Repository, subject containerreg.Descriptor, key *ecdsa.PrivateKey, storage buildapi.SignatureStorage, options []remote.Option) error {
+	payload := simpleSigningPayload{}
+	payload.Critical.Identity.DockerReference = repository.Name()
+	payload.Critical.Image.DockerManifestDigest = subject.Digest.String()
+	payload.Critical.Type = "cosign container image signature"
+
+	payloadData, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(payloadData)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		return err
+	}
+
+	layer := mutate.Addendum{
+		Layer:     static.NewLayer(payloadData, MediaTypeSimpleSigning),
+		MediaType: MediaTypeSimpleSigning,
+		Annotations: map[string]string{
+			AnnotationSignature: base64.StdEncoding.EncodeToString(signature),
+		},
+	}
+
+	switch storage {
+	case buildapi.SignatureStorageReferrers:
+		_, err := pushReferrer(repository, subject, MediaTypeSignatureArtifact, layer, options)
+		return err
+
+	case "", buildapi.SignatureStorageTag:
+		return appendToTag(repository.Tag(fmt.Sprintf("%s-%s.sig", subject.Digest.Algorithm, subject.Digest.Hex)), layer, options)
+
+	default:
+		return fmt.Errorf("unsupported signature storage %q", storage)
+	}
+}
+
+// appendToTag appends a layer to the artifact with the given tag, or pushes a new artifact if the tag
+// does not exist, existing layers are retained like cosign does for signatures and attestations
+func appendToTag(tag name.Tag, layer mutate.Addendum, options []remote.Option) error {
+	artifact, err := remote.Image(tag, options...)
+	if err != nil {
+		var transportErr *transport.Error
+		if !errors.As(err, &transportErr) || transportErr.StatusCode != 404 {
+			return err
+		}
+
+		artifact = mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), types.OCIConfigJSON)
+	}
+
+	artifact, err = mutate.Append(artifact, layer)
+	if err != nil {
+		return err
+	}
+
+	return remote.Write(tag, artifact, options...)
+}
+
+func getDescriptor(manifest interface {
+	MediaType() (types.MediaType, error)
+	Digest() (containerreg.Hash, error)
+	Size() (int64, error)
+}) (*containerreg.Descriptor, error) {
+	mediaType, err := manifest.MediaType()
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := manifest.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := manifest.Size()
+	if err != nil {
+		return nil, err
+	}
+
+	return &containerreg.Descriptor{MediaType: mediaType, Digest: digest, Size: size}, nil
+}
@@ -0,0 +1,270 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	containerreg "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/image"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Signing", func() {
+	var (
+		key       *ecdsa.PrivateKey
+		directory string
+	)
+
+	BeforeEach(func() {
+		var err error
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+
+		directory, err = os.MkdirTemp("", "signing-key")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(func() {
+			Expect(os.RemoveAll(directory)).To(Succeed())
+		})
+	})
+
+	writeKey := func(pemType string, data []byte) {
+		Expect(os.WriteFile(filepath.Join(directory, image.SigningKeyFileName), pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: data}), 0600)).To(Succeed())
+	}
+
+	writePassword := func(password string) {
+		Expect(os.WriteFile(filepath.Join(directory, image.SigningPasswordFileName), []byte(password), 0600)).To(Succeed())
+	}
+
+	// encrypt encrypts a key the same way as cosign, but with a lower cost to keep the test fast
+	encrypt := func(der []byte, password string) []byte {
+		salt := make([]byte, 32)
+		_, err := rand.Read(salt)
+		Expect(err).ToNot(HaveOccurred())
+
+		var nonce [24]byte
+		_, err = rand.Read(nonce[:])
+		Expect(err).ToNot(HaveOccurred())
+
+		secretKey, err := scrypt.Key([]byte(password), salt, 1024, 8, 1, 32)
+		Expect(err).ToNot(HaveOccurred())
+
+		var secretKeyArray [32]byte
+		copy(secretKeyArray[:], secretKey)
+
+		data, err := json.Marshal(map[string]interface{}{
+			"kdf": map[string]interface{}{
+				"name":   "scrypt",
+				"params": map[string]int{"N": 1024, "r": 8, "p": 1},
+				"salt":   salt,
+			},
+			"cipher": map[string]interface{}{
+				"name":  "nacl/secretbox",
+				"nonce": nonce[:],
+			},
+			"ciphertext": secretbox.Seal(nil, der, &nonce, &secretKeyArray),
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		return data
+	}
+
+	Context("LoadSigningKey", func() {
+		It("loads an encrypted key that is generated by cosign", func() {
+			der, err := x509.MarshalPKCS8PrivateKey(key)
+			Expect(err).ToNot(HaveOccurred())
+
+			writeKey("ENCRYPTED SIGSTORE PRIVATE KEY", encrypt(der, "secret"))
+			writePassword("secret\n")
+
+			loadedKey, err := image.LoadSigningKey(directory)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadedKey.Equal(key)).To(BeTrue())
+		})
+
+		It("fails to load an encrypted key with a wrong password", func() {
+			der, err := x509.MarshalPKCS8PrivateKey(key)
+			Expect(err).ToNot(HaveOccurred())
+
+			writeKey("ENCRYPTED COSIGN PRIVATE KEY", encrypt(der, "secret"))
+			writePassword("wrong")
+
+			_, err = image.LoadSigningKey(directory)
+			Expect(err).To(MatchError(ContainSubstring("the password is wrong")))
+		})
+
+		It("loads an unencrypted EC key without password", func() {
+			der, err := x509.MarshalECPrivateKey(key)
+			Expect(err).ToNot(HaveOccurred())
+
+			writeKey("EC PRIVATE KEY", der)
+
+			loadedKey, err := image.LoadSigningKey(directory)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadedKey.Equal(key)).To(BeTrue())
+		})
+
+		It("fails to load a key that is no ECDSA key", func() {
+			_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+
+			der, err := x509.MarshalPKCS8PrivateKey(ed25519Key)
+			Expect(err).ToNot(HaveOccurred())
+
+			writeKey("PRIVATE KEY", der)
+
+			_, err = image.LoadSigningKey(directory)
+			Expect(err).To(MatchError("the signing key is not an ECDSA key"))
+		})
+
+		It("fails if the key is missing", func() {
+			_, err := image.LoadSigningKey(directory)
+			Expect(err).To(MatchError(ContainSubstring("failed to read the signing key")))
+		})
+	})
+
+	Context("SignImageOrImageIndex", func() {
+		var (
+			registryHost string
+			imageName    name.Reference
+		)
+
+		BeforeEach(func() {
+			logger := log.New(io.Discard, "", 0)
+			server := httptest.NewServer(registry.New(registry.Logger(logger), registry.WithReferrersSupport(true)))
+			DeferCleanup(func() {
+				server.Close()
+			})
+			registryHost = strings.ReplaceAll(server.URL, "http://", "")
+
+			var err error
+			imageName, err = name.ParseReference(fmt.Sprintf("%s/%s/%s", registryHost, "test-namespace", "test-image"))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		// verifySignatureLayer checks that a signature layer contains a valid signature for the digest
+		verifySignatureLayer := func(manifest *containerreg.Manifest, layer containerreg.Layer, digest string) {
+			Expect(manifest.Layers).ToNot(BeEmpty())
+			signature, err := base64.StdEncoding.DecodeString(manifest.Layers[len(manifest.Layers)-1].Annotations[image.AnnotationSignature])
+			Expect(err).ToNot(HaveOccurred())
+
+			reader, err := layer.Uncompressed()
+			Expect(err).ToNot(HaveOccurred())
+			defer reader.Close()
+
+			payload, err := io.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(payload)).To(ContainSubstring(fmt.Sprintf(`"docker-manifest-digest":%q`, digest)))
+			Expect(string(payload)).To(ContainSubstring(fmt.Sprintf(`"docker-reference":%q`, imageName.Context().Name())))
+
+			hash := sha256.Sum256(payload)
+			Expect(ecdsa.VerifyASN1(&key.PublicKey, hash[:], signature)).To(BeTrue())
+		}
+
+		verifyTagSignature := func(digest string, expectedSignatures int) {
+			signatures, err := remote.Image(imageName.Context().Tag(strings.Replace(digest, ":", "-", 1) + ".sig"))
+			Expect(err).ToNot(HaveOccurred())
+
+			manifest, err := signatures.Manifest()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest.Layers).To(HaveLen(expectedSignatures))
+			Expect(manifest.Layers[0].MediaType).To(BeEquivalentTo(image.MediaTypeSimpleSigning))
+
+			layers, err := signatures.Layers()
+			Expect(err).ToNot(HaveOccurred())
+
+			verifySignatureLayer(manifest, layers[len(layers)-1], digest)
+		}
+
+		It("signs an image using the tag schema and retains existing signatures", func() {
+			img, err := random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			digest, _, err := image.PushImageOrImageIndex(imageName, img, nil, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+
+			signed, err := image.SignImageOrImageIndex(imageName, img, nil, key, buildapi.SignatureStorageTag, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(signed).To(Equal([]string{digest}))
+			verifyTagSignature(digest, 1)
+
+			_, err = image.SignImageOrImageIndex(imageName, img, nil, key, "", []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+			verifyTagSignature(digest, 2)
+		})
+
+		It("signs an image index and each image of it", func() {
+			imageIndex, err := random.Index(1024, 1, 2)
+			Expect(err).ToNot(HaveOccurred())
+
+			digest, _, err := image.PushImageOrImageIndex(imageName, nil, imageIndex, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+
+			signed, err := image.SignImageOrImageIndex(imageName, nil, imageIndex, key, buildapi.SignatureStorageTag, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(signed).To(HaveLen(3))
+			Expect(signed[0]).To(Equal(digest))
+
+			for _, signedDigest := range signed {
+				verifyTagSignature(signedDigest, 1)
+			}
+		})
+
+		It("signs an image using the referrers API", func() {
+			img, err := random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			digest, _, err := image.PushImageOrImageIndex(imageName, img, nil, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = image.SignImageOrImageIndex(imageName, img, nil, key, buildapi.SignatureStorageReferrers, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+
+			referrers, err := remote.Referrers(imageName.Context().Digest(digest))
+			Expect(err).ToNot(HaveOccurred())
+
+			indexManifest, err := referrers.IndexManifest()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(indexManifest.Manifests).To(HaveLen(1))
+			Expect(indexManifest.Manifests[0].ArtifactType).To(Equal(string(image.MediaTypeSignatureArtifact)))
+
+			signature, err := remote.Image(imageName.Context().Digest(indexManifest.Manifests[0].Digest.String()))
+			Expect(err).ToNot(HaveOccurred())
+
+			manifest, err := signature.Manifest()
+			Expect(err).ToNot(HaveOccurred())
+
+			layers, err := signature.Layers()
+			Expect(err).ToNot(HaveOccurred())
+
+			verifySignatureLayer(manifest, layers[0], digest)
+		})
+	})
+})
@@ -0,0 +1,248 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	// MediaTypeOpenVEX is the media type of an OpenVEX document
+	MediaTypeOpenVEX types.MediaType = "application/vnd.openvex+json"
+
+	openVEXContextPrefix = "https://openvex.dev/ns"
+
+	vexStatusNotAffected = "not_affected"
+)
+
+type openVEXDocument struct {
+	Context    string             `json:"@context"`
+	Statements []openVEXStatement `json:"statements"`
+}
+
+type openVEXStatement struct {
+	// Vulnerability is an object with a name and aliases since OpenVEX 0.2.0, and a string before
+	Vulnerability   json.RawMessage    `json:"vulnerability"`
+	Products        []openVEXComponent `json:"products"`
+	Status          string             `json:"status"`
+	Justification   string             `json:"justification"`
+	ImpactStatement string             `json:"impact_statement"`
+
+	// Expires is an extension of the OpenVEX format, the statement is ignored after that time
+	Expires *time.Time `json:"expires"`
+}
+
+type openVEXComponent struct {
+	ID          string `json:"@id"`
+	Identifiers struct {
+		PURL string `json:"purl"`
+	} `json:"identifiers"`
+	Subcomponents []openVEXComponent `json:"subcomponents"`
+}
+
+// purl returns the package URL of a component, which can be its ID or an identifier
+func (c openVEXComponent) purl() string {
+	if strings.HasPrefix(c.ID, "pkg:") {
+		return c.ID
+	}
+
+	return c.Identifiers.PURL
+}
+
+type vexStatement struct {
+	vulnerabilities map[string]bool
+	products        []openVEXComponent
+	justification   string
+}
+
+// VEX holds the not_affected statements of OpenVEX documents for an image
+type VEX struct {
+	imageName  string
+	statements []vexStatement
+}
+
+// NewVEX parses OpenVEX documents for the image with the given name. Only statements with the status
+// not_affected are kept, statements that expired before the given time are dropped.
+func NewVEX(imageName name.Reference, documents [][]byte, now time.Time) (*VEX, error) {
+	vex := &VEX{imageName: path.Base(imageName.Context().RepositoryStr())}
+
+	for i, data := range documents {
+		var document openVEXDocument
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse VEX document %d: %w", i, err)
+		}
+
+		if !strings.HasPrefix(document.Context, openVEXContextPrefix) {
+			return nil, fmt.Errorf("VEX document %d is not an OpenVEX document, the context is %q", i, document.Context)
+		}
+
+		for _, statement := range document.Statements {
+			if statement.Status != vexStatusNotAffected {
+				continue
+			}
+
+			// OpenVEX requires a justification or an impact statement for not_affected
+			if statement.Justification == "" && statement.ImpactStatement == "" {
+				return nil, fmt.Errorf("VEX document %d contains a not_affected statement without justification and impact statement", i)
+			}
+
+			// a statement without products would suppress the vulnerability in every image
+			if len(statement.Products) == 0 {
+				return nil, fmt.Errorf("VEX document %d contains a not_affected statement without products", i)
+			}
+
+			if statement.Expires != nil && statement.Expires.Before(now) {
+				continue
+			}
+
+			vulnerabilities, err := parseVEXVulnerability(statement.Vulnerability)
+			if err != nil {
+				return nil, fmt.Errorf("VEX document %d contains an invalid vulnerability: %w", i, err)
+			}
+
+			vex.statements = append(vex.statements, vexStatement{
+				vulnerabilities: vulnerabilities,
+				products:        statement.Products,
+				justification:   statement.Justification,
+			})
+		}
+	}
+
+	return vex, nil
+}
+
+// parseVEXVulnerability returns the name and the aliases of the vulnerability of a statement
+func parseVEXVulnerability(data json.RawMessage) (map[string]bool, error) {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil && id != "" {
+		return map[string]bool{id: true}, nil
+	}
+
+	var vulnerability struct {
+		Name    string   `json:"name"`
+		Aliases []string `json:"aliases"`
+	}
+	if err := json.Unmarshal(data, &vulnerability); err != nil || vulnerability.Name == "" {
+		return nil, fmt.Errorf("the vulnerability %s has no name", string(data))
+	}
+
+	ids := map[string]bool{vulnerability.Name: true}
+	for _, alias := range vulnerability.Aliases {
+		ids[alias] = true
+	}
+
+	return ids, nil
+}
+
+// Suppresses returns whether a not_affected statement applies to the vulnerability in the package, and the
+// justification of that statement. A statement applies if one of its products is the image, optionally
+// narrowed down to subcomponents, or if one of its products is the package itself.
+func (v *VEX) Suppresses(id string, packageURL string) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+
+	for _, statement := range v.statements {
+		if !statement.vulnerabilities[id] {
+			continue
+		}
+
+		for _, product := range statement.products {
+			if v.isImage(product.purl()) {
+				if len(product.Subcomponents) == 0 {
+					return statement.justification, true
+				}
+
+				for _, subcomponent := range product.Subcomponents {
+					if matchPackageURL(subcomponent.purl(), packageURL) {
+						return statement.justification, true
+					}
+				}
+
+				continue
+			}
+
+			if matchPackageURL(product.purl(), packageURL) {
+				return statement.justification, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// isImage returns whether a package URL is an OCI package URL for the image, the version is
+// ignored because the digest of the image is only known after it was pushed
+func (v *VEX) isImage(packageURL string) bool {
+	base, _ := splitPackageURL(packageURL)
+	return base == "pkg:oci/"+v.imageName
+}
+
+// matchPackageURL returns whether a package URL matches a pattern, which is a package URL that can omit
+// the version, qualifiers and subpath
+func matchPackageURL(pattern string, packageURL string) bool {
+	if pattern == "" || packageURL == "" {
+		return false
+	}
+
+	patternBase, patternVersion := splitPackageURL(pattern)
+	base, version := splitPackageURL(packageURL)
+
+	return patternBase == base && (patternVersion == "" || patternVersion == version)
+}
+
+// splitPackageURL returns the package URL without version, qualifiers and subpath, and the version
+func splitPackageURL(packageURL string) (string, string) {
+	if i := strings.IndexAny(packageURL, "?#"); i >= 0 {
+		packageURL = packageURL[:i]
+	}
+
+	base, version, _ := strings.Cut(packageURL, "@")
+	return base, version
+}
+
+// PullVEXDocument pulls an OCI artifact and returns the OpenVEX document in its layer, which is the layer with
+// the OpenVEX media type or the only layer of the artifact
+func PullVEXDocument(reference name.Reference, options []remote.Option) ([]byte, error) {
+	artifact, err := remote.Image(reference, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull the VEX document %s: %w", reference.String(), err)
+	}
+
+	manifest, err := artifact.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	layers, err := artifact.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, layerDescriptor := range manifest.Layers {
+		if layerDescriptor.MediaType != MediaTypeOpenVEX && len(manifest.Layers) > 1 {
+			continue
+		}
+
+		reader, err := layers[i].Uncompressed()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		return io.ReadAll(reader)
+	}
+
+	return nil, fmt.Errorf("the artifact %s does not contain a layer with media type %s", reference.String(), MediaTypeOpenVEX)
+}
@@ -0,0 +1,212 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"k8s.io/utils/ptr"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/image"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VEX", func() {
+	const document = `{
+		"@context": "https://openvex.dev/ns/v0.2.0",
+		"@id": "https://example.com/vex/some-image",
+		"statements": [{
+			"vulnerability": {"name": "CVE-0000-0001", "aliases": ["GHSA-0000-0000-0001"]},
+			"products": [{"@id": "pkg:oci/some-image", "subcomponents": [{"@id": "pkg:golang/example.com/some-module"}]}],
+			"status": "not_affected",
+			"justification": "vulnerable_code_not_in_execute_path"
+		}, {
+			"vulnerability": {"name": "CVE-0000-0002"},
+			"products": [{"@id": "pkg:deb/debian/openssl@3.0.11-1"}],
+			"status": "not_affected",
+			"justification": "vulnerable_code_not_present"
+		}, {
+			"vulnerability": {"name": "CVE-0000-0003"},
+			"products": [{"@id": "pkg:oci/some-image"}],
+			"status": "not_affected",
+			"justification": "component_not_present",
+			"expires": "2024-06-01T00:00:00Z"
+		}, {
+			"vulnerability": {"name": "CVE-0000-0004"},
+			"products": [{"@id": "pkg:oci/some-image"}],
+			"status": "affected"
+		}]
+	}`
+
+	var imageName name.Reference
+
+	BeforeEach(func() {
+		var err error
+		imageName, err = name.ParseReference("registry.example.com/some-namespace/some-image:latest")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("NewVEX", func() {
+		It("fails for a document that is not an OpenVEX document", func() {
+			_, err := image.NewVEX(imageName, [][]byte{[]byte(`{"bomFormat":"CycloneDX"}`)}, time.Now())
+			Expect(err).To(MatchError(ContainSubstring("is not an OpenVEX document")))
+		})
+
+		It("fails for a not_affected statement without justification", func() {
+			_, err := image.NewVEX(imageName, [][]byte{[]byte(`{
+				"@context": "https://openvex.dev/ns/v0.2.0",
+				"statements": [{"vulnerability": {"name": "CVE-0000-0001"}, "status": "not_affected"}]
+			}`)}, time.Now())
+			Expect(err).To(MatchError(ContainSubstring("without justification and impact statement")))
+		})
+
+		It("fails for a not_affected statement without products", func() {
+			_, err := image.NewVEX(imageName, [][]byte{[]byte(`{
+				"@context": "https://openvex.dev/ns/v0.2.0",
+				"statements": [{"vulnerability": {"name": "CVE-0000-0001"}, "status": "not_affected", "justification": "component_not_present"}]
+			}`)}, time.Now())
+			Expect(err).To(MatchError(ContainSubstring("without products")))
+		})
+
+		It("supports the vulnerability as string of older OpenVEX versions", func() {
+			vex, err := image.NewVEX(imageName, [][]byte{[]byte(`{
+				"@context": "https://openvex.dev/ns",
+				"statements": [{"vulnerability": "CVE-0000-0001", "status": "not_affected", "impact_statement": "not used", "products": [{"@id": "pkg:oci/some-image"}]}]
+			}`)}, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+
+			_, ok := vex.Suppresses("CVE-0000-0001", "pkg:npm/some-package@1.0.0")
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("Suppresses", func() {
+		var vex *image.VEX
+
+		BeforeEach(func() {
+			var err error
+			vex, err = image.NewVEX(imageName, [][]byte{[]byte(document)}, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("suppresses a vulnerability in a subcomponent of the image", func() {
+			justification, ok := vex.Suppresses("CVE-0000-0001", "pkg:golang/example.com/some-module@v1.2.3")
+			Expect(ok).To(BeTrue())
+			Expect(justification).To(Equal("vulnerable_code_not_in_execute_path"))
+		})
+
+		It("suppresses a vulnerability by its alias", func() {
+			_, ok := vex.Suppresses("GHSA-0000-0000-0001", "pkg:golang/example.com/some-module@v1.2.3")
+			Expect(ok).To(BeTrue())
+		})
+
+		It("does not suppress a vulnerability in another package of the image", func() {
+			_, ok := vex.Suppresses("CVE-0000-0001", "pkg:golang/example.com/other-module@v1.2.3")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("suppresses a vulnerability in a package with the version of the product", func() {
+			_, ok := vex.Suppresses("CVE-0000-0002", "pkg:deb/debian/openssl@3.0.11-1?arch=amd64&distro=debian-12")
+			Expect(ok).To(BeTrue())
+
+			_, ok = vex.Suppresses("CVE-0000-0002", "pkg:deb/debian/openssl@3.0.13-1?arch=amd64&distro=debian-12")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("ignores expired statements and statements that are not not_affected", func() {
+			_, ok := vex.Suppresses("CVE-0000-0003", "pkg:deb/debian/zlib@1.2.13")
+			Expect(ok).To(BeFalse())
+
+			_, ok = vex.Suppresses("CVE-0000-0004", "pkg:deb/debian/zlib@1.2.13")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("suppresses nothing without documents", func() {
+			var noVEX *image.VEX
+			_, ok := noVEX.Suppresses("CVE-0000-0001", "pkg:golang/example.com/some-module@v1.2.3")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("RunVulnerabilityScan with VEX documents", func() {
+		BeforeEach(func() {
+			// install a fake grype that reports vulnerabilities with their packages
+			binDir := GinkgoT().TempDir()
+			script := `#!/bin/sh
+cat <<'EOF'
+{"matches":[
+	{"vulnerability":{"id":"CVE-0000-0001","severity":"High"},"artifact":{"purl":"pkg:golang/example.com/some-module@v1.2.3"}},
+	{"vulnerability":{"id":"CVE-0000-0001","severity":"High"},"artifact":{"purl":"pkg:golang/example.com/other-module@v1.0.0"}},
+	{"vulnerability":{"id":"CVE-0000-0002","severity":"Critical"},"artifact":{"purl":"pkg:deb/debian/openssl@3.0.11-1"}}
+]}
+EOF
+`
+			Expect(os.WriteFile(filepath.Join(binDir, "grype"), []byte(script), 0700)).To(Succeed())
+
+			GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+		})
+
+		It("lists the suppressed vulnerabilities separately", func() {
+			vex, err := image.NewVEX(imageName, [][]byte{[]byte(document)}, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+
+			result, err := image.RunVulnerabilityScan(context.TODO(), imageName.String(), buildapi.VulnerabilityScanOptions{
+				Enabled: true,
+				Scanner: ptr.To(buildapi.VulnerabilityScannerGrype),
+			}, nil, false, false, "", vex, 20)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Vulnerabilities).To(Equal([]buildapi.Vulnerability{
+				{ID: "CVE-0000-0001", Severity: buildapi.High},
+			}))
+			Expect(result.Suppressed).To(Equal([]buildapi.SuppressedVulnerability{
+				{ID: "CVE-0000-0002", Severity: buildapi.Critical, Justification: "vulnerable_code_not_present"},
+				{ID: "CVE-0000-0001", Severity: buildapi.High, Justification: "vulnerable_code_not_in_execute_path"},
+			}))
+			Expect(result.Summary).To(Equal(buildapi.VulnerabilitySummary{High: 1}))
+		})
+	})
+
+	Context("PullVEXDocument", func() {
+		It("pulls the document from the layer of an artifact", func() {
+			logger := log.New(io.Discard, "", 0)
+			server := httptest.NewServer(registry.New(registry.Logger(logger)))
+			DeferCleanup(func() {
+				server.Close()
+			})
+
+			reference, err := name.ParseReference(fmt.Sprintf("%s/vex/some-image:latest", strings.ReplaceAll(server.URL, "http://", "")))
+			Expect(err).ToNot(HaveOccurred())
+
+			artifact, err := mutate.Append(
+				mutate.MediaType(empty.Image, types.OCIManifestSchema1),
+				mutate.Addendum{Layer: static.NewLayer([]byte(document), image.MediaTypeOpenVEX), MediaType: image.MediaTypeOpenVEX},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(remote.Write(reference, artifact)).To(Succeed())
+
+			data, err := image.PullVEXDocument(reference, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(document))
+		})
+	})
+})
@@ -0,0 +1,175 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	// MediaTypeTrivyDBLayer is the media type of the layer of a trivy vulnerability database artifact
+	MediaTypeTrivyDBLayer types.MediaType = "application/vnd.aquasec.trivy.db.layer.v1.tar+gzip"
+
+	// vulnerabilityDBDirectory is the directory in the trivy cache directory that contains the database
+	vulnerabilityDBDirectory = "db"
+
+	// grypeDBDirectory is the directory in the cache directory that grype uses as its database cache
+	// directory, so that one volume can provide the databases of trivy and grype
+	grypeDBDirectory = "grype"
+)
+
+// trivyDBMetadata is the metadata.json file of a trivy vulnerability database
+type trivyDBMetadata struct {
+	Version   int       `json:"Version"`
+	UpdatedAt time.Time `json:"UpdatedAt"`
+}
+
+// grypeDBStatus is the JSON output of the grype db status command
+type grypeDBStatus struct {
+	Built time.Time `json:"built"`
+}
+
+// PullVulnerabilityDB pulls a trivy vulnerability database artifact, like a mirror of ghcr.io/aquasecurity/trivy-db:2
+// in a local registry, and extracts it into the db directory of the given trivy cache directory
+func PullVulnerabilityDB(reference name.Reference, cacheDir string, options []remote.Option) error {
+	artifact, err := remote.Image(reference, options...)
+	if err != nil {
+		return fmt.Errorf("failed to pull the vulnerability database %s: %w", reference.String(), err)
+	}
+
+	manifest, err := artifact.Manifest()
+	if err != nil {
+		return err
+	}
+
+	layers, err := artifact.Layers()
+	if err != nil {
+		return err
+	}
+
+	for i, layerDescriptor := range manifest.Layers {
+		if layerDescriptor.MediaType != MediaTypeTrivyDBLayer {
+			continue
+		}
+
+		reader, err := layers[i].Uncompressed()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		targetDir := filepath.Join(cacheDir, vulnerabilityDBDirectory)
+		if err := os.MkdirAll(targetDir, 0755); err != nil {
+			return err
+		}
+
+		return extractFlatTar(reader, targetDir)
+	}
+
+	return fmt.Errorf("the artifact %s does not contain a layer with media type %s", reference.String(), MediaTypeTrivyDBLayer)
+}
+
+// extractFlatTar extracts the regular files of a tar archive into the target directory, ignoring
+// any directory structure in the archive
+func extractFlatTar(reader io.Reader, targetDir string) error {
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		file, err := os.OpenFile(filepath.Join(targetDir, filepath.Base(header.Name)), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+
+		// #nosec G110 the database is a trusted artifact that is configured by the administrator
+		if _, err := io.Copy(file, tarReader); err != nil {
+			file.Close()
+			return err
+		}
+
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+}
+
+// GetVulnerabilityDBUpdatedAt returns when the vulnerability database in the given trivy cache directory was updated
+func GetVulnerabilityDBUpdatedAt(cacheDir string) (time.Time, error) {
+	data, err := os.ReadFile(filepath.Join(cacheDir, vulnerabilityDBDirectory, "metadata.json"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read the metadata of the vulnerability database: %w", err)
+	}
+
+	var metadata trivyDBMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse the metadata of the vulnerability database: %w", err)
+	}
+
+	return metadata.UpdatedAt, nil
+}
+
+// GetGrypeDBUpdatedAt returns when the grype vulnerability database in the grype directory of the
+// given cache directory was built
+func GetGrypeDBUpdatedAt(ctx context.Context, cacheDir string) (time.Time, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "grype", "db", "status", "--output", "json")
+	cmd.Stdin = nil
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), getGrypeDBEnv(cacheDir)...)
+
+	if err := cmd.Run(); err != nil {
+		log.Printf("failed to get the status of the grype database:\n%s", stderr.String())
+		return time.Time{}, fmt.Errorf("failed to get the status of the vulnerability database: %w", err)
+	}
+
+	var status grypeDBStatus
+	if err := json.Unmarshal(stdout.Bytes(), &status); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse the status of the vulnerability database: %w", err)
+	}
+
+	if status.Built.IsZero() {
+		return time.Time{}, fmt.Errorf("the status of the vulnerability database does not contain when it was built")
+	}
+
+	return status.Built, nil
+}
+
+// getGrypeDBEnv returns the environment for grype to use the pre-populated database in the grype
+// directory of the given cache directory without updating it. The age of the database is checked
+// against the configured maximum age instead of the default of grype.
+func getGrypeDBEnv(cacheDir string) []string {
+	return []string{
+		"GRYPE_DB_CACHE_DIR=" + filepath.Join(cacheDir, grypeDBDirectory),
+		"GRYPE_DB_AUTO_UPDATE=false",
+		"GRYPE_DB_VALIDATE_AGE=false",
+		"GRYPE_CHECK_FOR_APP_UPDATE=false",
+	}
+}
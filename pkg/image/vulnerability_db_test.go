@@ -0,0 +1,190 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/image"
+	"k8s.io/utils/ptr"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Vulnerability database", func() {
+	const metadata = `{"Version":2,"NextUpdate":"2024-06-02T06:00:00Z","UpdatedAt":"2024-06-01T06:00:00Z"}`
+
+	// createDBLayer creates a gzipped tar archive like the layer of the trivy-db artifact
+	createDBLayer := func() []byte {
+		var buffer bytes.Buffer
+		gzipWriter := gzip.NewWriter(&buffer)
+		tarWriter := tar.NewWriter(gzipWriter)
+
+		for fileName, content := range map[string]string{"metadata.json": metadata, "trivy.db": "some-db"} {
+			Expect(tarWriter.WriteHeader(&tar.Header{Name: fileName, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})).To(Succeed())
+			_, err := tarWriter.Write([]byte(content))
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(tarWriter.Close()).To(Succeed())
+		Expect(gzipWriter.Close()).To(Succeed())
+		return buffer.Bytes()
+	}
+
+	Context("PullVulnerabilityDB", func() {
+		var dbImage name.Reference
+
+		BeforeEach(func() {
+			logger := log.New(io.Discard, "", 0)
+			server := httptest.NewServer(registry.New(registry.Logger(logger)))
+			DeferCleanup(func() {
+				server.Close()
+			})
+
+			var err error
+			dbImage, err = name.ParseReference(fmt.Sprintf("%s/aquasecurity/trivy-db:2", strings.ReplaceAll(server.URL, "http://", "")))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("pulls and extracts the database into the cache directory", func() {
+			artifact, err := mutate.Append(
+				mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), "application/vnd.aquasec.trivy.config.v1+json"),
+				mutate.Addendum{Layer: static.NewLayer(createDBLayer(), image.MediaTypeTrivyDBLayer), MediaType: image.MediaTypeTrivyDBLayer},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(remote.Write(dbImage, artifact)).To(Succeed())
+
+			cacheDir := GinkgoT().TempDir()
+			Expect(image.PullVulnerabilityDB(dbImage, cacheDir, []remote.Option{})).To(Succeed())
+
+			Expect(filepath.Join(cacheDir, "db", "trivy.db")).To(BeARegularFile())
+
+			updatedAt, err := image.GetVulnerabilityDBUpdatedAt(cacheDir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updatedAt).To(BeTemporally("==", time.Date(2024, 6, 1, 6, 0, 0, 0, time.UTC)))
+		})
+
+		It("fails for an artifact without database layer", func() {
+			artifact, err := mutate.Append(
+				mutate.MediaType(empty.Image, types.OCIManifestSchema1),
+				mutate.Addendum{Layer: static.NewLayer([]byte("something"), types.OCILayer), MediaType: types.OCILayer},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(remote.Write(dbImage, artifact)).To(Succeed())
+
+			Expect(image.PullVulnerabilityDB(dbImage, GinkgoT().TempDir(), []remote.Option{})).To(MatchError(ContainSubstring("does not contain a layer with media type")))
+		})
+	})
+
+	Context("GetVulnerabilityDBUpdatedAt", func() {
+		It("fails if the cache directory contains no database", func() {
+			_, err := image.GetVulnerabilityDBUpdatedAt(GinkgoT().TempDir())
+			Expect(err).To(MatchError(ContainSubstring("failed to read the metadata of the vulnerability database")))
+		})
+	})
+
+	Context("GetGrypeDBUpdatedAt", func() {
+		var envFile string
+
+		// installFakeGrype puts a fake grype executable on the PATH that records its database environment
+		// and prints the given status
+		installFakeGrype := func(status string) {
+			binDir := GinkgoT().TempDir()
+			envFile = filepath.Join(binDir, "env")
+			script := fmt.Sprintf("#!/bin/sh\nenv | grep '^GRYPE_DB_' > %s\ncat <<'EOF'\n%s\nEOF\n", envFile, status)
+			Expect(os.WriteFile(filepath.Join(binDir, "grype"), []byte(script), 0700)).To(Succeed())
+
+			GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+		}
+
+		It("returns when the database in the grype directory was built", func() {
+			installFakeGrype(`{"schemaVersion":"v6.0.2","built":"2024-06-01T06:00:00Z","path":"/workspace/vulnerability-db/grype/6/vulnerability.db","valid":true}`)
+
+			updatedAt, err := image.GetGrypeDBUpdatedAt(context.TODO(), "/workspace/vulnerability-db")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updatedAt).To(BeTemporally("==", time.Date(2024, 6, 1, 6, 0, 0, 0, time.UTC)))
+
+			env, err := os.ReadFile(envFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(env)).To(ContainSubstring("GRYPE_DB_CACHE_DIR=/workspace/vulnerability-db/grype"))
+			Expect(string(env)).To(ContainSubstring("GRYPE_DB_AUTO_UPDATE=false"))
+		})
+
+		It("fails if the status does not contain when the database was built", func() {
+			installFakeGrype(`{"valid":false}`)
+
+			_, err := image.GetGrypeDBUpdatedAt(context.TODO(), "/workspace/vulnerability-db")
+			Expect(err).To(MatchError(ContainSubstring("does not contain when it was built")))
+		})
+	})
+
+	Context("RunVulnerabilityScan with a pre-populated database", func() {
+		var argsFile string
+
+		BeforeEach(func() {
+			// install a fake trivy that records its arguments and reports no vulnerabilities
+			binDir := GinkgoT().TempDir()
+			argsFile = filepath.Join(binDir, "args")
+			script := fmt.Sprintf("#!/bin/sh\necho \"$@\" > %s\necho '{\"Results\":[]}'\n", argsFile)
+			Expect(os.WriteFile(filepath.Join(binDir, "trivy"), []byte(script), 0700)).To(Succeed())
+
+			GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+		})
+
+		It("runs trivy offline with the database in the cache directory", func() {
+			result, err := image.RunVulnerabilityScan(context.TODO(), "registry.example.com/some-image", buildapi.VulnerabilityScanOptions{Enabled: true}, nil, false, false, "/workspace/vulnerability-db", nil, 20)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Vulnerabilities).To(BeEmpty())
+
+			args, err := os.ReadFile(argsFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(args)).To(ContainSubstring("--cache-dir /workspace/vulnerability-db"))
+			Expect(string(args)).To(ContainSubstring("--skip-db-update"))
+			Expect(string(args)).To(ContainSubstring("--offline-scan"))
+		})
+
+		It("runs grype offline with the database in the grype directory of the cache directory", func() {
+			binDir := GinkgoT().TempDir()
+			envFile := filepath.Join(binDir, "env")
+			script := fmt.Sprintf("#!/bin/sh\nenv | grep '^GRYPE_DB_' > %s\necho '{\"matches\":[]}'\n", envFile)
+			Expect(os.WriteFile(filepath.Join(binDir, "grype"), []byte(script), 0700)).To(Succeed())
+			GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			result, err := image.RunVulnerabilityScan(context.TODO(), "registry.example.com/some-image", buildapi.VulnerabilityScanOptions{
+				Enabled: true,
+				Scanner: ptr.To(buildapi.VulnerabilityScannerGrype),
+			}, nil, false, false, "/workspace/vulnerability-db", nil, 20)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Vulnerabilities).To(BeEmpty())
+
+			env, err := os.ReadFile(envFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(env)).To(ContainSubstring("GRYPE_DB_CACHE_DIR=/workspace/vulnerability-db/grype"))
+			Expect(string(env)).To(ContainSubstring("GRYPE_DB_AUTO_UPDATE=false"))
+			Expect(string(env)).To(ContainSubstring("GRYPE_DB_VALIDATE_AGE=false"))
+		})
+	})
+})
@@ -0,0 +1,84 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+)
+
+const (
+	// MediaTypeVulnerabilityReport is the artifact type of a vulnerability report in the JSON format of the scanner
+	MediaTypeVulnerabilityReport types.MediaType = "application/vnd.shipwright.vulnerability.report.v1+json"
+
+	// AnnotationVulnerabilityScanner is the annotation of a vulnerability report layer that contains the scanner
+	AnnotationVulnerabilityScanner = "io.shipwright.vulnerability.scanner"
+)
+
+// AttachVulnerabilityReport pushes the complete report of a vulnerability scan as an artifact that refers
+// to the subject image or image index. The digest of the artifact is returned.
+func AttachVulnerabilityReport(subject name.Digest, report []byte, scanner buildapi.VulnerabilityScanner, options []remote.Option) (string, error) {
+	subjectDescriptor, err := remote.Head(subject, options...)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve the descriptor of %s: %w", subject.String(), err)
+	}
+
+	digest, err := pushReferrer(subject.Context(), *subjectDescriptor, MediaTypeVulnerabilityReport, mutate.Addendum{
+		Layer:     static.NewLayer(report, MediaTypeVulnerabilityReport),
+		MediaType: MediaTypeVulnerabilityReport,
+		Annotations: map[string]string{
+			AnnotationVulnerabilityScanner: string(scanner),
+		},
+	}, options)
+	if err != nil {
+		return "", fmt.Errorf("failed to push the vulnerability report: %w", err)
+	}
+
+	return digest, nil
+}
+
+// EncodeVulnerabilityReport compresses a vulnerability report and encodes it in base64 so that it
+// can be passed in a result
+func EncodeVulnerabilityReport(report []byte) (string, error) {
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	if _, err := gzipWriter.Write(report); err != nil {
+		return "", err
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(buffer.Bytes()), nil
+}
+
+// DecodeVulnerabilityReport reverts EncodeVulnerabilityReport
+func DecodeVulnerabilityReport(encoded string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the vulnerability report: %w", err)
+	}
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress the vulnerability report: %w", err)
+	}
+	defer gzipReader.Close()
+
+	// #nosec G110 the report is limited by the size of a result
+	return io.ReadAll(gzipReader)
+}
@@ -0,0 +1,100 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/image"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Vulnerability report", func() {
+	const report = `{"Results":[{"Vulnerabilities":[{"VulnerabilityID":"CVE-0000-0001","Severity":"HIGH"}]}]}`
+
+	Context("AttachVulnerabilityReport", func() {
+		var subject name.Digest
+
+		BeforeEach(func() {
+			logger := log.New(io.Discard, "", 0)
+			server := httptest.NewServer(registry.New(registry.Logger(logger), registry.WithReferrersSupport(true)))
+			DeferCleanup(func() {
+				server.Close()
+			})
+
+			imageName, err := name.ParseReference(fmt.Sprintf("%s/%s/%s", strings.ReplaceAll(server.URL, "http://", ""), "test-namespace", "test-image"))
+			Expect(err).ToNot(HaveOccurred())
+
+			img, err := random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			digest, _, err := image.PushImageOrImageIndex(imageName, img, nil, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+
+			subject = imageName.Context().Digest(digest)
+		})
+
+		It("attaches the report as referrer of the image", func() {
+			digest, err := image.AttachVulnerabilityReport(subject, []byte(report), buildapi.VulnerabilityScannerTrivy, []remote.Option{})
+			Expect(err).ToNot(HaveOccurred())
+
+			referrers, err := remote.Referrers(subject)
+			Expect(err).ToNot(HaveOccurred())
+
+			indexManifest, err := referrers.IndexManifest()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(indexManifest.Manifests).To(HaveLen(1))
+			Expect(indexManifest.Manifests[0].Digest.String()).To(Equal(digest))
+			Expect(indexManifest.Manifests[0].ArtifactType).To(Equal(string(image.MediaTypeVulnerabilityReport)))
+
+			artifact, err := remote.Image(subject.Context().Digest(digest))
+			Expect(err).ToNot(HaveOccurred())
+
+			manifest, err := artifact.Manifest()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest.Layers).To(HaveLen(1))
+			Expect(manifest.Layers[0].Annotations).To(HaveKeyWithValue(image.AnnotationVulnerabilityScanner, "trivy"))
+
+			layers, err := artifact.Layers()
+			Expect(err).ToNot(HaveOccurred())
+
+			reader, err := layers[0].Uncompressed()
+			Expect(err).ToNot(HaveOccurred())
+			defer reader.Close()
+
+			content, err := io.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(Equal(report))
+		})
+	})
+
+	Context("EncodeVulnerabilityReport", func() {
+		It("encodes a report that can be decoded", func() {
+			encoded, err := image.EncodeVulnerabilityReport([]byte(report))
+			Expect(err).ToNot(HaveOccurred())
+
+			decoded, err := image.DecodeVulnerabilityReport(encoded)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(decoded)).To(Equal(report))
+		})
+
+		It("fails to decode a value that is not an encoded report", func() {
+			_, err := image.DecodeVulnerabilityReport("bm90LWd6aXA=")
+			Expect(err).To(MatchError(ContainSubstring("failed to decompress the vulnerability report")))
+		})
+	})
+})
@@ -17,9 +17,176 @@ import (
 	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
 )
 
+// VulnerabilityScanner scans an image for vulnerabilities, either in a registry or in a directory
+type VulnerabilityScanner interface {
+	// Scan returns the vulnerabilities of the image and the complete report of the scanner in its JSON format,
+	// the severity and unfixed ignore options are applied, errors of the scanner itself are returned as
+	// VulnerabilityScanError
+	Scan(ctx context.Context, imagePath string, settings buildapi.VulnerabilityScanOptions, auth *authn.AuthConfig, insecure bool, imageInDir bool) ([]VulnerabilityFinding, []byte, error)
+}
+
+// VulnerabilityFinding is a vulnerability that was found in a package of the image
+type VulnerabilityFinding struct {
+	buildapi.Vulnerability
+
+	// PackageURL is the package URL of the vulnerable package, if the scanner reports it
+	PackageURL string
+}
+
+// VulnerabilityScanResult is the result of a vulnerability scan
+type VulnerabilityScanResult struct {
+	// Scanner is the scanner that produced the report
+	Scanner buildapi.VulnerabilityScanner
+
+	// Vulnerabilities are the vulnerabilities sorted by severity and limited to the count limit
+	Vulnerabilities []buildapi.Vulnerability
+
+	// Suppressed are the vulnerabilities that were suppressed by a VEX statement, sorted by severity
+	// and limited to the count limit
+	Suppressed []buildapi.SuppressedVulnerability
+
+	// Summary counts all vulnerabilities per severity, it is not affected by the count limit
+	Summary buildapi.VulnerabilitySummary
+
+	// Report is the complete report of the scanner in its JSON format
+	Report []byte
+}
+
+// VulnerabilityScanError is returned if the scanner failed to scan the image, for example because
+// it could not download its database. It is not returned for vulnerabilities that were found.
+type VulnerabilityScanError struct {
+	Scanner buildapi.VulnerabilityScanner
+	Err     error
+}
+
+func (e *VulnerabilityScanError) Error() string {
+	return fmt.Sprintf("failed to run %s: %v", e.Scanner, e.Err)
+}
+
+func (e *VulnerabilityScanError) Unwrap() error {
+	return e.Err
+}
+
+// NewVulnerabilityScanner returns the vulnerability scanner with the given name, trivy is the default. If a
+// cache directory with a pre-populated vulnerability database is provided, the scanner runs offline. Trivy
+// uses the cache directory itself, grype uses its grype directory.
+func NewVulnerabilityScanner(scanner buildapi.VulnerabilityScanner, dbCacheDir string) (VulnerabilityScanner, error) {
+	switch scanner {
+	case "", buildapi.VulnerabilityScannerTrivy:
+		return &trivyScanner{cacheDir: dbCacheDir}, nil
+	case buildapi.VulnerabilityScannerGrype:
+		return &grypeScanner{cacheDir: dbCacheDir}, nil
+	default:
+		return nil, fmt.Errorf("unsupported vulnerability scanner %q, must be %s or %s", scanner, buildapi.VulnerabilityScannerTrivy, buildapi.VulnerabilityScannerGrype)
+	}
+}
+
+// RunVulnerabilityScan scans the image with the scanner that is defined in the settings, using the pre-populated
+// database in the cache directory if one is provided. Vulnerabilities with an ignored ID are removed, those with a
+// not_affected statement in the VEX are suppressed, the others are counted per severity, sorted by severity and
+// limited to the count limit.
+func RunVulnerabilityScan(ctx context.Context, imagePath string, settings buildapi.VulnerabilityScanOptions, auth *authn.AuthConfig, insecure bool, imageInDir bool, dbCacheDir string, vex *VEX, vulnCountLimit int) (*VulnerabilityScanResult, error) {
+	scannerName := buildapi.VulnerabilityScannerTrivy
+	if settings.Scanner != nil {
+		scannerName = *settings.Scanner
+	}
+
+	scanner, err := NewVulnerabilityScanner(scannerName, dbCacheDir)
+	if err != nil {
+		return nil, &VulnerabilityScanError{Scanner: scannerName, Err: err}
+	}
+
+	findings, report, err := scanner.Scan(ctx, imagePath, settings, auth, insecure, imageInDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// remove the vulnerabilities that are ignored by their ID, and suppress those that do not affect the image
+	ignoreMap := make(map[string]bool)
+	if settings.Ignore != nil {
+		for _, vuln := range settings.Ignore.ID {
+			ignoreMap[vuln] = true
+		}
+	}
+
+	var vulnerabilities []buildapi.Vulnerability
+	var suppressed []buildapi.SuppressedVulnerability
+	for _, finding := range findings {
+		if ignoreMap[finding.ID] {
+			continue
+		}
+
+		if justification, ok := vex.Suppresses(finding.ID, finding.PackageURL); ok {
+			suppressed = append(suppressed, buildapi.SuppressedVulnerability{
+				ID:            finding.ID,
+				Severity:      finding.Severity,
+				Justification: justification,
+			})
+			continue
+		}
+
+		vulnerabilities = append(vulnerabilities, finding.Vulnerability)
+	}
+
+	// Sort the vulnerabilities by severity
+	severityOrder := map[buildapi.VulnerabilitySeverity]int{
+		buildapi.Critical: 0,
+		buildapi.High:     1,
+		buildapi.Medium:   2,
+		buildapi.Low:      3,
+		buildapi.Unknown:  4,
+	}
+	sort.SliceStable(vulnerabilities, func(i, j int) bool {
+		return severityOrder[vulnerabilities[i].Severity] < severityOrder[vulnerabilities[j].Severity]
+	})
+	sort.SliceStable(suppressed, func(i, j int) bool {
+		return severityOrder[suppressed[i].Severity] < severityOrder[suppressed[j].Severity]
+	})
+
+	summary := summarizeVulnerabilities(vulnerabilities)
+
+	if len(vulnerabilities) > vulnCountLimit {
+		vulnerabilities = vulnerabilities[:vulnCountLimit]
+	}
+	if len(suppressed) > vulnCountLimit {
+		suppressed = suppressed[:vulnCountLimit]
+	}
+
+	return &VulnerabilityScanResult{
+		Scanner:         scannerName,
+		Vulnerabilities: vulnerabilities,
+		Suppressed:      suppressed,
+		Summary:         summary,
+		Report:          report,
+	}, nil
+}
+
+func summarizeVulnerabilities(vulnerabilities []buildapi.Vulnerability) buildapi.VulnerabilitySummary {
+	var summary buildapi.VulnerabilitySummary
+	for _, vulnerability := range vulnerabilities {
+		switch vulnerability.Severity {
+		case buildapi.Critical:
+			summary.Critical++
+		case buildapi.High:
+			summary.High++
+		case buildapi.Medium:
+			summary.Medium++
+		case buildapi.Low:
+			summary.Low++
+		default:
+			summary.Unknown++
+		}
+	}
+
+	return summary
+}
+
 type TrivyVulnerability struct {
 	VulnerabilityID string `json:"vulnerabilityID,omitempty"`
 	Severity        string `json:"severity,omitempty"`
+	PkgIdentifier   struct {
+		PURL string `json:"PURL,omitempty"`
+	} `json:"PkgIdentifier,omitempty"`
 }
 
 type TrivyResult struct {
@@ -28,8 +195,12 @@ type TrivyResult struct {
 	} `json:"Results"`
 }
 
-func RunVulnerabilityScan(ctx context.Context, imagePath string, settings buildapi.VulnerabilityScanOptions, auth *authn.AuthConfig, insecure bool, imageInDir bool, vulnCountLimit int) ([]buildapi.Vulnerability, error) {
+// trivyScanner scans images using trivy, it runs offline if a cache directory is set
+type trivyScanner struct {
+	cacheDir string
+}
 
+func (t *trivyScanner) Scan(ctx context.Context, imagePath string, settings buildapi.VulnerabilityScanOptions, auth *authn.AuthConfig, insecure bool, imageInDir bool) ([]VulnerabilityFinding, []byte, error) {
 	trivyArgs := []string{"image", "--quiet", "--format", "json"}
 	if imageInDir {
 		trivyArgs = append(trivyArgs, "--input", imagePath)
@@ -51,6 +222,10 @@ func RunVulnerabilityScan(ctx context.Context, imagePath string, settings builda
 			trivyArgs = append(trivyArgs, "--ignore-unfixed")
 		}
 	}
+	if t.cacheDir != "" {
+		// the cache directory can be read-only, the cache of the scanned layers is kept in memory
+		trivyArgs = append(trivyArgs, "--cache-dir", t.cacheDir, "--cache-backend", "memory", "--skip-db-update", "--skip-java-db-update", "--offline-scan")
+	}
 
 	var result []byte
 	var err error
@@ -73,39 +248,20 @@ func RunVulnerabilityScan(ctx context.Context, imagePath string, settings builda
 		//  GET https://ghcr.io/v2/aquasecurity/trivy-db/manifests/2: TOOMANYREQUESTS: retry-after: 508.904┬Ás, allowed: 44000/minute
 		//
 		// FATAL	Fatal error	init error: DB error: failed to download vulnerability DB: OCI artifact error: failed to download vulnerability DB: failed to download artifact from any source
-		if i < 10 && strings.Contains(sResult, "failed to download vulnerability DB") {
+		if i < 9 && strings.Contains(sResult, "failed to download vulnerability DB") {
 			log.Println("Will retry")
 			time.Sleep(time.Second)
 		} else {
-			return nil, fmt.Errorf("failed to run trivy: %w", err)
+			return nil, nil, &VulnerabilityScanError{Scanner: buildapi.VulnerabilityScannerTrivy, Err: err}
 		}
 	}
 
 	var trivyResult TrivyResult
 	if err := json.Unmarshal(result, &trivyResult); err != nil {
-		return nil, err
+		return nil, nil, &VulnerabilityScanError{Scanner: buildapi.VulnerabilityScannerTrivy, Err: fmt.Errorf("failed to parse the result: %w", err)}
 	}
 
-	// Get the vulnerabilities from the trivy scan
-	vulnerabilities := parseTrivyResult(trivyResult, settings.Ignore)
-
-	// Sort the vulnerabilities by severity
-	severityOrder := map[buildapi.VulnerabilitySeverity]int{
-		buildapi.Critical: 0,
-		buildapi.High:     1,
-		buildapi.Medium:   2,
-		buildapi.Low:      3,
-		buildapi.Unknown:  4,
-	}
-	sort.Slice(vulnerabilities, func(i, j int) bool {
-		return severityOrder[vulnerabilities[i].Severity] < severityOrder[vulnerabilities[j].Severity]
-	})
-
-	if len(vulnerabilities) > vulnCountLimit {
-		vulnerabilities = vulnerabilities[:vulnCountLimit]
-	}
-
-	return vulnerabilities, nil
+	return parseTrivyResult(trivyResult), result, nil
 }
 
 func getSeverityStringForTrivyScan(ignoreSeverity buildapi.IgnoredVulnerabilitySeverity) string {
@@ -121,31 +277,21 @@ func getSeverityStringForTrivyScan(ignoreSeverity buildapi.IgnoredVulnerabilityS
 	}
 }
 
-func parseTrivyResult(trivyResult TrivyResult, ignoreOptions *buildapi.VulnerabilityIgnoreOptions) []buildapi.Vulnerability {
-	var totalVulnerabilities []TrivyVulnerability
+func parseTrivyResult(trivyResult TrivyResult) []VulnerabilityFinding {
+	var findings []VulnerabilityFinding
 	for _, result := range trivyResult.Results {
-		totalVulnerabilities = append(totalVulnerabilities, result.Vulnerabilities...)
-	}
-
-	var vulnerabilities []buildapi.Vulnerability
-
-	// create a map for ignored vulnerabilities
-	ignoreMap := make(map[string]bool)
-	if ignoreOptions != nil {
-		for _, vuln := range ignoreOptions.ID {
-			ignoreMap[vuln] = true
+		for _, vuln := range result.Vulnerabilities {
+			findings = append(findings, VulnerabilityFinding{
+				Vulnerability: buildapi.Vulnerability{
+					ID:       vuln.VulnerabilityID,
+					Severity: buildapi.VulnerabilitySeverity(strings.ToLower(vuln.Severity)),
+				},
+				PackageURL: vuln.PkgIdentifier.PURL,
+			})
 		}
 	}
-	for _, vuln := range totalVulnerabilities {
-		if !ignoreMap[vuln.VulnerabilityID] {
-			vulnerability := buildapi.Vulnerability{
-				ID:       vuln.VulnerabilityID,
-				Severity: buildapi.VulnerabilitySeverity(strings.ToLower(vuln.Severity)),
-			}
-			vulnerabilities = append(vulnerabilities, vulnerability)
-		}
-	}
-	return vulnerabilities
+
+	return findings
 }
 
 func getAuthStringForTrivyScan(auth *authn.AuthConfig) []string {
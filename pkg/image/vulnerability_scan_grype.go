@@ -0,0 +1,152 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+
+	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+)
+
+type grypeResult struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+			Fix      struct {
+				State string `json:"state"`
+			} `json:"fix"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			PURL string `json:"purl"`
+		} `json:"artifact"`
+	} `json:"matches"`
+}
+
+// grypeScanner scans images using grype, it runs offline if a cache directory is set
+type grypeScanner struct {
+	cacheDir string
+}
+
+func (g *grypeScanner) Scan(ctx context.Context, imagePath string, settings buildapi.VulnerabilityScanOptions, auth *authn.AuthConfig, insecure bool, imageInDir bool) ([]VulnerabilityFinding, []byte, error) {
+	source := "registry:" + imagePath
+	if imageInDir {
+		source = "docker-archive:" + imagePath
+		if info, err := os.Stat(imagePath); err == nil && info.IsDir() {
+			source = "oci-dir:" + imagePath
+		}
+	}
+
+	grypeArgs := []string{source, "--quiet", "--output", "json"}
+	if settings.Ignore != nil && settings.Ignore.Unfixed != nil && *settings.Ignore.Unfixed {
+		grypeArgs = append(grypeArgs, "--only-fixed")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "grype", grypeArgs...)
+	cmd.Stdin = nil
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// grype reads the registry credentials from the environment
+	cmd.Env = append(os.Environ(), getAuthEnvForGrypeScan(auth)...)
+	if insecure {
+		cmd.Env = append(cmd.Env, "GRYPE_REGISTRY_INSECURE_SKIP_TLS_VERIFY=true", "GRYPE_REGISTRY_INSECURE_USE_HTTP=true")
+	}
+	if g.cacheDir != "" {
+		cmd.Env = append(cmd.Env, getGrypeDBEnv(g.cacheDir)...)
+	}
+
+	if err := cmd.Run(); err != nil {
+		log.Printf("failed to run grype:\n%s", stderr.String())
+		return nil, nil, &VulnerabilityScanError{Scanner: buildapi.VulnerabilityScannerGrype, Err: err}
+	}
+
+	var result grypeResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, nil, &VulnerabilityScanError{Scanner: buildapi.VulnerabilityScannerGrype, Err: fmt.Errorf("failed to parse the result: %w", err)}
+	}
+
+	var ignoreSeverity *buildapi.IgnoredVulnerabilitySeverity
+	if settings.Ignore != nil {
+		ignoreSeverity = settings.Ignore.Severity
+	}
+
+	var findings []VulnerabilityFinding
+	for _, match := range result.Matches {
+		severity := getSeverityForGrypeScan(match.Vulnerability.Severity)
+		if ignoreSeverity != nil && isIgnoredSeverity(severity, *ignoreSeverity) {
+			continue
+		}
+
+		findings = append(findings, VulnerabilityFinding{
+			Vulnerability: buildapi.Vulnerability{
+				ID:       match.Vulnerability.ID,
+				Severity: severity,
+			},
+			PackageURL: match.Artifact.PURL,
+		})
+	}
+
+	return findings, stdout.Bytes(), nil
+}
+
+// getSeverityForGrypeScan maps the severities of grype to ours, negligible vulnerabilities are low
+func getSeverityForGrypeScan(severity string) buildapi.VulnerabilitySeverity {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return buildapi.Critical
+	case "high":
+		return buildapi.High
+	case "medium":
+		return buildapi.Medium
+	case "low", "negligible":
+		return buildapi.Low
+	default:
+		return buildapi.Unknown
+	}
+}
+
+// isIgnoredSeverity mirrors the severities that trivy reports for the ignore options, which
+// includes that vulnerabilities with an unknown severity are ignored
+func isIgnoredSeverity(severity buildapi.VulnerabilitySeverity, ignoreSeverity buildapi.IgnoredVulnerabilitySeverity) bool {
+	switch severity {
+	case buildapi.Unknown:
+		return true
+	case buildapi.Low:
+		return ignoreSeverity == buildapi.IgnoredLow || ignoreSeverity == buildapi.IgnoredMedium || ignoreSeverity == buildapi.IgnoredHigh
+	case buildapi.Medium:
+		return ignoreSeverity == buildapi.IgnoredMedium || ignoreSeverity == buildapi.IgnoredHigh
+	case buildapi.High:
+		return ignoreSeverity == buildapi.IgnoredHigh
+	default:
+		return false
+	}
+}
+
+func getAuthEnvForGrypeScan(auth *authn.AuthConfig) []string {
+	var authEnv []string
+	if auth != nil {
+		if auth.Username != "" {
+			authEnv = append(authEnv, "GRYPE_REGISTRY_AUTH_USERNAME="+auth.Username)
+		}
+		if auth.Password != "" {
+			authEnv = append(authEnv, "GRYPE_REGISTRY_AUTH_PASSWORD="+auth.Password)
+		}
+		if auth.RegistryToken != "" {
+			authEnv = append(authEnv, "GRYPE_REGISTRY_AUTH_TOKEN="+auth.RegistryToken)
+		}
+	}
+	return authEnv
+}
@@ -6,8 +6,13 @@ package image_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path"
+	"path/filepath"
+
+	"k8s.io/utils/ptr"
 
 	buildapi "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
 	"github.com/shipwright-io/build/pkg/image"
@@ -34,9 +39,9 @@ var _ = Describe("Vulnerability Scanning", func() {
 		})
 
 		It("runs the image vulnerability scan", func() {
-			vulns, err := image.RunVulnerabilityScan(context.TODO(), vulnerableImage, vulnOptions, nil, false, false, 20)
+			result, err := image.RunVulnerabilityScan(context.TODO(), vulnerableImage, vulnOptions, nil, false, false, "", nil, 20)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(vulns).ToNot(BeEmpty())
+			Expect(result.Vulnerabilities).ToNot(BeEmpty())
 		})
 	})
 
@@ -51,9 +56,9 @@ var _ = Describe("Vulnerability Scanning", func() {
 		})
 
 		It("runs the image vulnerability scan", func() {
-			vulns, err := image.RunVulnerabilityScan(context.TODO(), directory, vulnOptions, nil, false, true, 20)
+			result, err := image.RunVulnerabilityScan(context.TODO(), directory, vulnOptions, nil, false, true, "", nil, 20)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(vulns).ToNot(BeEmpty())
+			Expect(result.Vulnerabilities).ToNot(BeEmpty())
 		})
 	})
 
@@ -65,9 +70,9 @@ var _ = Describe("Vulnerability Scanning", func() {
 		})
 
 		It("runs the image vulnerability scan", func() {
-			vulns, err := image.RunVulnerabilityScan(context.TODO(), directory, buildapi.VulnerabilityScanOptions{}, nil, false, true, 20)
+			result, err := image.RunVulnerabilityScan(context.TODO(), directory, buildapi.VulnerabilityScanOptions{}, nil, false, true, "", nil, 20)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(vulns).ToNot(BeEmpty())
+			Expect(result.Vulnerabilities).ToNot(BeEmpty())
 		})
 
 		It("should ignore the severity defined in ignore options", func() {
@@ -78,10 +83,10 @@ var _ = Describe("Vulnerability Scanning", func() {
 					Severity: &ignoreSeverity,
 				},
 			}
-			vulns, err := image.RunVulnerabilityScan(context.TODO(), directory, vulnOptions, nil, false, true, 20)
+			result, err := image.RunVulnerabilityScan(context.TODO(), directory, vulnOptions, nil, false, true, "", nil, 20)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(vulns).ToNot(BeEmpty())
-			Expect(vulns).ToNot(containsSeverity("LOW"))
+			Expect(result.Vulnerabilities).ToNot(BeEmpty())
+			Expect(result.Vulnerabilities).ToNot(containsSeverity("LOW"))
 		})
 
 		It("should ignore the vulnerabilities defined in ignore options", func() {
@@ -96,13 +101,92 @@ var _ = Describe("Vulnerability Scanning", func() {
 				},
 			}
 
-			vulns, err := image.RunVulnerabilityScan(context.TODO(), directory, vulnOptions, nil, false, true, 20)
+			result, err := image.RunVulnerabilityScan(context.TODO(), directory, vulnOptions, nil, false, true, "", nil, 20)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(vulns).ToNot(BeEmpty())
-			Expect(vulns).ToNot(containsVulnerability(vulnOptions.Ignore.ID[0]))
+			Expect(result.Vulnerabilities).ToNot(BeEmpty())
+			Expect(result.Vulnerabilities).ToNot(containsVulnerability(vulnOptions.Ignore.ID[0]))
 		})
 	})
 
+	Context("For the grype scanner", func() {
+		// installFakeGrype puts a fake grype executable on the PATH that prints the given output and exits with the given code
+		installFakeGrype := func(output string, exitCode int) {
+			binDir := GinkgoT().TempDir()
+			script := fmt.Sprintf("#!/bin/sh\ncat <<'EOF'\n%s\nEOF\nexit %d\n", output, exitCode)
+			Expect(os.WriteFile(filepath.Join(binDir, "grype"), []byte(script), 0700)).To(Succeed())
+
+			GinkgoT().Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+		}
+
+		grypeOptions := func(ignore *buildapi.VulnerabilityIgnoreOptions) buildapi.VulnerabilityScanOptions {
+			return buildapi.VulnerabilityScanOptions{
+				Enabled: true,
+				Scanner: ptr.To(buildapi.VulnerabilityScannerGrype),
+				Ignore:  ignore,
+			}
+		}
+
+		BeforeEach(func() {
+			installFakeGrype(`{"matches":[
+				{"vulnerability":{"id":"CVE-0000-0001","severity":"Negligible"}},
+				{"vulnerability":{"id":"CVE-0000-0002","severity":"Critical"}},
+				{"vulnerability":{"id":"CVE-0000-0003","severity":"Medium"}},
+				{"vulnerability":{"id":"CVE-0000-0004","severity":"Unknown"}}
+			]}`, 0)
+		})
+
+		It("parses and sorts the vulnerabilities", func() {
+			result, err := image.RunVulnerabilityScan(context.TODO(), "registry.example.com/some-image", grypeOptions(nil), nil, false, false, "", nil, 20)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Vulnerabilities).To(Equal([]buildapi.Vulnerability{
+				{ID: "CVE-0000-0002", Severity: buildapi.Critical},
+				{ID: "CVE-0000-0003", Severity: buildapi.Medium},
+				{ID: "CVE-0000-0001", Severity: buildapi.Low},
+				{ID: "CVE-0000-0004", Severity: buildapi.Unknown},
+			}))
+		})
+
+		It("counts all vulnerabilities in the summary and returns the complete report", func() {
+			result, err := image.RunVulnerabilityScan(context.TODO(), "registry.example.com/some-image", grypeOptions(nil), nil, false, false, "", nil, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Scanner).To(Equal(buildapi.VulnerabilityScannerGrype))
+			Expect(result.Vulnerabilities).To(HaveLen(1))
+			Expect(result.Summary).To(Equal(buildapi.VulnerabilitySummary{Critical: 1, Medium: 1, Low: 1, Unknown: 1}))
+			Expect(string(result.Report)).To(ContainSubstring("CVE-0000-0004"))
+		})
+
+		It("applies the ignore options", func() {
+			result, err := image.RunVulnerabilityScan(context.TODO(), "registry.example.com/some-image", grypeOptions(&buildapi.VulnerabilityIgnoreOptions{
+				ID:       []string{"CVE-0000-0002"},
+				Severity: ptr.To(buildapi.IgnoredLow),
+			}), nil, false, false, "", nil, 20)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Vulnerabilities).To(Equal([]buildapi.Vulnerability{
+				{ID: "CVE-0000-0003", Severity: buildapi.Medium},
+			}))
+		})
+
+		It("returns a scan error if grype fails", func() {
+			installFakeGrype("failed to load vulnerability db", 1)
+
+			_, err := image.RunVulnerabilityScan(context.TODO(), "registry.example.com/some-image", grypeOptions(nil), nil, false, false, "", nil, 20)
+
+			var scanErr *image.VulnerabilityScanError
+			Expect(errors.As(err, &scanErr)).To(BeTrue())
+			Expect(scanErr.Scanner).To(Equal(buildapi.VulnerabilityScannerGrype))
+		})
+	})
+
+	It("returns a scan error for an unsupported scanner", func() {
+		_, err := image.RunVulnerabilityScan(context.TODO(), "registry.example.com/some-image", buildapi.VulnerabilityScanOptions{
+			Enabled: true,
+			Scanner: ptr.To(buildapi.VulnerabilityScanner("clair")),
+		}, nil, false, false, "", nil, 20)
+
+		var scanErr *image.VulnerabilityScanError
+		Expect(errors.As(err, &scanErr)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring(`unsupported vulnerability scanner "clair"`)))
+	})
 })
 
 func containsVulnerability(vulnerability string) types.GomegaMatcher {
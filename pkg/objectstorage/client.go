@@ -0,0 +1,201 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package objectstorage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultRegion is used to sign requests when no region is provided
+const DefaultRegion = "us-east-1"
+
+// Object describes an object in a bucket
+type Object struct {
+	Key          string
+	ETag         string
+	VersionID    string
+	LastModified time.Time
+	Size         int64
+}
+
+// Client is a minimal client for S3-compatible object storages that uses
+// path-style requests, which are supported by AWS S3 as well as MinIO
+type Client struct {
+	endpoint        *url.URL
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	httpClient      *http.Client
+}
+
+// NewClient creates a client for the provided endpoint. Requests are only
+// signed if an access key is provided, otherwise they are sent anonymously.
+func NewClient(endpoint string, region string, accessKeyID string, secretAccessKey string) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported endpoint %q, only http and https are supported", endpoint)
+	}
+
+	if region == "" {
+		region = DefaultRegion
+	}
+
+	return &Client{
+		endpoint:        u,
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		httpClient:      http.DefaultClient,
+	}, nil
+}
+
+// GetObject retrieves the content of an object, an empty version ID refers
+// to the latest version. The caller must close the returned reader.
+func (c *Client) GetObject(ctx context.Context, bucket string, key string, versionID string) (io.ReadCloser, *Object, error) {
+	query := url.Values{}
+	if versionID != "" {
+		query.Set("versionId", versionID)
+	}
+
+	resp, err := c.do(ctx, bucket, key, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	object := Object{
+		Key:       key,
+		ETag:      strings.Trim(resp.Header.Get("ETag"), `"`),
+		VersionID: resp.Header.Get("X-Amz-Version-Id"),
+		Size:      resp.ContentLength,
+	}
+
+	if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		object.LastModified = lastModified
+	}
+
+	return resp.Body, &object, nil
+}
+
+// ListObjects returns all objects with a key that starts with the provided prefix
+func (c *Client) ListObjects(ctx context.Context, bucket string, prefix string) ([]Object, error) {
+	type listBucketResult struct {
+		IsTruncated           bool   `xml:"IsTruncated"`
+		NextContinuationToken string `xml:"NextContinuationToken"`
+		Contents              []struct {
+			Key          string    `xml:"Key"`
+			ETag         string    `xml:"ETag"`
+			LastModified time.Time `xml:"LastModified"`
+			Size         int64     `xml:"Size"`
+		} `xml:"Contents"`
+	}
+
+	var objects []Object
+	var continuationToken string
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		resp, err := c.do(ctx, bucket, "", query)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the object listing of bucket %q: %w", bucket, err)
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, Object{
+				Key:          content.Key,
+				ETag:         strings.Trim(content.ETag, `"`),
+				LastModified: content.LastModified,
+				Size:         content.Size,
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+func (c *Client) do(ctx context.Context, bucket string, key string, query url.Values) (*http.Response, error) {
+	u := *c.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + bucket + "/" + key
+	u.RawPath = ""
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.accessKeyID != "" {
+		signRequest(req, c.region, c.accessKeyID, c.secretAccessKey, time.Now())
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, newResponseError(resp)
+	}
+
+	return resp, nil
+}
+
+// ResponseError is returned when the object storage responds with an unexpected status code
+type ResponseError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *ResponseError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("unexpected status code %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("unexpected status code %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func newResponseError(resp *http.Response) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+
+	// the error body is optional, i.e. it is missing for HEAD requests
+	_ = xml.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&body)
+
+	return &ResponseError{
+		StatusCode: resp.StatusCode,
+		Code:       body.Code,
+		Message:    body.Message,
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package objectstorage
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shipwright-io/build/pkg/bundle"
+)
+
+// DownloadDetails contains details about the downloaded source
+type DownloadDetails struct {
+	// Digest is the SHA-256 digest of the object content, or in case of a
+	// prefix, the digest of the downloaded directory as computed by
+	// bundle.Inspect
+	Digest string
+
+	// ETag is the ETag of the object, it is empty in case of a prefix
+	ETag string
+
+	// DirHash is the directory hash of the downloaded directory as computed
+	// by bundle.Inspect in case of a prefix
+	DirHash string
+
+	// VersionID is the version of the object, if versioning is enabled
+	VersionID string
+
+	// MostRecentFileTimestamp is the most recent modification timestamp of
+	// the downloaded objects, or files in case an archive was extracted
+	MostRecentFileTimestamp *time.Time
+}
+
+// DownloadObject downloads a single object into the target directory. Tar
+// archives, also when gzip compressed, are extracted. Any other object is
+// stored using the last segment of its key as file name.
+func (c *Client) DownloadObject(ctx context.Context, bucket string, key string, versionID string, targetPath string) (*DownloadDetails, error) {
+	rc, object, err := c.GetObject(ctx, bucket, key, versionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	if err := os.MkdirAll(targetPath, os.FileMode(0755)); err != nil {
+		return nil, err
+	}
+
+	// store the object in a temporary file first, so that its content can be inspected
+	tmp, err := os.CreateTemp("", "object-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	// the ETag is not necessarily a digest of the content, the content is hashed while it is downloaded
+	var hash = sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), rc); err != nil {
+		return nil, fmt.Errorf("failed to download object %q: %w", key, err)
+	}
+
+	details := DownloadDetails{
+		Digest:    "sha256:" + hex.EncodeToString(hash.Sum(nil)),
+		ETag:      object.ETag,
+		VersionID: object.VersionID,
+	}
+
+	unpackDetails, err := extractArchive(tmp, targetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if unpackDetails != nil {
+		details.MostRecentFileTimestamp = unpackDetails.MostRecentFileTimestamp
+		return &details, nil
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if err := writeFile(filepath.Join(targetPath, path.Base(key)), tmp, object.LastModified); err != nil {
+		return nil, err
+	}
+
+	if !object.LastModified.IsZero() {
+		details.MostRecentFileTimestamp = &object.LastModified
+	}
+
+	return &details, nil
+}
+
+// DownloadPrefix downloads all objects with a key that starts with the prefix
+// into the target directory, using the remainder of the key as relative path
+func (c *Client) DownloadPrefix(ctx context.Context, bucket string, prefix string, targetPath string) (*DownloadDetails, error) {
+	objects, err := c.ListObjects(ctx, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no objects found with prefix %q in bucket %q", prefix, bucket)
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+
+	var details = DownloadDetails{}
+	for _, object := range objects {
+		// skip the placeholder objects that some tools create for directories
+		if strings.HasSuffix(object.Key, "/") {
+			continue
+		}
+
+		relativePath := strings.TrimLeft(strings.TrimPrefix(object.Key, prefix), "/")
+		if relativePath == "" {
+			relativePath = path.Base(object.Key)
+		}
+
+		if !filepath.IsLocal(relativePath) {
+			return nil, fmt.Errorf("object key %q results in a path outside of the target directory", object.Key)
+		}
+
+		if err := c.downloadObjectToFile(ctx, bucket, object, filepath.Join(targetPath, relativePath)); err != nil {
+			return nil, err
+		}
+
+		if !object.LastModified.IsZero() && (details.MostRecentFileTimestamp == nil || object.LastModified.After(*details.MostRecentFileTimestamp)) {
+			lastModified := object.LastModified
+			details.MostRecentFileTimestamp = &lastModified
+		}
+	}
+
+	directoryDetails, err := bundle.Inspect(targetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	details.Digest = directoryDetails.Digest
+	details.DirHash = directoryDetails.DirHash
+
+	return &details, nil
+}
+
+func (c *Client) downloadObjectToFile(ctx context.Context, bucket string, object Object, target string) error {
+	rc, _, err := c.GetObject(ctx, bucket, object.Key, "")
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := os.MkdirAll(filepath.Dir(target), os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	return writeFile(target, rc, object.LastModified)
+}
+
+// extractArchive extracts the file if it is a tar archive, optionally gzip
+// compressed, and returns nil details if it is no archive
+func extractArchive(file *os.File, targetPath string) (*bundle.UnpackDetails, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var reader = bufio.NewReader(file)
+	if magic, err := reader.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, nil
+		}
+		defer gzipReader.Close()
+
+		reader = bufio.NewReader(gzipReader)
+	}
+
+	// the tar header contains the magic at offset 257, both ustar and gnu formats start with it
+	if header, err := reader.Peek(262); err != nil || string(header[257:262]) != "ustar" {
+		return nil, nil
+	}
+
+	return bundle.Unpack(reader, targetPath)
+}
+
+func writeFile(target string, content io.Reader, lastModified time.Time) error {
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(0644))
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if !lastModified.IsZero() {
+		return os.Chtimes(target, lastModified, lastModified)
+	}
+
+	return nil
+}
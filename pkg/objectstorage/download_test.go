@@ -0,0 +1,189 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package objectstorage_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/shipwright-io/build/pkg/objectstorage"
+	utils "github.com/shipwright-io/build/test/utils"
+)
+
+var _ = Describe("Download", func() {
+	const bucket = "sources"
+
+	var (
+		fakeObjectStorage *utils.FakeObjectStorage
+		client            *objectstorage.Client
+		target            string
+		lastModified      = time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	)
+
+	tarball := func(gzipped bool, files map[string]string) []byte {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for name, content := range files {
+			Expect(tw.WriteHeader(&tar.Header{
+				Name:     name,
+				Mode:     0644,
+				Size:     int64(len(content)),
+				ModTime:  lastModified,
+				Typeflag: tar.TypeReg,
+				Format:   tar.FormatPAX,
+			})).To(Succeed())
+			_, err := tw.Write([]byte(content))
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(tw.Close()).To(Succeed())
+
+		if !gzipped {
+			return buf.Bytes()
+		}
+
+		var compressed bytes.Buffer
+		gw := gzip.NewWriter(&compressed)
+		_, err := gw.Write(buf.Bytes())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gw.Close()).To(Succeed())
+
+		return compressed.Bytes()
+	}
+
+	BeforeEach(func() {
+		fakeObjectStorage = utils.StartFakeObjectStorage("minioadmin")
+
+		var err error
+		client, err = objectstorage.NewClient(fakeObjectStorage.URL, "", "minioadmin", "minioadmin")
+		Expect(err).ToNot(HaveOccurred())
+
+		target, err = os.MkdirTemp("", "object-storage")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		fakeObjectStorage.Close()
+		Expect(os.RemoveAll(target)).To(Succeed())
+	})
+
+	Context("downloading a single object", func() {
+		It("stores an object that is no archive using the last segment of the key", func() {
+			etag := fakeObjectStorage.PutObject(bucket, "artifacts/app.jar", []byte("jar content"), "", lastModified)
+			contentDigest := sha256.Sum256([]byte("jar content"))
+
+			details, err := client.DownloadObject(context.TODO(), bucket, "artifacts/app.jar", "", target)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(details.Digest).To(Equal("sha256:" + hex.EncodeToString(contentDigest[:])))
+			Expect(details.ETag).To(Equal(etag))
+			Expect(details.MostRecentFileTimestamp).ToNot(BeNil())
+			Expect(*details.MostRecentFileTimestamp).To(BeTemporally("==", lastModified))
+
+			Expect(filepath.Join(target, "app.jar")).To(BeAnExistingFile())
+			Expect(os.ReadFile(filepath.Join(target, "app.jar"))).To(Equal([]byte("jar content")))
+		})
+
+		It("extracts a tar archive", func() {
+			fakeObjectStorage.PutObject(bucket, "source.tar", tarball(false, map[string]string{"main.go": "package main"}), "", lastModified)
+
+			_, err := client.DownloadObject(context.TODO(), bucket, "source.tar", "", target)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.ReadFile(filepath.Join(target, "main.go"))).To(Equal([]byte("package main")))
+			Expect(filepath.Join(target, "source.tar")).ToNot(BeAnExistingFile())
+		})
+
+		It("extracts a gzip compressed tar archive", func() {
+			fakeObjectStorage.PutObject(bucket, "source.tar.gz", tarball(true, map[string]string{"src/main.go": "package main"}), "", lastModified)
+
+			details, err := client.DownloadObject(context.TODO(), bucket, "source.tar.gz", "", target)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(details.MostRecentFileTimestamp).ToNot(BeNil())
+			Expect(*details.MostRecentFileTimestamp).To(BeTemporally("==", lastModified))
+			Expect(os.ReadFile(filepath.Join(target, "src", "main.go"))).To(Equal([]byte("package main")))
+		})
+
+		It("downloads the requested version of an object", func() {
+			fakeObjectStorage.PutObject(bucket, "data.csv", []byte("v1"), "version-1", lastModified)
+			fakeObjectStorage.PutObject(bucket, "data.csv", []byte("v2"), "version-2", lastModified.Add(time.Hour))
+
+			details, err := client.DownloadObject(context.TODO(), bucket, "data.csv", "version-1", target)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(details.VersionID).To(Equal("version-1"))
+			Expect(os.ReadFile(filepath.Join(target, "data.csv"))).To(Equal([]byte("v1")))
+		})
+
+		It("fails for an object that does not exist", func() {
+			fakeObjectStorage.PutObject(bucket, "data.csv", []byte("content"), "", lastModified)
+
+			_, err := client.DownloadObject(context.TODO(), bucket, "missing.csv", "", target)
+			Expect(err).To(MatchError(ContainSubstring("NoSuchKey")))
+		})
+
+		It("fails with wrong credentials", func() {
+			fakeObjectStorage.PutObject(bucket, "data.csv", []byte("content"), "", lastModified)
+
+			client, err := objectstorage.NewClient(fakeObjectStorage.URL, "", "someone", "secret")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.DownloadObject(context.TODO(), bucket, "data.csv", "", target)
+			Expect(err).To(MatchError(ContainSubstring("unexpected status code 403")))
+		})
+	})
+
+	Context("downloading a prefix", func() {
+		It("stores all objects using the remainder of the key as relative path", func() {
+			fakeObjectStorage.MaxKeys = 1
+			fakeObjectStorage.PutObject(bucket, "builds/42/main.go", []byte("package main"), "", lastModified)
+			fakeObjectStorage.PutObject(bucket, "builds/42/pkg/util.go", []byte("package pkg"), "", lastModified.Add(time.Hour))
+			fakeObjectStorage.PutObject(bucket, "builds/43/main.go", []byte("package other"), "", lastModified)
+
+			details, err := client.DownloadPrefix(context.TODO(), bucket, "builds/42/", target)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(details.Digest).To(HavePrefix("sha256:"))
+			Expect(details.DirHash).To(HavePrefix("h1:"))
+			Expect(details.MostRecentFileTimestamp).ToNot(BeNil())
+			Expect(*details.MostRecentFileTimestamp).To(BeTemporally("==", lastModified.Add(time.Hour)))
+
+			Expect(os.ReadFile(filepath.Join(target, "main.go"))).To(Equal([]byte("package main")))
+			Expect(os.ReadFile(filepath.Join(target, "pkg", "util.go"))).To(Equal([]byte("package pkg")))
+		})
+
+		It("computes a digest that changes when an object changes", func() {
+			fakeObjectStorage.PutObject(bucket, "data/a.txt", []byte("a"), "", lastModified)
+
+			first, err := client.DownloadPrefix(context.TODO(), bucket, "data/", target)
+			Expect(err).ToNot(HaveOccurred())
+
+			fakeObjectStorage.PutObject(bucket, "data/a.txt", []byte("b"), "", lastModified)
+
+			second, err := client.DownloadPrefix(context.TODO(), bucket, "data/", target)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(second.Digest).ToNot(Equal(first.Digest))
+		})
+
+		It("fails when no object has the prefix", func() {
+			fakeObjectStorage.PutObject(bucket, "data/a.txt", []byte("a"), "", lastModified)
+
+			_, err := client.DownloadPrefix(context.TODO(), bucket, "other/", target)
+			Expect(err).To(MatchError(ContainSubstring("no objects found")))
+		})
+
+		It("fails for keys that point outside of the target directory", func() {
+			fakeObjectStorage.PutObject(bucket, "data/../../escape.txt", []byte("a"), "", lastModified)
+
+			_, err := client.DownloadPrefix(context.TODO(), bucket, "data/", target)
+			Expect(err).To(MatchError(ContainSubstring("outside of the target directory")))
+		})
+	})
+})
@@ -125,6 +125,20 @@ var _ = Describe("Reconcile Build", func() {
 			})
 		})
 
+		Context("when spec output signing key secret is specified", func() {
+			It("fails when the secret does not exist", func() {
+				buildSample.Spec.Output.PushSecret = nil
+				buildSample.Spec.Output.Signing = &build.SigningOptions{KeySecret: "signing-key"}
+
+				statusCall := ctl.StubFunc(corev1.ConditionFalse, build.SpecOutputSigningSecretRefNotFound, "referenced secret signing-key not found")
+				statusWriter.UpdateCalls(statusCall)
+
+				_, err := reconciler.Reconcile(context.TODO(), request)
+				Expect(err).To(BeNil())
+				Expect(statusWriter.UpdateCallCount()).To(Equal(1))
+			})
+		})
+
 		Context("when spec output registry secret is specified", func() {
 			It("fails when the secret does not exist", func() {
 
@@ -189,6 +189,20 @@ func add(ctx context.Context, mgr manager.Manager, r reconcile.Reconciler, maxCo
 				}
 			}
 
+			for _, mirror := range build.Spec.Output.Mirrors {
+				if mirror.PushSecret != nil && *mirror.PushSecret == secret.Name {
+					flagReconcile = true
+				}
+			}
+
+			if build.Spec.Output.Signing != nil && build.Spec.Output.Signing.KeySecret == secret.Name {
+				flagReconcile = true
+			}
+
+			if build.Spec.Source != nil && build.Spec.Source.Inline != nil && build.Spec.Source.Inline.SecretRef != nil && *build.Spec.Source.Inline.SecretRef == secret.Name {
+				flagReconcile = true
+			}
+
 			if flagReconcile {
 				reconcileList = append(reconcileList, reconcile.Request{
 					NamespacedName: types.NamespacedName{
@@ -161,6 +161,8 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 						validate.NewBuildName(build),
 						validate.NewEnv(build),
 						validate.NewNodeSelector(build),
+						validate.NewSourceWorkspace(build),
+						validate.NewRetry(build),
 					)
 
 					// an internal/technical error during validation happened
@@ -203,6 +205,17 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 				return reconcile.Result{}, nil
 			}
 
+			// Validate the output of the Build together with the overrides of the BuildRun
+			if reason, message := validate.BuildRunOutput(build, buildRun); reason != "" {
+				return reconcile.Result{}, resources.UpdateConditionWithFalseStatus(
+					ctx,
+					r.client,
+					buildRun,
+					message,
+					reason,
+				)
+			}
+
 			// Ensure the build-related labels on the BuildRun
 			if buildRun.GetLabels() == nil {
 				buildRun.Labels = make(map[string]string)
@@ -216,6 +229,12 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 				return reconcile.Result{}, nil
 			}
 
+			// wait for the backoff of a retried BuildRun before the TaskRun of the next attempt is created
+			if remaining := resources.GetRemainingRetryBackoff(buildRun); remaining > 0 {
+				ctxlog.Info(ctx, "waiting for the retry backoff of the BuildRun", namespace, request.Namespace, name, request.Name, "backoff", remaining.String())
+				return reconcile.Result{RequeueAfter: remaining}, nil
+			}
+
 			// Set OwnerReference for Build and BuildRun only when build retention AtBuildDeletion is set to "true"
 			if build.Spec.Retention != nil && build.Spec.Retention.AtBuildDeletion != nil {
 				if *build.Spec.Retention.AtBuildDeletion && !resources.IsOwnedByBuild(build, buildRun.OwnerReferences) {
@@ -291,6 +310,14 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 				return reconcile.Result{}, nil
 			}
 
+			// Create the PersistentVolumeClaim for the source files if the source workspace uses a claim template
+			if err := resources.CreateSourceWorkspaceClaim(ctx, r.client, build, buildRun); err != nil {
+				if !resources.IsClientStatusUpdateError(err) && buildRun.Status.IsFailed(buildv1beta1.Succeeded) {
+					return reconcile.Result{}, nil
+				}
+				return reconcile.Result{}, err
+			}
+
 			// Create the TaskRun, this needs to be the last step in this block to be idempotent
 			generatedTaskRun, err := r.createTaskRun(ctx, svcAccount, strategy, build, buildRun)
 			if err != nil {
@@ -334,22 +361,25 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 				ctxlog.Error(ctx, err, "Failed to update BuildRun status is ignored", namespace, request.Namespace, name, request.Name)
 			}
 
-			// Increase BuildRun count in metrics
-			buildmetrics.BuildRunCountInc(
-				buildRun.Status.BuildSpec.StrategyName(),
-				buildRun.Namespace,
-				buildRun.Spec.BuildName(),
-				buildRun.Name,
-			)
+			// Only the first attempt of a BuildRun is counted in metrics, retries are not new BuildRuns
+			if len(buildRun.Status.Attempts) == 0 {
+				// Increase BuildRun count in metrics
+				buildmetrics.BuildRunCountInc(
+					buildRun.Status.BuildSpec.StrategyName(),
+					buildRun.Namespace,
+					buildRun.Spec.BuildName(),
+					buildRun.Name,
+				)
 
-			// Report buildrun ramp-up duration (time between buildrun creation and taskrun creation)
-			buildmetrics.BuildRunRampUpDurationObserve(
-				buildRun.Status.BuildSpec.StrategyName(),
-				buildRun.Namespace,
-				buildRun.Spec.BuildName(),
-				buildRun.Name,
-				generatedTaskRun.CreationTimestamp.Time.Sub(buildRun.CreationTimestamp.Time),
-			)
+				// Report buildrun ramp-up duration (time between buildrun creation and taskrun creation)
+				buildmetrics.BuildRunRampUpDurationObserve(
+					buildRun.Status.BuildSpec.StrategyName(),
+					buildRun.Namespace,
+					buildRun.Spec.BuildName(),
+					buildRun.Name,
+					generatedTaskRun.CreationTimestamp.Time.Sub(buildRun.CreationTimestamp.Time),
+				)
+			}
 		} else {
 			return reconcile.Result{}, getTaskRunErr
 		}
@@ -369,6 +399,12 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 			}
 		}
 
+		// A TaskRun of an attempt that was already retried does not change the BuildRun anymore
+		if resources.IsPreviousAttempt(buildRun, lastTaskRun.Name) {
+			ctxlog.Info(ctx, "taskRun belongs to a previous attempt of the buildRun", namespace, request.Namespace, name, request.Name)
+			return reconcile.Result{}, nil
+		}
+
 		if buildRun.IsCanceled() && !lastTaskRun.IsCancelled() {
 			ctxlog.Info(ctx, "buildRun marked for cancellation, patching task run", namespace, request.Namespace, name, request.Name)
 			// patch tekton taskrun a la tkn to start tekton's cancelling logic
@@ -390,6 +426,11 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 		if len(lastTaskRun.Status.Results) > 0 {
 			ctxlog.Info(ctx, "surfacing taskRun results to BuildRun status", namespace, request.Namespace, name, request.Name)
 			resources.UpdateBuildRunUsingTaskResults(ctx, buildRun, lastTaskRun.Status.Results, request)
+
+			if err := resources.CreateVulnerabilityReportConfigMap(ctx, r.client, buildRun, lastTaskRun.Status.Results); err != nil {
+				ctxlog.Error(ctx, err, "Error during creation of the vulnerability report configMap.")
+				return reconcile.Result{}, err
+			}
 		}
 
 		trCondition := lastTaskRun.Status.GetCondition(apis.ConditionSucceeded)
@@ -401,12 +442,33 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 			resources.UpdateBuildRunUsingTaskFailures(ctx, r.client, buildRun, lastTaskRun)
 			taskRunStatus := trCondition.Status
 
-			// check if we should delete the generated service account by checking the build run spec and that the task run is complete
+			// retry a failed BuildRun with a new TaskRun, the generated service account is kept for the
+			// next attempt, which gets a new persistent volume claim or directory for its source files
+			if taskRunStatus == corev1.ConditionFalse && resources.RetryBuildRun(buildRun, lastTaskRun) {
+				ctxlog.Info(ctx, "retrying buildRun with a new taskRun", namespace, request.Namespace, name, request.Name, "attempt", len(buildRun.Status.Attempts)+1)
+				if err := resources.DeleteRetriedSourceWorkspaceClaim(ctx, r.client, buildRun); err != nil {
+					ctxlog.Error(ctx, err, "Error during deletion of generated persistent volume claim.")
+					return reconcile.Result{}, err
+				}
+
+				if err := r.client.Status().Update(ctx, buildRun); err != nil {
+					return reconcile.Result{}, err
+				}
+
+				return reconcile.Result{}, nil
+			}
+
+			// check if we should delete the generated service account and persistent volume claim by checking the build run spec and that the task run is complete
 			if taskRunStatus == corev1.ConditionTrue || taskRunStatus == corev1.ConditionFalse {
 				if err := resources.DeleteServiceAccount(ctx, r.client, buildRun); err != nil {
 					ctxlog.Error(ctx, err, "Error during deletion of generated service account.")
 					return reconcile.Result{}, err
 				}
+
+				if err := resources.DeleteSourceWorkspaceClaim(ctx, r.client, buildRun, taskRunStatus == corev1.ConditionTrue); err != nil {
+					ctxlog.Error(ctx, err, "Error during deletion of generated persistent volume claim.")
+					return reconcile.Result{}, err
+				}
 			}
 
 			buildRun.Status.TaskRunName = &lastTaskRun.Name
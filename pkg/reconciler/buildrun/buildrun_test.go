@@ -435,6 +435,112 @@ var _ = Describe("Reconcile BuildRun", func() {
 				Expect(reconcile.Result{}).To(Equal(result))
 			})
 
+			It("retries the BuildRun when the TaskRun failed for a retryable reason", func() {
+				taskRunSample = ctl.DefaultTaskRunWithStatus(taskRunName, buildRunName, ns, corev1.ConditionFalse, "TaskRunImagePullFailed")
+				buildRunSample.Spec.Retry = &build.Retry{MaxAttempts: 2}
+
+				var updatedBuildRun *build.BuildRun
+				statusWriter.UpdateCalls(func(_ context.Context, object crc.Object, _ ...crc.SubResourceUpdateOption) error {
+					if buildRun, ok := object.(*build.BuildRun); ok {
+						updatedBuildRun = buildRun.DeepCopy()
+					}
+					return nil
+				})
+
+				result, err := reconciler.Reconcile(context.TODO(), taskRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reconcile.Result{}).To(Equal(result))
+
+				Expect(updatedBuildRun).ToNot(BeNil())
+				Expect(updatedBuildRun.Status.TaskRunName).To(BeNil())
+				Expect(updatedBuildRun.Status.CompletionTime).To(BeNil())
+				Expect(updatedBuildRun.Status.Attempts).To(HaveLen(1))
+				Expect(updatedBuildRun.Status.Attempts[0].TaskRunName).To(Equal(taskRunName))
+				Expect(updatedBuildRun.Status.Attempts[0].Reason).To(Equal("TaskRunImagePullFailed"))
+				Expect(updatedBuildRun.Status.GetCondition(build.Succeeded).Reason).To(Equal(build.BuildRunStateRetrying))
+
+				// the generated service account is kept for the next attempt
+				Expect(client.DeleteCallCount()).To(Equal(0))
+			})
+
+			It("deletes the generated claim of the failed attempt when it retries a BuildRun with a volume claim template", func() {
+				taskRunSample = ctl.DefaultTaskRunWithStatus(taskRunName, buildRunName, ns, corev1.ConditionFalse, "TaskRunImagePullFailed")
+				buildRunSample.UID = "buildrun-uid"
+				buildRunSample.Spec.Retry = &build.Retry{MaxAttempts: 2}
+				buildRunSample.Spec.SourceWorkspace = &build.SourceWorkspace{
+					VolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					},
+				}
+
+				client.GetCalls(func(ctx context.Context, nn types.NamespacedName, object crc.Object, getOptions ...crc.GetOption) error {
+					if claim, ok := object.(*corev1.PersistentVolumeClaim); ok && nn.Name == "foobar-buildrun-source" {
+						claim.Name = nn.Name
+						claim.Namespace = nn.Namespace
+						claim.OwnerReferences = []metav1.OwnerReference{
+							*metav1.NewControllerRef(buildRunSample, build.SchemeGroupVersion.WithKind("BuildRun")),
+						}
+						return nil
+					}
+
+					return getClientStub(ctx, nn, object, getOptions...)
+				})
+
+				var updatedBuildRun *build.BuildRun
+				statusWriter.UpdateCalls(func(_ context.Context, object crc.Object, _ ...crc.SubResourceUpdateOption) error {
+					if buildRun, ok := object.(*build.BuildRun); ok {
+						updatedBuildRun = buildRun.DeepCopy()
+					}
+					return nil
+				})
+
+				_, err := reconciler.Reconcile(context.TODO(), taskRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(updatedBuildRun).ToNot(BeNil())
+				Expect(updatedBuildRun.Status.Attempts).To(HaveLen(1))
+				Expect(resources.GetGeneratedSourceWorkspaceClaimName(updatedBuildRun)).To(Equal("foobar-buildrun-source-2"))
+
+				// the next attempt starts with a new claim, so that it clones the source into an empty directory
+				Expect(client.DeleteCallCount()).To(Equal(1))
+				_, deletedObject, _ := client.DeleteArgsForCall(0)
+				Expect(deletedObject).To(BeAssignableToTypeOf(&corev1.PersistentVolumeClaim{}))
+				Expect(deletedObject.GetName()).To(Equal("foobar-buildrun-source"))
+			})
+
+			It("fails the BuildRun when the last attempt failed", func() {
+				taskRunSample = ctl.DefaultTaskRunWithStatus(taskRunName, buildRunName, ns, corev1.ConditionFalse, "TaskRunImagePullFailed")
+				buildRunSample.Spec.Retry = &build.Retry{MaxAttempts: 2}
+				buildRunSample.Status.Attempts = []build.BuildRunAttempt{{TaskRunName: "foobar-buildrun-x7k2q", Reason: "TaskRunImagePullFailed"}}
+
+				var updatedBuildRun *build.BuildRun
+				statusWriter.UpdateCalls(func(_ context.Context, object crc.Object, _ ...crc.SubResourceUpdateOption) error {
+					if buildRun, ok := object.(*build.BuildRun); ok {
+						updatedBuildRun = buildRun.DeepCopy()
+					}
+					return nil
+				})
+
+				_, err := reconciler.Reconcile(context.TODO(), taskRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(updatedBuildRun).ToNot(BeNil())
+				Expect(updatedBuildRun.Status.Attempts).To(HaveLen(1))
+				Expect(updatedBuildRun.Status.GetCondition(build.Succeeded).Status).To(Equal(corev1.ConditionFalse))
+				Expect(updatedBuildRun.Status.GetCondition(build.Succeeded).Reason).To(Equal("TaskRunImagePullFailed"))
+			})
+
+			It("ignores the TaskRun of a previous attempt", func() {
+				taskRunSample = ctl.DefaultTaskRunWithStatus(taskRunName, buildRunName, ns, corev1.ConditionFalse, "TaskRunImagePullFailed")
+				buildRunSample.Spec.Retry = &build.Retry{MaxAttempts: 3}
+				buildRunSample.Status.Attempts = []build.BuildRunAttempt{{TaskRunName: taskRunName, Reason: "TaskRunImagePullFailed"}}
+
+				result, err := reconciler.Reconcile(context.TODO(), taskRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reconcile.Result{}).To(Equal(result))
+				Expect(statusWriter.UpdateCallCount()).To(Equal(0))
+			})
+
 			It("does not break the reconcile when a taskrun pod initcontainers are not ready", func() {
 				taskRunSample = ctl.TaskRunWithCompletionAndStartTime(taskRunName, buildRunName, ns)
 
@@ -1012,6 +1118,110 @@ var _ = Describe("Reconcile BuildRun", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
+			It("delays the TaskRun of a retried BuildRun until the backoff passed", func() {
+				buildSample = ctl.DefaultBuild(buildName, strategyName, build.ClusterBuildStrategyKind)
+				buildRunSample.Spec.Retry = &build.Retry{MaxAttempts: 3, Backoff: &metav1.Duration{Duration: time.Minute}}
+				buildRunSample.Status.Attempts = []build.BuildRunAttempt{{
+					TaskRunName:    taskRunName,
+					Reason:         build.BuildRunStatePodEvicted,
+					CompletionTime: &metav1.Time{Time: time.Now()},
+				}}
+
+				client.GetCalls(ctl.StubBuildRunGetWithSAandStrategies(
+					buildSample,
+					buildRunSample,
+					ctl.DefaultServiceAccount(saName),
+					ctl.DefaultClusterBuildStrategy(),
+					ctl.DefaultNamespacedBuildStrategy()),
+				)
+
+				result, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically("~", time.Minute, 5*time.Second))
+				Expect(client.CreateCallCount()).To(Equal(0))
+			})
+
+			It("creates a new TaskRun for a retried BuildRun once the backoff passed", func() {
+				buildSample = ctl.DefaultBuild(buildName, strategyName, build.ClusterBuildStrategyKind)
+				buildRunSample.Spec.Retry = &build.Retry{MaxAttempts: 3, Backoff: &metav1.Duration{Duration: time.Minute}}
+				buildRunSample.Status.Attempts = []build.BuildRunAttempt{{
+					TaskRunName:    taskRunName,
+					Reason:         build.BuildRunStatePodEvicted,
+					CompletionTime: &metav1.Time{Time: time.Now().Add(-2 * time.Minute)},
+				}}
+
+				client.GetCalls(ctl.StubBuildRunGetWithSAandStrategies(
+					buildSample,
+					buildRunSample,
+					ctl.DefaultServiceAccount(saName),
+					ctl.DefaultClusterBuildStrategy(),
+					ctl.DefaultNamespacedBuildStrategy()),
+				)
+
+				client.CreateCalls(func(_ context.Context, object crc.Object, _ ...crc.CreateOption) error {
+					switch object := object.(type) {
+					case *pipelineapi.TaskRun:
+						ctl.DefaultTaskRunWithStatus("foobar-buildrun-x7k2q", buildRunName, ns, corev1.ConditionUnknown, "Pending").DeepCopyInto(object)
+					}
+					return nil
+				})
+
+				result, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reconcile.Result{}).To(Equal(result))
+				Expect(client.CreateCallCount()).To(Equal(1))
+			})
+
+			It("creates a new claim for a retried BuildRun with a volume claim template", func() {
+				buildSample = ctl.DefaultBuild(buildName, strategyName, build.ClusterBuildStrategyKind)
+				buildSample.Spec.SourceWorkspace = &build.SourceWorkspace{
+					VolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					},
+				}
+				buildRunSample.Spec.Retry = &build.Retry{MaxAttempts: 3}
+				buildRunSample.Status.Attempts = []build.BuildRunAttempt{{
+					TaskRunName:    taskRunName,
+					Reason:         "GitError",
+					CompletionTime: &metav1.Time{Time: time.Now().Add(-time.Minute)},
+				}}
+
+				client.GetCalls(ctl.StubBuildRunGetWithSAandStrategies(
+					buildSample,
+					buildRunSample,
+					ctl.DefaultServiceAccount(saName),
+					ctl.DefaultClusterBuildStrategy(),
+					ctl.DefaultNamespacedBuildStrategy()),
+				)
+
+				var createdClaim *corev1.PersistentVolumeClaim
+				var createdTaskRun *pipelineapi.TaskRun
+				client.CreateCalls(func(_ context.Context, object crc.Object, _ ...crc.CreateOption) error {
+					switch object := object.(type) {
+					case *corev1.PersistentVolumeClaim:
+						createdClaim = object.DeepCopy()
+					case *pipelineapi.TaskRun:
+						createdTaskRun = object.DeepCopy()
+						ctl.DefaultTaskRunWithStatus("foobar-buildrun-x7k2q", buildRunName, ns, corev1.ConditionUnknown, "Pending").DeepCopyInto(object)
+					}
+					return nil
+				})
+
+				_, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(createdClaim).ToNot(BeNil())
+				Expect(createdClaim.Name).To(Equal("foobar-buildrun-source-2"))
+
+				Expect(createdTaskRun).ToNot(BeNil())
+				Expect(createdTaskRun.Spec.Workspaces).To(ContainElement(pipelineapi.WorkspaceBinding{
+					Name: "source",
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: "foobar-buildrun-source-2",
+					},
+				}))
+			})
+
 			It("stops creation when a FALSE registered status of the build occurs", func() {
 				// Init the Build with registered status false
 				buildSample = ctl.DefaultBuildWithFalseRegistered(buildName, strategyName, build.ClusterBuildStrategyKind)
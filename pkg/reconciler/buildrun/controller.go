@@ -59,9 +59,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler, maxConcurrentReconciles in
 		UpdateFunc: func(e event.TypedUpdateEvent[*buildv1beta1.BuildRun]) bool {
 			// Only reconcile a BuildRun update when
 			// - it is set to canceled
+			// - a failed attempt is retried
 			switch {
 			case !e.ObjectOld.IsCanceled() && e.ObjectNew.IsCanceled():
 				return true
+			case len(e.ObjectNew.Status.Attempts) > len(e.ObjectOld.Status.Attempts):
+				return true
 			}
 
 			return false
@@ -44,6 +44,12 @@ const (
 	BuildRunNoRefOrSpec                              string = "BuildRunNoRefOrSpec"
 	BuildRunAmbiguousBuild                           string = "BuildRunAmbiguousBuild"
 	BuildRunBuildFieldOverrideForbidden              string = "BuildRunBuildFieldOverrideForbidden"
+	BuildRunAdditionalLocalSourcesNotValid           string = "BuildRunAdditionalLocalSourcesNotValid"
+	BuildRunSourceWorkspaceNotValid                  string = "BuildRunSourceWorkspaceNotValid"
+	BuildRunSourceWorkspaceClaimConflict             string = "BuildRunSourceWorkspaceClaimConflict"
+	BuildRunRetryNotValid                            string = "BuildRunRetryNotValid"
+	BuildRunOutputImageConfigNotValid                string = "BuildRunOutputImageConfigNotValid"
+	BuildRunOutputExportNotValid                     string = "BuildRunOutputExportNotValid"
 )
 
 // UpdateBuildRunUsingTaskRunCondition updates the BuildRun Succeeded Condition
@@ -133,6 +139,27 @@ func UpdateBuildRunUsingTaskRunCondition(ctx context.Context, client client.Clie
 							pod.Name,
 							failedContainer.Name,
 						)
+					} else if failedContainer.Name == "step-image-processing" && failedContainerStatus.State.Terminated.ExitCode == 23 {
+						reason = buildv1beta1.BuildRunStateVulnerabilityScanFailed
+						message = fmt.Sprintf("The vulnerability scanner failed to scan the image. For detailed information, see kubectl --namespace %s logs %s --container=%s",
+							pod.Namespace,
+							pod.Name,
+							failedContainer.Name,
+						)
+					} else if failedContainer.Name == "step-image-processing" && failedContainerStatus.State.Terminated.ExitCode == 24 {
+						reason = buildv1beta1.BuildRunStateLicenseViolation
+						message = fmt.Sprintf("Packages with licenses that are not allowed by the license policy have been found in the image which can be seen in the buildrun status. For detailed information, see kubectl --namespace %s logs %s --container=%s",
+							pod.Namespace,
+							pod.Name,
+							failedContainer.Name,
+						)
+					} else if failedContainer.Name == "step-image-processing" && failedContainerStatus.State.Terminated.ExitCode == 25 {
+						reason = buildv1beta1.BuildRunStateImagePushFailed
+						message = fmt.Sprintf("The image could not be pushed to the registry. For detailed information, see kubectl --namespace %s logs %s --container=%s",
+							pod.Namespace,
+							pod.Name,
+							failedContainer.Name,
+						)
 					}
 				}
 			} else {
@@ -7,10 +7,12 @@ package resources
 import (
 	"encoding/json"
 	"fmt"
+	"path"
 	"strconv"
 	"time"
 
 	core "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
 
 	build "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
 	"github.com/shipwright-io/build/pkg/config"
@@ -22,6 +24,10 @@ import (
 const (
 	containerNameImageProcessing = "image-processing"
 	outputDirectoryMountPath     = "/workspace/output-image"
+	vulnerabilityDBMountPath     = "/workspace/vulnerability-db"
+	vexMountPath                 = "/workspace/vex"
+	exportMountPath              = "/workspace/shp-export"
+	vexFileName                  = "openvex.json"
 	paramOutputDirectory         = "output-directory"
 )
 
@@ -47,6 +53,58 @@ func (v *VulnerablilityScanParams) Type() string {
 	return "vulnerability-scan-params"
 }
 
+// LicensePolicyParams is the license policy argument of the image-processing step
+type LicensePolicyParams struct {
+	build.LicensePolicy
+}
+
+var _ pflag.Value = &LicensePolicyParams{}
+
+func (l *LicensePolicyParams) Set(s string) error {
+	return json.Unmarshal([]byte(s), l)
+}
+
+func (l *LicensePolicyParams) String() string {
+	data, err := json.Marshal(*l)
+	if err != nil {
+		panic(err.Error())
+	}
+	return string(data)
+}
+
+func (l *LicensePolicyParams) Type() string {
+	return "license-policy-params"
+}
+
+// ImageConfigParams is the image configuration argument of the image-processing step, the configuration
+// is nil if the argument is not set
+type ImageConfigParams struct {
+	*build.ImageConfig
+}
+
+var _ pflag.Value = &ImageConfigParams{}
+
+func (i *ImageConfigParams) Set(s string) error {
+	i.ImageConfig = &build.ImageConfig{}
+	return json.Unmarshal([]byte(s), i.ImageConfig)
+}
+
+func (i *ImageConfigParams) String() string {
+	if i.ImageConfig == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(i.ImageConfig)
+	if err != nil {
+		panic(err.Error())
+	}
+	return string(data)
+}
+
+func (i *ImageConfigParams) Type() string {
+	return "image-config-params"
+}
+
 // SetupImageProcessing appends the image-processing step to a TaskRun if desired
 func SetupImageProcessing(taskRun *pipelineapi.TaskRun, cfg *config.Config, creationTimestamp time.Time, buildOutput, buildRunOutput build.Image) error {
 	stepArgs := []string{}
@@ -109,16 +167,160 @@ func SetupImageProcessing(taskRun *pipelineapi.TaskRun, cfg *config.Config, crea
 		stepArgs = append(stepArgs, convertMutateArgs("--label", labels)...)
 	}
 
+	// check if we need to set image index annotations
+	indexAnnotations := mergeMaps(buildOutput.IndexAnnotations, buildRunOutput.IndexAnnotations)
+	if len(indexAnnotations) > 0 {
+		stepArgs = append(stepArgs, convertMutateArgs("--index-annotation", indexAnnotations)...)
+	}
+
+	// check if we need to remove platforms from the image index
+	for _, platform := range getPlatforms(buildOutput, buildRunOutput) {
+		stepArgs = append(stepArgs, "--platform", platform)
+	}
+
+	// check if we need to convert the layers
+	if layerFormat := getLayerFormat(buildOutput, buildRunOutput); layerFormat != nil {
+		stepArgs = append(stepArgs, "--layer-format", string(*layerFormat))
+	}
+
+	// check if we need to write the image to a volume instead of pushing it, this is only possible if
+	// image-processing pushes the image, because the build strategy would push it to the registry
+	export := getExport(buildOutput, buildRunOutput)
+	if export != nil {
+		if !volumeAdded {
+			return fmt.Errorf("cannot export the image, because the build strategy pushes the image")
+		}
+
+		stepArgs = append(stepArgs,
+			"--export-format", string(export.Format),
+			"--export-directory", path.Join(exportMountPath, export.Path),
+		)
+	}
+
+	// check if we need to add the standard OCI annotations, their values are added by SetupOCIAnnotations
+	if isOCIAnnotationsEnabled(cfg, buildOutput, buildRunOutput) {
+		stepArgs = append(stepArgs, "--oci-annotations")
+	}
+
+	// check if we need to change the image configuration
+	if imageConfig := getImageConfig(buildOutput, buildRunOutput); imageConfig != nil {
+		imageConfigParams := &ImageConfigParams{imageConfig}
+		stepArgs = append(stepArgs, "--image-config", imageConfigParams.String())
+	}
+
+	// check if we need to add tags or copy the image to mirrors
+	additionalTags := getAdditionalTags(buildOutput, buildRunOutput)
+	for _, tag := range additionalTags {
+		stepArgs = append(stepArgs, "--additional-tag", tag)
+	}
+
+	mirrors := getMirrors(buildOutput, buildRunOutput)
+	for i, mirror := range mirrors {
+		if mirror.PushSecret != nil {
+			stepArgs = append(stepArgs, "--mirror", fmt.Sprintf("%s=%s", mirror.Image, getMirrorSecretMountPath(i)))
+		} else {
+			stepArgs = append(stepArgs, "--mirror", mirror.Image)
+		}
+	}
+
+	// check if we need to resolve placeholders that depend on the source, only the additional tags and the
+	// mirrors can contain them
+	templates := append([]string{}, additionalTags...)
+	for _, mirror := range mirrors {
+		templates = append(templates, mirror.Image)
+	}
+
+	sourcePlaceholders := getSourcePlaceholders(templates...)
+	if len(sourcePlaceholders) > 0 {
+		templateValueArgs, err := getTemplateValueArgs(taskRun, sourcePlaceholders)
+		if err != nil {
+			return err
+		}
+		stepArgs = append(stepArgs, templateValueArgs...)
+	}
+
+	if len(additionalTags) > 0 || len(mirrors) > 0 || len(sourcePlaceholders) > 0 {
+		stepArgs = append(stepArgs, "--result-file-image-references", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imageReferencesResult))
+	}
+
 	vulnerabilitySettings := GetVulnerabilityScanOptions(buildOutput, buildRunOutput)
 
 	// check if we need to add vulnerability scan arguments
 	if vulnerabilitySettings != nil && vulnerabilitySettings.Enabled {
 		vulnerablilityScanParams := &VulnerablilityScanParams{*vulnerabilitySettings}
+
+		// use the default scanner of the controller configuration if the build does not define one
+		if vulnerablilityScanParams.Scanner == nil && cfg.VulnerabilityScanner != "" {
+			vulnerablilityScanParams.Scanner = ptr.To(build.VulnerabilityScanner(cfg.VulnerabilityScanner))
+		}
+
 		stepArgs = append(stepArgs, "--vuln-settings", vulnerablilityScanParams.String())
 
 		if cfg.VulnerabilityCountLimit > 0 {
 			stepArgs = append(stepArgs, "--vuln-count-limit", strconv.Itoa(cfg.VulnerabilityCountLimit))
 		}
+
+		// use a pre-populated vulnerability database if one is configured
+		stepArgs = append(stepArgs, getVulnerabilityDBArgs(cfg.VulnerabilityDatabase)...)
+
+		// add the result arguments for the summary and the complete report
+		stepArgs = append(stepArgs,
+			"--result-file-image-vulnerability-summary", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imageVulnerabilitySummary),
+			"--result-file-image-vulnerability-report-digest", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imageVulnerabilityReportDigest),
+			"--result-file-image-vulnerability-report", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imageVulnerabilityReport),
+			"--result-file-image-vulnerability-report-omitted", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imageVulnerabilityReportOmitted),
+		)
+
+		if cfg.VulnerabilityReportMaxResultSize > 0 {
+			stepArgs = append(stepArgs, "--vuln-report-max-result-size", strconv.Itoa(cfg.VulnerabilityReportMaxResultSize))
+		}
+
+		// use the VEX documents to suppress vulnerabilities that do not affect the image
+		vexArgs, err := getVEXArgs(vulnerabilitySettings.Ignore)
+		if err != nil {
+			return err
+		}
+		stepArgs = append(stepArgs, vexArgs...)
+	}
+
+	// check if we need to scan the licenses of the image
+	if licensePolicy := getLicensePolicy(buildOutput, buildRunOutput); licensePolicy != nil {
+		licensePolicyParams := &LicensePolicyParams{*licensePolicy}
+
+		stepArgs = append(stepArgs,
+			"--license-policy", licensePolicyParams.String(),
+			"--result-file-image-license-violations", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imageLicenseViolations),
+			"--result-file-image-truncated-results", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imageTruncatedResults),
+		)
+
+		// the license violations are limited like the vulnerabilities, the limit was already added for a vulnerability scan
+		if cfg.VulnerabilityCountLimit > 0 && (vulnerabilitySettings == nil || !vulnerabilitySettings.Enabled) {
+			stepArgs = append(stepArgs, "--vuln-count-limit", strconv.Itoa(cfg.VulnerabilityCountLimit))
+		}
+	}
+
+	// check if we need to generate a software bill of materials
+	if sbomOptions := getSBOMOptions(buildOutput, buildRunOutput); sbomOptions != nil {
+		stepArgs = append(stepArgs,
+			"--sbom-format", string(sbomOptions.Format),
+			"--result-file-image-sbom-digest", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imageSBOMDigest),
+		)
+	}
+
+	// check if we need to sign the image
+	signingOptions := getSigningOptions(buildOutput, buildRunOutput)
+	signingKeyMountPath := fmt.Sprintf("/workspace/%s-signing-key", prefixParamsResultsVolumes)
+	if signingOptions != nil {
+		stepArgs = append(stepArgs, "--signing-key-path", signingKeyMountPath)
+
+		if signingOptions.Storage != nil {
+			stepArgs = append(stepArgs, "--signature-storage", string(*signingOptions.Storage))
+		}
+	}
+
+	// check if we need to generate a provenance, the input is added by SetupProvenance
+	if provenanceOptions := getProvenanceOptions(buildOutput, buildRunOutput); provenanceOptions != nil && provenanceOptions.Enabled {
+		stepArgs = append(stepArgs, "--provenance")
 	}
 
 	// check if we need to set image timestamp
@@ -155,10 +357,22 @@ func SetupImageProcessing(taskRun *pipelineapi.TaskRun, cfg *config.Config, crea
 		// add the insecure flag
 		stepArgs = append(stepArgs, fmt.Sprintf("--insecure=$(params.%s-%s)", prefixParamsResultsVolumes, paramOutputInsecure))
 
+		// add the push settings that differ from the defaults of the step
+		if cfg.ImagePush.Retries != nil {
+			stepArgs = append(stepArgs, "--push-retries", strconv.Itoa(*cfg.ImagePush.Retries))
+		}
+		if cfg.ImagePush.RetryBackoff != nil {
+			stepArgs = append(stepArgs, "--push-retry-backoff", cfg.ImagePush.RetryBackoff.String())
+		}
+		if cfg.ImagePush.Concurrency != nil {
+			stepArgs = append(stepArgs, "--push-concurrency", strconv.Itoa(*cfg.ImagePush.Concurrency))
+		}
+
 		// add the result arguments
 		stepArgs = append(stepArgs, "--result-file-image-digest", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imageDigestResult))
 		stepArgs = append(stepArgs, "--result-file-image-size", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imageSizeResult))
 		stepArgs = append(stepArgs, "--result-file-image-vulnerabilities", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imageVulnerabilities))
+		stepArgs = append(stepArgs, "--result-file-image-platforms", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imagePlatformsResult))
 
 		// add the push step
 
@@ -201,6 +415,105 @@ func SetupImageProcessing(taskRun *pipelineapi.TaskRun, cfg *config.Config, crea
 			)
 		}
 
+		// add the CA bundles and client certificates of the registries
+		sources.AppendRegistryCertificates(cfg, taskRun.Spec.TaskSpec, &imageProcessingStep)
+
+		// add the mirrors of the registries
+		sources.AppendRegistryMirrors(cfg, &imageProcessingStep)
+
+		for i, mirror := range mirrors {
+			if mirror.PushSecret == nil {
+				continue
+			}
+
+			sources.AppendSecretVolume(taskRun.Spec.TaskSpec, *mirror.PushSecret)
+
+			// define the volume mount on the container
+			imageProcessingStep.VolumeMounts = append(imageProcessingStep.VolumeMounts, core.VolumeMount{
+				Name:      sources.SanitizeVolumeNameForSecretName(*mirror.PushSecret),
+				MountPath: getMirrorSecretMountPath(i),
+				ReadOnly:  true,
+			})
+		}
+
+		if export != nil {
+			volumeName := fmt.Sprintf("%s-export", prefixParamsResultsVolumes)
+
+			taskRun.Spec.TaskSpec.Volumes = append(taskRun.Spec.TaskSpec.Volumes, core.Volume{
+				Name: volumeName,
+				VolumeSource: core.VolumeSource{
+					PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{
+						ClaimName: export.PersistentVolumeClaim,
+					},
+				},
+			})
+
+			// define the volume mount on the container
+			imageProcessingStep.VolumeMounts = append(imageProcessingStep.VolumeMounts, core.VolumeMount{
+				Name:      volumeName,
+				MountPath: exportMountPath,
+			})
+		}
+
+		if vulnerabilitySettings != nil && vulnerabilitySettings.Enabled && cfg.VulnerabilityDatabase.VolumeClaim != "" {
+			volumeName := fmt.Sprintf("%s-vulnerability-db", prefixParamsResultsVolumes)
+
+			taskRun.Spec.TaskSpec.Volumes = append(taskRun.Spec.TaskSpec.Volumes, core.Volume{
+				Name: volumeName,
+				VolumeSource: core.VolumeSource{
+					PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{
+						ClaimName: cfg.VulnerabilityDatabase.VolumeClaim,
+						ReadOnly:  true,
+					},
+				},
+			})
+
+			// define the volume mount on the container
+			imageProcessingStep.VolumeMounts = append(imageProcessingStep.VolumeMounts, core.VolumeMount{
+				Name:      volumeName,
+				MountPath: vulnerabilityDBMountPath,
+				ReadOnly:  true,
+			})
+		}
+
+		if vulnerabilitySettings != nil && vulnerabilitySettings.Enabled && vulnerabilitySettings.Ignore != nil {
+			for i, document := range vulnerabilitySettings.Ignore.VEX {
+				if document.ConfigMap == nil {
+					continue
+				}
+
+				volumeName := fmt.Sprintf("%s-vex-%d", prefixParamsResultsVolumes, i)
+
+				taskRun.Spec.TaskSpec.Volumes = append(taskRun.Spec.TaskSpec.Volumes, core.Volume{
+					Name: volumeName,
+					VolumeSource: core.VolumeSource{
+						ConfigMap: &core.ConfigMapVolumeSource{
+							LocalObjectReference: core.LocalObjectReference{Name: document.ConfigMap.Name},
+							Items:                []core.KeyToPath{{Key: document.ConfigMap.Key, Path: vexFileName}},
+						},
+					},
+				})
+
+				// define the volume mount on the container
+				imageProcessingStep.VolumeMounts = append(imageProcessingStep.VolumeMounts, core.VolumeMount{
+					Name:      volumeName,
+					MountPath: fmt.Sprintf("%s/%d", vexMountPath, i),
+					ReadOnly:  true,
+				})
+			}
+		}
+
+		if signingOptions != nil {
+			sources.AppendSecretVolume(taskRun.Spec.TaskSpec, signingOptions.KeySecret)
+
+			// define the volume mount on the container
+			imageProcessingStep.VolumeMounts = append(imageProcessingStep.VolumeMounts, core.VolumeMount{
+				Name:      sources.SanitizeVolumeNameForSecretName(signingOptions.KeySecret),
+				MountPath: signingKeyMountPath,
+				ReadOnly:  true,
+			})
+		}
+
 		// append the mutate step
 		taskRun.Spec.TaskSpec.Steps = append(taskRun.Spec.TaskSpec.Steps, imageProcessingStep)
 	}
@@ -242,6 +555,163 @@ func GetVulnerabilityScanOptions(buildOutput, buildRunOutput build.Image) *build
 	}
 }
 
+func getAdditionalTags(buildOutput, buildRunOutput build.Image) []string {
+	if len(buildRunOutput.AdditionalTags) > 0 {
+		return buildRunOutput.AdditionalTags
+	}
+
+	return buildOutput.AdditionalTags
+}
+
+func getMirrors(buildOutput, buildRunOutput build.Image) []build.ImageMirror {
+	if len(buildRunOutput.Mirrors) > 0 {
+		return buildRunOutput.Mirrors
+	}
+
+	return buildOutput.Mirrors
+}
+
+// getMirrorSecretMountPath returns where the push secret of a mirror is mounted, mirrors are identified by their index
+func getMirrorSecretMountPath(i int) string {
+	return fmt.Sprintf("/workspace/%s-mirror-secret-%d", prefixParamsResultsVolumes, i)
+}
+
+func getLicensePolicy(buildOutput, buildRunOutput build.Image) *build.LicensePolicy {
+	switch {
+	case buildRunOutput.LicensePolicy != nil:
+		return buildRunOutput.LicensePolicy
+	case buildOutput.LicensePolicy != nil:
+		return buildOutput.LicensePolicy
+	default:
+		return nil
+	}
+}
+
+func getExport(buildOutput, buildRunOutput build.Image) *build.ImageExport {
+	switch {
+	case buildRunOutput.Export != nil:
+		return buildRunOutput.Export
+	case buildOutput.Export != nil:
+		return buildOutput.Export
+	default:
+		return nil
+	}
+}
+
+func getLayerFormat(buildOutput, buildRunOutput build.Image) *build.LayerFormat {
+	if buildRunOutput.LayerFormat != nil {
+		return buildRunOutput.LayerFormat
+	}
+
+	return buildOutput.LayerFormat
+}
+
+func getPlatforms(buildOutput, buildRunOutput build.Image) []string {
+	if len(buildRunOutput.Platforms) > 0 {
+		return buildRunOutput.Platforms
+	}
+
+	return buildOutput.Platforms
+}
+
+func isOCIAnnotationsEnabled(cfg *config.Config, buildOutput, buildRunOutput build.Image) bool {
+	switch {
+	case buildRunOutput.OCIAnnotations != nil:
+		return *buildRunOutput.OCIAnnotations
+	case buildOutput.OCIAnnotations != nil:
+		return *buildOutput.OCIAnnotations
+	default:
+		return cfg.OCIAnnotations
+	}
+}
+
+func getImageConfig(buildOutput, buildRunOutput build.Image) *build.ImageConfig {
+	switch {
+	case buildRunOutput.Config != nil:
+		return buildRunOutput.Config
+	case buildOutput.Config != nil:
+		return buildOutput.Config
+	default:
+		return nil
+	}
+}
+
+func getSBOMOptions(buildOutput, buildRunOutput build.Image) *build.SBOMOptions {
+	switch {
+	case buildRunOutput.SBOM != nil:
+		return buildRunOutput.SBOM
+	case buildOutput.SBOM != nil:
+		return buildOutput.SBOM
+	default:
+		return nil
+	}
+}
+
+func getSigningOptions(buildOutput, buildRunOutput build.Image) *build.SigningOptions {
+	switch {
+	case buildRunOutput.Signing != nil:
+		return buildRunOutput.Signing
+	case buildOutput.Signing != nil:
+		return buildOutput.Signing
+	default:
+		return nil
+	}
+}
+
+func getVulnerabilityDBArgs(database config.VulnerabilityDatabase) []string {
+	var args []string
+	switch {
+	case database.VolumeClaim != "":
+		args = append(args, "--vuln-db-path", vulnerabilityDBMountPath)
+	case database.Image != "":
+		args = append(args, "--vuln-db-image", database.Image)
+	default:
+		return nil
+	}
+
+	if database.MaxAge != nil {
+		args = append(args, "--vuln-db-max-age", database.MaxAge.String())
+	}
+
+	return append(args, "--result-file-image-vulnerability-db-updated-at", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imageVulnerabilityDBUpdatedAt))
+}
+
+// getVEXArgs returns the arguments for the VEX documents, the documents in ConfigMaps are mounted
+// into the image-processing step by their index
+func getVEXArgs(ignoreOptions *build.VulnerabilityIgnoreOptions) ([]string, error) {
+	if ignoreOptions == nil || len(ignoreOptions.VEX) == 0 {
+		return nil, nil
+	}
+
+	var args []string
+	for i, document := range ignoreOptions.VEX {
+		switch {
+		case document.ConfigMap != nil && document.Image == nil:
+			args = append(args, "--vuln-vex-file", fmt.Sprintf("%s/%d/%s", vexMountPath, i, vexFileName))
+		case document.Image != nil && document.ConfigMap == nil:
+			args = append(args, "--vuln-vex-image", *document.Image)
+		default:
+			return nil, fmt.Errorf("the VEX document %d must reference either a ConfigMap or an image", i)
+		}
+	}
+
+	return append(args,
+		"--result-file-image-suppressed-vulnerabilities", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imageSuppressedVulnerabilities),
+		"--result-file-image-truncated-results", fmt.Sprintf("$(results.%s-%s.path)", prefixParamsResultsVolumes, imageTruncatedResults),
+	), nil
+}
+
+func getProvenanceOptions(buildOutput, buildRunOutput build.Image) *build.ProvenanceOptions {
+	switch {
+	case buildRunOutput.Provenance != nil:
+		return buildRunOutput.Provenance
+	case buildOutput.Provenance != nil:
+		return buildOutput.Provenance
+	default:
+		return nil
+	}
+}
+
 func getImageTimestamp(buildOutput, buildRunOutput build.Image) *string {
 	switch {
 	case buildRunOutput.Timestamp != nil:
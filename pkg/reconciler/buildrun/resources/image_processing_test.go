@@ -5,11 +5,15 @@
 package resources_test
 
 import (
+	"encoding/json"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
 	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
 	"github.com/shipwright-io/build/pkg/config"
 	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
@@ -91,6 +95,8 @@ var _ = Describe("Image Processing overrides", func() {
 					"$(results.shp-image-size.path)",
 					"--result-file-image-vulnerabilities",
 					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
 				}))
 				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].VolumeMounts).ToNot(utils.ContainNamedElement("shp-output-directory"))
 			})
@@ -117,6 +123,16 @@ var _ = Describe("Image Processing overrides", func() {
 					"{\"enabled\":true}",
 					"--vuln-count-limit",
 					"50",
+					"--result-file-image-vulnerability-summary",
+					"$(results.shp-image-vulnerability-summary.path)",
+					"--result-file-image-vulnerability-report-digest",
+					"$(results.shp-image-vulnerability-report-digest.path)",
+					"--result-file-image-vulnerability-report",
+					"$(results.shp-image-vulnerability-report.path)",
+					"--result-file-image-vulnerability-report-omitted",
+					"$(results.shp-image-vulnerability-report-omitted.path)",
+					"--vuln-report-max-result-size",
+					"1024",
 					"--image",
 					"$(params.shp-output-image)",
 					"--insecure=$(params.shp-output-insecure)",
@@ -126,11 +142,575 @@ var _ = Describe("Image Processing overrides", func() {
 					"$(results.shp-image-size.path)",
 					"--result-file-image-vulnerabilities",
 					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
 				}))
 				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].VolumeMounts).ToNot(utils.ContainNamedElement("shp-output-directory"))
 			})
 		})
 
+		Context("for a build with vulnerability scan options and a default scanner in the configuration", func() {
+			scannerConfig := *config
+			scannerConfig.VulnerabilityScanner = "grype"
+
+			BeforeEach(func() {
+				processedTaskRun = taskRun.DeepCopy()
+			})
+
+			It("uses the scanner of the BuildRun over the default of the configuration", func() {
+				Expect(resources.SetupImageProcessing(processedTaskRun, &scannerConfig, refTimestamp, buildv1beta1.Image{
+					Image: "some-registry/some-namespace/some-image",
+					VulnerabilityScan: &buildv1beta1.VulnerabilityScanOptions{
+						Enabled: true,
+					},
+				}, buildv1beta1.Image{
+					VulnerabilityScan: &buildv1beta1.VulnerabilityScanOptions{
+						Enabled: true,
+						Scanner: ptr.To(buildv1beta1.VulnerabilityScannerTrivy),
+					},
+				})).To(Succeed())
+
+				Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(2))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args[0:2]).To(Equal([]string{
+					"--vuln-settings",
+					"{\"enabled\":true,\"scanner\":\"trivy\"}",
+				}))
+			})
+
+			It("uses the default scanner of the configuration if none is defined", func() {
+				Expect(resources.SetupImageProcessing(processedTaskRun, &scannerConfig, refTimestamp, buildv1beta1.Image{
+					Image: "some-registry/some-namespace/some-image",
+					VulnerabilityScan: &buildv1beta1.VulnerabilityScanOptions{
+						Enabled: true,
+					},
+				}, buildv1beta1.Image{})).To(Succeed())
+
+				Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(2))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args[0:2]).To(Equal([]string{
+					"--vuln-settings",
+					"{\"enabled\":true,\"scanner\":\"grype\"}",
+				}))
+			})
+		})
+
+		Context("for a build with vulnerability scan options and a pre-populated vulnerability database", func() {
+			BeforeEach(func() {
+				databaseConfig := *config
+				databaseConfig.VulnerabilityDatabase.VolumeClaim = "trivy-db"
+				databaseConfig.VulnerabilityDatabase.MaxAge = ptr.To(72 * time.Hour)
+
+				processedTaskRun = taskRun.DeepCopy()
+				Expect(resources.SetupImageProcessing(processedTaskRun, &databaseConfig, refTimestamp, buildv1beta1.Image{
+					Image: "some-registry/some-namespace/some-image",
+					VulnerabilityScan: &buildv1beta1.VulnerabilityScanOptions{
+						Enabled: true,
+					},
+				}, buildv1beta1.Image{})).To(Succeed())
+			})
+
+			It("adds the image-processing step with the database volume mounted", func() {
+				Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(2))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args).To(Equal([]string{
+					"--vuln-settings",
+					"{\"enabled\":true}",
+					"--vuln-count-limit",
+					"50",
+					"--vuln-db-path",
+					"/workspace/vulnerability-db",
+					"--vuln-db-max-age",
+					"72h0m0s",
+					"--result-file-image-vulnerability-db-updated-at",
+					"$(results.shp-image-vulnerability-db-updated-at.path)",
+					"--result-file-image-vulnerability-summary",
+					"$(results.shp-image-vulnerability-summary.path)",
+					"--result-file-image-vulnerability-report-digest",
+					"$(results.shp-image-vulnerability-report-digest.path)",
+					"--result-file-image-vulnerability-report",
+					"$(results.shp-image-vulnerability-report.path)",
+					"--result-file-image-vulnerability-report-omitted",
+					"$(results.shp-image-vulnerability-report-omitted.path)",
+					"--vuln-report-max-result-size",
+					"1024",
+					"--image",
+					"$(params.shp-output-image)",
+					"--insecure=$(params.shp-output-insecure)",
+					"--result-file-image-digest",
+					"$(results.shp-image-digest.path)",
+					"--result-file-image-size",
+					"$(results.shp-image-size.path)",
+					"--result-file-image-vulnerabilities",
+					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
+				}))
+				Expect(processedTaskRun.Spec.TaskSpec.Volumes).To(ContainElement(corev1.Volume{
+					Name: "shp-vulnerability-db",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: "trivy-db",
+							ReadOnly:  true,
+						},
+					},
+				}))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name:      "shp-vulnerability-db",
+					MountPath: "/workspace/vulnerability-db",
+					ReadOnly:  true,
+				}))
+			})
+		})
+
+		Context("for a build with vulnerability scan options with VEX documents", func() {
+			var vexDocuments []buildv1beta1.VEXDocument
+
+			BeforeEach(func() {
+				processedTaskRun = taskRun.DeepCopy()
+				vexDocuments = []buildv1beta1.VEXDocument{
+					{ConfigMap: &buildv1beta1.VEXConfigMapKeyRef{Name: "some-vex", Key: "some-image.json"}},
+					{Image: ptr.To("registry.example.com/vex/some-image:latest")},
+				}
+			})
+
+			setup := func() error {
+				return resources.SetupImageProcessing(processedTaskRun, config, refTimestamp, buildv1beta1.Image{
+					Image: "some-registry/some-namespace/some-image",
+					VulnerabilityScan: &buildv1beta1.VulnerabilityScanOptions{
+						Enabled: true,
+						Ignore:  &buildv1beta1.VulnerabilityIgnoreOptions{VEX: vexDocuments},
+					},
+				}, buildv1beta1.Image{})
+			}
+
+			It("adds the VEX documents and mounts the ConfigMap", func() {
+				Expect(setup()).To(Succeed())
+
+				Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(2))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args).To(ContainElements(
+					"/workspace/vex/0/openvex.json",
+					"registry.example.com/vex/some-image:latest",
+					"$(results.shp-image-suppressed-vulnerabilities.path)",
+					"$(results.shp-image-truncated-results.path)",
+				))
+				Expect(processedTaskRun.Spec.TaskSpec.Volumes).To(ContainElement(corev1.Volume{
+					Name: "shp-vex-0",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "some-vex"},
+							Items:                []corev1.KeyToPath{{Key: "some-image.json", Path: "openvex.json"}},
+						},
+					},
+				}))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name:      "shp-vex-0",
+					MountPath: "/workspace/vex/0",
+					ReadOnly:  true,
+				}))
+			})
+
+			It("fails for a VEX document without reference", func() {
+				vexDocuments = append(vexDocuments, buildv1beta1.VEXDocument{})
+
+				Expect(setup()).To(MatchError(ContainSubstring("the VEX document 2 must reference either a ConfigMap or an image")))
+			})
+		})
+
+		Context("for a build with signing options in the output", func() {
+			BeforeEach(func() {
+				processedTaskRun = taskRun.DeepCopy()
+				resources.SetupImageProcessing(processedTaskRun, config, refTimestamp, buildv1beta1.Image{
+					Image: "some-registry/some-namespace/some-image",
+					Signing: &buildv1beta1.SigningOptions{
+						KeySecret: "signing-key",
+						Storage:   ptr.To(buildv1beta1.SignatureStorageReferrers),
+					},
+				}, buildv1beta1.Image{})
+			})
+
+			It("adds the image-processing step with the signing key mounted", func() {
+				Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(2))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Name).To(Equal("image-processing"))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args).To(Equal([]string{
+					"--signing-key-path",
+					"/workspace/shp-signing-key",
+					"--signature-storage",
+					"Referrers",
+					"--image",
+					"$(params.shp-output-image)",
+					"--insecure=$(params.shp-output-insecure)",
+					"--result-file-image-digest",
+					"$(results.shp-image-digest.path)",
+					"--result-file-image-size",
+					"$(results.shp-image-size.path)",
+					"--result-file-image-vulnerabilities",
+					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
+				}))
+				Expect(processedTaskRun.Spec.TaskSpec.Volumes).To(utils.ContainNamedElement("shp-signing-key"))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name:      "shp-signing-key",
+					MountPath: "/workspace/shp-signing-key",
+					ReadOnly:  true,
+				}))
+			})
+		})
+
+		Context("for a build with additional tags and mirrors in the output", func() {
+			BeforeEach(func() {
+				processedTaskRun = taskRun.DeepCopy()
+				Expect(resources.SetupImageProcessing(processedTaskRun, config, refTimestamp, buildv1beta1.Image{
+					Image:          "some-registry/some-namespace/some-image",
+					AdditionalTags: []string{"latest", "v1.0.0"},
+					Mirrors: []buildv1beta1.ImageMirror{{
+						Image:      "other-registry/some-namespace/some-image",
+						PushSecret: ptr.To("mirror-secret"),
+					}, {
+						Image: "some-registry/mirror-namespace/some-image",
+					}},
+				}, buildv1beta1.Image{})).To(Succeed())
+			})
+
+			It("adds the image-processing step with the push secret of the mirror mounted", func() {
+				Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(2))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Name).To(Equal("image-processing"))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args).To(Equal([]string{
+					"--additional-tag",
+					"latest",
+					"--additional-tag",
+					"v1.0.0",
+					"--mirror",
+					"other-registry/some-namespace/some-image=/workspace/shp-mirror-secret-0",
+					"--mirror",
+					"some-registry/mirror-namespace/some-image",
+					"--result-file-image-references",
+					"$(results.shp-image-references.path)",
+					"--image",
+					"$(params.shp-output-image)",
+					"--insecure=$(params.shp-output-insecure)",
+					"--result-file-image-digest",
+					"$(results.shp-image-digest.path)",
+					"--result-file-image-size",
+					"$(results.shp-image-size.path)",
+					"--result-file-image-vulnerabilities",
+					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
+				}))
+				Expect(processedTaskRun.Spec.TaskSpec.Volumes).To(utils.ContainNamedElement("shp-mirror-secret"))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name:      "shp-mirror-secret",
+					MountPath: "/workspace/shp-mirror-secret-0",
+					ReadOnly:  true,
+				}))
+			})
+		})
+
+		Context("for a build with additional tags in the build and the build run", func() {
+			BeforeEach(func() {
+				processedTaskRun = taskRun.DeepCopy()
+				Expect(resources.SetupImageProcessing(processedTaskRun, config, refTimestamp, buildv1beta1.Image{
+					Image:          "some-registry/some-namespace/some-image",
+					AdditionalTags: []string{"latest"},
+				}, buildv1beta1.Image{
+					AdditionalTags: []string{"nightly"},
+				})).To(Succeed())
+			})
+
+			It("adds the tags of the BuildRun", func() {
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args).To(ContainElement("nightly"))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args).ToNot(ContainElement("latest"))
+			})
+		})
+
+		Context("for a build with platforms and index annotations in the output", func() {
+			BeforeEach(func() {
+				processedTaskRun = taskRun.DeepCopy()
+				Expect(resources.SetupImageProcessing(processedTaskRun, config, refTimestamp, buildv1beta1.Image{
+					Image:     "some-registry/some-namespace/some-image",
+					Platforms: []string{"linux/amd64", "linux/arm64"},
+					IndexAnnotations: map[string]string{
+						"org.opencontainers.image.description": "multi-platform image",
+					},
+				}, buildv1beta1.Image{
+					Platforms: []string{"linux/amd64"},
+				})).To(Succeed())
+			})
+
+			It("adds the image-processing step with the platforms of the BuildRun and the index annotations", func() {
+				Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(2))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Name).To(Equal("image-processing"))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args).To(Equal([]string{
+					"--index-annotation",
+					"org.opencontainers.image.description=multi-platform image",
+					"--platform",
+					"linux/amd64",
+					"--image",
+					"$(params.shp-output-image)",
+					"--insecure=$(params.shp-output-insecure)",
+					"--result-file-image-digest",
+					"$(results.shp-image-digest.path)",
+					"--result-file-image-size",
+					"$(results.shp-image-size.path)",
+					"--result-file-image-vulnerabilities",
+					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
+				}))
+			})
+		})
+
+		Context("for a build with push settings in the configuration", func() {
+			BeforeEach(func() {
+				pushConfig := *config
+				pushConfig.ImagePush.Retries = ptr.To(10)
+				pushConfig.ImagePush.RetryBackoff = ptr.To(2 * time.Second)
+				pushConfig.ImagePush.Concurrency = ptr.To(8)
+
+				processedTaskRun = taskRun.DeepCopy()
+				Expect(resources.SetupImageProcessing(processedTaskRun, &pushConfig, refTimestamp, buildv1beta1.Image{
+					Image:  "some-registry/some-namespace/some-image",
+					Labels: map[string]string{"foo": "bar"},
+				}, buildv1beta1.Image{})).To(Succeed())
+			})
+
+			It("adds the image-processing step with the push settings", func() {
+				Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(2))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args).To(Equal([]string{
+					"--label",
+					"foo=bar",
+					"--image",
+					"$(params.shp-output-image)",
+					"--insecure=$(params.shp-output-insecure)",
+					"--push-retries",
+					"10",
+					"--push-retry-backoff",
+					"2s",
+					"--push-concurrency",
+					"8",
+					"--result-file-image-digest",
+					"$(results.shp-image-digest.path)",
+					"--result-file-image-size",
+					"$(results.shp-image-size.path)",
+					"--result-file-image-vulnerabilities",
+					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
+				}))
+			})
+		})
+
+		Context("for a build with registry TLS in the configuration", func() {
+			BeforeEach(func() {
+				registryTLSConfig := *config
+				// the package name of the configuration is shadowed by the variable
+				Expect(json.Unmarshal([]byte(`[{"host":"some-registry","caBundleConfigMap":"registry-ca","caBundleKey":"ca.crt"}]`), &registryTLSConfig.RegistryTLS)).To(Succeed())
+
+				processedTaskRun = taskRun.DeepCopy()
+				Expect(resources.SetupImageProcessing(processedTaskRun, &registryTLSConfig, refTimestamp, buildv1beta1.Image{
+					Image:  "some-registry/some-namespace/some-image",
+					Labels: map[string]string{"foo": "bar"},
+				}, buildv1beta1.Image{})).To(Succeed())
+			})
+
+			It("mounts the registry certificates into the image-processing step", func() {
+				Expect(processedTaskRun.Spec.TaskSpec.Volumes).To(ContainElement(WithTransform(func(volume corev1.Volume) string {
+					return volume.Name
+				}, Equal("shp-registry-certificates"))))
+
+				step := processedTaskRun.Spec.TaskSpec.Steps[1]
+				Expect(step.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name:      "shp-registry-certificates",
+					MountPath: "/workspace/shp-registry-certificates",
+					ReadOnly:  true,
+				}))
+				Expect(step.Args).To(ContainElements("--registry-certificates-directory", "/workspace/shp-registry-certificates"))
+			})
+		})
+
+		Context("for a build with registry mirrors in the configuration", func() {
+			BeforeEach(func() {
+				registryMirrorsConfig := *config
+				// the package name of the configuration is shadowed by the variable
+				Expect(json.Unmarshal([]byte(`[{"prefix":"some-registry","mirrors":["mirror.example.com/some-registry"],"mirrorByDigestOnly":true}]`), &registryMirrorsConfig.RegistryMirrors)).To(Succeed())
+
+				processedTaskRun = taskRun.DeepCopy()
+				Expect(resources.SetupImageProcessing(processedTaskRun, &registryMirrorsConfig, refTimestamp, buildv1beta1.Image{
+					Image:  "some-registry/some-namespace/some-image",
+					Labels: map[string]string{"foo": "bar"},
+				}, buildv1beta1.Image{})).To(Succeed())
+			})
+
+			It("passes the registry mirrors to the image-processing step", func() {
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args).To(ContainElements("--registry-mirror", "some-registry=mirror.example.com/some-registry;mirror-by-digest-only"))
+			})
+		})
+
+		Context("for a build with a layer format in the build and the build run", func() {
+			BeforeEach(func() {
+				processedTaskRun = taskRun.DeepCopy()
+				Expect(resources.SetupImageProcessing(processedTaskRun, config, refTimestamp, buildv1beta1.Image{
+					Image:       "some-registry/some-namespace/some-image",
+					LayerFormat: ptr.To(buildv1beta1.LayerFormatZstd),
+				}, buildv1beta1.Image{
+					LayerFormat: ptr.To(buildv1beta1.LayerFormatEstargz),
+				})).To(Succeed())
+			})
+
+			It("adds the image-processing step with the layer format of the BuildRun", func() {
+				Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(2))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Name).To(Equal("image-processing"))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args).To(Equal([]string{
+					"--layer-format",
+					"estargz",
+					"--image",
+					"$(params.shp-output-image)",
+					"--insecure=$(params.shp-output-insecure)",
+					"--result-file-image-digest",
+					"$(results.shp-image-digest.path)",
+					"--result-file-image-size",
+					"$(results.shp-image-size.path)",
+					"--result-file-image-vulnerabilities",
+					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
+				}))
+			})
+		})
+
+		Context("for a build with an export in the output", func() {
+			It("fails because the build strategy pushes the image", func() {
+				processedTaskRun = taskRun.DeepCopy()
+				Expect(resources.SetupImageProcessing(processedTaskRun, config, refTimestamp, buildv1beta1.Image{
+					Image: "some-registry/some-namespace/some-image",
+					Export: &buildv1beta1.ImageExport{
+						Format:                buildv1beta1.ImageExportFormatOCILayout,
+						PersistentVolumeClaim: "images",
+					},
+				}, buildv1beta1.Image{})).To(MatchError("cannot export the image, because the build strategy pushes the image"))
+			})
+		})
+
+		Context("for a build with an image configuration in the build and the build run", func() {
+			BeforeEach(func() {
+				processedTaskRun = taskRun.DeepCopy()
+				Expect(resources.SetupImageProcessing(processedTaskRun, config, refTimestamp, buildv1beta1.Image{
+					Image: "some-registry/some-namespace/some-image",
+					Config: &buildv1beta1.ImageConfig{
+						User: ptr.To("1000"),
+					},
+				}, buildv1beta1.Image{
+					Config: &buildv1beta1.ImageConfig{
+						Env: []buildv1beta1.ImageConfigEnvVar{{
+							Name:  "MODE",
+							Value: "production",
+						}},
+						Entrypoint:   []string{"/app/server"},
+						ExposedPorts: []string{"8080"},
+					},
+				})).To(Succeed())
+			})
+
+			It("adds the image-processing step with the image configuration of the BuildRun", func() {
+				Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(2))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Name).To(Equal("image-processing"))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args).To(Equal([]string{
+					"--image-config",
+					`{"env":[{"name":"MODE","value":"production"}],"entrypoint":["/app/server"],"cmd":null,"exposedPorts":["8080"]}`,
+					"--image",
+					"$(params.shp-output-image)",
+					"--insecure=$(params.shp-output-insecure)",
+					"--result-file-image-digest",
+					"$(results.shp-image-digest.path)",
+					"--result-file-image-size",
+					"$(results.shp-image-size.path)",
+					"--result-file-image-vulnerabilities",
+					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
+				}))
+			})
+		})
+
+		Context("for a build with a license policy in the output", func() {
+			BeforeEach(func() {
+				processedTaskRun = taskRun.DeepCopy()
+				Expect(resources.SetupImageProcessing(processedTaskRun, config, refTimestamp, buildv1beta1.Image{
+					Image: "some-registry/some-namespace/some-image",
+					LicensePolicy: &buildv1beta1.LicensePolicy{
+						Denied: []string{"GPL-3.0-only"},
+					},
+				}, buildv1beta1.Image{
+					LicensePolicy: &buildv1beta1.LicensePolicy{
+						Allowed: []string{"MIT", "Apache-2.0"},
+					},
+				})).To(Succeed())
+			})
+
+			It("adds the image-processing step with the license policy of the BuildRun", func() {
+				Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(2))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Name).To(Equal("image-processing"))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args).To(Equal([]string{
+					"--license-policy",
+					`{"allowed":["MIT","Apache-2.0"]}`,
+					"--result-file-image-license-violations",
+					"$(results.shp-image-license-violations.path)",
+					"--result-file-image-truncated-results",
+					"$(results.shp-image-truncated-results.path)",
+					"--vuln-count-limit",
+					"50",
+					"--image",
+					"$(params.shp-output-image)",
+					"--insecure=$(params.shp-output-insecure)",
+					"--result-file-image-digest",
+					"$(results.shp-image-digest.path)",
+					"--result-file-image-size",
+					"$(results.shp-image-size.path)",
+					"--result-file-image-vulnerabilities",
+					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
+				}))
+			})
+		})
+
+		Context("for a build with SBOM options in the output", func() {
+			BeforeEach(func() {
+				processedTaskRun = taskRun.DeepCopy()
+				resources.SetupImageProcessing(processedTaskRun, config, refTimestamp, buildv1beta1.Image{
+					Image: "some-registry/some-namespace/some-image",
+					SBOM: &buildv1beta1.SBOMOptions{
+						Format: buildv1beta1.SBOMFormatSPDXJSON,
+					},
+				}, buildv1beta1.Image{
+					SBOM: &buildv1beta1.SBOMOptions{
+						Format: buildv1beta1.SBOMFormatCycloneDX,
+					},
+				})
+			})
+
+			It("adds the image-processing step with the SBOM format of the BuildRun", func() {
+				Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(2))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Name).To(Equal("image-processing"))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args).To(Equal([]string{
+					"--sbom-format",
+					"cyclonedx",
+					"--result-file-image-sbom-digest",
+					"$(results.shp-image-sbom-digest.path)",
+					"--image",
+					"$(params.shp-output-image)",
+					"--insecure=$(params.shp-output-insecure)",
+					"--result-file-image-digest",
+					"$(results.shp-image-digest.path)",
+					"--result-file-image-size",
+					"$(results.shp-image-size.path)",
+					"--result-file-image-vulnerabilities",
+					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
+				}))
+			})
+		})
+
 	})
 
 	Context("for a TaskRun that references the output directory", func() {
@@ -203,6 +783,8 @@ var _ = Describe("Image Processing overrides", func() {
 						"$(results.shp-image-size.path)",
 						"--result-file-image-vulnerabilities",
 						"$(results.shp-image-vulnerabilities.path)",
+						"--result-file-image-platforms",
+						"$(results.shp-image-platforms.path)",
 					}))
 					Expect(processedTaskRun.Spec.TaskSpec.Steps[1].VolumeMounts).To(utils.ContainNamedElement("shp-output-directory"))
 				})
@@ -246,12 +828,52 @@ var _ = Describe("Image Processing overrides", func() {
 						"$(results.shp-image-size.path)",
 						"--result-file-image-vulnerabilities",
 						"$(results.shp-image-vulnerabilities.path)",
+						"--result-file-image-platforms",
+						"$(results.shp-image-platforms.path)",
 					}))
 					Expect(processedTaskRun.Spec.TaskSpec.Steps[1].VolumeMounts).To(utils.ContainNamedElement("shp-output-directory"))
 				})
 			})
 		})
 
+		Context("for a build with an export in the output", func() {
+			BeforeEach(func() {
+				processedTaskRun = taskRun.DeepCopy()
+				Expect(resources.SetupImageProcessing(processedTaskRun, config, refTimestamp, buildv1beta1.Image{
+					Image: "some-registry/some-namespace/some-image",
+					Export: &buildv1beta1.ImageExport{
+						Format:                buildv1beta1.ImageExportFormatOCILayout,
+						PersistentVolumeClaim: "images",
+						Path:                  "some-image",
+					},
+				}, buildv1beta1.Image{})).To(Succeed())
+			})
+
+			It("adds the image-processing step with the export volume mounted", func() {
+				Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(2))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Name).To(Equal("image-processing"))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].Args).To(ContainElements(
+					"--push",
+					"--export-format",
+					"OCILayout",
+					"--export-directory",
+					"/workspace/shp-export/some-image",
+				))
+				Expect(processedTaskRun.Spec.TaskSpec.Volumes).To(ContainElement(corev1.Volume{
+					Name: "shp-export",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: "images",
+						},
+					},
+				}))
+				Expect(processedTaskRun.Spec.TaskSpec.Steps[1].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name:      "shp-export",
+					MountPath: "/workspace/shp-export",
+				}))
+			})
+		})
+
 		Context("for a build with an output with a secret", func() {
 			BeforeEach(func() {
 				processedTaskRun = taskRun.DeepCopy()
@@ -298,6 +920,8 @@ var _ = Describe("Image Processing overrides", func() {
 					"$(results.shp-image-size.path)",
 					"--result-file-image-vulnerabilities",
 					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
 					"--secret-path",
 					"/workspace/shp-push-secret",
 				}))
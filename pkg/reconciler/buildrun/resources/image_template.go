@@ -0,0 +1,162 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+const (
+	placeholderBuildName       = "build.name"
+	placeholderBuildRunName    = "buildrun.name"
+	placeholderParamsPrefix    = "params."
+	placeholderSourcePrefix    = "source."
+	placeholderCommitSha       = "source.git.commitSha"
+	placeholderBranchName      = "source.git.branchName"
+	placeholderSourceTimestamp = "source.timestamp"
+)
+
+// imagePlaceholderRegexp matches a placeholder like $(build.name) in an image reference or tag
+var imagePlaceholderRegexp = regexp.MustCompile(`\$\(([a-zA-Z0-9_.-]+)\)`)
+
+// sourcePlaceholderResults maps the placeholders that depend on the source to the task results of the
+// source step that provide their value, they are resolved by the image-processing step
+var sourcePlaceholderResults = map[string]string{
+	placeholderCommitSha:       "shp-source-default-commit-sha",
+	placeholderBranchName:      "shp-source-default-branch-name",
+	placeholderSourceTimestamp: "shp-source-default-source-timestamp",
+}
+
+// resolveOutputTemplates returns a copy of the output with the placeholders in the image, the additional tags and
+// the mirrors resolved, placeholders that depend on the source are kept. The image cannot contain those, because
+// the build strategy receives it before the source step ran.
+func resolveOutputTemplates(output buildv1beta1.Image, build *buildv1beta1.Build, buildRun *buildv1beta1.BuildRun, parameters []buildv1beta1.Parameter, paramValues []buildv1beta1.ParamValue) (buildv1beta1.Image, error) {
+	resolved := output.DeepCopy()
+
+	resolve := func(template string) (string, error) {
+		return resolveImageTemplate(template, build, buildRun, parameters, paramValues)
+	}
+
+	var err error
+	if resolved.Image, err = resolve(resolved.Image); err != nil {
+		return output, err
+	}
+
+	if placeholders := getSourcePlaceholders(resolved.Image); len(placeholders) > 0 {
+		return output, fmt.Errorf("cannot use the placeholder $(%s) in the output image, placeholders that depend on the source can only be used in additional tags and mirrors", placeholders[0])
+	}
+
+	for i := range resolved.AdditionalTags {
+		if resolved.AdditionalTags[i], err = resolve(resolved.AdditionalTags[i]); err != nil {
+			return output, err
+		}
+	}
+
+	for i := range resolved.Mirrors {
+		if resolved.Mirrors[i].Image, err = resolve(resolved.Mirrors[i].Image); err != nil {
+			return output, err
+		}
+	}
+
+	return *resolved, nil
+}
+
+// resolveImageTemplate replaces the placeholders for the names of the build and build run, and for the values of
+// parameters. Parameters must have a literal string value, or a default in the build strategy.
+func resolveImageTemplate(template string, build *buildv1beta1.Build, buildRun *buildv1beta1.BuildRun, parameters []buildv1beta1.Parameter, paramValues []buildv1beta1.ParamValue) (string, error) {
+	var err error
+	resolved := imagePlaceholderRegexp.ReplaceAllStringFunc(template, func(match string) string {
+		placeholder := imagePlaceholderRegexp.FindStringSubmatch(match)[1]
+
+		switch {
+		case placeholder == placeholderBuildName:
+			if build.Name == "" {
+				err = fmt.Errorf("the placeholder %s cannot be used with an embedded build", match)
+			}
+			return build.Name
+
+		case placeholder == placeholderBuildRunName:
+			return buildRun.Name
+
+		case strings.HasPrefix(placeholder, placeholderParamsPrefix):
+			value, paramErr := getStringParamValue(strings.TrimPrefix(placeholder, placeholderParamsPrefix), parameters, paramValues)
+			if paramErr != nil {
+				err = paramErr
+			}
+			return value
+
+		case sourcePlaceholderResults[placeholder] != "":
+			return match
+
+		default:
+			err = fmt.Errorf("the placeholder %s in %q is not supported", match, template)
+			return match
+		}
+	})
+
+	return resolved, err
+}
+
+func getStringParamValue(name string, parameters []buildv1beta1.Parameter, paramValues []buildv1beta1.ParamValue) (string, error) {
+	parameter := FindParameterByName(parameters, name)
+	if parameter == nil {
+		return "", fmt.Errorf("the parameter %q that is used in the output image is not defined in the build strategy", name)
+	}
+
+	if paramValue := FindParamValueByName(paramValues, name); paramValue != nil {
+		if paramValue.SingleValue == nil || paramValue.SingleValue.Value == nil {
+			return "", fmt.Errorf("the parameter %q that is used in the output image must have a string value", name)
+		}
+		return *paramValue.SingleValue.Value, nil
+	}
+
+	if parameter.Default == nil {
+		return "", fmt.Errorf("the parameter %q that is used in the output image has no value", name)
+	}
+
+	return *parameter.Default, nil
+}
+
+// getSourcePlaceholders returns the placeholders that depend on the source which are used in the templates
+func getSourcePlaceholders(templates ...string) []string {
+	found := map[string]bool{}
+	for _, template := range templates {
+		for _, match := range imagePlaceholderRegexp.FindAllStringSubmatch(template, -1) {
+			if strings.HasPrefix(match[1], placeholderSourcePrefix) {
+				found[match[1]] = true
+			}
+		}
+	}
+
+	placeholders := make([]string, 0, len(found))
+	for placeholder := range found {
+		placeholders = append(placeholders, placeholder)
+	}
+	sort.Strings(placeholders)
+
+	return placeholders
+}
+
+// getTemplateValueArgs returns the image-processing arguments with the result files that provide the values of the
+// placeholders that depend on the source
+func getTemplateValueArgs(taskRun *pipelineapi.TaskRun, placeholders []string) ([]string, error) {
+	var args []string
+	for _, placeholder := range placeholders {
+		resultName := sourcePlaceholderResults[placeholder]
+		if !hasTaskSpecResult(taskRun, resultName) {
+			return nil, fmt.Errorf("cannot use the placeholder $(%s) in the output image, because the source does not provide its value", placeholder)
+		}
+
+		args = append(args, "--template-value-file", fmt.Sprintf("%s=$(results.%s.path)", placeholder, resultName))
+	}
+
+	return args, nil
+}
@@ -0,0 +1,56 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+	"net/url"
+
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+// SetupOCIAnnotations adds the information about the source and the base image to the image-processing step, if
+// it is configured to add the standard OCI annotations. The revision is read from the result of the source step,
+// and the base image is the value of the parameter that the build strategy names in its annotation.
+func SetupOCIAnnotations(taskRun *pipelineapi.TaskRun, build *buildv1beta1.Build, strategy buildv1beta1.BuilderStrategy, paramValues []buildv1beta1.ParamValue) {
+	var imageProcessingStep *pipelineapi.Step
+	for i := range taskRun.Spec.TaskSpec.Steps {
+		if taskRun.Spec.TaskSpec.Steps[i].Name == containerNameImageProcessing {
+			imageProcessingStep = &taskRun.Spec.TaskSpec.Steps[i]
+			break
+		}
+	}
+
+	if imageProcessingStep == nil || !hasArg(imageProcessingStep.Args, "--oci-annotations") {
+		return
+	}
+
+	if build.Spec.Source != nil && build.Spec.Source.Git != nil {
+		imageProcessingStep.Args = append(imageProcessingStep.Args, "--oci-annotation-source", withoutCredentials(build.Spec.Source.Git.URL))
+	}
+
+	if resultName := sourcePlaceholderResults[placeholderCommitSha]; hasTaskSpecResult(taskRun, resultName) {
+		imageProcessingStep.Args = append(imageProcessingStep.Args, "--oci-annotation-revision-file", fmt.Sprintf("$(results.%s.path)", resultName))
+	}
+
+	// the base image is omitted if the parameter has no literal value
+	if parameterName := strategy.GetAnnotations()[buildv1beta1.AnnotationBaseImageParameter]; parameterName != "" {
+		if baseImage, err := getStringParamValue(parameterName, strategy.GetParameters(), paramValues); err == nil && baseImage != "" {
+			imageProcessingStep.Args = append(imageProcessingStep.Args, "--oci-annotation-base-image", baseImage)
+		}
+	}
+}
+
+// withoutCredentials removes the user information from a URL, other values like SSH URLs are returned unchanged
+func withoutCredentials(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.User == nil {
+		return value
+	}
+
+	parsed.User = nil
+	return parsed.String()
+}
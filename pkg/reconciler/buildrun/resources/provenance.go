@@ -0,0 +1,197 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/image"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources/sources"
+	"github.com/shipwright-io/build/version"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+const (
+	// provenanceBuildType is the SLSA build type of a provenance that is generated for a BuildRun
+	provenanceBuildType = "https://shipwright.io/buildrun/v1beta1"
+
+	// provenanceBuilderID is the SLSA builder id of a provenance that is generated for a BuildRun
+	provenanceBuilderID = "https://shipwright.io/build"
+)
+
+// SetupProvenance adds the information about the build to the image-processing step, if it is
+// configured to generate a provenance. Digests of dependencies that are determined during the
+// build, like the Git commit, are passed as result files.
+func SetupProvenance(taskRun *pipelineapi.TaskRun, build *buildv1beta1.Build, buildRun *buildv1beta1.BuildRun, strategy buildv1beta1.BuilderStrategy) error {
+	var imageProcessingStep *pipelineapi.Step
+	for i := range taskRun.Spec.TaskSpec.Steps {
+		if taskRun.Spec.TaskSpec.Steps[i].Name == containerNameImageProcessing {
+			imageProcessingStep = &taskRun.Spec.TaskSpec.Steps[i]
+			break
+		}
+	}
+
+	if imageProcessingStep == nil || !hasArg(imageProcessingStep.Args, "--provenance") {
+		return nil
+	}
+
+	input := getProvenanceInput(taskRun, build, buildRun, strategy)
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the provenance input: %w", err)
+	}
+
+	// the input is base64-encoded as Tekton would otherwise substitute variables in it
+	imageProcessingStep.Args = append(imageProcessingStep.Args, "--provenance-input", base64.StdEncoding.EncodeToString(data))
+
+	var resultNames []string
+	for _, dependency := range input.Dependencies {
+		for _, resultName := range dependency.DigestResults {
+			resultNames = append(resultNames, resultName)
+		}
+	}
+
+	sort.Strings(resultNames)
+	for _, resultName := range resultNames {
+		imageProcessingStep.Args = append(imageProcessingStep.Args, "--provenance-result-file", fmt.Sprintf("%s=$(results.%s.path)", resultName, resultName))
+	}
+
+	return nil
+}
+
+func getProvenanceInput(taskRun *pipelineapi.TaskRun, build *buildv1beta1.Build, buildRun *buildv1beta1.BuildRun, strategy buildv1beta1.BuilderStrategy) image.ProvenanceInput {
+	input := image.ProvenanceInput{
+		BuildType:      provenanceBuildType,
+		BuilderID:      provenanceBuilderID,
+		BuilderVersion: map[string]string{"shipwright-build": version.Version},
+		InvocationID:   string(buildRun.UID),
+		ExternalParameters: map[string]interface{}{
+			"build": map[string]interface{}{
+				"name": build.Name,
+				"spec": build.Spec,
+			},
+			"buildRun": map[string]interface{}{
+				"name": buildRun.Name,
+				"spec": buildRun.Spec,
+			},
+		},
+	}
+
+	if !buildRun.CreationTimestamp.IsZero() {
+		startedOn := buildRun.CreationTimestamp.UTC()
+		input.StartedOn = &startedOn
+	}
+
+	var steps []map[string]string
+	for _, step := range strategy.GetBuildSteps() {
+		steps = append(steps, map[string]string{"name": step.Name, "image": step.Image})
+
+		dependency := image.ProvenanceDependency{URI: "oci://" + step.Image, Name: step.Name}
+		if _, digest, found := strings.Cut(step.Image, "@"); found {
+			if algorithm, hex, found := strings.Cut(digest, ":"); found {
+				dependency.Digest = map[string]string{algorithm: hex}
+			}
+		}
+
+		input.Dependencies = append(input.Dependencies, dependency)
+	}
+
+	input.InternalParameters = map[string]interface{}{
+		"strategy": map[string]interface{}{
+			"name":       strategy.GetName(),
+			"generation": strategy.GetGeneration(),
+		},
+		"steps": steps,
+	}
+
+	if dependency := getSourceDependency(taskRun, build, buildRun); dependency != nil {
+		input.Dependencies = append(input.Dependencies, *dependency)
+	}
+
+	return input
+}
+
+func getSourceDependency(taskRun *pipelineapi.TaskRun, build *buildv1beta1.Build, buildRun *buildv1beta1.BuildRun) *image.ProvenanceDependency {
+	// uploaded, inline and object storage prefix sources are directories that are recorded
+	// with the in-toto dirHash algorithm, which is the h1 hash of the Go module sum database
+	sourceDirHashResult := sources.TaskResultName(sources.DefaultSourceName, "source-dir-hash")
+
+	if local, _ := isLocalCopyBuildSource(build, buildRun); local != nil {
+		dependency := image.ProvenanceDependency{Name: "local"}
+		if local.Name != "" {
+			dependency.Name = local.Name
+		}
+
+		if hasTaskSpecResult(taskRun, sourceDirHashResult) {
+			dependency.DigestResults = map[string]string{"dirHash": sourceDirHashResult}
+		}
+
+		return &dependency
+	}
+
+	source := build.Spec.Source
+	if source == nil {
+		return nil
+	}
+
+	var dependency image.ProvenanceDependency
+	var algorithm, resultName string
+
+	switch {
+	case source.Type == buildv1beta1.GitType && source.Git != nil:
+		dependency.URI = "git+" + source.Git.URL
+		if source.Git.Revision != nil {
+			dependency.URI += "@" + *source.Git.Revision
+		}
+		algorithm, resultName = "gitCommit", sources.TaskResultName(sources.DefaultSourceName, "commit-sha")
+
+	case source.Type == buildv1beta1.OCIArtifactType && source.OCIArtifact != nil:
+		dependency.URI = "oci://" + source.OCIArtifact.Image
+		algorithm, resultName = "sha256", sources.TaskResultName(sources.DefaultSourceName, "image-digest")
+
+	case source.Type == buildv1beta1.ObjectStorageType && source.ObjectStorage != nil:
+		dependency.URI = fmt.Sprintf("%s/%s", strings.TrimSuffix(source.ObjectStorage.Endpoint, "/"), source.ObjectStorage.Bucket)
+		if source.ObjectStorage.Key != nil {
+			dependency.URI += "/" + *source.ObjectStorage.Key
+		} else if source.ObjectStorage.Prefix != nil {
+			dependency.URI += "/" + *source.ObjectStorage.Prefix
+		}
+
+		// a single object is hashed as a file, a prefix is hashed as the downloaded directory
+		algorithm, resultName = "sha256", sources.TaskResultName(sources.DefaultSourceName, "digest")
+		if source.ObjectStorage.Key == nil {
+			algorithm, resultName = "dirHash", sourceDirHashResult
+		}
+
+	case source.Type == buildv1beta1.InlineType && source.Inline != nil:
+		dependency.Name = "inline"
+		algorithm, resultName = "dirHash", sourceDirHashResult
+
+	default:
+		return nil
+	}
+
+	if hasTaskSpecResult(taskRun, resultName) {
+		dependency.DigestResults = map[string]string{algorithm: resultName}
+	}
+
+	return &dependency
+}
+
+func hasArg(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,223 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/image"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
+
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+var _ = Describe("SetupProvenance", func() {
+	var (
+		build            *buildv1beta1.Build
+		buildRun         *buildv1beta1.BuildRun
+		strategy         *buildv1beta1.ClusterBuildStrategy
+		processedTaskRun *pipelineapi.TaskRun
+	)
+
+	BeforeEach(func() {
+		build = &buildv1beta1.Build{
+			ObjectMeta: metav1.ObjectMeta{Name: "some-build"},
+			Spec: buildv1beta1.BuildSpec{
+				Source: &buildv1beta1.Source{
+					Type: buildv1beta1.GitType,
+					Git: &buildv1beta1.Git{
+						URL:      "https://github.com/shipwright-io/sample-go",
+						Revision: ptr.To("main"),
+					},
+				},
+				Strategy: buildv1beta1.Strategy{
+					Name: "buildkit",
+					Kind: ptr.To(buildv1beta1.ClusterBuildStrategyKind),
+				},
+				Output: buildv1beta1.Image{
+					Image:      "some-registry/some-namespace/some-image",
+					Provenance: &buildv1beta1.ProvenanceOptions{Enabled: true},
+				},
+			},
+		}
+
+		buildRun = &buildv1beta1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "some-buildrun",
+				UID:               "some-uid",
+				CreationTimestamp: metav1.NewTime(time.Unix(1234567890, 0)),
+			},
+		}
+
+		strategy = &buildv1beta1.ClusterBuildStrategy{
+			ObjectMeta: metav1.ObjectMeta{Name: "buildkit"},
+			Spec: buildv1beta1.BuildStrategySpec{
+				Steps: []buildv1beta1.Step{{
+					Name:  "build-and-push",
+					Image: "moby/buildkit@sha256:0123456789abcdef",
+				}},
+			},
+		}
+
+		processedTaskRun = &pipelineapi.TaskRun{
+			Spec: pipelineapi.TaskRunSpec{
+				TaskSpec: &pipelineapi.TaskSpec{
+					Results: []pipelineapi.TaskResult{{Name: "shp-source-default-commit-sha"}},
+					Steps: []pipelineapi.Step{{
+						Name: "test-step",
+					}},
+				},
+			},
+		}
+	})
+
+	setup := func() {
+		Expect(resources.SetupImageProcessing(processedTaskRun, config.NewDefaultConfig(), buildRun.CreationTimestamp.Time, build.Spec.Output, buildv1beta1.Image{})).To(Succeed())
+		Expect(resources.SetupProvenance(processedTaskRun, build, buildRun, strategy)).To(Succeed())
+	}
+
+	getArgValue := func(args []string, arg string) string {
+		for i := range args {
+			if args[i] == arg && i+1 < len(args) {
+				return args[i+1]
+			}
+		}
+
+		return ""
+	}
+
+	It("does not add the provenance input if the provenance is not enabled", func() {
+		build.Spec.Output.Provenance = nil
+		setup()
+
+		Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(1))
+	})
+
+	It("adds the provenance input and the result file of the Git commit to the image-processing step", func() {
+		setup()
+
+		Expect(processedTaskRun.Spec.TaskSpec.Steps).To(HaveLen(2))
+		args := processedTaskRun.Spec.TaskSpec.Steps[1].Args
+		Expect(args).To(ContainElement("--provenance"))
+		Expect(getArgValue(args, "--provenance-result-file")).To(Equal("shp-source-default-commit-sha=$(results.shp-source-default-commit-sha.path)"))
+
+		data, err := base64.StdEncoding.DecodeString(getArgValue(args, "--provenance-input"))
+		Expect(err).ToNot(HaveOccurred())
+
+		var input image.ProvenanceInput
+		Expect(json.Unmarshal(data, &input)).To(Succeed())
+		Expect(input.InvocationID).To(Equal("some-uid"))
+		Expect(input.StartedOn.Unix()).To(BeEquivalentTo(1234567890))
+		Expect(input.ExternalParameters).To(HaveKey("build"))
+		Expect(input.Dependencies).To(Equal([]image.ProvenanceDependency{
+			{
+				URI:    "oci://moby/buildkit@sha256:0123456789abcdef",
+				Name:   "build-and-push",
+				Digest: map[string]string{"sha256": "0123456789abcdef"},
+			},
+			{
+				URI:           "git+https://github.com/shipwright-io/sample-go@main",
+				DigestResults: map[string]string{"gitCommit": "shp-source-default-commit-sha"},
+			},
+		}))
+	})
+
+	getSourceDependency := func() image.ProvenanceDependency {
+		args := processedTaskRun.Spec.TaskSpec.Steps[1].Args
+		data, err := base64.StdEncoding.DecodeString(getArgValue(args, "--provenance-input"))
+		Expect(err).ToNot(HaveOccurred())
+
+		var input image.ProvenanceInput
+		Expect(json.Unmarshal(data, &input)).To(Succeed())
+		Expect(input.Dependencies).To(HaveLen(2))
+
+		return input.Dependencies[1]
+	}
+
+	It("uses the SHA-256 of the object for an ObjectStorage source with a key", func() {
+		build.Spec.Source = &buildv1beta1.Source{
+			Type: buildv1beta1.ObjectStorageType,
+			ObjectStorage: &buildv1beta1.ObjectStorage{
+				Endpoint: "https://s3.example.com/",
+				Bucket:   "sources",
+				Key:      ptr.To("app.tar.gz"),
+			},
+		}
+		processedTaskRun.Spec.TaskSpec.Results = []pipelineapi.TaskResult{{Name: "shp-source-default-digest"}}
+		setup()
+
+		Expect(getSourceDependency()).To(Equal(image.ProvenanceDependency{
+			URI:           "https://s3.example.com/sources/app.tar.gz",
+			DigestResults: map[string]string{"sha256": "shp-source-default-digest"},
+		}))
+	})
+
+	It("uses the directory hash for an ObjectStorage source with a prefix", func() {
+		build.Spec.Source = &buildv1beta1.Source{
+			Type: buildv1beta1.ObjectStorageType,
+			ObjectStorage: &buildv1beta1.ObjectStorage{
+				Endpoint: "https://s3.example.com",
+				Bucket:   "sources",
+				Prefix:   ptr.To("app/"),
+			},
+		}
+		processedTaskRun.Spec.TaskSpec.Results = []pipelineapi.TaskResult{{Name: "shp-source-default-digest"}, {Name: "shp-source-default-source-dir-hash"}}
+		setup()
+
+		Expect(getSourceDependency()).To(Equal(image.ProvenanceDependency{
+			URI:           "https://s3.example.com/sources/app/",
+			DigestResults: map[string]string{"dirHash": "shp-source-default-source-dir-hash"},
+		}))
+	})
+
+	It("adds the directory hash of a Local source defined in the BuildRun", func() {
+		buildRun.Spec.Source = &buildv1beta1.BuildRunSource{
+			Type:  buildv1beta1.LocalType,
+			Local: &buildv1beta1.Local{Name: "my-upload"},
+		}
+		processedTaskRun.Spec.TaskSpec.Results = []pipelineapi.TaskResult{{Name: "shp-source-default-source-digest"}, {Name: "shp-source-default-source-dir-hash"}}
+		setup()
+
+		Expect(getSourceDependency()).To(Equal(image.ProvenanceDependency{
+			Name:          "my-upload",
+			DigestResults: map[string]string{"dirHash": "shp-source-default-source-dir-hash"},
+		}))
+	})
+
+	It("adds the directory hash of an Inline source", func() {
+		build.Spec.Source = &buildv1beta1.Source{
+			Type: buildv1beta1.InlineType,
+			Inline: &buildv1beta1.Inline{
+				Files: map[string]string{"Dockerfile": "FROM scratch"},
+			},
+		}
+		processedTaskRun.Spec.TaskSpec.Results = []pipelineapi.TaskResult{{Name: "shp-source-default-source-digest"}, {Name: "shp-source-default-source-dir-hash"}}
+		setup()
+
+		Expect(getSourceDependency()).To(Equal(image.ProvenanceDependency{
+			Name:          "inline",
+			DigestResults: map[string]string{"dirHash": "shp-source-default-source-dir-hash"},
+		}))
+	})
+
+	It("does not add a result file for a source without digest result", func() {
+		processedTaskRun.Spec.TaskSpec.Results = nil
+		setup()
+
+		args := processedTaskRun.Spec.TaskSpec.Steps[1].Args
+		Expect(args).To(ContainElement("--provenance-input"))
+		Expect(args).ToNot(ContainElement("--provenance-result-file"))
+	})
+})
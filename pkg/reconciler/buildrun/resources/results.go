@@ -6,9 +6,13 @@ package resources
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	build "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
 	"github.com/shipwright-io/build/pkg/ctxlog"
@@ -18,9 +22,21 @@ import (
 )
 
 const (
-	imageDigestResult    = "image-digest"
-	imageSizeResult      = "image-size"
-	imageVulnerabilities = "image-vulnerabilities"
+	imageDigestResult     = "image-digest"
+	imageSizeResult       = "image-size"
+	imageReferencesResult = "image-references"
+	imagePlatformsResult  = "image-platforms"
+	imageVulnerabilities  = "image-vulnerabilities"
+	imageSBOMDigest       = "image-sbom-digest"
+
+	imageVulnerabilityDBUpdatedAt   = "image-vulnerability-db-updated-at"
+	imageVulnerabilitySummary       = "image-vulnerability-summary"
+	imageVulnerabilityReportDigest  = "image-vulnerability-report-digest"
+	imageVulnerabilityReport        = "image-vulnerability-report"
+	imageVulnerabilityReportOmitted = "image-vulnerability-report-omitted"
+	imageSuppressedVulnerabilities  = "image-suppressed-vulnerabilities"
+	imageLicenseViolations          = "image-license-violations"
+	imageTruncatedResults           = "image-truncated-results"
 )
 
 // UpdateBuildRunUsingTaskResults surface the task results
@@ -54,8 +70,66 @@ func updateBuildRunStatusWithOutputResult(ctx context.Context, buildRun *build.B
 			} else {
 				buildRun.Status.Output.Size = size
 			}
+		case generateOutputResultName(imageReferencesResult):
+			if result.Value.StringVal != "" {
+				buildRun.Status.Output.Images = strings.Split(result.Value.StringVal, ",")
+			}
+
+		case generateOutputResultName(imagePlatformsResult):
+			var platforms []build.PlatformDigest
+			if err := json.Unmarshal([]byte(result.Value.StringVal), &platforms); err != nil {
+				ctxlog.Info(ctx, "invalid value for output image platforms from taskRun result", namespace, request.Namespace, name, request.Name, "error", err)
+			} else {
+				buildRun.Status.Output.Platforms = platforms
+			}
+
 		case generateOutputResultName(imageVulnerabilities):
 			buildRun.Status.Output.Vulnerabilities = getImageVulnerabilitiesResult(result)
+
+		case generateOutputResultName(imageSuppressedVulnerabilities):
+			buildRun.Status.Output.SuppressedVulnerabilities = getImageSuppressedVulnerabilitiesResult(result)
+
+		case generateOutputResultName(imageSBOMDigest):
+			buildRun.Status.Output.SBOMDigest = result.Value.StringVal
+
+		case generateOutputResultName(imageVulnerabilityDBUpdatedAt):
+			if updatedAt, err := time.Parse(time.RFC3339, result.Value.StringVal); err != nil {
+				ctxlog.Info(ctx, "invalid value for vulnerability database update timestamp from taskRun result", namespace, request.Namespace, name, request.Name, "error", err)
+			} else {
+				buildRun.Status.Output.VulnerabilityDBUpdatedAt = &metav1.Time{Time: updatedAt}
+			}
+
+		case generateOutputResultName(imageVulnerabilitySummary):
+			var summary build.VulnerabilitySummary
+			if err := json.Unmarshal([]byte(result.Value.StringVal), &summary); err != nil {
+				ctxlog.Info(ctx, "invalid value for vulnerability summary from taskRun result", namespace, request.Namespace, name, request.Name, "error", err)
+			} else {
+				buildRun.Status.Output.VulnerabilitySummary = &summary
+			}
+
+		case generateOutputResultName(imageVulnerabilityReportDigest):
+			buildRun.Status.Output.VulnerabilityReportDigest = result.Value.StringVal
+
+		case generateOutputResultName(imageVulnerabilityReportOmitted):
+			buildRun.Status.Output.VulnerabilityReportOmitted = result.Value.StringVal
+
+		case generateOutputResultName(imageLicenseViolations):
+			var violations []build.LicenseViolation
+			if err := json.Unmarshal([]byte(result.Value.StringVal), &violations); err != nil {
+				ctxlog.Info(ctx, "invalid value for license violations from taskRun result", namespace, request.Namespace, name, request.Name, "error", err)
+			} else {
+				buildRun.Status.Output.LicenseViolations = violations
+			}
+
+		case generateOutputResultName(imageTruncatedResults):
+			for _, truncatedResult := range strings.Split(result.Value.StringVal, ",") {
+				switch truncatedResult {
+				case "suppressedVulnerabilities":
+					buildRun.Status.Output.SuppressedVulnerabilitiesTruncated = true
+				case "licenseViolations":
+					buildRun.Status.Output.LicenseViolationsTruncated = true
+				}
+			}
 		}
 	}
 }
@@ -74,10 +148,54 @@ func getTaskSpecResults() []pipelineapi.TaskResult {
 			Name:        fmt.Sprintf("%s-%s", prefixParamsResultsVolumes, imageSizeResult),
 			Description: "The compressed size of the image",
 		},
+		{
+			Name:        fmt.Sprintf("%s-%s", prefixParamsResultsVolumes, imageReferencesResult),
+			Description: "All references the image was pushed to",
+		},
+		{
+			Name:        fmt.Sprintf("%s-%s", prefixParamsResultsVolumes, imagePlatformsResult),
+			Description: "The digests of the images per platform",
+		},
 		{
 			Name:        fmt.Sprintf("%s-%s", prefixParamsResultsVolumes, imageVulnerabilities),
 			Description: "List of vulnerabilities",
 		},
+		{
+			Name:        fmt.Sprintf("%s-%s", prefixParamsResultsVolumes, imageSuppressedVulnerabilities),
+			Description: "List of vulnerabilities that were suppressed by a VEX statement",
+		},
+		{
+			Name:        fmt.Sprintf("%s-%s", prefixParamsResultsVolumes, imageSBOMDigest),
+			Description: "The digest of the software bill of materials",
+		},
+		{
+			Name:        fmt.Sprintf("%s-%s", prefixParamsResultsVolumes, imageVulnerabilityDBUpdatedAt),
+			Description: "The update timestamp of the pre-populated vulnerability database",
+		},
+		{
+			Name:        fmt.Sprintf("%s-%s", prefixParamsResultsVolumes, imageVulnerabilitySummary),
+			Description: "The number of vulnerabilities per severity",
+		},
+		{
+			Name:        fmt.Sprintf("%s-%s", prefixParamsResultsVolumes, imageVulnerabilityReportDigest),
+			Description: "The digest of the vulnerability report",
+		},
+		{
+			Name:        fmt.Sprintf("%s-%s", prefixParamsResultsVolumes, imageVulnerabilityReport),
+			Description: "The compressed vulnerability report if the image was not pushed",
+		},
+		{
+			Name:        fmt.Sprintf("%s-%s", prefixParamsResultsVolumes, imageVulnerabilityReportOmitted),
+			Description: "Why the vulnerability report was not passed as result",
+		},
+		{
+			Name:        fmt.Sprintf("%s-%s", prefixParamsResultsVolumes, imageLicenseViolations),
+			Description: "List of packages with a license that is not allowed by the license policy",
+		},
+		{
+			Name:        fmt.Sprintf("%s-%s", prefixParamsResultsVolumes, imageTruncatedResults),
+			Description: "The names of the results that were truncated to their maximum size",
+		},
 	}
 }
 
@@ -99,6 +217,27 @@ func getImageVulnerabilitiesResult(result pipelineapi.TaskRunResult) []build.Vul
 	return vulns
 }
 
+func getImageSuppressedVulnerabilitiesResult(result pipelineapi.TaskRunResult) []build.SuppressedVulnerability {
+	var vulns []build.SuppressedVulnerability
+	if len(result.Value.StringVal) == 0 {
+		return vulns
+	}
+
+	for _, vulnerability := range strings.Split(result.Value.StringVal, ",") {
+		vuln := strings.SplitN(vulnerability, ":", 3)
+		if len(vuln) < 3 {
+			continue
+		}
+
+		vulns = append(vulns, build.SuppressedVulnerability{
+			ID:            vuln[0],
+			Severity:      getSeverity(vuln[1]),
+			Justification: vuln[2],
+		})
+	}
+	return vulns
+}
+
 func getSeverity(sev string) build.VulnerabilitySeverity {
 	switch strings.ToUpper(sev) {
 	case "L":
@@ -6,6 +6,7 @@ package resources_test
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -16,6 +17,7 @@ import (
 
 	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -106,6 +108,81 @@ var _ = Describe("TaskRun results to BuildRun", func() {
 			Expect(br.Status.Source.OciArtifact.Digest).To(Equal(bundleImageDigest))
 		})
 
+		It("should surface the TaskRun results emitting from default(object storage) source step", func() {
+			br.Status.BuildSpec = &build.BuildSpec{
+				Source: &build.Source{
+					Type: build.ObjectStorageType,
+					ObjectStorage: &build.ObjectStorage{
+						Endpoint: "http://minio.minio.svc:9000",
+						Bucket:   "sources",
+						Key:      ptr.To("source.tar.gz"),
+					},
+				},
+			}
+
+			tr.Status.Results = append(tr.Status.Results,
+				pipelineapi.TaskRunResult{
+					Name: "shp-source-default-digest",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: "9b2cf535f27731c974343645a3985328",
+					},
+				},
+				pipelineapi.TaskRunResult{
+					Name: "shp-source-default-source-timestamp",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: "1709287200",
+					},
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.Results, taskRunRequest)
+
+			Expect(br.Status.Source).ToNot(BeNil())
+			Expect(br.Status.Source.ObjectStorage.Digest).To(Equal("9b2cf535f27731c974343645a3985328"))
+			Expect(br.Status.Source.Timestamp.Unix()).To(Equal(int64(1709287200)))
+		})
+
+		It("should surface the TaskRun results emitting from default(local) source step", func() {
+			sourceDigest := "sha256:9b2d7e1c0d2f4f3c8a1e"
+			br.Status.BuildSpec = &build.BuildSpec{
+				Source: &build.Source{
+					Type: build.GitType,
+					Git: &build.Git{
+						URL: "https://github.com/shipwright-io/sample-go",
+					},
+				},
+			}
+			br.Spec.Source = &build.BuildRunSource{
+				Type:  build.LocalType,
+				Local: &build.Local{Name: "local-source"},
+			}
+
+			tr.Status.Results = append(tr.Status.Results,
+				pipelineapi.TaskRunResult{
+					Name: "shp-source-default-source-digest",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: sourceDigest,
+					},
+				},
+				pipelineapi.TaskRunResult{
+					Name: "shp-source-default-source-timestamp",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: "1691650396",
+					},
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.Results, taskRunRequest)
+
+			Expect(br.Status.Source).ToNot(BeNil())
+			Expect(br.Status.Source.Local.Digest).To(Equal(sourceDigest))
+			Expect(br.Status.Source.Git).To(BeNil())
+			Expect(br.Status.Source.Timestamp).ToNot(BeNil())
+			Expect(br.Status.Source.Timestamp.Unix()).To(Equal(int64(1691650396)))
+		})
+
 		It("should surface the TaskRun results emitting from output step with image vulnerabilities", func() {
 			imageDigest := "sha256:fe1b73cd25ac3f11dec752755e2"
 			tr.Status.Results = append(tr.Status.Results,
@@ -172,6 +249,171 @@ var _ = Describe("TaskRun results to BuildRun", func() {
 			Expect(br.Status.Output.Vulnerabilities).To(HaveLen(0))
 		})
 
+		It("should surface the TaskRun results emitting from output step with an SBOM digest", func() {
+			sbomDigest := "sha256:9e2a3d7c04d1f3b2a5c6e8f7b1d0c9a8e7f6b5a4"
+			tr.Status.Results = append(tr.Status.Results,
+				pipelineapi.TaskRunResult{
+					Name: "shp-image-digest",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: "sha256:fe1b73cd25ac3f11dec752755e2",
+					},
+				},
+				pipelineapi.TaskRunResult{
+					Name: "shp-image-sbom-digest",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: sbomDigest,
+					},
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.Results, taskRunRequest)
+
+			Expect(br.Status.Output.SBOMDigest).To(Equal(sbomDigest))
+		})
+
+		It("should surface the TaskRun results emitting from output step with the update timestamp of the vulnerability database", func() {
+			tr.Status.Results = append(tr.Status.Results,
+				pipelineapi.TaskRunResult{
+					Name: "shp-image-vulnerability-db-updated-at",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: "2024-06-01T06:00:00Z",
+					},
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.Results, taskRunRequest)
+
+			Expect(br.Status.Output.VulnerabilityDBUpdatedAt).ToNot(BeNil())
+			Expect(br.Status.Output.VulnerabilityDBUpdatedAt.Time).To(BeTemporally("==", time.Date(2024, 6, 1, 6, 0, 0, 0, time.UTC)))
+		})
+
+		It("should surface the TaskRun results emitting from output step with suppressed vulnerabilities", func() {
+			tr.Status.Results = append(tr.Status.Results,
+				pipelineapi.TaskRunResult{
+					Name: "shp-image-suppressed-vulnerabilities",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: "CVE-2024-0001:c:vulnerable_code_not_present,CVE-2024-0002:l:",
+					},
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.Results, taskRunRequest)
+
+			Expect(br.Status.Output.SuppressedVulnerabilities).To(Equal([]build.SuppressedVulnerability{
+				{ID: "CVE-2024-0001", Severity: build.Critical, Justification: "vulnerable_code_not_present"},
+				{ID: "CVE-2024-0002", Severity: build.Low},
+			}))
+		})
+
+		It("should surface the TaskRun results emitting from output step with all references of the image", func() {
+			tr.Status.Results = append(tr.Status.Results,
+				pipelineapi.TaskRunResult{
+					Name: "shp-image-references",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: "some-registry/some-image,some-registry/some-image:v1.0.0,other-registry/some-image",
+					},
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.Results, taskRunRequest)
+
+			Expect(br.Status.Output.Images).To(Equal([]string{
+				"some-registry/some-image",
+				"some-registry/some-image:v1.0.0",
+				"other-registry/some-image",
+			}))
+		})
+
+		It("should surface the TaskRun results emitting from output step with the digests per platform", func() {
+			tr.Status.Results = append(tr.Status.Results,
+				pipelineapi.TaskRunResult{
+					Name: "shp-image-platforms",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: `[{"platform":"linux/amd64","digest":"sha256:1111"},{"platform":"linux/arm64/v8","digest":"sha256:2222"}]`,
+					},
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.Results, taskRunRequest)
+
+			Expect(br.Status.Output.Platforms).To(Equal([]build.PlatformDigest{
+				{Platform: "linux/amd64", Digest: "sha256:1111"},
+				{Platform: "linux/arm64/v8", Digest: "sha256:2222"},
+			}))
+		})
+
+		It("should surface the TaskRun results emitting from output step with license violations", func() {
+			tr.Status.Results = append(tr.Status.Results,
+				pipelineapi.TaskRunResult{
+					Name: "shp-image-license-violations",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: `[{"package":"bash","license":"GPL-3.0-only"}]`,
+					},
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.Results, taskRunRequest)
+
+			Expect(br.Status.Output.LicenseViolations).To(Equal([]build.LicenseViolation{
+				{Package: "bash", License: "GPL-3.0-only"},
+			}))
+		})
+
+		It("should surface the TaskRun results emitting from output step with the vulnerability summary and report digest", func() {
+			tr.Status.Results = append(tr.Status.Results,
+				pipelineapi.TaskRunResult{
+					Name: "shp-image-vulnerability-summary",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: `{"critical":1,"high":2,"medium":3,"low":4,"unknown":0}`,
+					},
+				},
+				pipelineapi.TaskRunResult{
+					Name: "shp-image-vulnerability-report-digest",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: "sha256:4c8f9e2b1a0d3c5e7f6a8b9c0d1e2f3a",
+					},
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.Results, taskRunRequest)
+
+			Expect(br.Status.Output.VulnerabilitySummary).To(Equal(&build.VulnerabilitySummary{Critical: 1, High: 2, Medium: 3, Low: 4}))
+			Expect(br.Status.Output.VulnerabilityReportDigest).To(Equal("sha256:4c8f9e2b1a0d3c5e7f6a8b9c0d1e2f3a"))
+		})
+
+		It("should surface which lists were truncated", func() {
+			tr.Status.Results = append(tr.Status.Results,
+				pipelineapi.TaskRunResult{
+					Name: "shp-image-truncated-results",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: "suppressedVulnerabilities,licenseViolations",
+					},
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.Results, taskRunRequest)
+
+			Expect(br.Status.Output.SuppressedVulnerabilitiesTruncated).To(BeTrue())
+			Expect(br.Status.Output.LicenseViolationsTruncated).To(BeTrue())
+		})
+
+		It("should surface why the vulnerability report was omitted", func() {
+			tr.Status.Results = append(tr.Status.Results,
+				pipelineapi.TaskRunResult{
+					Name: "shp-image-vulnerability-report-omitted",
+					Value: pipelineapi.ParamValue{
+						Type:      pipelineapi.ParamTypeString,
+						StringVal: "the compressed vulnerability report has 4711 bytes and exceeds the maximum result size of 1024 bytes",
+					},
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.Results, taskRunRequest)
+
+			Expect(br.Status.Output.VulnerabilityReportOmitted).To(Equal("the compressed vulnerability report has 4711 bytes and exceeds the maximum result size of 1024 bytes"))
+		})
+
 		It("should surface the TaskRun results emitting from source and output step", func() {
 			commitSha := "0e0583421a5e4bf562ffe33f3651e16ba0c78591"
 			imageDigest := "sha256:fe1b73cd25ac3f11dec752755e2"
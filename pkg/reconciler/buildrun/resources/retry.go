@@ -0,0 +1,156 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxRetryBackoff is the longest time to wait between two attempts of a BuildRun
+const maxRetryBackoff = time.Hour
+
+// GetRetry returns the retry of a build run, the one of the build run takes precedence over
+// the one of the build
+func GetRetry(buildSpec *buildv1beta1.BuildSpec, buildRun *buildv1beta1.BuildRun) *buildv1beta1.Retry {
+	if buildRun.Spec.Retry != nil {
+		return buildRun.Spec.Retry
+	}
+
+	if buildSpec != nil {
+		return buildSpec.Retry
+	}
+
+	return nil
+}
+
+// GetRetryBackoff returns the time to wait after the given number of failed attempts, the
+// backoff doubles for every further attempt up to one hour
+func GetRetryBackoff(retry *buildv1beta1.Retry, failedAttempts int) time.Duration {
+	backoff := buildv1beta1.DefaultRetryBackoff
+	if retry != nil && retry.Backoff != nil {
+		backoff = retry.Backoff.Duration
+	}
+
+	for i := 1; i < failedAttempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, maxRetryBackoff)
+}
+
+// GetRemainingRetryBackoff returns the time that a retried build run still has to wait before
+// the TaskRun of its next attempt is created
+func GetRemainingRetryBackoff(buildRun *buildv1beta1.BuildRun) time.Duration {
+	if len(buildRun.Status.Attempts) == 0 {
+		return 0
+	}
+
+	lastAttempt := buildRun.Status.Attempts[len(buildRun.Status.Attempts)-1]
+	if lastAttempt.CompletionTime == nil {
+		return 0
+	}
+
+	backoff := GetRetryBackoff(GetRetry(buildRun.Status.BuildSpec, buildRun), len(buildRun.Status.Attempts))
+	return time.Until(lastAttempt.CompletionTime.Add(backoff))
+}
+
+// IsPreviousAttempt returns whether a TaskRun belongs to an attempt of a build run that was
+// already retried
+func IsPreviousAttempt(buildRun *buildv1beta1.BuildRun, taskRunName string) bool {
+	return slices.ContainsFunc(buildRun.Status.Attempts, func(attempt buildv1beta1.BuildRunAttempt) bool {
+		return attempt.TaskRunName == taskRunName
+	})
+}
+
+// RetryBuildRun records the failed TaskRun of a build run as an attempt and resets the build run
+// for a new TaskRun if the failure reason is retryable and attempts are left. It returns whether
+// the build run is retried.
+func RetryBuildRun(buildRun *buildv1beta1.BuildRun, taskRun *pipelineapi.TaskRun) bool {
+	if buildRun.IsCanceled() {
+		return false
+	}
+
+	// a local source is uploaded once into the TaskRun of the first attempt, the TaskRun of
+	// another attempt would wait for an upload that never comes
+	if hasLocalSource(buildRun) {
+		return false
+	}
+
+	condition := buildRun.Status.GetCondition(buildv1beta1.Succeeded)
+	if condition == nil || condition.Status != corev1.ConditionFalse {
+		return false
+	}
+
+	retry := GetRetry(buildRun.Status.BuildSpec, buildRun)
+	if retry == nil || len(buildRun.Status.Attempts)+1 >= retry.MaxAttempts {
+		return false
+	}
+
+	reasons := retry.Reasons
+	if len(reasons) == 0 {
+		reasons = buildv1beta1.DefaultRetryReasons
+	}
+
+	reason := condition.Reason
+	if !slices.Contains(reasons, reason) {
+		if buildRun.Status.FailureDetails == nil || !slices.Contains(reasons, buildRun.Status.FailureDetails.Reason) {
+			return false
+		}
+
+		reason = buildRun.Status.FailureDetails.Reason
+	}
+
+	completionTime := taskRun.Status.CompletionTime
+	if completionTime == nil {
+		now := metav1.Now()
+		completionTime = &now
+	}
+
+	buildRun.Status.Attempts = append(buildRun.Status.Attempts, buildv1beta1.BuildRunAttempt{
+		TaskRunName:    taskRun.Name,
+		Reason:         reason,
+		Message:        condition.Message,
+		StartTime:      taskRun.Status.StartTime,
+		CompletionTime: completionTime,
+	})
+
+	backoff := GetRetryBackoff(retry, len(buildRun.Status.Attempts))
+
+	// the results of the failed attempt do not describe the next attempt
+	buildRun.Status.TaskRunName = nil
+	buildRun.Status.FailureDetails = nil
+	buildRun.Status.Output = nil
+	buildRun.Status.Source = nil
+	buildRun.Status.SetCondition(&buildv1beta1.Condition{
+		LastTransitionTime: metav1.Now(),
+		Type:               buildv1beta1.Succeeded,
+		Status:             corev1.ConditionUnknown,
+		Reason:             buildv1beta1.BuildRunStateRetrying,
+		Message: fmt.Sprintf("attempt %d of %d failed with reason %s, retrying in %s",
+			len(buildRun.Status.Attempts),
+			retry.MaxAttempts,
+			reason,
+			backoff,
+		),
+	})
+
+	return true
+}
+
+func hasLocalSource(buildRun *buildv1beta1.BuildRun) bool {
+	if buildRun.Spec.Source != nil {
+		return buildRun.Spec.Source.Type == buildv1beta1.LocalType
+	}
+
+	buildSpec := buildRun.Status.BuildSpec
+	return buildSpec != nil && buildSpec.Source != nil && buildSpec.Source.Type == buildv1beta1.LocalType
+}
@@ -0,0 +1,193 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
+	test "github.com/shipwright-io/build/test/v1beta1_samples"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("Retrying BuildRuns", func() {
+	var (
+		ctl            test.Catalog
+		buildRunSample *buildv1beta1.BuildRun
+		taskRunSample  *pipelineapi.TaskRun
+	)
+
+	failWith := func(reason string) {
+		buildRunSample.Status.SetCondition(&buildv1beta1.Condition{
+			Type:    buildv1beta1.Succeeded,
+			Status:  corev1.ConditionFalse,
+			Reason:  reason,
+			Message: "the attempt failed",
+		})
+	}
+
+	BeforeEach(func() {
+		buildRunSample = ctl.DefaultBuildRun("foobuildrun", "foobuild")
+		buildRunSample.Status.BuildSpec = &buildv1beta1.BuildSpec{}
+		buildRunSample.Status.TaskRunName = ptr.To("foobuildrun-abcde")
+
+		taskRunSample = &pipelineapi.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "foobuildrun-abcde"}}
+		taskRunSample.Status.StartTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+		taskRunSample.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	})
+
+	Context("GetRetry", func() {
+		It("should prefer the retry of the BuildRun over the one of the Build", func() {
+			buildRunSample.Status.BuildSpec.Retry = &buildv1beta1.Retry{MaxAttempts: 2}
+			buildRunSample.Spec.Retry = &buildv1beta1.Retry{MaxAttempts: 3}
+
+			Expect(resources.GetRetry(buildRunSample.Status.BuildSpec, buildRunSample)).To(Equal(buildRunSample.Spec.Retry))
+		})
+
+		It("should use the retry of the Build if the BuildRun has none", func() {
+			buildRunSample.Status.BuildSpec.Retry = &buildv1beta1.Retry{MaxAttempts: 2}
+
+			Expect(resources.GetRetry(buildRunSample.Status.BuildSpec, buildRunSample)).To(Equal(buildRunSample.Status.BuildSpec.Retry))
+		})
+	})
+
+	Context("GetRetryBackoff", func() {
+		It("should default the backoff to 30 seconds", func() {
+			Expect(resources.GetRetryBackoff(&buildv1beta1.Retry{MaxAttempts: 2}, 1)).To(Equal(30 * time.Second))
+		})
+
+		It("should double the backoff for every further attempt up to one hour", func() {
+			retry := &buildv1beta1.Retry{MaxAttempts: 20, Backoff: &metav1.Duration{Duration: 10 * time.Second}}
+
+			Expect(resources.GetRetryBackoff(retry, 1)).To(Equal(10 * time.Second))
+			Expect(resources.GetRetryBackoff(retry, 2)).To(Equal(20 * time.Second))
+			Expect(resources.GetRetryBackoff(retry, 3)).To(Equal(40 * time.Second))
+			Expect(resources.GetRetryBackoff(retry, 15)).To(Equal(time.Hour))
+		})
+	})
+
+	Context("RetryBuildRun", func() {
+		It("should not retry a BuildRun without retry", func() {
+			failWith(buildv1beta1.BuildRunStatePodEvicted)
+
+			Expect(resources.RetryBuildRun(buildRunSample, taskRunSample)).To(BeFalse())
+			Expect(buildRunSample.Status.Attempts).To(BeEmpty())
+		})
+
+		It("should retry an infrastructure failure by default and record the attempt", func() {
+			buildRunSample.Spec.Retry = &buildv1beta1.Retry{MaxAttempts: 3}
+			failWith(buildv1beta1.BuildRunStatePodEvicted)
+			buildRunSample.Status.FailureDetails = &buildv1beta1.FailureDetails{Reason: "something"}
+			buildRunSample.Status.Source = &buildv1beta1.SourceResult{Timestamp: &metav1.Time{Time: time.Now()}}
+			buildRunSample.Status.Output = &buildv1beta1.Output{Digest: "sha256:0123456789abcdef"}
+
+			Expect(resources.RetryBuildRun(buildRunSample, taskRunSample)).To(BeTrue())
+			Expect(buildRunSample.Status.Attempts).To(Equal([]buildv1beta1.BuildRunAttempt{{
+				TaskRunName:    "foobuildrun-abcde",
+				Reason:         buildv1beta1.BuildRunStatePodEvicted,
+				Message:        "the attempt failed",
+				StartTime:      taskRunSample.Status.StartTime,
+				CompletionTime: taskRunSample.Status.CompletionTime,
+			}}))
+			Expect(buildRunSample.Status.TaskRunName).To(BeNil())
+			Expect(buildRunSample.Status.FailureDetails).To(BeNil())
+			Expect(buildRunSample.Status.Source).To(BeNil())
+			Expect(buildRunSample.Status.Output).To(BeNil())
+
+			condition := buildRunSample.Status.GetCondition(buildv1beta1.Succeeded)
+			Expect(condition.Status).To(Equal(corev1.ConditionUnknown))
+			Expect(condition.Reason).To(Equal(buildv1beta1.BuildRunStateRetrying))
+			Expect(condition.Message).To(Equal("attempt 1 of 3 failed with reason PodEvicted, retrying in 30s"))
+		})
+
+		It("should retry a failure whose failure details reason is retryable", func() {
+			buildRunSample.Spec.Retry = &buildv1beta1.Retry{MaxAttempts: 2}
+			failWith(string(pipelineapi.TaskRunReasonFailed))
+			buildRunSample.Status.FailureDetails = &buildv1beta1.FailureDetails{Reason: buildv1beta1.GitErrorReason}
+
+			Expect(resources.RetryBuildRun(buildRunSample, taskRunSample)).To(BeTrue())
+			Expect(buildRunSample.Status.Attempts[0].Reason).To(Equal(buildv1beta1.GitErrorReason))
+		})
+
+		It("should not retry a reason that is not configured", func() {
+			buildRunSample.Spec.Retry = &buildv1beta1.Retry{MaxAttempts: 3, Reasons: []string{buildv1beta1.BuildRunStateStepOutOfMemory}}
+			failWith(buildv1beta1.BuildRunStatePodEvicted)
+
+			Expect(resources.RetryBuildRun(buildRunSample, taskRunSample)).To(BeFalse())
+		})
+
+		It("should not retry VulnerabilitiesFound by default", func() {
+			buildRunSample.Spec.Retry = &buildv1beta1.Retry{MaxAttempts: 3}
+			failWith(buildv1beta1.BuildRunStateVulnerabilitiesFound)
+
+			Expect(resources.RetryBuildRun(buildRunSample, taskRunSample)).To(BeFalse())
+		})
+
+		It("should not retry once the maximum number of attempts is reached", func() {
+			buildRunSample.Spec.Retry = &buildv1beta1.Retry{MaxAttempts: 2}
+			buildRunSample.Status.Attempts = []buildv1beta1.BuildRunAttempt{{TaskRunName: "foobuildrun-fghij"}}
+			failWith(buildv1beta1.BuildRunStatePodEvicted)
+
+			Expect(resources.RetryBuildRun(buildRunSample, taskRunSample)).To(BeFalse())
+		})
+
+		It("should not retry a BuildRun with a Local source", func() {
+			buildRunSample.Spec.Retry = &buildv1beta1.Retry{MaxAttempts: 3}
+			buildRunSample.Spec.Source = &buildv1beta1.BuildRunSource{
+				Type:  buildv1beta1.LocalType,
+				Local: &buildv1beta1.Local{Name: "local"},
+			}
+			failWith(buildv1beta1.BuildRunStatePodEvicted)
+
+			Expect(resources.RetryBuildRun(buildRunSample, taskRunSample)).To(BeFalse())
+			Expect(buildRunSample.Status.Attempts).To(BeEmpty())
+		})
+
+		It("should not retry a BuildRun of a Build with a Local source", func() {
+			buildRunSample.Spec.Retry = &buildv1beta1.Retry{MaxAttempts: 3}
+			buildRunSample.Status.BuildSpec.Source = &buildv1beta1.Source{
+				Type:  buildv1beta1.LocalType,
+				Local: &buildv1beta1.Local{Name: "local"},
+			}
+			failWith(buildv1beta1.BuildRunStatePodEvicted)
+
+			Expect(resources.RetryBuildRun(buildRunSample, taskRunSample)).To(BeFalse())
+		})
+
+		It("should not retry a canceled BuildRun", func() {
+			buildRunSample.Spec.Retry = &buildv1beta1.Retry{MaxAttempts: 3}
+			buildRunSample.Spec.State = buildv1beta1.BuildRunRequestedStatePtr(buildv1beta1.BuildRunStateCancel)
+			failWith(buildv1beta1.BuildRunStatePodEvicted)
+
+			Expect(resources.RetryBuildRun(buildRunSample, taskRunSample)).To(BeFalse())
+		})
+	})
+
+	Context("Previous attempts", func() {
+		It("should identify the TaskRun of a previous attempt", func() {
+			buildRunSample.Status.Attempts = []buildv1beta1.BuildRunAttempt{{TaskRunName: "foobuildrun-fghij"}}
+
+			Expect(resources.IsPreviousAttempt(buildRunSample, "foobuildrun-fghij")).To(BeTrue())
+			Expect(resources.IsPreviousAttempt(buildRunSample, "foobuildrun-abcde")).To(BeFalse())
+		})
+
+		It("should return the remaining backoff after the last attempt", func() {
+			buildRunSample.Spec.Retry = &buildv1beta1.Retry{MaxAttempts: 3, Backoff: &metav1.Duration{Duration: time.Minute}}
+			buildRunSample.Status.Attempts = []buildv1beta1.BuildRunAttempt{{
+				TaskRunName:    "foobuildrun-fghij",
+				CompletionTime: &metav1.Time{Time: time.Now().Add(-20 * time.Second)},
+			}}
+
+			remaining := resources.GetRemainingRetryBackoff(buildRunSample)
+			Expect(remaining).To(BeNumerically("~", 40*time.Second, 2*time.Second))
+		})
+	})
+})
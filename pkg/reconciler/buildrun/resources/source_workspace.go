@@ -0,0 +1,194 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/ctxlog"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetSourceWorkspace returns the source workspace of a build run, the one of the build run
+// takes precedence over the one of the build
+func GetSourceWorkspace(buildSpec *buildv1beta1.BuildSpec, buildRun *buildv1beta1.BuildRun) *buildv1beta1.SourceWorkspace {
+	if buildRun.Spec.SourceWorkspace != nil {
+		return buildRun.Spec.SourceWorkspace
+	}
+
+	if buildSpec != nil {
+		return buildSpec.SourceWorkspace
+	}
+
+	return nil
+}
+
+// GetGeneratedSourceWorkspaceClaimName returns the name of the generated persistent volume claim for the current
+// attempt of a build run, retries use their own claim so that they start with an empty source directory
+func GetGeneratedSourceWorkspaceClaimName(buildRun *buildv1beta1.BuildRun) string {
+	return getGeneratedSourceWorkspaceClaimName(buildRun, len(buildRun.Status.Attempts)+1)
+}
+
+func getGeneratedSourceWorkspaceClaimName(buildRun *buildv1beta1.BuildRun, attempt int) string {
+	if attempt > 1 {
+		return fmt.Sprintf("%s-source-%d", buildRun.Name, attempt)
+	}
+
+	return fmt.Sprintf("%s-source", buildRun.Name)
+}
+
+// getSourceWorkspaceSubPath returns the directory of the current attempt of a build run in a shared persistent
+// volume claim
+func getSourceWorkspaceSubPath(buildRun *buildv1beta1.BuildRun) string {
+	if attempt := len(buildRun.Status.Attempts) + 1; attempt > 1 {
+		return fmt.Sprintf("%s-%d", buildRun.Name, attempt)
+	}
+
+	return buildRun.Name
+}
+
+// getSourceWorkspaceBinding returns the workspace binding for the source files, an emptyDir
+// volume is used unless a persistent volume claim is configured
+func getSourceWorkspaceBinding(build *buildv1beta1.Build, buildRun *buildv1beta1.BuildRun) pipelineapi.WorkspaceBinding {
+	binding := pipelineapi.WorkspaceBinding{Name: workspaceSource}
+
+	sourceWorkspace := GetSourceWorkspace(&build.Spec, buildRun)
+	switch {
+	case sourceWorkspace != nil && sourceWorkspace.VolumeClaimTemplate != nil:
+		binding.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: GetGeneratedSourceWorkspaceClaimName(buildRun),
+		}
+
+	case sourceWorkspace != nil && sourceWorkspace.PersistentVolumeClaimName != nil:
+		// build runs and their attempts that share a claim each use their own directory
+		binding.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: *sourceWorkspace.PersistentVolumeClaimName,
+		}
+		binding.SubPath = getSourceWorkspaceSubPath(buildRun)
+
+	default:
+		binding.EmptyDir = &corev1.EmptyDirVolumeSource{}
+	}
+
+	return binding
+}
+
+// CreateSourceWorkspaceClaim creates the persistent volume claim for the source files if the
+// source workspace uses a volume claim template
+func CreateSourceWorkspaceClaim(ctx context.Context, client client.Client, build *buildv1beta1.Build, buildRun *buildv1beta1.BuildRun) error {
+	sourceWorkspace := GetSourceWorkspace(&build.Spec, buildRun)
+	if sourceWorkspace == nil || sourceWorkspace.VolumeClaimTemplate == nil {
+		return nil
+	}
+
+	claim := &corev1.PersistentVolumeClaim{}
+	err := client.Get(
+		ctx,
+		types.NamespacedName{
+			Name:      GetGeneratedSourceWorkspaceClaimName(buildRun),
+			Namespace: buildRun.Namespace},
+		claim)
+
+	switch {
+	case err == nil && metav1.IsControlledBy(claim, buildRun): // if the claim already exists, do nothing
+		ctxlog.Info(ctx, "persistentVolumeClaim for BuildRun already exists", namespace, buildRun.Namespace, name, claim.Name, "BuildRun", buildRun.Name)
+		return nil
+
+	case err == nil: // a claim with the same name that was not generated for the BuildRun must not be used
+		conflictErr := fmt.Errorf("persistent volume claim %s already exists and is not owned by the BuildRun", claim.Name)
+		if updateErr := UpdateConditionWithFalseStatus(ctx, client, buildRun, conflictErr.Error(), BuildRunSourceWorkspaceClaimConflict); updateErr != nil {
+			return HandleError("failed to create the persistent volume claim", conflictErr, updateErr)
+		}
+
+		return conflictErr
+
+	case apierrors.IsNotFound(err):
+		claim = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      GetGeneratedSourceWorkspaceClaimName(buildRun),
+				Namespace: buildRun.Namespace,
+				Labels:    map[string]string{buildv1beta1.LabelBuildRun: buildRun.Name},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(buildRun, buildv1beta1.SchemeGroupVersion.WithKind("BuildRun")),
+				},
+			},
+			Spec: *sourceWorkspace.VolumeClaimTemplate.DeepCopy(),
+		}
+
+		if err := client.Create(ctx, claim); err != nil {
+			return err
+		}
+
+		ctxlog.Info(ctx, "created persistentVolumeClaim for BuildRun", namespace, buildRun.Namespace, name, claim.Name, "BuildRun", buildRun.Name)
+		return nil
+
+	default:
+		return err
+	}
+}
+
+// DeleteSourceWorkspaceClaim deletes the persistent volume claim of a completed BuildRun if it
+// was generated and the deletion policy asks for it. Retained claims are removed together with
+// the BuildRun through the owner reference.
+func DeleteSourceWorkspaceClaim(ctx context.Context, client client.Client, completedBuildRun *buildv1beta1.BuildRun, succeeded bool) error {
+	return deleteSourceWorkspaceClaim(ctx, client, completedBuildRun, GetGeneratedSourceWorkspaceClaimName(completedBuildRun), succeeded)
+}
+
+// DeleteRetriedSourceWorkspaceClaim deletes the persistent volume claim of the last failed attempt
+// of a retried BuildRun if it was generated and the deletion policy asks for it
+func DeleteRetriedSourceWorkspaceClaim(ctx context.Context, client client.Client, retriedBuildRun *buildv1beta1.BuildRun) error {
+	return deleteSourceWorkspaceClaim(ctx, client, retriedBuildRun, getGeneratedSourceWorkspaceClaimName(retriedBuildRun, len(retriedBuildRun.Status.Attempts)), false)
+}
+
+func deleteSourceWorkspaceClaim(ctx context.Context, client client.Client, buildRun *buildv1beta1.BuildRun, claimName string, succeeded bool) error {
+	sourceWorkspace := GetSourceWorkspace(buildRun.Status.BuildSpec, buildRun)
+	if sourceWorkspace == nil || sourceWorkspace.VolumeClaimTemplate == nil {
+		return nil
+	}
+
+	deletionPolicy := buildv1beta1.SourceWorkspaceDelete
+	if sourceWorkspace.DeletionPolicy != nil {
+		deletionPolicy = *sourceWorkspace.DeletionPolicy
+	}
+
+	switch deletionPolicy {
+	case buildv1beta1.SourceWorkspaceRetain:
+		return nil
+
+	case buildv1beta1.SourceWorkspaceDeleteOnSuccess:
+		if !succeeded {
+			return nil
+		}
+	}
+
+	claim := &corev1.PersistentVolumeClaim{}
+	if err := client.Get(ctx, types.NamespacedName{Name: claimName, Namespace: buildRun.Namespace}, claim); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	// only delete a claim that was generated for the BuildRun
+	if !metav1.IsControlledBy(claim, buildRun) {
+		ctxlog.Info(ctx, "persistent volume claim is not owned by the BuildRun and is not deleted", namespace, buildRun.Namespace, name, buildRun.Name, "persistentVolumeClaim", claim.Name)
+		return nil
+	}
+
+	ctxlog.Info(ctx, "deleting persistent volume claim", namespace, buildRun.Namespace, name, buildRun.Name, "persistentVolumeClaim", claim.Name)
+	if err := client.Delete(ctx, claim); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
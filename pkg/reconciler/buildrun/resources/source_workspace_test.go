@@ -0,0 +1,183 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/controller/fakes"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
+	test "github.com/shipwright-io/build/test/v1beta1_samples"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Operating source workspace claims", func() {
+	var (
+		client         *fakes.FakeClient
+		ctl            test.Catalog
+		buildSample    *buildv1beta1.Build
+		buildRunSample *buildv1beta1.BuildRun
+	)
+
+	BeforeEach(func() {
+		client = &fakes.FakeClient{}
+		buildSample = ctl.DefaultBuild("foobuild", "foostrategy", buildv1beta1.ClusterBuildStrategyKind)
+		buildRunSample = ctl.DefaultBuildRun("foobuildrun", "foobuild")
+		buildRunSample.UID = "buildrun-uid"
+	})
+
+	// existingClaim stubs the get calls to return a claim that is controlled by the given owner
+	existingClaim := func(owner *buildv1beta1.BuildRun) {
+		client.GetCalls(func(_ context.Context, nn types.NamespacedName, object crc.Object, _ ...crc.GetOption) error {
+			claim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: nn.Name, Namespace: nn.Namespace}}
+			if owner != nil {
+				claim.OwnerReferences = []metav1.OwnerReference{
+					*metav1.NewControllerRef(owner, buildv1beta1.SchemeGroupVersion.WithKind("BuildRun")),
+				}
+			}
+
+			claim.DeepCopyInto(object.(*corev1.PersistentVolumeClaim))
+			return nil
+		})
+	}
+
+	withClaimTemplate := func(deletionPolicy *buildv1beta1.SourceWorkspaceDeletionPolicy) *buildv1beta1.SourceWorkspace {
+		return &buildv1beta1.SourceWorkspace{
+			VolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: resource.MustParse("1Gi"),
+					},
+				},
+			},
+			DeletionPolicy: deletionPolicy,
+		}
+	}
+
+	Context("Creating the claim", func() {
+		It("should not create a claim if no source workspace is specified", func() {
+			Expect(resources.CreateSourceWorkspaceClaim(context.TODO(), client, buildSample, buildRunSample)).To(Succeed())
+			Expect(client.GetCallCount()).To(Equal(0))
+			Expect(client.CreateCallCount()).To(Equal(0))
+		})
+
+		It("should not create a claim if an existing claim is referenced", func() {
+			buildSample.Spec.SourceWorkspace = &buildv1beta1.SourceWorkspace{PersistentVolumeClaimName: ptr.To("shared")}
+
+			Expect(resources.CreateSourceWorkspaceClaim(context.TODO(), client, buildSample, buildRunSample)).To(Succeed())
+			Expect(client.CreateCallCount()).To(Equal(0))
+		})
+
+		It("should create a claim with a label and an ownerreference if it does not exist", func() {
+			buildSample.Spec.SourceWorkspace = withClaimTemplate(nil)
+
+			client.GetCalls(func(_ context.Context, nn types.NamespacedName, _ crc.Object, _ ...crc.GetOption) error {
+				return k8serrors.NewNotFound(schema.GroupResource{}, nn.Name)
+			})
+
+			client.CreateCalls(func(_ context.Context, object crc.Object, _ ...crc.CreateOption) error {
+				claim, ok := object.(*corev1.PersistentVolumeClaim)
+				Expect(ok).To(BeTrue())
+				Expect(claim.Name).To(Equal("foobuildrun-source"))
+				Expect(claim.Labels[buildv1beta1.LabelBuildRun]).To(Equal(buildRunSample.Name))
+				Expect(claim.OwnerReferences).To(HaveLen(1))
+				Expect(claim.OwnerReferences[0].Kind).To(Equal("BuildRun"))
+				Expect(claim.Spec.Resources.Requests.Storage().String()).To(Equal("1Gi"))
+				return nil
+			})
+
+			Expect(resources.CreateSourceWorkspaceClaim(context.TODO(), client, buildSample, buildRunSample)).To(Succeed())
+			Expect(client.CreateCallCount()).To(Equal(1))
+		})
+
+		It("should not create the claim again if it already exists", func() {
+			buildRunSample.Spec.SourceWorkspace = withClaimTemplate(nil)
+			existingClaim(buildRunSample)
+
+			Expect(resources.CreateSourceWorkspaceClaim(context.TODO(), client, buildSample, buildRunSample)).To(Succeed())
+			Expect(client.GetCallCount()).To(Equal(1))
+			Expect(client.CreateCallCount()).To(Equal(0))
+		})
+
+		It("should fail the BuildRun if a claim with the same name is not owned by it", func() {
+			buildRunSample.Spec.SourceWorkspace = withClaimTemplate(nil)
+			existingClaim(nil)
+
+			statusWriter := &fakes.FakeStatusWriter{}
+			client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+
+			Expect(resources.CreateSourceWorkspaceClaim(context.TODO(), client, buildSample, buildRunSample)).To(MatchError("persistent volume claim foobuildrun-source already exists and is not owned by the BuildRun"))
+			Expect(client.CreateCallCount()).To(Equal(0))
+			Expect(statusWriter.UpdateCallCount()).To(Equal(1))
+
+			condition := buildRunSample.Status.GetCondition(buildv1beta1.Succeeded)
+			Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(resources.BuildRunSourceWorkspaceClaimConflict))
+		})
+
+		It("should return an error if the claim cannot be retrieved", func() {
+			buildSample.Spec.SourceWorkspace = withClaimTemplate(nil)
+
+			client.GetReturns(fmt.Errorf("something wrong happened"))
+
+			Expect(resources.CreateSourceWorkspaceClaim(context.TODO(), client, buildSample, buildRunSample)).ToNot(Succeed())
+		})
+	})
+
+	Context("Deleting the claim of a completed BuildRun", func() {
+		DescribeTable("honors the deletion policy",
+			func(deletionPolicy *buildv1beta1.SourceWorkspaceDeletionPolicy, succeeded bool, expectedDeletions int) {
+				buildRunSample.Status.BuildSpec = &buildSample.Spec
+				buildRunSample.Status.BuildSpec.SourceWorkspace = withClaimTemplate(deletionPolicy)
+				existingClaim(buildRunSample)
+
+				Expect(resources.DeleteSourceWorkspaceClaim(context.TODO(), client, buildRunSample, succeeded)).To(Succeed())
+				Expect(client.DeleteCallCount()).To(Equal(expectedDeletions))
+			},
+			Entry("default on success", nil, true, 1),
+			Entry("default on failure", nil, false, 1),
+			Entry("Delete on failure", ptr.To(buildv1beta1.SourceWorkspaceDelete), false, 1),
+			Entry("DeleteOnSuccess on success", ptr.To(buildv1beta1.SourceWorkspaceDeleteOnSuccess), true, 1),
+			Entry("DeleteOnSuccess on failure", ptr.To(buildv1beta1.SourceWorkspaceDeleteOnSuccess), false, 0),
+			Entry("Retain on success", ptr.To(buildv1beta1.SourceWorkspaceRetain), true, 0),
+		)
+
+		It("should not delete an existing claim that is referenced", func() {
+			buildRunSample.Spec.SourceWorkspace = &buildv1beta1.SourceWorkspace{PersistentVolumeClaimName: ptr.To("shared")}
+
+			Expect(resources.DeleteSourceWorkspaceClaim(context.TODO(), client, buildRunSample, true)).To(Succeed())
+			Expect(client.DeleteCallCount()).To(Equal(0))
+		})
+
+		It("should not delete a claim with the same name that is not owned by the BuildRun", func() {
+			buildRunSample.Spec.SourceWorkspace = withClaimTemplate(nil)
+			existingClaim(nil)
+
+			Expect(resources.DeleteSourceWorkspaceClaim(context.TODO(), client, buildRunSample, true)).To(Succeed())
+			Expect(client.DeleteCallCount()).To(Equal(0))
+		})
+
+		It("should ignore a claim that is already gone", func() {
+			buildRunSample.Spec.SourceWorkspace = withClaimTemplate(nil)
+
+			client.GetReturns(k8serrors.NewNotFound(schema.GroupResource{}, "foobuildrun-source"))
+
+			Expect(resources.DeleteSourceWorkspaceClaim(context.TODO(), client, buildRunSample, true)).To(Succeed())
+			Expect(client.DeleteCallCount()).To(Equal(0))
+		})
+	})
+})
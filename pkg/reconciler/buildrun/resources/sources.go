@@ -18,46 +18,51 @@ import (
 	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 )
 
-const defaultSourceName = "default"
-
 const sourceTimestampName = "source-timestamp"
 
 // isLocalCopyBuildSource appends all "Sources" in a single slice, and if any entry is typed
-// "LocalCopy" it returns first LocalCopy typed BuildSource found, or nil.
+// "LocalCopy" it returns first LocalCopy typed BuildSource found, or nil. The additional local
+// sources are returned from the same object as the LocalCopy typed BuildSource.
 func isLocalCopyBuildSource(
 	build *buildv1beta1.Build,
 	buildRun *buildv1beta1.BuildRun,
-) *buildv1beta1.Local {
+) (*buildv1beta1.Local, []buildv1beta1.Local) {
 	if buildRun.Spec.Source != nil && buildRun.Spec.Source.Type == buildv1beta1.LocalType {
-		return buildRun.Spec.Source.Local
+		return buildRun.Spec.Source.Local, buildRun.Spec.Source.AdditionalLocals
 	}
 
 	if build.Spec.Source != nil && build.Spec.Source.Type == buildv1beta1.LocalType {
-		return build.Spec.Source.Local
+		return build.Spec.Source.Local, build.Spec.Source.AdditionalLocals
 	}
 
-	return nil
+	return nil, nil
 }
 
 func appendSourceTimestampResult(taskSpec *pipelineapi.TaskSpec) {
 	taskSpec.Results = append(taskSpec.Results,
 		pipelineapi.TaskResult{
-			Name:        sources.TaskResultName(defaultSourceName, sourceTimestampName),
+			Name:        sources.TaskResultName(sources.DefaultSourceName, sourceTimestampName),
 			Description: "The timestamp of the source.",
 		},
 	)
 }
 
 // AmendTaskSpecWithSources adds the necessary steps to either wait for user upload ("LocalCopy"), or
-// alternatively, configures the Task steps to use bundle and "git clone".
+// alternatively, configures the Task steps to use bundle, object storage, inline files and "git clone".
 func AmendTaskSpecWithSources(
 	cfg *config.Config,
 	taskSpec *pipelineapi.TaskSpec,
 	build *buildv1beta1.Build,
 	buildRun *buildv1beta1.BuildRun,
 ) {
-	if localCopy := isLocalCopyBuildSource(build, buildRun); localCopy != nil {
-		sources.AppendLocalCopyStep(cfg, taskSpec, localCopy.Timeout)
+	if localCopy, additionalLocals := isLocalCopyBuildSource(build, buildRun); localCopy != nil {
+		appendSourceTimestampResult(taskSpec)
+		sources.AppendLocalCopyStep(cfg, taskSpec, localCopy.Timeout, sources.DefaultSourceName)
+
+		// each additional local source gets its own step, Tekton runs them one after the other
+		for _, additionalLocal := range additionalLocals {
+			sources.AppendAdditionalLocalCopyStep(cfg, taskSpec, additionalLocal)
+		}
 	} else if build.Spec.Source != nil {
 
 		// create the step for spec.source, either Git or Bundle
@@ -65,12 +70,21 @@ func AmendTaskSpecWithSources(
 		case buildv1beta1.OCIArtifactType:
 			if build.Spec.Source.OCIArtifact != nil {
 				appendSourceTimestampResult(taskSpec)
-				sources.AppendBundleStep(cfg, taskSpec, build.Spec.Source.OCIArtifact, defaultSourceName)
+				sources.AppendBundleStep(cfg, taskSpec, build.Spec.Source.OCIArtifact, sources.DefaultSourceName)
+			}
+		case buildv1beta1.ObjectStorageType:
+			if build.Spec.Source.ObjectStorage != nil {
+				appendSourceTimestampResult(taskSpec)
+				sources.AppendObjectStorageStep(cfg, taskSpec, build.Spec.Source.ObjectStorage, sources.DefaultSourceName)
+			}
+		case buildv1beta1.InlineType:
+			if build.Spec.Source.Inline != nil {
+				sources.AppendInlineStep(cfg, taskSpec, build.Spec.Source.Inline, sources.DefaultSourceName)
 			}
 		case buildv1beta1.GitType:
 			if build.Spec.Source.Git != nil {
 				appendSourceTimestampResult(taskSpec)
-				sources.AppendGitStep(cfg, taskSpec, *build.Spec.Source.Git, defaultSourceName)
+				sources.AppendGitStep(cfg, taskSpec, *build.Spec.Source.Git, sources.DefaultSourceName)
 			}
 		}
 	}
@@ -79,19 +93,33 @@ func AmendTaskSpecWithSources(
 func updateBuildRunStatusWithSourceResult(buildrun *buildv1beta1.BuildRun, results []pipelineapi.TaskRunResult) {
 	buildSpec := buildrun.Status.BuildSpec
 
-	if buildSpec.Source == nil {
+	switch {
+	case buildrun.Spec.Source != nil && buildrun.Spec.Source.Type == buildv1beta1.LocalType:
+		sources.AppendLocalResult(buildrun, sources.DefaultSourceName, results)
+		for _, additionalLocal := range buildrun.Spec.Source.AdditionalLocals {
+			sources.AppendAdditionalLocalResult(buildrun, additionalLocal.Name, results)
+		}
+
+	case buildSpec.Source != nil && buildSpec.Source.Type == buildv1beta1.LocalType:
+		sources.AppendLocalResult(buildrun, sources.DefaultSourceName, results)
+		for _, additionalLocal := range buildSpec.Source.AdditionalLocals {
+			sources.AppendAdditionalLocalResult(buildrun, additionalLocal.Name, results)
+		}
+
+	case buildSpec.Source == nil:
 		return
-	}
 
-	switch {
 	case buildSpec.Source.Type == buildv1beta1.OCIArtifactType && buildSpec.Source.OCIArtifact != nil:
-		sources.AppendBundleResult(buildrun, defaultSourceName, results)
+		sources.AppendBundleResult(buildrun, sources.DefaultSourceName, results)
+
+	case buildSpec.Source.Type == buildv1beta1.ObjectStorageType && buildSpec.Source.ObjectStorage != nil:
+		sources.AppendObjectStorageResult(buildrun, sources.DefaultSourceName, results)
 
 	case buildSpec.Source.Type == buildv1beta1.GitType && buildSpec.Source.Git != nil:
-		sources.AppendGitResult(buildrun, defaultSourceName, results)
+		sources.AppendGitResult(buildrun, sources.DefaultSourceName, results)
 	}
 
-	if sourceTimestamp := sources.FindResultValue(results, defaultSourceName, sourceTimestampName); strings.TrimSpace(sourceTimestamp) != "" {
+	if sourceTimestamp := sources.FindResultValue(results, sources.DefaultSourceName, sourceTimestampName); strings.TrimSpace(sourceTimestamp) != "" {
 		if sec, err := strconv.ParseInt(sourceTimestamp, 10, 64); err == nil {
 			if buildrun.Status.Source != nil {
 				buildrun.Status.Source.Timestamp = &metav1.Time{Time: time.Unix(sec, 0)}
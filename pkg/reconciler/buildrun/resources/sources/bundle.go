@@ -62,9 +62,16 @@ func AppendBundleStep(cfg *config.Config, taskSpec *pipelineapi.TaskSpec, oci *b
 		)
 	}
 
+	// add the CA bundles and client certificates of the registries
+	AppendRegistryCertificates(cfg, taskSpec, &bundleStep)
+
+	// add the mirrors of the registries
+	AppendRegistryMirrors(cfg, &bundleStep)
+
 	// add prune flag in when prune after pull is configured
 	if oci.Prune != nil && *oci.Prune == build.PruneAfterPull {
 		bundleStep.Args = append(bundleStep.Args, "--prune")
+		AppendRegistryDeleteProviders(cfg, &bundleStep)
 	}
 
 	taskSpec.Steps = append(taskSpec.Steps, bundleStep)
@@ -0,0 +1,105 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sources
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	build "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+// AppendInlineStep appends the step that writes the inline files to the TaskSpec
+func AppendInlineStep(cfg *config.Config, taskSpec *pipelineapi.TaskSpec, inline *build.Inline, name string) {
+	// append the results
+	taskSpec.Results = append(taskSpec.Results,
+		pipelineapi.TaskResult{
+			Name:        TaskResultName(name, sourceDigestResult),
+			Description: "The digest of the inline source.",
+		},
+		pipelineapi.TaskResult{
+			Name:        TaskResultName(name, sourceDirHashResult),
+			Description: "The directory hash of the inline source.",
+		},
+	)
+
+	// initialize the step from the template and the build-specific arguments
+	inlineStep := pipelineapi.Step{
+		Name:            fmt.Sprintf("source-%s", name),
+		Image:           cfg.InlineContainerTemplate.Image,
+		ImagePullPolicy: cfg.InlineContainerTemplate.ImagePullPolicy,
+		Command:         cfg.InlineContainerTemplate.Command,
+		Args: []string{
+			"--target", fmt.Sprintf("$(params.%s-%s)", PrefixParamsResultsVolumes, paramSourceRoot),
+			"--result-file-source-digest", fmt.Sprintf("$(results.%s.path)", TaskResultName(name, sourceDigestResult)),
+			"--result-file-source-dir-hash", fmt.Sprintf("$(results.%s.path)", TaskResultName(name, sourceDirHashResult)),
+		},
+		Env:              cfg.InlineContainerTemplate.Env,
+		ComputeResources: cfg.InlineContainerTemplate.Resources,
+		SecurityContext:  cfg.InlineContainerTemplate.SecurityContext,
+		WorkingDir:       cfg.InlineContainerTemplate.WorkingDir,
+	}
+
+	// mount the ConfigMap, each key is copied as file
+	if inline.ConfigMapRef != nil {
+		volumeName := fmt.Sprintf("%s-source-%s-configmap", PrefixParamsResultsVolumes, name)
+		mountPath := fmt.Sprintf("/workspace/%s", volumeName)
+
+		taskSpec.Volumes = append(taskSpec.Volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: *inline.ConfigMapRef,
+					},
+				},
+			},
+		})
+
+		inlineStep.VolumeMounts = append(inlineStep.VolumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: mountPath,
+			ReadOnly:  true,
+		})
+
+		inlineStep.Args = append(inlineStep.Args, "--from-dir", mountPath)
+	}
+
+	// mount the Secret, each key is copied as file
+	if inline.SecretRef != nil {
+		AppendSecretVolume(taskSpec, *inline.SecretRef)
+
+		mountPath := fmt.Sprintf("/workspace/%s-source-%s-secret", PrefixParamsResultsVolumes, name)
+
+		inlineStep.VolumeMounts = append(inlineStep.VolumeMounts, corev1.VolumeMount{
+			Name:      SanitizeVolumeNameForSecretName(*inline.SecretRef),
+			MountPath: mountPath,
+			ReadOnly:  true,
+		})
+
+		inlineStep.Args = append(inlineStep.Args, "--from-dir", mountPath)
+	}
+
+	// the content is base64 encoded so that Tekton does not replace variables in it
+	paths := make([]string, 0, len(inline.Files))
+	for path := range inline.Files {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		inlineStep.Args = append(inlineStep.Args,
+			"--file", fmt.Sprintf("%s=%s", path, base64.StdEncoding.EncodeToString([]byte(inline.Files[path]))),
+		)
+	}
+
+	taskSpec.Steps = append(taskSpec.Steps, inlineStep)
+}
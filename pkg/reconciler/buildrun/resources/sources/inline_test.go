@@ -0,0 +1,103 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sources_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources/sources"
+
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("Inline", func() {
+
+	cfg := config.NewDefaultConfig()
+
+	Context("when adding an inline source with files", func() {
+
+		var taskSpec *pipelineapi.TaskSpec
+
+		BeforeEach(func() {
+			taskSpec = &pipelineapi.TaskSpec{}
+			sources.AppendInlineStep(cfg, taskSpec, &buildv1beta1.Inline{
+				Files: map[string]string{
+					"Dockerfile":      "FROM $(params.base-image)",
+					"config/app.yaml": "replicas: 2",
+				},
+			}, "default")
+		})
+
+		It("adds a step with the base64 encoded files in a stable order", func() {
+			Expect(len(taskSpec.Steps)).To(Equal(1))
+			Expect(taskSpec.Steps[0].Name).To(Equal("source-default"))
+			Expect(taskSpec.Steps[0].Image).To(Equal(cfg.InlineContainerTemplate.Image))
+			Expect(taskSpec.Steps[0].Args).To(Equal([]string{
+				"--target", "$(params.shp-source-root)",
+				"--result-file-source-digest", "$(results.shp-source-default-source-digest.path)",
+				"--result-file-source-dir-hash", "$(results.shp-source-default-source-dir-hash.path)",
+				"--file", "Dockerfile=RlJPTSAkKHBhcmFtcy5iYXNlLWltYWdlKQ==",
+				"--file", "config/app.yaml=cmVwbGljYXM6IDI=",
+			}))
+		})
+
+		It("adds the results for the source digest and directory hash", func() {
+			Expect(taskSpec.Results).To(Equal([]pipelineapi.TaskResult{{
+				Name:        "shp-source-default-source-digest",
+				Description: "The digest of the inline source.",
+			}, {
+				Name:        "shp-source-default-source-dir-hash",
+				Description: "The directory hash of the inline source.",
+			}}))
+		})
+
+		It("does not add volumes", func() {
+			Expect(taskSpec.Volumes).To(BeEmpty())
+		})
+	})
+
+	Context("when adding an inline source with a ConfigMap and a Secret", func() {
+
+		var taskSpec *pipelineapi.TaskSpec
+
+		BeforeEach(func() {
+			taskSpec = &pipelineapi.TaskSpec{}
+			sources.AppendInlineStep(cfg, taskSpec, &buildv1beta1.Inline{
+				ConfigMapRef: ptr.To("dockerfile"),
+				SecretRef:    ptr.To("settings"),
+			}, "default")
+		})
+
+		It("adds volumes for the ConfigMap and the Secret", func() {
+			Expect(len(taskSpec.Volumes)).To(Equal(2))
+			Expect(taskSpec.Volumes[0].Name).To(Equal("shp-source-default-configmap"))
+			Expect(taskSpec.Volumes[0].ConfigMap).ToNot(BeNil())
+			Expect(taskSpec.Volumes[0].ConfigMap.Name).To(Equal("dockerfile"))
+			Expect(taskSpec.Volumes[1].Name).To(Equal("shp-settings"))
+			Expect(taskSpec.Volumes[1].Secret).ToNot(BeNil())
+			Expect(taskSpec.Volumes[1].Secret.SecretName).To(Equal("settings"))
+		})
+
+		It("adds a step that copies the mounted ConfigMap and Secret", func() {
+			Expect(len(taskSpec.Steps)).To(Equal(1))
+			Expect(taskSpec.Steps[0].Args).To(Equal([]string{
+				"--target", "$(params.shp-source-root)",
+				"--result-file-source-digest", "$(results.shp-source-default-source-digest.path)",
+				"--result-file-source-dir-hash", "$(results.shp-source-default-source-dir-hash.path)",
+				"--from-dir", "/workspace/shp-source-default-configmap",
+				"--from-dir", "/workspace/shp-source-default-secret",
+			}))
+			Expect(len(taskSpec.Steps[0].VolumeMounts)).To(Equal(2))
+			Expect(taskSpec.Steps[0].VolumeMounts[0].Name).To(Equal("shp-source-default-configmap"))
+			Expect(taskSpec.Steps[0].VolumeMounts[0].MountPath).To(Equal("/workspace/shp-source-default-configmap"))
+			Expect(taskSpec.Steps[0].VolumeMounts[1].Name).To(Equal("shp-settings"))
+			Expect(taskSpec.Steps[0].VolumeMounts[1].MountPath).To(Equal("/workspace/shp-source-default-secret"))
+		})
+	})
+})
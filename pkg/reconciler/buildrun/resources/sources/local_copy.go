@@ -5,9 +5,11 @@ package sources
 
 import (
 	"fmt"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	build "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
 	"github.com/shipwright-io/build/pkg/config"
 	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 )
@@ -15,9 +17,40 @@ import (
 // WaiterContainerName name given to the container watier container.
 const WaiterContainerName = "source-local"
 
+const (
+	sourceDigestResult  = "source-digest"
+	sourceDirHashResult = "source-dir-hash"
+)
+
+// LocalCopyStepName returns the name of the step that waits for the upload of the additional
+// local source with the provided name, this allows client tools to address each upload target.
+func LocalCopyStepName(name string) string {
+	return fmt.Sprintf("%s-%s", WaiterContainerName, name)
+}
+
 // AppendLocalCopyStep defines and append a new task based on the waiter container template, passed
 // by the configuration instance.
-func AppendLocalCopyStep(cfg *config.Config, taskSpec *pipelineapi.TaskSpec, timeout *metav1.Duration) {
+func AppendLocalCopyStep(cfg *config.Config, taskSpec *pipelineapi.TaskSpec, timeout *metav1.Duration, name string) {
+	// append the results
+	taskSpec.Results = append(taskSpec.Results,
+		pipelineapi.TaskResult{
+			Name:        TaskResultName(name, sourceDigestResult),
+			Description: "The digest of the uploaded source.",
+		},
+		pipelineapi.TaskResult{
+			Name:        TaskResultName(name, sourceDirHashResult),
+			Description: "The directory hash of the uploaded source.",
+		},
+		pipelineapi.TaskResult{
+			Name:        TaskResultName(name, commitSHAResult),
+			Description: "The commit SHA of the uploaded source, if it contains a Git repository.",
+		},
+		pipelineapi.TaskResult{
+			Name:        TaskResultName(name, branchName),
+			Description: "The branch name of the uploaded source, if it contains a Git repository.",
+		},
+	)
+
 	step := pipelineapi.Step{
 		// the data upload mechanism targets a specific POD, and in this POD it aims for a specific
 		// container name, and having a static name, makes this process straight forward.
@@ -35,5 +68,109 @@ func AppendLocalCopyStep(cfg *config.Config, taskSpec *pipelineapi.TaskSpec, tim
 	if timeout != nil {
 		step.Args = append(step.Args, fmt.Sprintf("--timeout=%s", timeout.Duration.String()))
 	}
+
+	// once the upload is done, the waiter inspects the source and reports the results
+	step.Args = append(step.Args,
+		"--source-dir", fmt.Sprintf("$(params.%s-%s)", PrefixParamsResultsVolumes, paramSourceRoot),
+		"--result-file-source-digest", fmt.Sprintf("$(results.%s.path)", TaskResultName(name, sourceDigestResult)),
+		"--result-file-source-dir-hash", fmt.Sprintf("$(results.%s.path)", TaskResultName(name, sourceDirHashResult)),
+		"--result-file-source-timestamp", fmt.Sprintf("$(results.%s.path)", TaskResultName(name, "source-timestamp")),
+		"--result-file-commit-sha", fmt.Sprintf("$(results.%s.path)", TaskResultName(name, commitSHAResult)),
+		"--result-file-branch-name", fmt.Sprintf("$(results.%s.path)", TaskResultName(name, branchName)),
+	)
+
+	taskSpec.Steps = append(taskSpec.Steps, step)
+}
+
+// AppendAdditionalLocalCopyStep defines and appends a step to wait for the upload of an additional
+// local source. In difference to the step created by AppendLocalCopyStep, the step is named after
+// the source, and uses its own lock-file and a sub-directory of the source directory as target.
+func AppendAdditionalLocalCopyStep(cfg *config.Config, taskSpec *pipelineapi.TaskSpec, local build.Local) {
+	// append the result
+	taskSpec.Results = append(taskSpec.Results,
+		pipelineapi.TaskResult{
+			Name:        TaskResultName(local.Name, sourceDigestResult),
+			Description: fmt.Sprintf("The digest of the uploaded source %s.", local.Name),
+		},
+	)
+
+	targetDirectory := fmt.Sprintf("$(params.%s-%s)/%s", PrefixParamsResultsVolumes, paramSourceRoot, local.Name)
+
+	step := pipelineapi.Step{
+		Name:             LocalCopyStepName(local.Name),
+		Image:            cfg.WaiterContainerTemplate.Image,
+		ImagePullPolicy:  cfg.WaiterContainerTemplate.ImagePullPolicy,
+		Command:          cfg.WaiterContainerTemplate.Command,
+		Args:             cfg.WaiterContainerTemplate.Args,
+		Env:              cfg.WaiterContainerTemplate.Env,
+		ComputeResources: cfg.WaiterContainerTemplate.Resources,
+		SecurityContext:  cfg.WaiterContainerTemplate.SecurityContext,
+		// the working directory is created by Tekton, and tells client tools where to upload the data to
+		WorkingDir: targetDirectory,
+	}
+
+	if local.Timeout != nil {
+		step.Args = append(step.Args, fmt.Sprintf("--timeout=%s", local.Timeout.Duration.String()))
+	}
+
+	step.Args = append(step.Args,
+		"--lock-file", fmt.Sprintf("/tmp/waiter-%s.lock", local.Name),
+		"--source-dir", targetDirectory,
+		"--result-file-source-digest", fmt.Sprintf("$(results.%s.path)", TaskResultName(local.Name, sourceDigestResult)),
+	)
+
 	taskSpec.Steps = append(taskSpec.Steps, step)
 }
+
+// AppendLocalResult append local source result to build run
+func AppendLocalResult(buildRun *build.BuildRun, name string, results []pipelineapi.TaskRunResult) {
+	sourceDigest := FindResultValue(results, name, sourceDigestResult)
+	commitSha := FindResultValue(results, name, commitSHAResult)
+	branchName := FindResultValue(results, name, branchName)
+
+	if strings.TrimSpace(sourceDigest) != "" {
+		if buildRun.Status.Source == nil {
+			buildRun.Status.Source = &build.SourceResult{}
+		}
+		buildRun.Status.Source.Local = &build.LocalSourceResult{
+			Digest: sourceDigest,
+		}
+	}
+
+	if strings.TrimSpace(commitSha) != "" {
+		if buildRun.Status.Source == nil {
+			buildRun.Status.Source = &build.SourceResult{}
+		}
+		buildRun.Status.Source.Git = &build.GitSourceResult{
+			CommitSha:  commitSha,
+			BranchName: branchName,
+		}
+	}
+}
+
+// AppendAdditionalLocalResult append the result of an additional local source to build run
+func AppendAdditionalLocalResult(buildRun *build.BuildRun, name string, results []pipelineapi.TaskRunResult) {
+	sourceDigest := FindResultValue(results, name, sourceDigestResult)
+
+	if strings.TrimSpace(sourceDigest) == "" {
+		return
+	}
+
+	if buildRun.Status.Source == nil {
+		buildRun.Status.Source = &build.SourceResult{}
+	}
+
+	result := build.LocalSourceResult{
+		Name:   name,
+		Digest: sourceDigest,
+	}
+
+	for i := range buildRun.Status.Source.AdditionalLocals {
+		if buildRun.Status.Source.AdditionalLocals[i].Name == name {
+			buildRun.Status.Source.AdditionalLocals[i] = result
+			return
+		}
+	}
+
+	buildRun.Status.Source.AdditionalLocals = append(buildRun.Status.Source.AdditionalLocals, result)
+}
@@ -12,6 +12,7 @@ import (
 
 	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
 	"github.com/shipwright-io/build/pkg/config"
 	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources/sources"
 )
@@ -24,15 +25,116 @@ var _ = Describe("LocalCopy", func() {
 
 		BeforeEach(func() {
 			taskSpec = &pipelineapi.TaskSpec{}
-			sources.AppendLocalCopyStep(cfg, taskSpec, &metav1.Duration{Duration: time.Minute})
+			sources.AppendLocalCopyStep(cfg, taskSpec, &metav1.Duration{Duration: time.Minute}, "default")
+		})
+
+		It("adds results for the source digest, directory hash, commit sha and branch name", func() {
+			Expect(len(taskSpec.Results)).To(Equal(4))
+			Expect(taskSpec.Results[0].Name).To(Equal("shp-source-default-source-digest"))
+			Expect(taskSpec.Results[1].Name).To(Equal("shp-source-default-source-dir-hash"))
+			Expect(taskSpec.Results[2].Name).To(Equal("shp-source-default-commit-sha"))
+			Expect(taskSpec.Results[3].Name).To(Equal("shp-source-default-branch-name"))
 		})
 
 		It("produces a local-copy step", func() {
-			Expect(len(taskSpec.Results)).To(Equal(0))
 			Expect(len(taskSpec.Steps)).To(Equal(1))
 			Expect(taskSpec.Steps[0].Name).To(Equal(sources.WaiterContainerName))
 			Expect(taskSpec.Steps[0].Image).To(Equal(cfg.WaiterContainerTemplate.Image))
-			Expect(taskSpec.Steps[0].Args).To(Equal([]string{"start", "--timeout=1m0s"}))
+			Expect(taskSpec.Steps[0].Args).To(Equal([]string{
+				"start",
+				"--timeout=1m0s",
+				"--source-dir", "$(params.shp-source-root)",
+				"--result-file-source-digest", "$(results.shp-source-default-source-digest.path)",
+				"--result-file-source-dir-hash", "$(results.shp-source-default-source-dir-hash.path)",
+				"--result-file-source-timestamp", "$(results.shp-source-default-source-timestamp.path)",
+				"--result-file-commit-sha", "$(results.shp-source-default-commit-sha.path)",
+				"--result-file-branch-name", "$(results.shp-source-default-branch-name.path)",
+			}))
+		})
+	})
+
+	Context("when an additional local source is informed", func() {
+		var taskSpec *pipelineapi.TaskSpec
+
+		BeforeEach(func() {
+			taskSpec = &pipelineapi.TaskSpec{}
+			sources.AppendAdditionalLocalCopyStep(cfg, taskSpec, buildv1beta1.Local{
+				Name:    "artifacts",
+				Timeout: &metav1.Duration{Duration: 5 * time.Minute},
+			})
+		})
+
+		It("adds a result for the source digest", func() {
+			Expect(len(taskSpec.Results)).To(Equal(1))
+			Expect(taskSpec.Results[0].Name).To(Equal("shp-source-artifacts-source-digest"))
+		})
+
+		It("produces a local-copy step named after the source", func() {
+			Expect(len(taskSpec.Steps)).To(Equal(1))
+			Expect(taskSpec.Steps[0].Name).To(Equal("source-local-artifacts"))
+			Expect(taskSpec.Steps[0].Name).To(Equal(sources.LocalCopyStepName("artifacts")))
+			Expect(taskSpec.Steps[0].WorkingDir).To(Equal("$(params.shp-source-root)/artifacts"))
+			Expect(taskSpec.Steps[0].Args).To(Equal([]string{
+				"start",
+				"--timeout=5m0s",
+				"--lock-file", "/tmp/waiter-artifacts.lock",
+				"--source-dir", "$(params.shp-source-root)/artifacts",
+				"--result-file-source-digest", "$(results.shp-source-artifacts-source-digest.path)",
+			}))
+		})
+	})
+
+	Context("when the local source step emitted results", func() {
+		var buildRun *buildv1beta1.BuildRun
+
+		BeforeEach(func() {
+			buildRun = &buildv1beta1.BuildRun{}
+		})
+
+		It("surfaces the source digest", func() {
+			sources.AppendLocalResult(buildRun, "default", []pipelineapi.TaskRunResult{{
+				Name:  "shp-source-default-source-digest",
+				Value: pipelineapi.ParamValue{Type: pipelineapi.ParamTypeString, StringVal: "sha256:8f6c8b1a"},
+			}})
+
+			Expect(buildRun.Status.Source).ToNot(BeNil())
+			Expect(buildRun.Status.Source.Local).ToNot(BeNil())
+			Expect(buildRun.Status.Source.Local.Digest).To(Equal("sha256:8f6c8b1a"))
+			Expect(buildRun.Status.Source.Git).To(BeNil())
+		})
+
+		It("surfaces the Git details if the upload contained a repository", func() {
+			sources.AppendLocalResult(buildRun, "default", []pipelineapi.TaskRunResult{{
+				Name:  "shp-source-default-source-digest",
+				Value: pipelineapi.ParamValue{Type: pipelineapi.ParamTypeString, StringVal: "sha256:8f6c8b1a"},
+			}, {
+				Name:  "shp-source-default-commit-sha",
+				Value: pipelineapi.ParamValue{Type: pipelineapi.ParamTypeString, StringVal: "0e0583421a5e4bf562ffe33f3651e16ba0c78591"},
+			}, {
+				Name:  "shp-source-default-branch-name",
+				Value: pipelineapi.ParamValue{Type: pipelineapi.ParamTypeString, StringVal: "main"},
+			}})
+
+			Expect(buildRun.Status.Source).ToNot(BeNil())
+			Expect(buildRun.Status.Source.Git).ToNot(BeNil())
+			Expect(buildRun.Status.Source.Git.CommitSha).To(Equal("0e0583421a5e4bf562ffe33f3651e16ba0c78591"))
+			Expect(buildRun.Status.Source.Git.BranchName).To(Equal("main"))
+		})
+
+		It("surfaces the source digest of additional local sources only once", func() {
+			results := []pipelineapi.TaskRunResult{{
+				Name:  "shp-source-artifacts-source-digest",
+				Value: pipelineapi.ParamValue{Type: pipelineapi.ParamTypeString, StringVal: "sha256:4a1c7e02"},
+			}}
+
+			sources.AppendAdditionalLocalResult(buildRun, "artifacts", results)
+			sources.AppendAdditionalLocalResult(buildRun, "artifacts", results)
+
+			Expect(buildRun.Status.Source).ToNot(BeNil())
+			Expect(buildRun.Status.Source.AdditionalLocals).To(Equal([]buildv1beta1.LocalSourceResult{{
+				Name:   "artifacts",
+				Digest: "sha256:4a1c7e02",
+			}}))
 		})
 	})
 })
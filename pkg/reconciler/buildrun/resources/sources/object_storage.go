@@ -0,0 +1,118 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sources
+
+import (
+	"fmt"
+	"strings"
+
+	build "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+// AppendObjectStorageStep appends the object storage step to the TaskSpec
+func AppendObjectStorageStep(cfg *config.Config, taskSpec *pipelineapi.TaskSpec, objectStorage *build.ObjectStorage, name string) {
+	// append the results
+	taskSpec.Results = append(taskSpec.Results,
+		pipelineapi.TaskResult{
+			Name:        TaskResultName(name, "digest"),
+			Description: "The SHA-256 of the object, or the digest of the directory with the objects of the prefix.",
+		},
+		pipelineapi.TaskResult{
+			Name:        TaskResultName(name, "etag"),
+			Description: "The ETag of the object.",
+		},
+		pipelineapi.TaskResult{
+			Name:        TaskResultName(name, "version-id"),
+			Description: "The version of the object.",
+		},
+	)
+
+	// initialize the step from the template and the build-specific arguments
+	objectStorageStep := pipelineapi.Step{
+		Name:            fmt.Sprintf("source-%s", name),
+		Image:           cfg.ObjectStorageContainerTemplate.Image,
+		ImagePullPolicy: cfg.ObjectStorageContainerTemplate.ImagePullPolicy,
+		Command:         cfg.ObjectStorageContainerTemplate.Command,
+		Args: []string{
+			"--endpoint", objectStorage.Endpoint,
+			"--bucket", objectStorage.Bucket,
+			"--target", fmt.Sprintf("$(params.%s-%s)", PrefixParamsResultsVolumes, paramSourceRoot),
+			"--result-file-digest", fmt.Sprintf("$(results.%s.path)", TaskResultName(name, "digest")),
+			"--result-file-etag", fmt.Sprintf("$(results.%s.path)", TaskResultName(name, "etag")),
+			"--result-file-version-id", fmt.Sprintf("$(results.%s.path)", TaskResultName(name, "version-id")),
+			"--result-file-source-timestamp", fmt.Sprintf("$(results.%s.path)", TaskResultName(name, "source-timestamp")),
+		},
+		Env:              cfg.ObjectStorageContainerTemplate.Env,
+		ComputeResources: cfg.ObjectStorageContainerTemplate.Resources,
+		SecurityContext:  cfg.ObjectStorageContainerTemplate.SecurityContext,
+		WorkingDir:       cfg.ObjectStorageContainerTemplate.WorkingDir,
+	}
+
+	if objectStorage.Region != nil {
+		objectStorageStep.Args = append(objectStorageStep.Args, "--region", *objectStorage.Region)
+	}
+
+	if objectStorage.Key != nil {
+		objectStorageStep.Args = append(objectStorageStep.Args, "--key", *objectStorage.Key)
+	}
+
+	// the directory hash is only computed for the downloaded directory of a prefix
+	if objectStorage.Prefix != nil {
+		taskSpec.Results = append(taskSpec.Results, pipelineapi.TaskResult{
+			Name:        TaskResultName(name, sourceDirHashResult),
+			Description: "The directory hash of the objects of the prefix.",
+		})
+
+		objectStorageStep.Args = append(objectStorageStep.Args,
+			"--prefix", *objectStorage.Prefix,
+			"--result-file-source-dir-hash", fmt.Sprintf("$(results.%s.path)", TaskResultName(name, sourceDirHashResult)),
+		)
+	}
+
+	if objectStorage.VersionID != nil {
+		objectStorageStep.Args = append(objectStorageStep.Args, "--version-id", *objectStorage.VersionID)
+	}
+
+	// add credentials mount, if provided
+	if objectStorage.CredentialsSecret != nil {
+		AppendSecretVolume(taskSpec, *objectStorage.CredentialsSecret)
+
+		secretMountPath := fmt.Sprintf("/workspace/%s-object-storage-secret", PrefixParamsResultsVolumes)
+
+		// define the volume mount on the container
+		objectStorageStep.VolumeMounts = append(objectStorageStep.VolumeMounts, corev1.VolumeMount{
+			Name:      SanitizeVolumeNameForSecretName(*objectStorage.CredentialsSecret),
+			MountPath: secretMountPath,
+			ReadOnly:  true,
+		})
+
+		// append the argument
+		objectStorageStep.Args = append(objectStorageStep.Args,
+			"--secret-path", secretMountPath,
+		)
+	}
+
+	taskSpec.Steps = append(taskSpec.Steps, objectStorageStep)
+}
+
+// AppendObjectStorageResult append object storage source result to build run
+func AppendObjectStorageResult(buildRun *build.BuildRun, name string, results []pipelineapi.TaskRunResult) {
+	digest := FindResultValue(results, name, "digest")
+
+	if strings.TrimSpace(digest) != "" {
+		if buildRun.Status.Source == nil {
+			buildRun.Status.Source = &build.SourceResult{}
+		}
+		buildRun.Status.Source.ObjectStorage = &build.ObjectStorageSourceResult{
+			Digest:    digest,
+			ETag:      FindResultValue(results, name, "etag"),
+			VersionID: FindResultValue(results, name, "version-id"),
+		}
+	}
+}
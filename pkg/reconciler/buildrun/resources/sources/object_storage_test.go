@@ -0,0 +1,129 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sources_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources/sources"
+
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("ObjectStorage", func() {
+
+	cfg := config.NewDefaultConfig()
+
+	Context("when adding an object storage source with a key", func() {
+
+		var taskSpec *pipelineapi.TaskSpec
+
+		BeforeEach(func() {
+			taskSpec = &pipelineapi.TaskSpec{}
+			sources.AppendObjectStorageStep(cfg, taskSpec, &buildv1beta1.ObjectStorage{
+				Endpoint:  "http://minio.minio.svc:9000",
+				Bucket:    "sources",
+				Key:       ptr.To("builds/source.tar.gz"),
+				VersionID: ptr.To("version-1"),
+			}, "default")
+		})
+
+		It("adds results for the digest, the ETag and the version", func() {
+			Expect(len(taskSpec.Results)).To(Equal(3))
+			Expect(taskSpec.Results[0].Name).To(Equal("shp-source-default-digest"))
+			Expect(taskSpec.Results[1].Name).To(Equal("shp-source-default-etag"))
+			Expect(taskSpec.Results[2].Name).To(Equal("shp-source-default-version-id"))
+		})
+
+		It("adds a step", func() {
+			Expect(len(taskSpec.Steps)).To(Equal(1))
+			Expect(taskSpec.Steps[0].Name).To(Equal("source-default"))
+			Expect(taskSpec.Steps[0].Image).To(Equal(cfg.ObjectStorageContainerTemplate.Image))
+			Expect(taskSpec.Steps[0].Args).To(Equal([]string{
+				"--endpoint", "http://minio.minio.svc:9000",
+				"--bucket", "sources",
+				"--target", "$(params.shp-source-root)",
+				"--result-file-digest", "$(results.shp-source-default-digest.path)",
+				"--result-file-etag", "$(results.shp-source-default-etag.path)",
+				"--result-file-version-id", "$(results.shp-source-default-version-id.path)",
+				"--result-file-source-timestamp", "$(results.shp-source-default-source-timestamp.path)",
+				"--key", "builds/source.tar.gz",
+				"--version-id", "version-1",
+			}))
+		})
+	})
+
+	Context("when adding an object storage source with a prefix and credentials", func() {
+
+		var taskSpec *pipelineapi.TaskSpec
+
+		BeforeEach(func() {
+			taskSpec = &pipelineapi.TaskSpec{}
+			sources.AppendObjectStorageStep(cfg, taskSpec, &buildv1beta1.ObjectStorage{
+				Endpoint:          "https://s3.eu-central-1.amazonaws.com",
+				Region:            ptr.To("eu-central-1"),
+				Bucket:            "sources",
+				Prefix:            ptr.To("builds/42/"),
+				CredentialsSecret: ptr.To("s3-credentials"),
+			}, "default")
+		})
+
+		It("adds a result for the directory hash of the prefix", func() {
+			Expect(len(taskSpec.Results)).To(Equal(4))
+			Expect(taskSpec.Results[3].Name).To(Equal("shp-source-default-source-dir-hash"))
+		})
+
+		It("adds a volume for the secret", func() {
+			Expect(len(taskSpec.Volumes)).To(Equal(1))
+			Expect(taskSpec.Volumes[0].Name).To(Equal("shp-s3-credentials"))
+			Expect(taskSpec.Volumes[0].VolumeSource.Secret).NotTo(BeNil())
+			Expect(taskSpec.Volumes[0].VolumeSource.Secret.SecretName).To(Equal("s3-credentials"))
+		})
+
+		It("adds a step that mounts the secret", func() {
+			Expect(len(taskSpec.Steps)).To(Equal(1))
+			Expect(taskSpec.Steps[0].Args).To(ContainElements("--region", "eu-central-1", "--prefix", "builds/42/"))
+			Expect(taskSpec.Steps[0].Args).To(ContainElements("--result-file-source-dir-hash", "$(results.shp-source-default-source-dir-hash.path)"))
+			Expect(taskSpec.Steps[0].Args[len(taskSpec.Steps[0].Args)-2:]).To(Equal([]string{
+				"--secret-path", "/workspace/shp-object-storage-secret",
+			}))
+			Expect(len(taskSpec.Steps[0].VolumeMounts)).To(Equal(1))
+			Expect(taskSpec.Steps[0].VolumeMounts[0].Name).To(Equal("shp-s3-credentials"))
+			Expect(taskSpec.Steps[0].VolumeMounts[0].MountPath).To(Equal("/workspace/shp-object-storage-secret"))
+			Expect(taskSpec.Steps[0].VolumeMounts[0].ReadOnly).To(BeTrue())
+		})
+	})
+
+	Context("when the object storage step emitted results", func() {
+		It("surfaces the digest, the ETag and the version in the BuildRun status", func() {
+			buildRun := &buildv1beta1.BuildRun{}
+			sources.AppendObjectStorageResult(buildRun, "default", []pipelineapi.TaskRunResult{
+				{
+					Name:  "shp-source-default-digest",
+					Value: pipelineapi.ParamValue{Type: pipelineapi.ParamTypeString, StringVal: "sha256:0b5a4e3c9d6f8e2a1b7c4d9e0f3a6b8c2d5e7f9a1b3c4d6e8f0a2b4c6d8e0f1a"},
+				},
+				{
+					Name:  "shp-source-default-etag",
+					Value: pipelineapi.ParamValue{Type: pipelineapi.ParamTypeString, StringVal: "9b2cf535f27731c974343645a3985328"},
+				},
+				{
+					Name:  "shp-source-default-version-id",
+					Value: pipelineapi.ParamValue{Type: pipelineapi.ParamTypeString, StringVal: "version-1"},
+				},
+			})
+
+			Expect(buildRun.Status.Source).ToNot(BeNil())
+			Expect(buildRun.Status.Source.ObjectStorage).To(Equal(&buildv1beta1.ObjectStorageSourceResult{
+				Digest:    "sha256:0b5a4e3c9d6f8e2a1b7c4d9e0f3a6b8c2d5e7f9a1b3c4d6e8f0a2b4c6d8e0f1a",
+				ETag:      "9b2cf535f27731c974343645a3985328",
+				VersionID: "version-1",
+			}))
+		})
+	})
+})
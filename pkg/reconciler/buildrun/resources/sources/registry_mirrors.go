@@ -0,0 +1,38 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sources
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shipwright-io/build/pkg/config"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+// AppendRegistryMirrors passes the registry mirror rules of the configuration to a step that pulls images
+func AppendRegistryMirrors(cfg *config.Config, step *pipelineapi.Step) {
+	for _, registryMirror := range cfg.RegistryMirrors {
+		rule := fmt.Sprintf("%s=%s", registryMirror.Prefix, strings.Join(registryMirror.Mirrors, ","))
+		if registryMirror.MirrorByDigestOnly {
+			rule += ";mirror-by-digest-only"
+		}
+
+		step.Args = append(step.Args, "--registry-mirror", rule)
+	}
+}
+
+// AppendRegistryDeleteProviders passes the registry delete providers of the configuration to a step that deletes
+// images
+func AppendRegistryDeleteProviders(cfg *config.Config, step *pipelineapi.Step) {
+	for _, registryDeleteProvider := range cfg.RegistryDeleteProviders {
+		rule := fmt.Sprintf("%s=%s", registryDeleteProvider.Host, registryDeleteProvider.Provider)
+		if registryDeleteProvider.Endpoint != "" {
+			rule = fmt.Sprintf("%s,%s", rule, registryDeleteProvider.Endpoint)
+		}
+
+		step.Args = append(step.Args, "--registry-delete-provider", rule)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sources_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources/sources"
+
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+var _ = Describe("RegistryMirrors", func() {
+
+	It("adds an argument per registry mirror rule", func() {
+		cfg := config.NewDefaultConfig()
+		cfg.RegistryMirrors = []config.RegistryMirror{
+			{Prefix: "docker.io/library", Mirrors: []string{"mirror-a.example.com/library", "mirror-b.example.com/library"}},
+			{Prefix: "quay.io", Mirrors: []string{"mirror-a.example.com/quay"}, MirrorByDigestOnly: true},
+		}
+
+		step := pipelineapi.Step{}
+		sources.AppendRegistryMirrors(cfg, &step)
+
+		Expect(step.Args).To(Equal([]string{
+			"--registry-mirror", "docker.io/library=mirror-a.example.com/library,mirror-b.example.com/library",
+			"--registry-mirror", "quay.io=mirror-a.example.com/quay;mirror-by-digest-only",
+		}))
+	})
+})
+
+var _ = Describe("RegistryDeleteProviders", func() {
+
+	It("adds an argument per registry delete provider", func() {
+		cfg := config.NewDefaultConfig()
+		cfg.RegistryDeleteProviders = []config.RegistryDeleteProvider{
+			{Host: "quay.io", Provider: "quay"},
+			{Host: "registry.example.com", Provider: "gitlab", Endpoint: "https://gitlab.example.com"},
+		}
+
+		step := pipelineapi.Step{}
+		sources.AppendRegistryDeleteProviders(cfg, &step)
+
+		Expect(step.Args).To(Equal([]string{
+			"--registry-delete-provider", "quay.io=quay",
+			"--registry-delete-provider", "registry.example.com=gitlab,https://gitlab.example.com",
+		}))
+	})
+})
@@ -0,0 +1,94 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sources
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/image"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+var (
+	registryCertificatesVolumeName = fmt.Sprintf("%s-registry-certificates", PrefixParamsResultsVolumes)
+	registryCertificatesMountPath  = fmt.Sprintf("/workspace/%s-registry-certificates", PrefixParamsResultsVolumes)
+)
+
+// AppendRegistryCertificates mounts the CA bundles and client certificates of the registry hosts of the
+// configuration into a step and passes their directory to it. The volume is added to the TaskSpec once. The
+// ConfigMaps and Secrets are optional so that a namespace only needs the ones of the registries it uses.
+func AppendRegistryCertificates(cfg *config.Config, taskSpec *pipelineapi.TaskSpec, step *pipelineapi.Step) {
+	if len(cfg.RegistryTLS) == 0 {
+		return
+	}
+
+	volumeExists := false
+	for _, volume := range taskSpec.Volumes {
+		if volume.Name == registryCertificatesVolumeName {
+			volumeExists = true
+			break
+		}
+	}
+
+	if !volumeExists {
+		var projections []corev1.VolumeProjection
+		for _, registryTLS := range cfg.RegistryTLS {
+			directory := image.RegistryCertificatesDirectoryName(registryTLS.Host)
+
+			if registryTLS.CABundleConfigMap != "" {
+				projections = append(projections, corev1.VolumeProjection{
+					ConfigMap: &corev1.ConfigMapProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: registryTLS.CABundleConfigMap},
+						Items: []corev1.KeyToPath{{
+							Key:  registryTLS.CABundleKey,
+							Path: path.Join(directory, "ca.crt"),
+						}},
+						Optional: ptr.To(true),
+					},
+				})
+			}
+
+			if registryTLS.ClientCertificateSecret != "" {
+				projections = append(projections, corev1.VolumeProjection{
+					Secret: &corev1.SecretProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: registryTLS.ClientCertificateSecret},
+						Items: []corev1.KeyToPath{{
+							Key:  corev1.TLSCertKey,
+							Path: path.Join(directory, "client.cert"),
+						}, {
+							Key:  corev1.TLSPrivateKeyKey,
+							Path: path.Join(directory, "client.key"),
+						}},
+						Optional: ptr.To(true),
+					},
+				})
+			}
+		}
+
+		taskSpec.Volumes = append(taskSpec.Volumes, corev1.Volume{
+			Name: registryCertificatesVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources:     projections,
+					DefaultMode: secretMountMode,
+				},
+			},
+		})
+	}
+
+	// define the volume mount on the container
+	step.VolumeMounts = append(step.VolumeMounts, corev1.VolumeMount{
+		Name:      registryCertificatesVolumeName,
+		MountPath: registryCertificatesMountPath,
+		ReadOnly:  true,
+	})
+
+	// append the argument
+	step.Args = append(step.Args, "--registry-certificates-directory", registryCertificatesMountPath)
+}
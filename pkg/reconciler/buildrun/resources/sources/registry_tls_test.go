@@ -0,0 +1,72 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sources_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources/sources"
+
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+)
+
+var _ = Describe("RegistryCertificates", func() {
+
+	var taskSpec *pipelineapi.TaskSpec
+
+	BeforeEach(func() {
+		taskSpec = &pipelineapi.TaskSpec{}
+	})
+
+	Context("without registry TLS configuration", func() {
+
+		It("does not change the step", func() {
+			step := pipelineapi.Step{}
+			sources.AppendRegistryCertificates(config.NewDefaultConfig(), taskSpec, &step)
+
+			Expect(taskSpec.Volumes).To(BeEmpty())
+			Expect(step.VolumeMounts).To(BeEmpty())
+			Expect(step.Args).To(BeEmpty())
+		})
+	})
+
+	Context("with registry TLS configuration", func() {
+
+		cfg := config.NewDefaultConfig()
+		cfg.RegistryTLS = []config.RegistryTLS{
+			{Host: "registry.example.com:5000", CABundleConfigMap: "registry-ca", CABundleKey: "bundle.pem"},
+			{Host: "mtls.example.com", CABundleKey: "ca.crt", ClientCertificateSecret: "registry-client"},
+		}
+
+		It("adds one projected volume with the optional CA bundles and client certificates", func() {
+			first, second := pipelineapi.Step{}, pipelineapi.Step{}
+			sources.AppendRegistryCertificates(cfg, taskSpec, &first)
+			sources.AppendRegistryCertificates(cfg, taskSpec, &second)
+
+			Expect(taskSpec.Volumes).To(HaveLen(1))
+			Expect(taskSpec.Volumes[0].Name).To(Equal("shp-registry-certificates"))
+
+			projections := taskSpec.Volumes[0].Projected.Sources
+			Expect(projections).To(HaveLen(2))
+			Expect(projections[0].ConfigMap.Name).To(Equal("registry-ca"))
+			Expect(projections[0].ConfigMap.Items[0].Key).To(Equal("bundle.pem"))
+			Expect(projections[0].ConfigMap.Items[0].Path).To(Equal("registry.example.com_5000/ca.crt"))
+			Expect(*projections[0].ConfigMap.Optional).To(BeTrue())
+			Expect(projections[1].Secret.Name).To(Equal("registry-client"))
+			Expect(projections[1].Secret.Items[0].Path).To(Equal("mtls.example.com/client.cert"))
+			Expect(projections[1].Secret.Items[1].Path).To(Equal("mtls.example.com/client.key"))
+			Expect(*projections[1].Secret.Optional).To(BeTrue())
+
+			for _, step := range []pipelineapi.Step{first, second} {
+				Expect(step.VolumeMounts).To(HaveLen(1))
+				Expect(step.VolumeMounts[0].MountPath).To(Equal("/workspace/shp-registry-certificates"))
+				Expect(step.VolumeMounts[0].ReadOnly).To(BeTrue())
+				Expect(step.Args).To(Equal([]string{"--registry-certificates-directory", "/workspace/shp-registry-certificates"}))
+			}
+		})
+	})
+})
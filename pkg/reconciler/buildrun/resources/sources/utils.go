@@ -17,6 +17,9 @@ import (
 const (
 	PrefixParamsResultsVolumes = "shp"
 
+	// DefaultSourceName is the name used for the results of the primary source
+	DefaultSourceName = "default"
+
 	paramSourceRoot = "source-root"
 )
 
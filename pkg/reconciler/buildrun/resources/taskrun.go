@@ -10,7 +10,6 @@ import (
 	"strconv"
 	"strings"
 
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
@@ -177,12 +176,32 @@ func GenerateTaskRun(
 	strategy buildv1beta1.BuilderStrategy,
 ) (*pipelineapi.TaskRun, error) {
 
+	// Ensure a proper override of params between Build and BuildRun
+	// A BuildRun can override a param as long as it was defined in the Build
+	paramValues := OverrideParams(build.Spec.ParamValues, buildRun.Spec.ParamValues)
+
+	// resolve the placeholders in the outputs of the build and buildRun, those that depend on the
+	// source are resolved by image-processing
+	buildOutput, err := resolveOutputTemplates(build.Spec.Output, build, buildRun, strategy.GetParameters(), paramValues)
+	if err != nil {
+		return nil, err
+	}
+
+	buildRunOutput := &buildv1beta1.Image{}
+	if buildRun.Spec.Output != nil {
+		resolvedBuildRunOutput, err := resolveOutputTemplates(*buildRun.Spec.Output, build, buildRun, strategy.GetParameters(), paramValues)
+		if err != nil {
+			return nil, err
+		}
+		buildRunOutput = &resolvedBuildRunOutput
+	}
+
 	// retrieve expected imageURL form build or buildRun
 	var image string
 	if buildRun.Spec.Output != nil {
-		image = buildRun.Spec.Output.Image
+		image = buildRunOutput.Image
 	} else {
-		image = build.Spec.Output.Image
+		image = buildOutput.Image
 	}
 
 	insecure := false
@@ -227,10 +246,7 @@ func GenerateTaskRun(
 			TaskSpec:           taskSpec,
 			Workspaces: []pipelineapi.WorkspaceBinding{
 				// workspace for the source files
-				{
-					Name:     workspaceSource,
-					EmptyDir: &corev1.EmptyDirVolumeSource{},
-				},
+				getSourceWorkspaceBinding(build, buildRun),
 			},
 		},
 	}
@@ -310,10 +326,6 @@ func GenerateTaskRun(
 
 	expectedTaskRun.Spec.Params = params
 
-	// Ensure a proper override of params between Build and BuildRun
-	// A BuildRun can override a param as long as it was defined in the Build
-	paramValues := OverrideParams(build.Spec.ParamValues, buildRun.Spec.ParamValues)
-
 	// Append params to the TaskRun spec definition
 	for _, paramValue := range paramValues {
 		parameterDefinition := FindParameterByName(strategy.GetParameters(), paramValue.Name)
@@ -329,14 +341,17 @@ func GenerateTaskRun(
 
 	// Setup image processing, this can be a no-op if no annotations or labels need to be mutated,
 	// and if the strategy is pushing the image by not using $(params.shp-output-directory)
-	buildRunOutput := buildRun.Spec.Output
-	if buildRunOutput == nil {
-		buildRunOutput = &buildv1beta1.Image{}
-	}
-
 	// Make sure that image-processing is setup in case it is needed, which can fail with an error
 	// in case some assumptions cannot be met, i.e. illegal combination of fields
-	if err := SetupImageProcessing(expectedTaskRun, cfg, buildRun.CreationTimestamp.Time, build.Spec.Output, *buildRunOutput); err != nil {
+	if err := SetupImageProcessing(expectedTaskRun, cfg, buildRun.CreationTimestamp.Time, buildOutput, *buildRunOutput); err != nil {
+		return nil, err
+	}
+
+	// Add the information about the source and the base image for the standard OCI annotations
+	SetupOCIAnnotations(expectedTaskRun, build, strategy, paramValues)
+
+	// Add the information about the build that image-processing needs to generate a provenance
+	if err := SetupProvenance(expectedTaskRun, build, buildRun, strategy); err != nil {
 		return nil, err
 	}
 
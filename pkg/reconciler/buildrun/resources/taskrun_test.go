@@ -17,6 +17,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
 	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
 	"github.com/shipwright-io/build/pkg/config"
@@ -156,6 +157,8 @@ var _ = Describe("GenerateTaskrun", func() {
 					"$(results.shp-image-size.path)",
 					"--result-file-image-vulnerabilities",
 					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
 				}))
 			})
 
@@ -188,6 +191,8 @@ var _ = Describe("GenerateTaskrun", func() {
 					"$(results.shp-image-size.path)",
 					"--result-file-image-vulnerabilities",
 					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
 				}
 
 				Expect(got.Steps[3].Args).To(HaveLen(len(expected)))
@@ -633,6 +638,125 @@ var _ = Describe("GenerateTaskrun", func() {
 			})
 		})
 
+		Context("when the output image contains placeholders", func() {
+			BeforeEach(func() {
+				build, err = ctl.LoadBuildYAML([]byte(test.BuildahBuildWithOutput))
+				Expect(err).To(BeNil())
+
+				buildRun, err = ctl.LoadBuildRunFromBytes([]byte(test.BuildahBuildRunWithSA))
+				Expect(err).To(BeNil())
+
+				buildStrategy, err = ctl.LoadBuildStrategyFromBytes([]byte(test.BuildahBuildStrategySingleStep))
+				Expect(err).To(BeNil())
+			})
+
+			It("should resolve the names of the build and buildrun, and the parameter values", func() {
+				build.Spec.Output.Image = "registry.example.com/$(build.name)/app:$(buildrun.name)-$(params.dockerfile)"
+
+				got, err = resources.GenerateTaskRun(config.NewDefaultConfig(), build, buildRun, serviceAccountName, buildStrategy)
+				Expect(err).ToNot(HaveOccurred())
+
+				for _, param := range got.Spec.Params {
+					if param.Name == "shp-output-image" {
+						Expect(param.Value.StringVal).To(Equal(fmt.Sprintf("registry.example.com/buildah/app:%s-Dockerfile", buildRun.Name)))
+					}
+				}
+			})
+
+			It("should fail for a parameter that is not defined in the build strategy", func() {
+				build.Spec.Output.Image = "registry.example.com/app:$(params.undefined)"
+
+				_, err = resources.GenerateTaskRun(config.NewDefaultConfig(), build, buildRun, serviceAccountName, buildStrategy)
+				Expect(err).To(MatchError(ContainSubstring(`the parameter "undefined" that is used in the output image is not defined`)))
+			})
+
+			It("should fail for a placeholder that is not supported", func() {
+				build.Spec.Output.Image = "registry.example.com/app:$(context.taskRun.name)"
+
+				_, err = resources.GenerateTaskRun(config.NewDefaultConfig(), build, buildRun, serviceAccountName, buildStrategy)
+				Expect(err).To(MatchError(ContainSubstring("is not supported")))
+			})
+
+			It("should fail for a placeholder that depends on the source in the output image", func() {
+				build.Spec.Output.Image = "registry.example.com/app:$(source.git.commitSha)"
+
+				_, err = resources.GenerateTaskRun(config.NewDefaultConfig(), build, buildRun, serviceAccountName, buildStrategy)
+				Expect(err).To(MatchError(ContainSubstring("can only be used in additional tags and mirrors")))
+			})
+
+			It("should fail for a placeholder that depends on the source in the output image of the buildrun", func() {
+				buildRun.Spec.Output = &buildv1beta1.Image{Image: "registry.example.com/$(source.git.branchName)/app"}
+
+				_, err = resources.GenerateTaskRun(config.NewDefaultConfig(), build, buildRun, serviceAccountName, buildStrategy)
+				Expect(err).To(MatchError(ContainSubstring("cannot use the placeholder $(source.git.branchName) in the output image")))
+			})
+
+			It("should pass the result file to image-processing for a placeholder that depends on the source in a tag", func() {
+				build.Spec.Output.AdditionalTags = []string{"$(source.git.commitSha)"}
+
+				got, err = resources.GenerateTaskRun(config.NewDefaultConfig(), build, buildRun, serviceAccountName, buildStrategy)
+				Expect(err).ToNot(HaveOccurred())
+
+				imageProcessingStep := got.Spec.TaskSpec.Steps[len(got.Spec.TaskSpec.Steps)-1]
+				Expect(imageProcessingStep.Name).To(Equal("image-processing"))
+				Expect(imageProcessingStep.Args).To(ContainElements(
+					"--additional-tag", "$(source.git.commitSha)",
+					"--template-value-file", "source.git.commitSha=$(results.shp-source-default-commit-sha.path)",
+				))
+			})
+		})
+
+		Context("when the standard OCI annotations are enabled", func() {
+			var cfg *config.Config
+
+			BeforeEach(func() {
+				build, err = ctl.LoadBuildYAML([]byte(test.BuildahBuildWithOutput))
+				Expect(err).To(BeNil())
+
+				buildRun, err = ctl.LoadBuildRunFromBytes([]byte(test.BuildahBuildRunWithSA))
+				Expect(err).To(BeNil())
+
+				buildStrategy, err = ctl.LoadBuildStrategyFromBytes([]byte(test.BuildahBuildStrategySingleStep))
+				Expect(err).To(BeNil())
+
+				buildStrategy.Annotations = map[string]string{
+					buildv1beta1.AnnotationBaseImageParameter: "base-image",
+				}
+				buildStrategy.Spec.Parameters = append(buildStrategy.Spec.Parameters, buildv1beta1.Parameter{
+					Name:    "base-image",
+					Default: ptr.To("registry.access.redhat.com/ubi9/ubi-minimal:latest"),
+				})
+
+				cfg = config.NewDefaultConfig()
+				cfg.OCIAnnotations = true
+			})
+
+			It("should pass the source, the revision and the base image to image-processing", func() {
+				got, err = resources.GenerateTaskRun(cfg, build, buildRun, serviceAccountName, buildStrategy)
+				Expect(err).ToNot(HaveOccurred())
+
+				imageProcessingStep := got.Spec.TaskSpec.Steps[len(got.Spec.TaskSpec.Steps)-1]
+				Expect(imageProcessingStep.Name).To(Equal("image-processing"))
+				Expect(imageProcessingStep.Args).To(ContainElements(
+					"--oci-annotations",
+					"--oci-annotation-source", "https://github.com/shipwright-io/sample-go",
+					"--oci-annotation-revision-file", "$(results.shp-source-default-commit-sha.path)",
+					"--oci-annotation-base-image", "registry.access.redhat.com/ubi9/ubi-minimal:latest",
+				))
+			})
+
+			It("should not add the standard OCI annotations if the build opts out", func() {
+				build.Spec.Output.OCIAnnotations = ptr.To(false)
+
+				got, err = resources.GenerateTaskRun(cfg, build, buildRun, serviceAccountName, buildStrategy)
+				Expect(err).ToNot(HaveOccurred())
+
+				for _, step := range got.Spec.TaskSpec.Steps {
+					Expect(step.Args).ToNot(ContainElement("--oci-annotations"))
+				}
+			})
+		})
+
 		Context("when the build and buildrun both specify a nodeSelector", func() {
 			BeforeEach(func() {
 				build, err = ctl.LoadBuildYAML([]byte(test.MinimalBuildRunWithNodeSelector))
@@ -654,5 +778,74 @@ var _ = Describe("GenerateTaskrun", func() {
 				Expect(got.Spec.PodTemplate.NodeSelector).To(Equal(buildRun.Spec.NodeSelector))
 			})
 		})
+
+		Context("when a source workspace is specified", func() {
+			BeforeEach(func() {
+				build, err = ctl.LoadBuildYAML([]byte(test.BuildahBuildWithOutput))
+				Expect(err).To(BeNil())
+
+				buildRun, err = ctl.LoadBuildRunFromBytes([]byte(test.BuildahBuildRunWithSA))
+				Expect(err).To(BeNil())
+
+				buildStrategy, err = ctl.LoadBuildStrategyFromBytes([]byte(test.BuildahBuildStrategySingleStep))
+				Expect(err).To(BeNil())
+			})
+
+			It("should use an emptyDir volume by default", func() {
+				got, err = resources.GenerateTaskRun(config.NewDefaultConfig(), build, buildRun, serviceAccountName, buildStrategy)
+				Expect(err).To(BeNil())
+
+				Expect(got.Spec.Workspaces).To(HaveLen(1))
+				Expect(got.Spec.Workspaces[0].Name).To(Equal("source"))
+				Expect(got.Spec.Workspaces[0].EmptyDir).ToNot(BeNil())
+				Expect(got.Spec.Workspaces[0].PersistentVolumeClaim).To(BeNil())
+			})
+
+			It("should use the generated claim when the Build specifies a volume claim template", func() {
+				build.Spec.SourceWorkspace = &buildv1beta1.SourceWorkspace{
+					VolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{},
+				}
+
+				got, err = resources.GenerateTaskRun(config.NewDefaultConfig(), build, buildRun, serviceAccountName, buildStrategy)
+				Expect(err).To(BeNil())
+
+				Expect(got.Spec.Workspaces).To(HaveLen(1))
+				Expect(got.Spec.Workspaces[0].EmptyDir).To(BeNil())
+				Expect(got.Spec.Workspaces[0].PersistentVolumeClaim).ToNot(BeNil())
+				Expect(got.Spec.Workspaces[0].PersistentVolumeClaim.ClaimName).To(Equal(resources.GetGeneratedSourceWorkspaceClaimName(buildRun)))
+				Expect(got.Spec.Workspaces[0].SubPath).To(BeEmpty())
+			})
+
+			It("should give precedence to the existing claim specified in the BuildRun and use a sub path", func() {
+				build.Spec.SourceWorkspace = &buildv1beta1.SourceWorkspace{
+					VolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{},
+				}
+				buildRun.Spec.SourceWorkspace = &buildv1beta1.SourceWorkspace{
+					PersistentVolumeClaimName: ptr.To("shared-sources"),
+				}
+
+				got, err = resources.GenerateTaskRun(config.NewDefaultConfig(), build, buildRun, serviceAccountName, buildStrategy)
+				Expect(err).To(BeNil())
+
+				Expect(got.Spec.Workspaces).To(HaveLen(1))
+				Expect(got.Spec.Workspaces[0].PersistentVolumeClaim).ToNot(BeNil())
+				Expect(got.Spec.Workspaces[0].PersistentVolumeClaim.ClaimName).To(Equal("shared-sources"))
+				Expect(got.Spec.Workspaces[0].SubPath).To(Equal(buildRun.Name))
+			})
+
+			It("should use a new sub path of the existing claim for a retried BuildRun", func() {
+				buildRun.Spec.SourceWorkspace = &buildv1beta1.SourceWorkspace{
+					PersistentVolumeClaimName: ptr.To("shared-sources"),
+				}
+				buildRun.Status.Attempts = []buildv1beta1.BuildRunAttempt{{TaskRunName: "failed-taskrun", Reason: "GitError"}}
+
+				got, err = resources.GenerateTaskRun(config.NewDefaultConfig(), build, buildRun, serviceAccountName, buildStrategy)
+				Expect(err).To(BeNil())
+
+				Expect(got.Spec.Workspaces).To(HaveLen(1))
+				Expect(got.Spec.Workspaces[0].PersistentVolumeClaim.ClaimName).To(Equal("shared-sources"))
+				Expect(got.Spec.Workspaces[0].SubPath).To(Equal(buildRun.Name + "-2"))
+			})
+		})
 	})
 })
@@ -0,0 +1,76 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/ctxlog"
+	"github.com/shipwright-io/build/pkg/image"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// VulnerabilityReportConfigMapKey is the key of the complete vulnerability report in the ConfigMap
+const VulnerabilityReportConfigMapKey = "report.json"
+
+// GetVulnerabilityReportConfigMapName returns the name of the ConfigMap with the vulnerability report of a build run
+func GetVulnerabilityReportConfigMapName(buildRun *buildv1beta1.BuildRun) string {
+	return buildRun.Name + "-vulnerability-report"
+}
+
+// CreateVulnerabilityReportConfigMap stores the complete vulnerability report in a ConfigMap that is owned by
+// the build run. The image-processing step only emits the report as result if the output image was not pushed,
+// otherwise the report is attached to the image.
+func CreateVulnerabilityReportConfigMap(ctx context.Context, client client.Client, buildRun *buildv1beta1.BuildRun, taskRunResults []pipelineapi.TaskRunResult) error {
+	var encodedReport string
+	for _, result := range taskRunResults {
+		if result.Name == generateOutputResultName(imageVulnerabilityReport) {
+			encodedReport = result.Value.StringVal
+		}
+	}
+
+	if encodedReport == "" {
+		return nil
+	}
+
+	report, err := image.DecodeVulnerabilityReport(encodedReport)
+	if err != nil {
+		ctxlog.Info(ctx, "invalid value for vulnerability report from taskRun result", namespace, buildRun.Namespace, name, buildRun.Name, "error", err)
+		return nil
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetVulnerabilityReportConfigMapName(buildRun),
+			Namespace: buildRun.Namespace,
+			Labels:    map[string]string{buildv1beta1.LabelBuildRun: buildRun.Name},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(buildRun, buildv1beta1.SchemeGroupVersion.WithKind("BuildRun")),
+			},
+		},
+		Data: map[string]string{
+			VulnerabilityReportConfigMapKey: string(report),
+		},
+	}
+
+	// the ConfigMap can already exist if the status update of a previous reconcile failed
+	if err := client.Create(ctx, configMap); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	ctxlog.Info(ctx, "created configMap with the vulnerability report of the BuildRun", namespace, buildRun.Namespace, name, configMap.Name, "BuildRun", buildRun.Name)
+
+	if buildRun.Status.Output == nil {
+		buildRun.Status.Output = &buildv1beta1.Output{}
+	}
+	buildRun.Status.Output.VulnerabilityReportConfigMap = configMap.Name
+
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/controller/fakes"
+	"github.com/shipwright-io/build/pkg/image"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
+	test "github.com/shipwright-io/build/test/v1beta1_samples"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Storing the vulnerability report", func() {
+	const report = `{"Results":[]}`
+
+	var (
+		client         *fakes.FakeClient
+		ctl            test.Catalog
+		buildRunSample *buildv1beta1.BuildRun
+	)
+
+	BeforeEach(func() {
+		client = &fakes.FakeClient{}
+		buildRunSample = ctl.DefaultBuildRun("foobuildrun", "foobuild")
+	})
+
+	reportResult := func(value string) []pipelineapi.TaskRunResult {
+		return []pipelineapi.TaskRunResult{{
+			Name:  "shp-image-vulnerability-report",
+			Value: pipelineapi.ParamValue{Type: pipelineapi.ParamTypeString, StringVal: value},
+		}}
+	}
+
+	It("should not create a ConfigMap if there is no report", func() {
+		Expect(resources.CreateVulnerabilityReportConfigMap(context.TODO(), client, buildRunSample, nil)).To(Succeed())
+		Expect(client.CreateCallCount()).To(Equal(0))
+		Expect(buildRunSample.Status.Output).To(BeNil())
+	})
+
+	It("should create a ConfigMap with a label and an ownerreference that contains the report", func() {
+		encoded, err := image.EncodeVulnerabilityReport([]byte(report))
+		Expect(err).ToNot(HaveOccurred())
+
+		client.CreateCalls(func(_ context.Context, object crc.Object, _ ...crc.CreateOption) error {
+			configMap, ok := object.(*corev1.ConfigMap)
+			Expect(ok).To(BeTrue())
+			Expect(configMap.Name).To(Equal("foobuildrun-vulnerability-report"))
+			Expect(configMap.Labels[buildv1beta1.LabelBuildRun]).To(Equal(buildRunSample.Name))
+			Expect(configMap.OwnerReferences).To(HaveLen(1))
+			Expect(configMap.OwnerReferences[0].Kind).To(Equal("BuildRun"))
+			Expect(configMap.Data).To(HaveKeyWithValue(resources.VulnerabilityReportConfigMapKey, report))
+			return nil
+		})
+
+		Expect(resources.CreateVulnerabilityReportConfigMap(context.TODO(), client, buildRunSample, reportResult(encoded))).To(Succeed())
+		Expect(client.CreateCallCount()).To(Equal(1))
+		Expect(buildRunSample.Status.Output.VulnerabilityReportConfigMap).To(Equal("foobuildrun-vulnerability-report"))
+	})
+
+	It("should tolerate a ConfigMap that already exists", func() {
+		encoded, err := image.EncodeVulnerabilityReport([]byte(report))
+		Expect(err).ToNot(HaveOccurred())
+
+		client.CreateReturns(k8serrors.NewAlreadyExists(schema.GroupResource{}, "foobuildrun-vulnerability-report"))
+
+		Expect(resources.CreateVulnerabilityReportConfigMap(context.TODO(), client, buildRunSample, reportResult(encoded))).To(Succeed())
+		Expect(buildRunSample.Status.Output.VulnerabilityReportConfigMap).To(Equal("foobuildrun-vulnerability-report"))
+	})
+
+	It("should ignore a report that cannot be decoded", func() {
+		Expect(resources.CreateVulnerabilityReportConfigMap(context.TODO(), client, buildRunSample, reportResult("invalid"))).To(Succeed())
+		Expect(client.CreateCallCount()).To(Equal(0))
+	})
+})
@@ -6,12 +6,36 @@ package validate
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 
+	imagename "github.com/google/go-containerregistry/pkg/name"
 	build "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
 	"k8s.io/utils/ptr"
 )
 
+var (
+	// tagRegexp matches a valid tag as defined by the OCI distribution specification
+	tagRegexp = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}$`)
+
+	// placeholderRegexp matches a placeholder like $(build.name) that is resolved when the build runs
+	placeholderRegexp = regexp.MustCompile(`\$\([a-zA-Z0-9_.-]+\)`)
+
+	// sourcePlaceholderRegexp matches a placeholder like $(source.git.commitSha) that depends on the source
+	sourcePlaceholderRegexp = regexp.MustCompile(`\$\(source\.[a-zA-Z0-9_.-]+\)`)
+
+	// platformRegexp matches a platform in the format os/architecture[/variant]
+	platformRegexp = regexp.MustCompile(`^[a-z0-9_]+/[a-z0-9_]+(/[a-z0-9_]+)?$`)
+
+	// stopSignalRegexp matches a signal name like SIGTERM, or a signal number
+	stopSignalRegexp = regexp.MustCompile(`^(SIG[A-Z0-9+-]+|[0-9]+)$`)
+)
+
 // BuildSpecOutputValidator implements validation interface to add validations for `build.spec.output`.
 type BuildSpecOutputValidator struct {
 	Build *build.Build // build instance for analysis
@@ -47,9 +71,152 @@ func (b *BuildSpecOutputValidator) ValidatePath(_ context.Context) error {
 		}
 	}
 
+	if vulnerabilityScan := b.Build.Spec.Output.VulnerabilityScan; vulnerabilityScan != nil && vulnerabilityScan.Ignore != nil {
+		for _, document := range vulnerabilityScan.Ignore.VEX {
+			// check that each VEX document references either a ConfigMap key or an image
+			if (document.ConfigMap == nil) == (document.Image == nil) ||
+				document.ConfigMap != nil && (document.ConfigMap.Name == "" || document.ConfigMap.Key == "") ||
+				document.Image != nil && *document.Image == "" {
+				b.Build.Status.Reason = ptr.To[build.BuildReason](build.OutputVEXNotValid)
+				b.Build.Status.Message = ptr.To("each VEX document must reference either a ConfigMap with name and key, or an image")
+				break
+			}
+		}
+	}
+
+	// the build strategy receives the output image, so it cannot contain placeholders that are only resolved
+	// by image-processing after the source step
+	if placeholder := sourcePlaceholderRegexp.FindString(b.Build.Spec.Output.Image); placeholder != "" {
+		b.Build.Status.Reason = ptr.To[build.BuildReason](build.OutputImageNotValid)
+		b.Build.Status.Message = ptr.To(fmt.Sprintf("output image %q cannot contain the placeholder %s, placeholders that depend on the source can only be used in additional tags and mirrors", b.Build.Spec.Output.Image, placeholder))
+	}
+
+	for _, tag := range b.Build.Spec.Output.AdditionalTags {
+		if !tagRegexp.MatchString(withoutPlaceholders(tag)) {
+			b.Build.Status.Reason = ptr.To[build.BuildReason](build.OutputAdditionalTagNotValid)
+			b.Build.Status.Message = ptr.To(fmt.Sprintf("additional tag %q is invalid", tag))
+			break
+		}
+	}
+
+	for _, mirror := range b.Build.Spec.Output.Mirrors {
+		if _, err := imagename.ParseReference(withoutPlaceholders(mirror.Image)); err != nil {
+			b.Build.Status.Reason = ptr.To[build.BuildReason](build.OutputMirrorNotValid)
+			b.Build.Status.Message = ptr.To(fmt.Sprintf("mirror image %q is invalid: %v", mirror.Image, err))
+			break
+		}
+	}
+
+	for _, platform := range b.Build.Spec.Output.Platforms {
+		if !platformRegexp.MatchString(platform) {
+			b.Build.Status.Reason = ptr.To[build.BuildReason](build.OutputPlatformNotValid)
+			b.Build.Status.Message = ptr.To(fmt.Sprintf("platform %q is invalid, must be in the format os/architecture[/variant]", platform))
+			break
+		}
+	}
+
+	if config := b.Build.Spec.Output.Config; config != nil {
+		if err := validateImageConfig(config); err != nil {
+			b.Build.Status.Reason = ptr.To[build.BuildReason](build.OutputImageConfigNotValid)
+			b.Build.Status.Message = ptr.To(fmt.Sprintf("image config is invalid: %v", err))
+		}
+	}
+
+	if b.Build.Spec.Output.Export != nil {
+		if err := validateImageExport(b.Build.Spec.Output); err != nil {
+			b.Build.Status.Reason = ptr.To[build.BuildReason](build.OutputExportNotValid)
+			b.Build.Status.Message = ptr.To(fmt.Sprintf("image export is invalid: %v", err))
+		}
+	}
+
+	return nil
+}
+
+// validateImageExport checks that the export names a volume and a path inside of it, and that the output does
+// not use a feature that pushes to the registry
+func validateImageExport(output build.Image) error {
+	export := output.Export
+
+	if export.PersistentVolumeClaim == "" {
+		return errors.New("the persistent volume claim must be set")
+	}
+
+	if path.IsAbs(export.Path) || slices.Contains(strings.Split(export.Path, "/"), "..") {
+		return fmt.Errorf("the path %q must be relative to the volume", export.Path)
+	}
+
+	switch {
+	case len(output.AdditionalTags) > 0:
+		return errors.New("additional tags cannot be used with an export")
+	case len(output.Mirrors) > 0:
+		return errors.New("mirrors cannot be used with an export")
+	case output.Signing != nil:
+		return errors.New("signing cannot be used with an export")
+	case output.SBOM != nil:
+		return errors.New("an SBOM cannot be used with an export")
+	case output.Provenance != nil && output.Provenance.Enabled:
+		return errors.New("provenance cannot be used with an export")
+	}
+
+	return nil
+}
+
+// validateImageConfig checks that the changes of the image configuration can be applied to an image, and
+// that they do not contradict each other
+func validateImageConfig(config *build.ImageConfig) error {
+	envNames := map[string]bool{}
+	for _, envVar := range config.Env {
+		switch {
+		case envVar.Name == "" || strings.Contains(envVar.Name, "="):
+			return fmt.Errorf("environment variable name %q is invalid", envVar.Name)
+		case envNames[envVar.Name]:
+			return fmt.Errorf("environment variable %q is defined more than once", envVar.Name)
+		}
+		envNames[envVar.Name] = true
+	}
+
+	if config.User != nil && *config.User == "" {
+		return fmt.Errorf("user must not be empty")
+	}
+
+	ports := map[string]bool{}
+	for _, exposedPort := range config.ExposedPorts {
+		port, protocol, found := strings.Cut(exposedPort, "/")
+		if !found {
+			protocol = "tcp"
+		}
+
+		if number, err := strconv.ParseUint(port, 10, 16); err != nil || number == 0 {
+			return fmt.Errorf("exposed port %q has an invalid port number", exposedPort)
+		}
+
+		if protocol != "tcp" && protocol != "udp" && protocol != "sctp" {
+			return fmt.Errorf("exposed port %q has an invalid protocol, must be tcp, udp or sctp", exposedPort)
+		}
+
+		if ports[port+"/"+protocol] {
+			return fmt.Errorf("exposed port %q is defined more than once", exposedPort)
+		}
+		ports[port+"/"+protocol] = true
+	}
+
+	if config.WorkingDir != nil && !path.IsAbs(*config.WorkingDir) {
+		return fmt.Errorf("working directory %q must be an absolute path", *config.WorkingDir)
+	}
+
+	if config.StopSignal != nil && !stopSignalRegexp.MatchString(*config.StopSignal) {
+		return fmt.Errorf("stop signal %q is invalid, must be a signal name like SIGTERM or a number", *config.StopSignal)
+	}
+
 	return nil
 }
 
+// withoutPlaceholders replaces the placeholders with a value that is valid in a tag, so that the rest of the value
+// can be validated
+func withoutPlaceholders(value string) string {
+	return placeholderRegexp.ReplaceAllString(value, "x")
+}
+
 func (b *BuildSpecOutputValidator) isEmptySource() bool {
 	return b.Build.Spec.Source == nil ||
 		b.Build.Spec.Source.Git == nil && b.Build.Spec.Source.OCIArtifact == nil && b.Build.Spec.Source.Local == nil
@@ -11,8 +11,10 @@ import (
 	. "github.com/onsi/gomega"
 
 	corev1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
 	. "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
 	"github.com/shipwright-io/build/pkg/validate"
 )
 
@@ -99,4 +101,408 @@ var _ = Describe("BuildSpecOutputValidator", func() {
 			Expect(*build.Status.Message).To(ContainSubstring("output timestamp value is invalid"))
 		})
 	})
+
+	Context("VEX documents are specified", func() {
+		var sampleBuild = func(documents ...VEXDocument) *Build {
+			return &Build{
+				ObjectMeta: corev1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Spec: BuildSpec{
+					Strategy: Strategy{
+						Name: "magic",
+					},
+					Output: Image{
+						VulnerabilityScan: &VulnerabilityScanOptions{
+							Enabled: true,
+							Ignore: &VulnerabilityIgnoreOptions{
+								VEX: documents,
+							},
+						},
+					},
+				},
+			}
+		}
+
+		It("should pass documents in a ConfigMap and in an image", func() {
+			image := "registry.example.com/vex/some-image:latest"
+			build := sampleBuild(
+				VEXDocument{ConfigMap: &VEXConfigMapKeyRef{Name: "vex", Key: "openvex.json"}},
+				VEXDocument{Image: &image},
+			)
+
+			validate(build)
+			Expect(build.Status.Reason).To(BeNil())
+		})
+
+		It("should fail for a document without reference", func() {
+			build := sampleBuild(VEXDocument{})
+
+			validate(build)
+			Expect(*build.Status.Reason).To(Equal(OutputVEXNotValid))
+			Expect(*build.Status.Message).To(ContainSubstring("each VEX document must reference"))
+		})
+
+		It("should fail for a ConfigMap reference without key", func() {
+			build := sampleBuild(VEXDocument{ConfigMap: &VEXConfigMapKeyRef{Name: "vex"}})
+
+			validate(build)
+			Expect(*build.Status.Reason).To(Equal(OutputVEXNotValid))
+		})
+	})
+
+	Context("additional tags and mirrors are specified", func() {
+		var sampleBuild = func(tags []string, mirrors ...ImageMirror) *Build {
+			return &Build{
+				ObjectMeta: corev1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Spec: BuildSpec{
+					Strategy: Strategy{
+						Name: "magic",
+					},
+					Output: Image{
+						Image:          "registry.example.com/some-namespace/some-image",
+						AdditionalTags: tags,
+						Mirrors:        mirrors,
+					},
+				},
+			}
+		}
+
+		It("should pass valid tags and mirrors", func() {
+			build := sampleBuild([]string{"latest", "v1.2.3_rc.1", "$(source.git.branchName)-$(source.git.commitSha)"}, ImageMirror{Image: "mirror.example.com/some-namespace/some-image:$(buildrun.name)"})
+
+			validate(build)
+			Expect(build.Status.Reason).To(BeNil())
+		})
+
+		It("should fail for an invalid tag", func() {
+			build := sampleBuild([]string{"feature/branch"})
+
+			validate(build)
+			Expect(*build.Status.Reason).To(Equal(OutputAdditionalTagNotValid))
+			Expect(*build.Status.Message).To(ContainSubstring("feature/branch"))
+		})
+
+		It("should fail for a placeholder that depends on the source in the output image", func() {
+			build := sampleBuild(nil)
+			build.Spec.Output.Image = "registry.example.com/some-namespace/some-image:$(source.git.commitSha)"
+
+			validate(build)
+			Expect(*build.Status.Reason).To(Equal(OutputImageNotValid))
+			Expect(*build.Status.Message).To(ContainSubstring("$(source.git.commitSha)"))
+		})
+
+		It("should fail for an invalid mirror", func() {
+			build := sampleBuild(nil, ImageMirror{Image: "mirror.example.com/Some-Image"})
+
+			validate(build)
+			Expect(*build.Status.Reason).To(Equal(OutputMirrorNotValid))
+		})
+	})
+
+	Context("platforms are specified", func() {
+		var sampleBuild = func(platforms ...string) *Build {
+			return &Build{
+				ObjectMeta: corev1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Spec: BuildSpec{
+					Strategy: Strategy{
+						Name: "magic",
+					},
+					Output: Image{
+						Image:     "registry.example.com/some-namespace/some-image",
+						Platforms: platforms,
+					},
+				},
+			}
+		}
+
+		It("should pass valid platforms", func() {
+			build := sampleBuild("linux/amd64", "linux/arm64/v8", "windows/amd64")
+
+			validate(build)
+			Expect(build.Status.Reason).To(BeNil())
+		})
+
+		It("should fail for a platform without architecture", func() {
+			build := sampleBuild("linux")
+
+			validate(build)
+			Expect(*build.Status.Reason).To(Equal(OutputPlatformNotValid))
+			Expect(*build.Status.Message).To(ContainSubstring(`"linux"`))
+		})
+	})
+
+	Context("image config is specified", func() {
+		var sampleBuild = func(config ImageConfig) *Build {
+			return &Build{
+				ObjectMeta: corev1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Spec: BuildSpec{
+					Strategy: Strategy{
+						Name: "magic",
+					},
+					Output: Image{
+						Image:  "registry.example.com/some-namespace/some-image",
+						Config: &config,
+					},
+				},
+			}
+		}
+
+		It("should pass a valid configuration", func() {
+			build := sampleBuild(ImageConfig{
+				Env:          []ImageConfigEnvVar{{Name: "MODE", Value: "production"}, {Name: "EMPTY"}},
+				Entrypoint:   []string{"/app/server"},
+				Cmd:          []string{},
+				User:         ptr.To("1000:1000"),
+				ExposedPorts: []string{"8080", "8080/udp", "9090/tcp"},
+				WorkingDir:   ptr.To("/app"),
+				StopSignal:   ptr.To("SIGRTMIN+3"),
+			})
+
+			validate(build)
+			Expect(build.Status.Reason).To(BeNil())
+		})
+
+		It("should fail for an environment variable that is defined more than once", func() {
+			build := sampleBuild(ImageConfig{
+				Env: []ImageConfigEnvVar{{Name: "MODE", Value: "production"}, {Name: "MODE", Value: "debug"}},
+			})
+
+			validate(build)
+			Expect(*build.Status.Reason).To(Equal(OutputImageConfigNotValid))
+			Expect(*build.Status.Message).To(ContainSubstring("defined more than once"))
+		})
+
+		It("should fail for a port that is exposed twice", func() {
+			build := sampleBuild(ImageConfig{
+				ExposedPorts: []string{"8080", "8080/tcp"},
+			})
+
+			validate(build)
+			Expect(*build.Status.Reason).To(Equal(OutputImageConfigNotValid))
+		})
+
+		It("should fail for an invalid port", func() {
+			build := sampleBuild(ImageConfig{
+				ExposedPorts: []string{"http/tcp"},
+			})
+
+			validate(build)
+			Expect(*build.Status.Reason).To(Equal(OutputImageConfigNotValid))
+		})
+
+		It("should fail for a relative working directory", func() {
+			build := sampleBuild(ImageConfig{
+				WorkingDir: ptr.To("app"),
+			})
+
+			validate(build)
+			Expect(*build.Status.Reason).To(Equal(OutputImageConfigNotValid))
+		})
+
+		It("should fail for an invalid stop signal", func() {
+			build := sampleBuild(ImageConfig{
+				StopSignal: ptr.To("TERM"),
+			})
+
+			validate(build)
+			Expect(*build.Status.Reason).To(Equal(OutputImageConfigNotValid))
+		})
+	})
+
+	Context("image export is specified", func() {
+		var sampleBuild = func(export ImageExport) *Build {
+			return &Build{
+				ObjectMeta: corev1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Spec: BuildSpec{
+					Strategy: Strategy{
+						Name: "magic",
+					},
+					Output: Image{
+						Image:  "registry.example.com/some-namespace/some-image",
+						Export: &export,
+					},
+				},
+			}
+		}
+
+		It("should pass a valid export", func() {
+			build := sampleBuild(ImageExport{
+				Format:                ImageExportFormatOCILayout,
+				PersistentVolumeClaim: "images",
+				Path:                  "builds/some-image",
+			})
+
+			validate(build)
+			Expect(build.Status.Reason).To(BeNil())
+		})
+
+		It("should fail for an export without a persistent volume claim", func() {
+			build := sampleBuild(ImageExport{
+				Format: ImageExportFormatTarball,
+			})
+
+			validate(build)
+			Expect(*build.Status.Reason).To(Equal(OutputExportNotValid))
+			Expect(*build.Status.Message).To(ContainSubstring("persistent volume claim"))
+		})
+
+		It("should fail for a path outside of the volume", func() {
+			build := sampleBuild(ImageExport{
+				Format:                ImageExportFormatTarball,
+				PersistentVolumeClaim: "images",
+				Path:                  "../other",
+			})
+
+			validate(build)
+			Expect(*build.Status.Reason).To(Equal(OutputExportNotValid))
+		})
+
+		It("should fail for an export with additional tags", func() {
+			build := sampleBuild(ImageExport{
+				Format:                ImageExportFormatOCILayout,
+				PersistentVolumeClaim: "images",
+			})
+			build.Spec.Output.AdditionalTags = []string{"latest"}
+
+			validate(build)
+			Expect(*build.Status.Reason).To(Equal(OutputExportNotValid))
+			Expect(*build.Status.Message).To(ContainSubstring("additional tags"))
+		})
+	})
+})
+
+var _ = Describe("BuildRun output", func() {
+	It("should pass a valid image config of the BuildRun", func() {
+		br := &BuildRun{
+			Spec: BuildRunSpec{
+				Build: ReferencedBuild{Name: ptr.To("foo")},
+				Output: &Image{
+					Config: &ImageConfig{
+						Env:        []ImageConfigEnvVar{{Name: "MODE", Value: "production"}},
+						WorkingDir: ptr.To("/app"),
+					},
+				},
+			},
+		}
+
+		reason, message := validate.BuildRunFields(br)
+		Expect(reason).To(BeEmpty())
+		Expect(message).To(BeEmpty())
+	})
+
+	It("should fail for an invalid image config of the BuildRun", func() {
+		br := &BuildRun{
+			Spec: BuildRunSpec{
+				Build: ReferencedBuild{Name: ptr.To("foo")},
+				Output: &Image{
+					Config: &ImageConfig{
+						WorkingDir: ptr.To("app"),
+					},
+				},
+			},
+		}
+
+		reason, message := validate.BuildRunFields(br)
+		Expect(reason).To(Equal(resources.BuildRunOutputImageConfigNotValid))
+		Expect(message).To(Equal(`image config is invalid: working directory "app" must be an absolute path`))
+	})
+
+	It("should fail for an environment variable that the BuildRun defines twice", func() {
+		br := &BuildRun{
+			Spec: BuildRunSpec{
+				Build: ReferencedBuild{Name: ptr.To("foo")},
+				Output: &Image{
+					Config: &ImageConfig{
+						Env: []ImageConfigEnvVar{{Name: "MODE", Value: "production"}, {Name: "MODE", Value: "debug"}},
+					},
+				},
+			},
+		}
+
+		reason, message := validate.BuildRunFields(br)
+		Expect(reason).To(Equal(resources.BuildRunOutputImageConfigNotValid))
+		Expect(message).To(ContainSubstring("defined more than once"))
+	})
+
+	Context("BuildRunOutput", func() {
+		var sampleBuild = func(output Image) *Build {
+			return &Build{
+				ObjectMeta: corev1.ObjectMeta{Name: "foo"},
+				Spec: BuildSpec{
+					Strategy: Strategy{Name: "magic"},
+					Output:   output,
+				},
+			}
+		}
+
+		It("should pass a BuildRun without output", func() {
+			reason, _ := validate.BuildRunOutput(sampleBuild(Image{Image: "registry.example.com/some-image"}), &BuildRun{})
+			Expect(reason).To(BeEmpty())
+		})
+
+		It("should pass an export of the BuildRun for a Build that does not push anything else", func() {
+			reason, _ := validate.BuildRunOutput(sampleBuild(Image{Image: "registry.example.com/some-image"}), &BuildRun{
+				Spec: BuildRunSpec{
+					Output: &Image{
+						Export: &ImageExport{Format: ImageExportFormatOCILayout, PersistentVolumeClaim: "images"},
+					},
+				},
+			})
+			Expect(reason).To(BeEmpty())
+		})
+
+		It("should fail for an export of the BuildRun with the signing of the Build", func() {
+			reason, message := validate.BuildRunOutput(sampleBuild(Image{
+				Image:   "registry.example.com/some-image",
+				Signing: &SigningOptions{},
+			}), &BuildRun{
+				Spec: BuildRunSpec{
+					Output: &Image{
+						Export: &ImageExport{Format: ImageExportFormatOCILayout, PersistentVolumeClaim: "images"},
+					},
+				},
+			})
+			Expect(reason).To(Equal(resources.BuildRunOutputExportNotValid))
+			Expect(message).To(Equal("image export is invalid: signing cannot be used with an export"))
+		})
+
+		It("should fail for an export of the Build with additional tags of the BuildRun", func() {
+			reason, message := validate.BuildRunOutput(sampleBuild(Image{
+				Image:  "registry.example.com/some-image",
+				Export: &ImageExport{Format: ImageExportFormatTarball, PersistentVolumeClaim: "images"},
+			}), &BuildRun{
+				Spec: BuildRunSpec{
+					Output: &Image{AdditionalTags: []string{"latest"}},
+				},
+			})
+			Expect(reason).To(Equal(resources.BuildRunOutputExportNotValid))
+			Expect(message).To(Equal("image export is invalid: additional tags cannot be used with an export"))
+		})
+
+		It("should fail for an export of the BuildRun without a volume", func() {
+			reason, message := validate.BuildRunOutput(sampleBuild(Image{Image: "registry.example.com/some-image"}), &BuildRun{
+				Spec: BuildRunSpec{
+					Output: &Image{
+						Export: &ImageExport{Format: ImageExportFormatOCILayout},
+					},
+				},
+			})
+			Expect(reason).To(Equal(resources.BuildRunOutputExportNotValid))
+			Expect(message).To(Equal("image export is invalid: the persistent volume claim must be set"))
+		})
+	})
 })
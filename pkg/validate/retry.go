@@ -0,0 +1,62 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/utils/ptr"
+
+	build "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+)
+
+// RetryRef contains all required fields
+// to validate a retry
+type RetryRef struct {
+	Build *build.Build // build instance for analysis
+}
+
+func NewRetry(build *build.Build) *RetryRef {
+	return &RetryRef{build}
+}
+
+// ValidatePath implements BuildPath interface and validates
+// that the retry has attempts, a backoff that is not negative,
+// and no reasons that lead to the same result on every attempt
+func (r *RetryRef) ValidatePath(_ context.Context) error {
+	if err := validateRetry(r.Build.Spec.Retry); err != nil {
+		r.Build.Status.Reason = ptr.To(build.RetryNotValid)
+		r.Build.Status.Message = ptr.To(err.Error())
+	}
+
+	return nil
+}
+
+func validateRetry(retry *build.Retry) error {
+	if retry == nil {
+		return nil
+	}
+
+	if retry.MaxAttempts < 1 {
+		return fmt.Errorf("retry maxAttempts must be at least 1")
+	}
+
+	if retry.Backoff != nil && retry.Backoff.Duration < 0 {
+		return fmt.Errorf("retry backoff must not be negative")
+	}
+
+	for _, reason := range retry.Reasons {
+		switch reason {
+		case "":
+			return fmt.Errorf("retry reasons must not be empty")
+
+		case build.BuildRunStateVulnerabilitiesFound, build.BuildRunStateLicenseViolation:
+			return fmt.Errorf("retry reason %s cannot be retried because another attempt leads to the same result", reason)
+		}
+	}
+
+	return nil
+}
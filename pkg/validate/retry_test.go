@@ -0,0 +1,116 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	build "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
+	"github.com/shipwright-io/build/pkg/validate"
+)
+
+var _ = Describe("Retry", func() {
+	Context("ValidatePath", func() {
+		It("should pass when no retry is specified", func() {
+			b := &build.Build{}
+
+			Expect(validate.NewRetry(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(BeNil())
+		})
+
+		It("should pass for a retry with attempts, a backoff and reasons", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Retry: &build.Retry{
+						MaxAttempts: 3,
+						Backoff:     &metav1.Duration{Duration: time.Minute},
+						Reasons:     []string{build.BuildRunStatePodEvicted, build.GitErrorReason},
+					},
+				},
+			}
+
+			Expect(validate.NewRetry(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(BeNil())
+		})
+
+		It("should fail when maxAttempts is smaller than 1", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Retry: &build.Retry{},
+				},
+			}
+
+			Expect(validate.NewRetry(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.RetryNotValid)))
+			Expect(b.Status.Message).To(Equal(ptr.To("retry maxAttempts must be at least 1")))
+		})
+
+		It("should fail when the backoff is negative", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Retry: &build.Retry{
+						MaxAttempts: 2,
+						Backoff:     &metav1.Duration{Duration: -time.Second},
+					},
+				},
+			}
+
+			Expect(validate.NewRetry(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.RetryNotValid)))
+			Expect(b.Status.Message).To(Equal(ptr.To("retry backoff must not be negative")))
+		})
+
+		It("should fail when the reasons contain VulnerabilitiesFound", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Retry: &build.Retry{
+						MaxAttempts: 2,
+						Reasons:     []string{build.BuildRunStateVulnerabilitiesFound},
+					},
+				},
+			}
+
+			Expect(validate.NewRetry(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.RetryNotValid)))
+			Expect(b.Status.Message).To(Equal(ptr.To("retry reason VulnerabilitiesFound cannot be retried because another attempt leads to the same result")))
+		})
+	})
+
+	Context("BuildRunFields", func() {
+		It("should fail for a BuildRun retry without attempts", func() {
+			br := &build.BuildRun{
+				Spec: build.BuildRunSpec{
+					Build: build.ReferencedBuild{Name: ptr.To("foo")},
+					Retry: &build.Retry{},
+				},
+			}
+
+			reason, message := validate.BuildRunFields(br)
+			Expect(reason).To(Equal(resources.BuildRunRetryNotValid))
+			Expect(message).To(Equal("retry maxAttempts must be at least 1"))
+		})
+
+		It("should fail for a retry override with an embedded build spec", func() {
+			br := &build.BuildRun{
+				Spec: build.BuildRunSpec{
+					Build: build.ReferencedBuild{Spec: &build.BuildSpec{}},
+					Retry: &build.Retry{MaxAttempts: 2},
+				},
+			}
+
+			reason, message := validate.BuildRunFields(br)
+			Expect(reason).To(Equal(resources.BuildRunBuildFieldOverrideForbidden))
+			Expect(message).To(Equal("cannot use 'retry' override and 'buildSpec' simultaneously"))
+		})
+	})
+})
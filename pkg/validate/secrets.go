@@ -31,7 +31,7 @@ func NewCredentials(client client.Client, build *build.Build) *Credentials {
 }
 
 // ValidatePath implements BuildPath interface and validates
-// that all referenced secrets under spec exists
+// that all referenced secrets and the ConfigMap of an inline source under spec exists
 func (s Credentials) ValidatePath(ctx context.Context) error {
 	var missingSecrets []string
 	secret := &corev1.Secret{}
@@ -55,6 +55,17 @@ func (s Credentials) ValidatePath(ctx context.Context) error {
 		s.Build.Status.Reason = ptr.To[build.BuildReason](build.MultipleSecretRefNotFound)
 		s.Build.Status.Message = ptr.To(fmt.Sprintf("missing secrets are %s", strings.Join(missingSecrets, ",")))
 	}
+
+	if source := s.Build.Spec.Source; source != nil && source.Type == build.InlineType && source.Inline != nil && source.Inline.ConfigMapRef != nil {
+		configMap := &corev1.ConfigMap{}
+		if err := s.Client.Get(ctx, types.NamespacedName{Name: *source.Inline.ConfigMapRef, Namespace: s.Build.Namespace}, configMap); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		} else if apierrors.IsNotFound(err) {
+			s.Build.Status.Reason = ptr.To(build.SpecSourceInlineConfigMapRefNotFound)
+			s.Build.Status.Message = ptr.To(fmt.Sprintf("referenced configmap %s not found", *source.Inline.ConfigMapRef))
+		}
+	}
+
 	return nil
 }
 
@@ -65,8 +76,22 @@ func (s Credentials) buildCredentialReferences() map[string]build.BuildReason {
 		secretRefMap[*s.Build.Spec.Output.PushSecret] = build.SpecOutputSecretRefNotFound
 	}
 
+	for _, mirror := range s.Build.Spec.Output.Mirrors {
+		if mirror.PushSecret != nil {
+			secretRefMap[*mirror.PushSecret] = build.SpecOutputSecretRefNotFound
+		}
+	}
+
+	if s.Build.Spec.Output.Signing != nil {
+		secretRefMap[s.Build.Spec.Output.Signing.KeySecret] = build.SpecOutputSigningSecretRefNotFound
+	}
+
 	if s.Build.GetSourceCredentials() != nil {
 		secretRefMap[*s.Build.GetSourceCredentials()] = build.SpecSourceSecretRefNotFound
 	}
+
+	if source := s.Build.Spec.Source; source != nil && source.Type == build.InlineType && source.Inline != nil && source.Inline.SecretRef != nil {
+		secretRefMap[*source.Inline.SecretRef] = build.SpecSourceInlineSecretRefNotFound
+	}
 	return secretRefMap
 }
@@ -0,0 +1,92 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+
+	build "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/controller/fakes"
+	"github.com/shipwright-io/build/pkg/validate"
+)
+
+var _ = Describe("Credentials", func() {
+	var client *fakes.FakeClient
+
+	// existing returns a get stub for which only the given Secrets and ConfigMaps exist
+	existing := func(secrets []string, configMaps []string) func(context.Context, types.NamespacedName, crc.Object, ...crc.GetOption) error {
+		return func(_ context.Context, nn types.NamespacedName, object crc.Object, _ ...crc.GetOption) error {
+			switch object.(type) {
+			case *corev1.Secret:
+				for _, secret := range secrets {
+					if secret == nn.Name {
+						return nil
+					}
+				}
+			case *corev1.ConfigMap:
+				for _, configMap := range configMaps {
+					if configMap == nn.Name {
+						return nil
+					}
+				}
+			}
+
+			return errors.NewNotFound(schema.GroupResource{}, nn.Name)
+		}
+	}
+
+	inlineBuild := func(inline *build.Inline) *build.Build {
+		return &build.Build{
+			Spec: build.BuildSpec{
+				Source: &build.Source{
+					Type:   build.InlineType,
+					Inline: inline,
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		client = &fakes.FakeClient{}
+	})
+
+	Context("inline source", func() {
+		It("should pass when the referenced Secret and ConfigMap exist", func() {
+			client.GetCalls(existing([]string{"inline-secret"}, []string{"inline-config"}))
+			b := inlineBuild(&build.Inline{SecretRef: ptr.To("inline-secret"), ConfigMapRef: ptr.To("inline-config")})
+
+			Expect(validate.NewCredentials(client, b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(BeNil())
+		})
+
+		It("should fail when the referenced Secret does not exist", func() {
+			client.GetCalls(existing(nil, nil))
+			b := inlineBuild(&build.Inline{SecretRef: ptr.To("inline-secret")})
+
+			Expect(validate.NewCredentials(client, b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.SpecSourceInlineSecretRefNotFound)))
+			Expect(b.Status.Message).To(Equal(ptr.To("referenced secret inline-secret not found")))
+		})
+
+		It("should fail when the referenced ConfigMap does not exist", func() {
+			client.GetCalls(existing(nil, nil))
+			b := inlineBuild(&build.Inline{ConfigMapRef: ptr.To("inline-config")})
+
+			Expect(validate.NewCredentials(client, b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.SpecSourceInlineConfigMapRefNotFound)))
+			Expect(b.Status.Message).To(Equal(ptr.To("referenced configmap inline-config not found")))
+		})
+	})
+})
@@ -6,9 +6,24 @@ package validate
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
 
 	build "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources/sources"
+)
+
+const (
+	// inlineFilesMaxSize is the maximum size of all inline files together, they
+	// are passed base64 encoded as arguments to the step and therefore become
+	// part of the Pod
+	inlineFilesMaxSize = 64 * 1024
 )
 
 // SourcesRef implements RuntimeRef interface to add validations for `build.spec.source`.
@@ -19,7 +34,24 @@ type SourceRef struct {
 // ValidatePath executes the validation routine, inspecting the `build.spec.source` path
 func (s *SourceRef) ValidatePath(_ context.Context) error {
 	if s.Build.Spec.Source != nil {
-		return s.validateSourceEntry(s.Build.Spec.Source)
+		if err := s.validateSourceEntry(s.Build.Spec.Source); err != nil {
+			return err
+		}
+
+		if err := validateObjectStorage(s.Build.Spec.Source); err != nil {
+			s.Build.Status.Reason = ptr.To(build.ObjectStorageSourceNotValid)
+			s.Build.Status.Message = ptr.To(err.Error())
+		}
+
+		if err := validateInline(s.Build.Spec.Source); err != nil {
+			s.Build.Status.Reason = ptr.To(build.InlineSourceNotValid)
+			s.Build.Status.Message = ptr.To(err.Error())
+		}
+
+		if err := validateAdditionalLocals(s.Build.Spec.Source.Type, s.Build.Spec.Source.AdditionalLocals); err != nil {
+			s.Build.Status.Reason = ptr.To(build.AdditionalLocalSourcesNotValid)
+			s.Build.Status.Message = ptr.To(err.Error())
+		}
 	}
 
 	return nil
@@ -29,22 +61,29 @@ func (s *SourceRef) ValidatePath(_ context.Context) error {
 func (s *SourceRef) validateSourceEntry(source *build.Source) error {
 
 	// dont bail out if the Source object is empty, we preserve the old behaviour as in v1alpha1
-	if source.Type == "" && source.Git == nil &&
-		source.OCIArtifact == nil && source.Local == nil {
+	if source.Type == "" && numberOfSources(source) == 0 {
 		return nil
 	}
 
 	switch source.Type {
 	case "Git":
-		if source.Git == nil || source.OCIArtifact != nil || source.Local != nil {
+		if source.Git == nil || numberOfSources(source) != 1 {
 			return fmt.Errorf("type does not match the source")
 		}
 	case "OCI":
-		if source.OCIArtifact == nil || source.Git != nil || source.Local != nil {
+		if source.OCIArtifact == nil || numberOfSources(source) != 1 {
 			return fmt.Errorf("type does not match the source")
 		}
 	case "Local":
-		if source.Local == nil || source.OCIArtifact != nil || source.Git != nil {
+		if source.Local == nil || numberOfSources(source) != 1 {
+			return fmt.Errorf("type does not match the source")
+		}
+	case "ObjectStorage":
+		if source.ObjectStorage == nil || numberOfSources(source) != 1 {
+			return fmt.Errorf("type does not match the source")
+		}
+	case "Inline":
+		if source.Inline == nil || numberOfSources(source) != 1 {
 			return fmt.Errorf("type does not match the source")
 		}
 	case "":
@@ -53,6 +92,121 @@ func (s *SourceRef) validateSourceEntry(source *build.Source) error {
 	return nil
 }
 
+// numberOfSources returns how many of the different source types are defined
+func numberOfSources(source *build.Source) int {
+	var count int
+	for _, defined := range []bool{
+		source.Git != nil,
+		source.OCIArtifact != nil,
+		source.Local != nil,
+		source.ObjectStorage != nil,
+		source.Inline != nil,
+	} {
+		if defined {
+			count++
+		}
+	}
+
+	return count
+}
+
+// validateAdditionalLocals checks that additional local sources are only used with a source of
+// type Local, and that their names are unique and usable as step name and result name.
+func validateAdditionalLocals(sourceType build.BuildSourceType, additionalLocals []build.Local) error {
+	if len(additionalLocals) == 0 {
+		return nil
+	}
+
+	if sourceType != build.LocalType {
+		return fmt.Errorf("additional local sources can only be used with a source of type %s", build.LocalType)
+	}
+
+	names := map[string]bool{}
+	for _, additionalLocal := range additionalLocals {
+		switch {
+		case additionalLocal.Name == "":
+			return fmt.Errorf("additional local sources must have a name")
+
+		case additionalLocal.Name == sources.DefaultSourceName:
+			return fmt.Errorf("additional local source name %q is reserved", sources.DefaultSourceName)
+
+		case names[additionalLocal.Name]:
+			return fmt.Errorf("additional local source name %q is not unique", additionalLocal.Name)
+		}
+
+		if errs := validation.IsDNS1123Label(sources.LocalCopyStepName(additionalLocal.Name)); len(errs) > 0 {
+			return fmt.Errorf("additional local source name %q is invalid: %s", additionalLocal.Name, strings.Join(errs, ", "))
+		}
+
+		names[additionalLocal.Name] = true
+	}
+
+	return nil
+}
+
+// validateObjectStorage checks that an object storage source defines where to
+// download from, and that it selects either a single object or a prefix.
+func validateObjectStorage(source *build.Source) error {
+	if source.Type != build.ObjectStorageType || source.ObjectStorage == nil {
+		return nil
+	}
+
+	objectStorage := source.ObjectStorage
+	switch {
+	case objectStorage.Endpoint == "":
+		return fmt.Errorf("object storage endpoint must be specified")
+
+	case objectStorage.Bucket == "":
+		return fmt.Errorf("object storage bucket must be specified")
+
+	case (objectStorage.Key == nil || *objectStorage.Key == "") == (objectStorage.Prefix == nil || *objectStorage.Prefix == ""):
+		return fmt.Errorf("exactly one of object storage key and prefix must be specified")
+
+	case objectStorage.VersionID != nil && objectStorage.Key == nil:
+		return fmt.Errorf("object storage versionId can only be specified together with key")
+	}
+
+	if u, err := url.Parse(objectStorage.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("object storage endpoint %q must be a http or https URL", objectStorage.Endpoint)
+	}
+
+	return nil
+}
+
+// validateInline checks that an inline source defines files, a ConfigMap or a Secret,
+// that the file paths are relative and do not contain the separator of the step argument,
+// and that the encoded files do not exceed the size limit.
+func validateInline(source *build.Source) error {
+	if source.Type != build.InlineType || source.Inline == nil {
+		return nil
+	}
+
+	inline := source.Inline
+	if len(inline.Files) == 0 && inline.ConfigMapRef == nil && inline.SecretRef == nil {
+		return fmt.Errorf("inline source must specify files, a configMapRef or a secretRef")
+	}
+
+	var size int
+	for path, content := range inline.Files {
+		if path == "" || !filepath.IsLocal(path) {
+			return fmt.Errorf("inline file path %q must be a relative path inside the source directory", path)
+		}
+
+		if strings.Contains(path, "=") {
+			return fmt.Errorf("inline file path %q must not contain an equal sign", path)
+		}
+
+		// the step argument has the format path=base64(content)
+		size += len(path) + 1 + base64.StdEncoding.EncodedLen(len(content))
+	}
+
+	if size > inlineFilesMaxSize {
+		return fmt.Errorf("inline files have an encoded size of %d bytes which exceeds the limit of %d bytes", size, inlineFilesMaxSize)
+	}
+
+	return nil
+}
+
 // NewSourcesRef instantiate a new SourcesRef passing the build object pointer along.
 func NewSourceRef(b *build.Build) *SourceRef {
 	return &SourceRef{Build: b}
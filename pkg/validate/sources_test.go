@@ -6,9 +6,11 @@ package validate_test
 
 import (
 	"context"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
 
 	build "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
 	"github.com/shipwright-io/build/pkg/validate"
@@ -59,5 +61,269 @@ var _ = Describe("SourcesRef", func() {
 
 			Expect(srcRef.ValidatePath(context.TODO())).To(HaveOccurred())
 		})
+
+		It("should successfully validate an object storage source with a key", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type: build.ObjectStorageType,
+						ObjectStorage: &build.ObjectStorage{
+							Endpoint:  "http://minio.minio.svc:9000",
+							Bucket:    "sources",
+							Key:       ptr.To("source.tar.gz"),
+							VersionID: ptr.To("version-1"),
+						},
+					},
+				},
+			}
+
+			Expect(validate.NewSourceRef(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(BeNil())
+		})
+
+		It("should fail to validate an object storage source with another source type", func() {
+			srcRef := validate.NewSourceRef(&build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type:          build.GitType,
+						Git:           &build.Git{URL: "https://github.com/shipwright-io/sample-go"},
+						ObjectStorage: &build.ObjectStorage{},
+					},
+				},
+			})
+
+			Expect(srcRef.ValidatePath(context.TODO())).To(HaveOccurred())
+		})
+
+		It("should fail to validate an object storage source with both key and prefix", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type: build.ObjectStorageType,
+						ObjectStorage: &build.ObjectStorage{
+							Endpoint: "http://minio.minio.svc:9000",
+							Bucket:   "sources",
+							Key:      ptr.To("source.tar.gz"),
+							Prefix:   ptr.To("builds/"),
+						},
+					},
+				},
+			}
+
+			Expect(validate.NewSourceRef(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.ObjectStorageSourceNotValid)))
+			Expect(*b.Status.Message).To(Equal("exactly one of object storage key and prefix must be specified"))
+		})
+
+		It("should fail to validate an object storage source with a version and a prefix", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type: build.ObjectStorageType,
+						ObjectStorage: &build.ObjectStorage{
+							Endpoint:  "http://minio.minio.svc:9000",
+							Bucket:    "sources",
+							Prefix:    ptr.To("builds/"),
+							VersionID: ptr.To("version-1"),
+						},
+					},
+				},
+			}
+
+			Expect(validate.NewSourceRef(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.ObjectStorageSourceNotValid)))
+		})
+
+		It("should fail to validate an object storage source with an endpoint that is no URL", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type: build.ObjectStorageType,
+						ObjectStorage: &build.ObjectStorage{
+							Endpoint: "minio.minio.svc:9000",
+							Bucket:   "sources",
+							Key:      ptr.To("source.tar.gz"),
+						},
+					},
+				},
+			}
+
+			Expect(validate.NewSourceRef(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.ObjectStorageSourceNotValid)))
+		})
+
+		It("should successfully validate an inline source with files", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type: build.InlineType,
+						Inline: &build.Inline{
+							Files: map[string]string{
+								"Dockerfile": "FROM registry.access.redhat.com/ubi9/ubi-minimal",
+							},
+						},
+					},
+				},
+			}
+
+			Expect(validate.NewSourceRef(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(BeNil())
+		})
+
+		It("should fail to validate an inline source without any content", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type:   build.InlineType,
+						Inline: &build.Inline{},
+					},
+				},
+			}
+
+			Expect(validate.NewSourceRef(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.InlineSourceNotValid)))
+		})
+
+		It("should fail to validate an inline source with a file outside of the source directory", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type: build.InlineType,
+						Inline: &build.Inline{
+							Files: map[string]string{
+								"../Dockerfile": "FROM scratch",
+							},
+						},
+					},
+				},
+			}
+
+			Expect(validate.NewSourceRef(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.InlineSourceNotValid)))
+		})
+
+		It("should fail to validate an inline source with a file path that contains an equal sign", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type: build.InlineType,
+						Inline: &build.Inline{
+							Files: map[string]string{
+								"config/key=value.yaml": "replicas: 2",
+							},
+						},
+					},
+				},
+			}
+
+			Expect(validate.NewSourceRef(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.InlineSourceNotValid)))
+			Expect(b.Status.Message).To(Equal(ptr.To(`inline file path "config/key=value.yaml" must not contain an equal sign`)))
+		})
+
+		It("should fail to validate an inline source with files that exceed the size limit", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type: build.InlineType,
+						Inline: &build.Inline{
+							Files: map[string]string{
+								"data.txt": strings.Repeat("x", 64*1024),
+							},
+						},
+					},
+				},
+			}
+
+			Expect(validate.NewSourceRef(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.InlineSourceNotValid)))
+			Expect(*b.Status.Message).To(ContainSubstring("exceeds the limit of 65536 bytes"))
+		})
+
+		It("should fail to validate inline files whose base64 encoding exceeds the size limit", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type: build.InlineType,
+						Inline: &build.Inline{
+							Files: map[string]string{
+								"data.txt": strings.Repeat("x", 50*1024),
+							},
+						},
+					},
+				},
+			}
+
+			Expect(validate.NewSourceRef(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.InlineSourceNotValid)))
+			Expect(*b.Status.Message).To(Equal("inline files have an encoded size of 68277 bytes which exceeds the limit of 65536 bytes"))
+		})
+
+		It("should successfully validate additional local sources with unique names", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type:  build.LocalType,
+						Local: &build.Local{Name: "code"},
+						AdditionalLocals: []build.Local{
+							{Name: "artifacts"},
+							{Name: "config"},
+						},
+					},
+				},
+			}
+
+			Expect(validate.NewSourceRef(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(BeNil())
+		})
+
+		It("should fail to validate additional local sources for a source that is not of type Local", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type:             build.GitType,
+						Git:              &build.Git{},
+						AdditionalLocals: []build.Local{{Name: "artifacts"}},
+					},
+				},
+			}
+
+			Expect(validate.NewSourceRef(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.AdditionalLocalSourcesNotValid)))
+		})
+
+		It("should fail to validate additional local sources with duplicate names", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type:  build.LocalType,
+						Local: &build.Local{Name: "code"},
+						AdditionalLocals: []build.Local{
+							{Name: "artifacts"},
+							{Name: "artifacts"},
+						},
+					},
+				},
+			}
+
+			Expect(validate.NewSourceRef(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.AdditionalLocalSourcesNotValid)))
+			Expect(*b.Status.Message).To(ContainSubstring("not unique"))
+		})
+
+		It("should fail to validate additional local sources with names that cannot be used as step name", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					Source: &build.Source{
+						Type:             build.LocalType,
+						Local:            &build.Local{Name: "code"},
+						AdditionalLocals: []build.Local{{Name: "Some_Artifacts"}},
+					},
+				},
+			}
+
+			Expect(validate.NewSourceRef(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.AdditionalLocalSourcesNotValid)))
+		})
 	})
 })
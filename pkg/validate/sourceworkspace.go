@@ -0,0 +1,54 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/utils/ptr"
+
+	build "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+)
+
+// SourceWorkspaceRef contains all required fields
+// to validate a source workspace
+type SourceWorkspaceRef struct {
+	Build *build.Build // build instance for analysis
+}
+
+func NewSourceWorkspace(build *build.Build) *SourceWorkspaceRef {
+	return &SourceWorkspaceRef{build}
+}
+
+// ValidatePath implements BuildPath interface and validates
+// that the source workspace uses either a claim template or an existing claim
+func (s *SourceWorkspaceRef) ValidatePath(_ context.Context) error {
+	if err := validateSourceWorkspace(s.Build.Spec.SourceWorkspace); err != nil {
+		s.Build.Status.Reason = ptr.To(build.SourceWorkspaceNotValid)
+		s.Build.Status.Message = ptr.To(err.Error())
+	}
+
+	return nil
+}
+
+func validateSourceWorkspace(sourceWorkspace *build.SourceWorkspace) error {
+	if sourceWorkspace == nil {
+		return nil
+	}
+
+	switch {
+	case (sourceWorkspace.VolumeClaimTemplate == nil) == (sourceWorkspace.PersistentVolumeClaimName == nil):
+		return fmt.Errorf("exactly one of sourceWorkspace volumeClaimTemplate and persistentVolumeClaimName must be specified")
+
+	case sourceWorkspace.PersistentVolumeClaimName != nil && *sourceWorkspace.PersistentVolumeClaimName == "":
+		return fmt.Errorf("sourceWorkspace persistentVolumeClaimName must not be empty")
+
+	case sourceWorkspace.DeletionPolicy != nil && sourceWorkspace.VolumeClaimTemplate == nil:
+		return fmt.Errorf("sourceWorkspace deletionPolicy can only be specified together with volumeClaimTemplate")
+	}
+
+	return nil
+}
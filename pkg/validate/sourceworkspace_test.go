@@ -0,0 +1,112 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	build "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
+	"github.com/shipwright-io/build/pkg/validate"
+)
+
+var _ = Describe("SourceWorkspace", func() {
+	Context("ValidatePath", func() {
+		It("should pass when no source workspace is specified", func() {
+			b := &build.Build{}
+
+			Expect(validate.NewSourceWorkspace(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(BeNil())
+		})
+
+		It("should pass for a volume claim template with a deletion policy", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					SourceWorkspace: &build.SourceWorkspace{
+						VolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{},
+						DeletionPolicy:      ptr.To(build.SourceWorkspaceDeleteOnSuccess),
+					},
+				},
+			}
+
+			Expect(validate.NewSourceWorkspace(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(BeNil())
+		})
+
+		It("should fail when neither a volume claim template nor a claim name is specified", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					SourceWorkspace: &build.SourceWorkspace{},
+				},
+			}
+
+			Expect(validate.NewSourceWorkspace(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.SourceWorkspaceNotValid)))
+			Expect(b.Status.Message).To(Equal(ptr.To("exactly one of sourceWorkspace volumeClaimTemplate and persistentVolumeClaimName must be specified")))
+		})
+
+		It("should fail when both a volume claim template and a claim name are specified", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					SourceWorkspace: &build.SourceWorkspace{
+						VolumeClaimTemplate:       &corev1.PersistentVolumeClaimSpec{},
+						PersistentVolumeClaimName: ptr.To("shared"),
+					},
+				},
+			}
+
+			Expect(validate.NewSourceWorkspace(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.SourceWorkspaceNotValid)))
+		})
+
+		It("should fail when a deletion policy is specified for an existing claim", func() {
+			b := &build.Build{
+				Spec: build.BuildSpec{
+					SourceWorkspace: &build.SourceWorkspace{
+						PersistentVolumeClaimName: ptr.To("shared"),
+						DeletionPolicy:            ptr.To(build.SourceWorkspaceRetain),
+					},
+				},
+			}
+
+			Expect(validate.NewSourceWorkspace(b).ValidatePath(context.TODO())).To(Succeed())
+			Expect(b.Status.Reason).To(Equal(ptr.To(build.SourceWorkspaceNotValid)))
+			Expect(b.Status.Message).To(Equal(ptr.To("sourceWorkspace deletionPolicy can only be specified together with volumeClaimTemplate")))
+		})
+	})
+
+	Context("BuildRunFields", func() {
+		It("should fail for an invalid source workspace in the BuildRun", func() {
+			br := &build.BuildRun{
+				Spec: build.BuildRunSpec{
+					Build:           build.ReferencedBuild{Name: ptr.To("foo")},
+					SourceWorkspace: &build.SourceWorkspace{PersistentVolumeClaimName: ptr.To("")},
+				},
+			}
+
+			reason, message := validate.BuildRunFields(br)
+			Expect(reason).To(Equal(resources.BuildRunSourceWorkspaceNotValid))
+			Expect(message).To(Equal("sourceWorkspace persistentVolumeClaimName must not be empty"))
+		})
+
+		It("should fail for a source workspace override together with an embedded build", func() {
+			br := &build.BuildRun{
+				Spec: build.BuildRunSpec{
+					Build:           build.ReferencedBuild{Spec: &build.BuildSpec{}},
+					SourceWorkspace: &build.SourceWorkspace{PersistentVolumeClaimName: ptr.To("shared")},
+				},
+			}
+
+			reason, _ := validate.BuildRunFields(br)
+			Expect(reason).To(Equal(resources.BuildRunBuildFieldOverrideForbidden))
+		})
+	})
+})
@@ -37,6 +37,10 @@ const (
 	Triggers = "triggers"
 	// NodeSelector for validating `spec.nodeSelector` entry
 	NodeSelector = "nodeselector"
+	// SourceWorkspace for validating `spec.sourceWorkspace` entry
+	SourceWorkspace = "sourceworkspace"
+	// Retry for validating `spec.retry` entry
+	Retry = "retry"
 )
 
 const (
@@ -79,6 +83,10 @@ func NewValidation(
 		return &Trigger{build: build}, nil
 	case NodeSelector:
 		return &NodeSelectorRef{Build: build}, nil
+	case SourceWorkspace:
+		return &SourceWorkspaceRef{Build: build}, nil
+	case Retry:
+		return &RetryRef{Build: build}, nil
 	default:
 		return nil, fmt.Errorf("unknown validation type")
 	}
@@ -103,6 +111,27 @@ func BuildRunFields(buildRun *build.BuildRun) (string, string) {
 			"no build referenced or specified, either 'buildRef' or 'buildSpec' has to be set"
 	}
 
+	if buildRun.Spec.Source != nil {
+		if err := validateAdditionalLocals(buildRun.Spec.Source.Type, buildRun.Spec.Source.AdditionalLocals); err != nil {
+			return resources.BuildRunAdditionalLocalSourcesNotValid, err.Error()
+		}
+	}
+
+	if err := validateSourceWorkspace(buildRun.Spec.SourceWorkspace); err != nil {
+		return resources.BuildRunSourceWorkspaceNotValid, err.Error()
+	}
+
+	if err := validateRetry(buildRun.Spec.Retry); err != nil {
+		return resources.BuildRunRetryNotValid, err.Error()
+	}
+
+	// the image config of the BuildRun takes precedence over the one of the Build, and is validated the same way
+	if buildRun.Spec.Output != nil && buildRun.Spec.Output.Config != nil {
+		if err := validateImageConfig(buildRun.Spec.Output.Config); err != nil {
+			return resources.BuildRunOutputImageConfigNotValid, fmt.Sprintf("image config is invalid: %v", err)
+		}
+	}
+
 	if buildRun.Spec.Build.Spec != nil {
 		if buildRun.Spec.Build.Name != nil {
 			return resources.BuildRunAmbiguousBuild,
@@ -129,6 +158,16 @@ func BuildRunFields(buildRun *build.BuildRun) (string, string) {
 				"cannot use 'timeout' override and 'buildSpec' simultaneously"
 		}
 
+		if buildRun.Spec.SourceWorkspace != nil {
+			return resources.BuildRunBuildFieldOverrideForbidden,
+				"cannot use 'sourceWorkspace' override and 'buildSpec' simultaneously"
+		}
+
+		if buildRun.Spec.Retry != nil {
+			return resources.BuildRunBuildFieldOverrideForbidden,
+				"cannot use 'retry' override and 'buildSpec' simultaneously"
+		}
+
 		if buildRun.Spec.Build.Spec.Trigger != nil {
 			return resources.BuildRunBuildFieldOverrideForbidden,
 				"cannot use 'triggers' override in the 'BuildRun', only allowed in the 'Build'"
@@ -137,3 +176,41 @@ func BuildRunFields(buildRun *build.BuildRun) (string, string) {
 
 	return "", ""
 }
+
+// BuildRunOutput validates the output of the Build combined with the overrides of the BuildRun, because
+// features that push to the registry of one cannot be combined with an export of the other
+func BuildRunOutput(b *build.Build, buildRun *build.BuildRun) (string, string) {
+	if buildRun.Spec.Output == nil {
+		return "", ""
+	}
+
+	// the fields of the BuildRun take precedence like in the image-processing step
+	output := b.Spec.Output
+	override := buildRun.Spec.Output
+	if override.Export != nil {
+		output.Export = override.Export
+	}
+	if len(override.AdditionalTags) > 0 {
+		output.AdditionalTags = override.AdditionalTags
+	}
+	if len(override.Mirrors) > 0 {
+		output.Mirrors = override.Mirrors
+	}
+	if override.Signing != nil {
+		output.Signing = override.Signing
+	}
+	if override.SBOM != nil {
+		output.SBOM = override.SBOM
+	}
+	if override.Provenance != nil {
+		output.Provenance = override.Provenance
+	}
+
+	if output.Export != nil {
+		if err := validateImageExport(output); err != nil {
+			return resources.BuildRunOutputExportNotValid, fmt.Sprintf("image export is invalid: %v", err)
+		}
+	}
+
+	return "", ""
+}
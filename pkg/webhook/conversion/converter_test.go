@@ -533,6 +533,12 @@ request:
 								Duration: 1 * time.Minute,
 							},
 						},
+						AdditionalLocals: []v1beta1.Local{{
+							Name: "foobar_local_two",
+							Timeout: &v1.Duration{
+								Duration: 1 * time.Minute,
+							},
+						}},
 					},
 				},
 			}
@@ -180,6 +180,8 @@ var _ = Describe("Integration tests Build and TaskRun", func() {
 					"$(results.shp-image-size.path)",
 					"--result-file-image-vulnerabilities",
 					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
 				}))
 			})
 
@@ -590,6 +590,8 @@ var _ = Describe("Integration tests BuildStrategies and TaskRuns", func() {
 					"$(results.shp-image-size.path)",
 					"--result-file-image-vulnerabilities",
 					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
 				}))
 			})
 		})
@@ -648,6 +650,8 @@ var _ = Describe("Integration tests BuildStrategies and TaskRuns", func() {
 					"$(results.shp-image-size.path)",
 					"--result-file-image-vulnerabilities",
 					"$(results.shp-image-vulnerabilities.path)",
+					"--result-file-image-platforms",
+					"$(results.shp-image-platforms.path)",
 					"--secret-path",
 					"/workspace/shp-push-secret",
 				}))
@@ -0,0 +1,183 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"crypto/md5" // #nosec G501 S3 uses the MD5 of the content as ETag
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeObjectStorage is an in-memory implementation of the subset of the S3 API that
+// is used to download objects, it uses path-style requests like MinIO does
+type FakeObjectStorage struct {
+	*httptest.Server
+
+	// AccessKeyID, if set, is required in the credentials of signed requests
+	AccessKeyID string
+
+	// MaxKeys is the maximum number of keys returned in one object listing
+	MaxKeys int
+
+	lock    sync.Mutex
+	objects map[string]map[string][]fakeObject
+}
+
+type fakeObject struct {
+	content      []byte
+	etag         string
+	versionID    string
+	lastModified time.Time
+}
+
+// StartFakeObjectStorage starts a fake object storage that requires requests to be
+// signed with the provided access key, an empty access key allows anonymous access
+func StartFakeObjectStorage(accessKeyID string) *FakeObjectStorage {
+	f := &FakeObjectStorage{
+		AccessKeyID: accessKeyID,
+		MaxKeys:     1000,
+		objects:     map[string]map[string][]fakeObject{},
+	}
+
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+
+	return f
+}
+
+// PutObject stores a new version of an object and returns its ETag
+func (f *FakeObjectStorage) PutObject(bucket string, key string, content []byte, versionID string, lastModified time.Time) string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.objects[bucket] == nil {
+		f.objects[bucket] = map[string][]fakeObject{}
+	}
+
+	sum := md5.Sum(content) // #nosec G401 S3 uses the MD5 of the content as ETag
+	etag := hex.EncodeToString(sum[:])
+
+	f.objects[bucket][key] = append(f.objects[bucket][key], fakeObject{
+		content:      content,
+		etag:         etag,
+		versionID:    versionID,
+		lastModified: lastModified.UTC().Truncate(time.Second),
+	})
+
+	return etag
+}
+
+func (f *FakeObjectStorage) handle(w http.ResponseWriter, r *http.Request) {
+	if f.AccessKeyID != "" && !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="+f.AccessKeyID+"/") {
+		writeFakeObjectStorageError(w, http.StatusForbidden, "AccessDenied", "Access Denied.")
+		return
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	objects, ok := f.objects[bucket]
+	if !ok {
+		writeFakeObjectStorageError(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+
+	if key == "" {
+		f.list(w, r, objects)
+		return
+	}
+
+	versions, ok := objects[key]
+	if !ok {
+		writeFakeObjectStorageError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+
+	object := versions[len(versions)-1]
+	if versionID := r.URL.Query().Get("versionId"); versionID != "" {
+		found := false
+		for _, version := range versions {
+			if version.versionID == versionID {
+				object, found = version, true
+			}
+		}
+
+		if !found {
+			writeFakeObjectStorageError(w, http.StatusNotFound, "NoSuchVersion", "The specified version does not exist.")
+			return
+		}
+	}
+
+	w.Header().Set("ETag", strconv.Quote(object.etag))
+	w.Header().Set("Last-Modified", object.lastModified.Format(http.TimeFormat))
+	w.Header().Set("Content-Length", strconv.Itoa(len(object.content)))
+	if object.versionID != "" {
+		w.Header().Set("X-Amz-Version-Id", object.versionID)
+	}
+
+	_, _ = w.Write(object.content)
+}
+
+func (f *FakeObjectStorage) list(w http.ResponseWriter, r *http.Request, objects map[string][]fakeObject) {
+	type content struct {
+		Key          string `xml:"Key"`
+		ETag         string `xml:"ETag"`
+		LastModified string `xml:"LastModified"`
+		Size         int    `xml:"Size"`
+	}
+
+	type listBucketResult struct {
+		XMLName               xml.Name  `xml:"ListBucketResult"`
+		IsTruncated           bool      `xml:"IsTruncated"`
+		NextContinuationToken string    `xml:"NextContinuationToken,omitempty"`
+		Contents              []content `xml:"Contents"`
+	}
+
+	prefix := r.URL.Query().Get("prefix")
+	continuationToken := r.URL.Query().Get("continuation-token")
+
+	var keys []string
+	for key := range objects {
+		if strings.HasPrefix(key, prefix) && key > continuationToken {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	result := listBucketResult{}
+	if len(keys) > f.MaxKeys {
+		keys = keys[:f.MaxKeys]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+
+	for _, key := range keys {
+		object := objects[key][len(objects[key])-1]
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			ETag:         strconv.Quote(object.etag),
+			LastModified: object.lastModified.Format(time.RFC3339),
+			Size:         len(object.content),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func writeFakeObjectStorageError(w http.ResponseWriter, statusCode int, code string, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(statusCode)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, message)
+}